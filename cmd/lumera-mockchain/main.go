@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/mockchain"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+)
+
+// lumera-mockchain serves a fake LCD for running lumera-supply and its frontends
+// locally: point the service at it with -lcd http://localhost:1317.
+func main() {
+	var (
+		addr       = flag.String("addr", getEnv("LUMERA_MOCK_ADDR", ":1317"), "HTTP listen address")
+		statePath  = flag.String("state", getEnv("LUMERA_MOCK_STATE", ""), "Chain state JSON file (default: a small built-in chain)")
+		policyPath = flag.String("policy", getEnv("LUMERA_MOCK_POLICY", ""), "Supply policy whose addresses and module accounts get accounts on the mock chain (optional)")
+		claims     = flag.Int("claims", 1000, "Claim records in the built-in chain, spread over the four tiers")
+		printState = flag.Bool("print-state", false, "Print the chain state as JSON and exit, as a starting point for -state")
+	)
+	flag.Parse()
+
+	now := time.Now()
+	st := mockchain.Default(now, *claims)
+	if *statePath != "" {
+		var err error
+		if st, err = mockchain.Load(*statePath); err != nil {
+			log.Fatalf("state: %v", err)
+		}
+	}
+	if *policyPath != "" {
+		pol, err := policy.Load(*policyPath)
+		if err != nil {
+			log.Fatalf("policy: %v", err)
+		}
+		st.AddPolicy(pol, now)
+	}
+	srv, err := mockchain.New(st)
+	if err != nil {
+		log.Fatalf("state: %v", err)
+	}
+	if *printState {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(srv.State()); err != nil {
+			log.Fatalf("encode failed: %v", err)
+		}
+		return
+	}
+
+	log.Printf("Lumera mock LCD listening on %s (denom=%s supply=%s accounts=%d claims=%d)", *addr, st.Denom, st.Supply, len(st.Accounts), len(st.Claims))
+	server := &http.Server{Addr: *addr, Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+	log.Fatal(server.ListenAndServe())
+}
+
+func getEnv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
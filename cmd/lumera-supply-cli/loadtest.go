@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/bench"
+)
+
+// runLoadtest implements `lumera-supply-cli loadtest --target ...`.
+func runLoadtest(args []string) {
+	fs := flag.NewFlagSet("loadtest", flag.ExitOnError)
+	var (
+		target      = fs.String("target", "http://localhost:8080", "Base URL of a running lumera-supply service")
+		paths       = fs.String("paths", "/total,/circulating,/non_circulating", "Comma-separated request paths")
+		concurrency = fs.Int("c", 8, "Concurrent workers")
+		duration    = fs.Duration("duration", 10*time.Second, "Run length (ignored when -n is set)")
+		requests    = fs.Int("n", 0, "Total requests to issue (0 = run for -duration)")
+	)
+	_ = fs.Parse(args)
+
+	res, err := bench.RunLoad(context.Background(), bench.LoadOptions{
+		Target:      *target,
+		Paths:       strings.Split(*paths, ","),
+		Concurrency: *concurrency,
+		Duration:    *duration,
+		Requests:    *requests,
+	})
+	if err != nil {
+		log.Fatalf("loadtest failed: %v", err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(res); err != nil {
+		log.Fatalf("encode failed: %v", err)
+	}
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
 	"time"
 
+	lumerasupply "github.com/lumera-labs/lumera-supply"
 	"github.com/lumera-labs/lumera-supply/pkg/lcd"
 	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/service"
 	"github.com/lumera-labs/lumera-supply/pkg/supply"
 	"github.com/lumera-labs/lumera-supply/pkg/types"
 )
@@ -20,24 +25,85 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "loadtest":
+			runLoadtest(os.Args[2:])
+			return
+		case "report":
+			runReport(os.Args[2:])
+			return
+		case "scenario":
+			runScenario(os.Args[2:])
+			return
+		case "simulate":
+			runSimulate(os.Args[2:])
+			return
+		case "serve":
+			// the HTTP service, as lumera-supply runs it
+			service.Main(os.Args[2:], service.Version{GitTag: GitTag, GitCommit: GitCommit})
+			return
+		}
+	}
+
 	var (
-		lcdURL     = flag.String("lcd", getEnv("LUMERA_LCD_URL", "http://localhost:1317"), "Cosmos LCD base URL")
+		lcdURL     = flag.String("lcd", getEnv("LUMERA_LCD_URL", "http://localhost:1317"), "Cosmos LCD base URL; a comma-separated list adds fallbacks")
 		policyPath = flag.String("policy", getEnv("LUMERA_POLICY_PATH", "policy.json"), "Path to policy JSON file")
 		denom      = flag.String("denom", getEnv("LUMERA_DEFAULT_DENOM", "ulume"), "Base denom (e.g., ulume)")
+		rpcURL     = flag.String("rpc", getEnv("LUMERA_RPC_URL", ""), "CometBFT RPC URL for batched queries (optional)")
+		claimPfx   = flag.String("claim-prefixes", getEnv("LUMERA_CLAIM_PREFIXES", strings.Join(lcd.DefaultClaimPrefixes, ",")), "Comma-separated claim module LCD route prefixes, tried in order until one serves")
+		caFile     = flag.String("lcd-ca", getEnv("LUMERA_LCD_CA_FILE", ""), "PEM file with extra CA certificates for LCD/RPC TLS (optional)")
+		insecure   = flag.Bool("lcd-insecure", getEnv("LUMERA_LCD_INSECURE", "") == "true", "Skip TLS verification for LCD/RPC (development only)")
+		compress   = flag.String("lcd-compression", getEnv("LUMERA_LCD_COMPRESSION", lcd.CompressionGzip), "Accept-Encoding for LCD/RPC responses: gzip or none")
 		pretty     = flag.Bool("pretty", true, "Pretty-print JSON output")
+		now        = flag.String("now", "", "Evaluate locks at this instant (RFC3339 or YYYY-MM-DD) instead of the block time, for what-if figures")
 	)
 	flag.Parse()
+	var at time.Time
+	if *now != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, *now); err != nil {
+			if at, err = time.Parse(time.DateOnly, *now); err != nil {
+				log.Fatalf("invalid -now %q (RFC3339 or YYYY-MM-DD expected)", *now)
+			}
+		}
+	}
 
 	// Load policy (warn-only if missing)
-	pol, err := policy.Load(*policyPath)
-	if err != nil {
+	pol, fallback, err := policy.LoadOrEmbedded(*policyPath, lumerasupply.EmbeddedPolicy)
+	switch {
+	case fallback:
+		log.Printf("policy load warning: %v (using the policy embedded at build time, %s)", err, pol.ETag)
+	case err != nil:
 		log.Printf("policy load warning: %v (continuing without policy)", err)
 	}
 
-	client := lcd.NewClient(*lcdURL, &http.Client{Timeout: 8 * time.Second})
+	transport, err := lcd.NewTransport(lcd.TransportOptions{CAFile: *caFile, InsecureSkipVerify: *insecure, Compression: *compress})
+	if err != nil {
+		log.Fatalf("lcd transport: %v", err)
+	}
+	var upstream http.RoundTripper = transport
+	lcdURLs := lcd.ParseURLs(*lcdURL)
+	if len(lcdURLs) == 0 {
+		log.Fatalf("-lcd is empty")
+	}
+	if len(lcdURLs) > 1 {
+		upstream = lcd.NewFailover(lcdURLs, transport)
+	}
+	client := lcd.NewClient(lcdURLs[0], &http.Client{Timeout: 8 * time.Second, Transport: upstream})
+	client.SetRPC(*rpcURL)
+	client.SetClaimPrefixes(strings.Split(*claimPfx, ","))
 	comp := supply.NewComputer(client, pol)
 
-	snap, err := comp.ComputeSnapshot(*denom)
+	// Ctrl-C aborts the upstream requests in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var snap *types.SupplySnapshot
+	if at.IsZero() {
+		snap, err = comp.ComputeSnapshot(ctx, *denom)
+	} else {
+		snap, err = comp.ComputeSnapshotAt(ctx, *denom, at)
+	}
 	if err != nil {
 		log.Fatalf("compute snapshot failed: %v", err)
 	}
@@ -55,16 +121,29 @@ func main() {
 func projectCLI(s *types.SupplySnapshot) any {
 	// Shape: match API semantics; include totals and full non_circulating breakdown for auditing
 	type addressItem struct {
-		Address string `json:"address"`
-		Amount  string `json:"amount"`
-		EndDate string `json:"end_date,omitempty"`
+		Address   string `json:"address"`
+		Amount    string `json:"amount"`
+		EndDate   string `json:"end_date,omitempty"`
+		EndUnix   int64  `json:"end_unix,omitempty"`
+		Permanent bool   `json:"permanent"`
+		Source    string `json:"source,omitempty"`
+		Tier      int    `json:"tier,omitempty"`
+		Group     string `json:"address_group,omitempty"`
 	}
 	type cohortEntry struct {
-		Name    string        `json:"name"`
-		Reason  string        `json:"reason"`
-		Address string        `json:"address,omitempty"`
-		Items   []addressItem `json:"items,omitempty"`
-		Amount  string        `json:"amount"`
+		Name          string                        `json:"name"`
+		ID            int                           `json:"id,omitempty"`
+		Slug          string                        `json:"slug"`
+		Reason        string                        `json:"reason"`
+		Address       string                        `json:"address,omitempty"`
+		Items         []addressItem                 `json:"items,omitempty"`
+		ItemCount     int                           `json:"item_count,omitempty"`
+		Amount        string                        `json:"amount"`
+		Tiers         []types.TierTotal             `json:"tiers,omitempty"`
+		Groups        map[string]types.AddressGroup `json:"address_groups,omitempty"`
+		Tags          []string                      `json:"tags,omitempty"`
+		DisclosureURL string                        `json:"disclosure_url,omitempty"`
+		Notes         string                        `json:"notes,omitempty"`
 	}
 	type nonCirc struct {
 		Sum     string        `json:"sum"`
@@ -75,28 +154,37 @@ func projectCLI(s *types.SupplySnapshot) any {
 	for _, c := range s.NonCirculating.Cohorts {
 		items := make([]addressItem, 0, len(c.Items))
 		for _, it := range c.Items {
-			items = append(items, addressItem{Address: it.Address, Amount: it.Amount, EndDate: it.EndDate})
+			items = append(items, addressItem{Address: it.Address, Amount: it.Amount, EndDate: it.EndDate, EndUnix: it.EndUnix, Permanent: it.Permanent, Source: it.Source, Tier: it.Tier, Group: it.AddressGroup})
 		}
-		coh = append(coh, cohortEntry{Name: c.Name, Reason: c.Reason, Address: c.Address, Items: items, Amount: c.Amount})
+		coh = append(coh, cohortEntry{Name: c.Name, ID: c.ID, Slug: c.Slug, Reason: c.Reason, Address: c.Address, Items: items, ItemCount: c.ItemCount, Amount: c.Amount, Tiers: c.Tiers, Groups: c.AddressGroups, Tags: c.Tags,
+			DisclosureURL: c.DisclosureURL, Notes: c.Notes})
+	}
+	// the chain's display exponent, 6 when it has no bank metadata for the denom
+	decimals := 6
+	if s.Display != nil {
+		decimals = s.Display.Decimals
 	}
 	return struct {
-		Denom          string    `json:"denom"`
-		Decimals       int       `json:"decimals"`
-		Height         int64     `json:"height"`
-		UpdatedAt      time.Time `json:"updated_at"`
-		ETag           string    `json:"etag"`
-		PolicyETag     string    `json:"policy-etag"`
-		GitHash        string    `json:"git-hash"`
-		GitTag         string    `json:"git-tag"`
-		Total          string    `json:"total"`
-		Circulating    string    `json:"circulating"`
-		NonCirculating nonCirc   `json:"non_circulating"`
-		Max            *string   `json:"max"`
+		Denom          string              `json:"denom"`
+		Decimals       int                 `json:"decimals"`
+		Height         int64               `json:"height"`
+		UpdatedAt      time.Time           `json:"updated_at"`
+		EvaluatedAt    time.Time           `json:"evaluated_at"`
+		ETag           string              `json:"etag"`
+		PolicyETag     string              `json:"policy-etag"`
+		GitHash        string              `json:"git-hash"`
+		GitTag         string              `json:"git-tag"`
+		Total          string              `json:"total"`
+		Circulating    string              `json:"circulating"`
+		NonCirculating nonCirc             `json:"non_circulating"`
+		Max            *string             `json:"max"`
+		ComputeStats   *types.ComputeStats `json:"compute_stats,omitempty"`
 	}{
 		Denom:          s.Denom,
-		Decimals:       6,
+		Decimals:       decimals,
 		Height:         s.Height,
 		UpdatedAt:      s.UpdatedAt,
+		EvaluatedAt:    s.EvaluationTime(),
 		ETag:           s.ETag,
 		PolicyETag:     s.PolicyETag,
 		GitHash:        GitCommit,
@@ -105,6 +193,7 @@ func projectCLI(s *types.SupplySnapshot) any {
 		Circulating:    s.Circulating,
 		NonCirculating: nonCirc{Sum: s.NonCirculating.Sum, Cohorts: coh},
 		Max:            s.Max,
+		ComputeStats:   s.ComputeStats,
 	}
 }
 
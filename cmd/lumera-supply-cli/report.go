@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/report"
+	"github.com/lumera-labs/lumera-supply/pkg/store"
+)
+
+// runReport implements `lumera-supply-cli report --period weekly --format md|html|pdf`.
+func runReport(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	var (
+		storeDir = fs.String("store", getEnv("LUMERA_STORE_DIR", ""), "Store directory of a lumera-supply service (snapshot history)")
+		period   = fs.String("period", "weekly", "Report period: daily, weekly or monthly")
+		format   = fs.String("format", "md", "Output format: md, html or pdf")
+		horizon  = fs.Duration("upcoming", 30*24*time.Hour, "How far ahead to list upcoming unlocks")
+		outPath  = fs.String("o", "", "Output file (default stdout)")
+	)
+	_ = fs.Parse(args)
+
+	window, err := report.ParsePeriod(*period)
+	if err != nil {
+		log.Fatalf("report: %v", err)
+	}
+	render := map[string]func(io.Writer, *report.Report) error{
+		"md":   report.WriteMarkdown,
+		"html": report.WriteHTML,
+		"pdf":  report.WritePDF,
+	}[*format]
+	if render == nil {
+		log.Fatalf("report: unknown format %q (want md, html or pdf)", *format)
+	}
+	if *storeDir == "" {
+		log.Fatalf("report: -store is required")
+	}
+	st, err := store.Open(*storeDir)
+	if err != nil {
+		log.Fatalf("store open: %v", err)
+	}
+	history, err := st.OpenHistory()
+	if err != nil {
+		log.Fatalf("history: %v", err)
+	}
+	r, err := report.Build(history, report.Options{Name: *period, Period: window, Horizon: *horizon})
+	if err != nil {
+		log.Fatalf("report: %v", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatalf("report: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := render(out, r); err != nil {
+		log.Fatalf("report: %v", err)
+	}
+}
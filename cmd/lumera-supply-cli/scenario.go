@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lumera-labs/lumera-supply/pkg/scenario"
+)
+
+// runScenario implements `lumera-supply-cli scenario FILE...`: it plays each scenario
+// against a mock chain and exits non-zero when any expectation fails.
+func runScenario(args []string) {
+	fs := flag.NewFlagSet("scenario", flag.ExitOnError)
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("usage: lumera-supply-cli scenario FILE...")
+	}
+
+	failed := false
+	for _, path := range fs.Args() {
+		sc, err := scenario.Load(path)
+		if err != nil {
+			log.Fatalf("scenario: %v", err)
+		}
+		failures, err := scenario.Run(context.Background(), sc)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", sc.Name, err)
+			failed = true
+			continue
+		}
+		if len(failures) == 0 {
+			fmt.Printf("ok   %s (%d steps)\n", sc.Name, len(sc.Steps))
+			continue
+		}
+		fmt.Printf("FAIL %s\n", sc.Name)
+		for _, f := range failures {
+			fmt.Printf("     %v\n", f)
+		}
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
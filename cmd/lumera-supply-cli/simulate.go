@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	lumerasupply "github.com/lumera-labs/lumera-supply"
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// runSimulate implements `lumera-supply-cli simulate --horizon 36m`: it projects the
+// supply month by month from the schedules on chain and in the policy.
+func runSimulate(args []string) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	var (
+		lcdURL     = fs.String("lcd", getEnv("LUMERA_LCD_URL", "http://localhost:1317"), "Cosmos LCD base URL; a comma-separated list adds fallbacks")
+		policyPath = fs.String("policy", getEnv("LUMERA_POLICY_PATH", "policy.json"), "Path to policy JSON file")
+		denom      = fs.String("denom", getEnv("LUMERA_DEFAULT_DENOM", "ulume"), "Base denom (e.g., ulume)")
+		horizon    = fs.String("horizon", "36m", "How far to project: months (36m) or years (3y)")
+		from       = fs.String("from", "", "First point (RFC3339 or YYYY-MM-DD); default the latest block time")
+		format     = fs.String("format", "csv", "Output format: csv or json")
+	)
+	_ = fs.Parse(args)
+	months, err := parseHorizon(*horizon)
+	if err != nil {
+		log.Fatalf("simulate: %v", err)
+	}
+	var start time.Time
+	if *from != "" {
+		if start, err = time.Parse(time.RFC3339, *from); err != nil {
+			if start, err = time.Parse(time.DateOnly, *from); err != nil {
+				log.Fatalf("invalid -from %q (RFC3339 or YYYY-MM-DD expected)", *from)
+			}
+		}
+	}
+	if *format != "csv" && *format != "json" {
+		log.Fatalf("invalid -format %q (csv or json expected)", *format)
+	}
+
+	pol, fallback, err := policy.LoadOrEmbedded(*policyPath, lumerasupply.EmbeddedPolicy)
+	switch {
+	case fallback:
+		log.Printf("policy load warning: %v (using the policy embedded at build time, %s)", err, pol.ETag)
+	case err != nil:
+		log.Printf("policy load warning: %v (continuing without policy)", err)
+	}
+	lcdURLs := lcd.ParseURLs(*lcdURL)
+	if len(lcdURLs) == 0 {
+		log.Fatalf("-lcd is empty")
+	}
+	var upstream http.RoundTripper = http.DefaultTransport
+	if len(lcdURLs) > 1 {
+		upstream = lcd.NewFailover(lcdURLs, upstream)
+	}
+	client := lcd.NewClient(lcdURLs[0], &http.Client{Timeout: 8 * time.Second, Transport: upstream})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	proj, err := supply.NewComputer(client, pol).Simulate(ctx, *denom, start, months)
+	if err != nil {
+		log.Fatalf("simulate failed: %v", err)
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(proj); err != nil {
+			log.Fatalf("encode failed: %v", err)
+		}
+		return
+	}
+	if err := writeProjectionCSV(os.Stdout, proj); err != nil {
+		log.Fatalf("encode failed: %v", err)
+	}
+}
+
+// parseHorizon reads a whole number of months ("36m") or years ("3y").
+func parseHorizon(v string) (int, error) {
+	per := 1
+	n, ok := strings.CutSuffix(v, "m")
+	if !ok {
+		if n, ok = strings.CutSuffix(v, "y"); !ok {
+			return 0, fmt.Errorf("invalid horizon %q (e.g. 36m or 3y)", v)
+		}
+		per = 12
+	}
+	k, err := strconv.Atoi(n)
+	if err != nil || k < 0 || k > 1200/per {
+		return 0, fmt.Errorf("invalid horizon %q (e.g. 36m or 3y)", v)
+	}
+	return k * per, nil
+}
+
+// writeProjectionCSV writes a row per point, with a column per cohort that any point
+// holds, in base units.
+func writeProjectionCSV(out io.Writer, proj *types.SupplyProjection) error {
+	seen := map[string]bool{}
+	var cohorts []string
+	for _, p := range proj.Points {
+		for _, co := range p.Cohorts {
+			if !seen[co.Cohort] {
+				seen[co.Cohort] = true
+				cohorts = append(cohorts, co.Cohort)
+			}
+		}
+	}
+	sort.Strings(cohorts)
+	w := csv.NewWriter(out)
+	_ = w.Write(append([]string{"time", "total", "circulating", "non_circulating"}, cohorts...))
+	for _, p := range proj.Points {
+		amounts := map[string]string{}
+		for _, co := range p.Cohorts {
+			amounts[co.Cohort] = co.Amount
+		}
+		row := []string{p.Time.Format(time.RFC3339), p.Total, p.Circulating, p.NonCirculating}
+		for _, name := range cohorts {
+			a := amounts[name]
+			if a == "" {
+				a = "0"
+			}
+			row = append(row, a)
+		}
+		_ = w.Write(row)
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,47 @@
+package bech32
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	data := []byte{0x00, 0x14, 0x75, 0x1e, 0x76, 0xe8, 0x19, 0x91, 0x96, 0xd4, 0x54, 0x94, 0x1c, 0x45, 0xd1, 0xb3, 0xa3, 0x23, 0xf1, 0x43}
+	s, err := Encode("lumera", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hrp, got, err := Decode(s)
+	if err != nil || hrp != "lumera" || !bytes.Equal(got, data) {
+		t.Fatalf("Decode(%s) = %q %x %v", s, hrp, got, err)
+	}
+}
+
+func TestDecodeVectors(t *testing.T) {
+	// BIP-173 valid test vectors
+	for _, s := range []string{"A12UEL5L", "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", "split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w"} {
+		if _, _, err := Decode(s); err != nil {
+			t.Errorf("Decode(%s): %v", s, err)
+		}
+	}
+	for _, s := range []string{"pzry9x0s0muk", "1pzry9x0s0muk", "A1G7SGD8", "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx", "Abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw"} {
+		if _, _, err := Decode(s); err == nil {
+			t.Errorf("Decode(%s): expected error", s)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	lower := "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw"
+	for _, s := range []string{lower, strings.ToUpper(lower)} {
+		if got, err := Normalize(s); err != nil || got != lower {
+			t.Errorf("Normalize(%s) = %q, %v", s, got, err)
+		}
+	}
+	for _, s := range []string{"Abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx"} {
+		if _, err := Normalize(s); err == nil {
+			t.Errorf("Normalize(%s): expected error", s)
+		}
+	}
+}
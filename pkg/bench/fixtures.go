@@ -0,0 +1,56 @@
+// Package bench provides synthetic fixtures and a load generator for measuring the HTTP layer.
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+// NewFakeLCD starts an httptest LCD serving a fixed chain state with claims
+// tier-1 records, so snapshots carry that many claim_delayed items.
+func NewFakeLCD(claims int) *httptest.Server {
+	now := time.Now().UTC()
+	type coin struct {
+		Denom  string `json:"denom"`
+		Amount string `json:"amount"`
+	}
+	type claim struct {
+		DestAddress string `json:"destAddress"`
+		ClaimTime   string `json:"claimTime"`
+		Balance     []coin `json:"balance"`
+	}
+	list := make([]claim, 0, claims)
+	for i := 0; i < claims; i++ {
+		list = append(list, claim{
+			DestAddress: fmt.Sprintf("lumera1bench%034d", i),
+			ClaimTime:   fmt.Sprintf("%d", now.Add(-time.Duration(i)*time.Minute).Unix()),
+			Balance:     []coin{{Denom: "ulume", Amount: fmt.Sprintf("%d", 1000000+i)}},
+		})
+	}
+	claimsBody, _ := json.Marshal(struct {
+		Claims []claim `json:"claims"`
+	}{list})
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			fmt.Fprintf(w, `{"block":{"header":{"height":"1000","time":%q}}}`, now.Format(time.RFC3339Nano))
+		case r.URL.Path == "/cosmos/bank/v1beta1/supply/by_denom":
+			fmt.Fprint(w, `{"amount":{"denom":"ulume","amount":"1000000000000000"}}`)
+		case strings.HasSuffix(r.URL.Path, "/total_escrow"):
+			fmt.Fprint(w, `{"amount":{"denom":"ulume","amount":"1000000"}}`)
+		case r.URL.Path == "/cosmos/distribution/v1beta1/community_pool":
+			fmt.Fprint(w, `{"pool":[{"denom":"ulume","amount":"5000000.25"}]}`)
+		case r.URL.Path == "/LumeraProtocol/lumera/claim/list_claimed/1":
+			_, _ = w.Write(claimsBody)
+		case strings.HasPrefix(r.URL.Path, "/LumeraProtocol/lumera/claim/list_claimed/"):
+			fmt.Fprint(w, `{"claims":[]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
@@ -0,0 +1,89 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/cache"
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+	"github.com/lumera-labs/lumera-supply/pkg/httpserver"
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+)
+
+func newBenchServer(b *testing.B, claims int) http.Handler {
+	b.Helper()
+	up := NewFakeLCD(claims)
+	b.Cleanup(up.Close)
+	comp := supply.NewComputer(lcd.NewClient(up.URL, up.Client()), &policy.Policy{})
+	c := cache.NewSnapshotCache(comp, cache.Options{})
+	if _, err := c.Update(context.Background(), "ulume"); err != nil {
+		b.Fatalf("warm cache: %v", err)
+	}
+	// generous limits so the limiter never rejects benchmark traffic
+	return httpserver.New(httpserver.Config{Cache: c, Computer: comp, DefaultDenom: "ulume", Limits: config.Limits{RatePerMin: 600000, Burst: 100000}})
+}
+
+func benchPath(b *testing.B, claims int, path string) {
+	h := newBenchServer(b, claims)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			b.Fatalf("%s: status %d", path, rec.Code)
+		}
+	}
+}
+
+func BenchmarkTotal(b *testing.B)             { benchPath(b, 10, "/total") }
+func BenchmarkNonCircVerbose1k(b *testing.B)  { benchPath(b, 1000, "/non_circulating?verbose=1") }
+func BenchmarkNonCircVerbose10k(b *testing.B) { benchPath(b, 10000, "/non_circulating?verbose=1") }
+func BenchmarkNonCircGroupByTag(b *testing.B) { benchPath(b, 1000, "/non_circulating?group_by=tag") }
+
+func TestRunLoad(t *testing.T) {
+	up := NewFakeLCD(5)
+	defer up.Close()
+	comp := supply.NewComputer(lcd.NewClient(up.URL, up.Client()), &policy.Policy{})
+	c := cache.NewSnapshotCache(comp, cache.Options{})
+	srv := httptest.NewServer(httpserver.New(httpserver.Config{Cache: c, Computer: comp, DefaultDenom: "ulume", Limits: config.Limits{RatePerMin: 600000, Burst: 100000}}))
+	defer srv.Close()
+
+	res, err := RunLoad(context.Background(), LoadOptions{Target: srv.URL, Paths: []string{"/total", "/circulating"}, Concurrency: 4, Requests: 40})
+	if err != nil {
+		t.Fatalf("RunLoad: %v", err)
+	}
+	if res.Requests != 40 || res.Errors != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if res.P99Ms < res.P50Ms {
+		t.Fatalf("p99 %v < p50 %v", res.P99Ms, res.P50Ms)
+	}
+}
+
+func TestRunLoadCountsFailures(t *testing.T) {
+	// every other request has its connection dropped before a response
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.Add(1)%2 == 0 {
+			panic(http.ErrAbortHandler)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// without keep-alives the client does not retry the dropped requests itself
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	res, err := RunLoad(context.Background(), LoadOptions{Target: srv.URL, Concurrency: 1, Requests: 10, Client: client})
+	if err != nil {
+		t.Fatalf("RunLoad: %v", err)
+	}
+	if res.Requests != 10 || res.Errors != 5 || res.StatusCodes[200] != 5 || res.PerPath["/total"] != 10 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
@@ -0,0 +1,156 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoadOptions configures a closed-loop load run against a running service.
+type LoadOptions struct {
+	Target      string        // base URL, e.g. http://localhost:8080
+	Paths       []string      // request paths cycled round-robin, e.g. /total
+	Concurrency int           // parallel workers
+	Duration    time.Duration // run length; ignored when Requests > 0
+	Requests    int           // total requests to issue (0 = use Duration)
+	Client      *http.Client
+}
+
+// LoadResult summarizes latencies and outcomes of a load run. Requests counts every
+// request issued, including failed ones and those cut off when the run ends; RPS and
+// the latencies cover the requests that got a response.
+type LoadResult struct {
+	Requests    int            `json:"requests"`
+	Errors      int            `json:"errors"`
+	StatusCodes map[int]int    `json:"status_codes"`
+	Elapsed     time.Duration  `json:"-"`
+	RPS         float64        `json:"rps"`
+	P50Ms       float64        `json:"p50_ms"`
+	P90Ms       float64        `json:"p90_ms"`
+	P99Ms       float64        `json:"p99_ms"`
+	MaxMs       float64        `json:"max_ms"`
+	PerPath     map[string]int `json:"per_path"`
+}
+
+// RunLoad issues requests until the request budget or duration is exhausted.
+func RunLoad(ctx context.Context, opt LoadOptions) (*LoadResult, error) {
+	if opt.Target == "" {
+		return nil, errors.New("missing target")
+	}
+	if len(opt.Paths) == 0 {
+		opt.Paths = []string{"/total"}
+	}
+	if opt.Concurrency <= 0 {
+		opt.Concurrency = 8
+	}
+	if opt.Requests <= 0 && opt.Duration <= 0 {
+		opt.Duration = 10 * time.Second
+	}
+	if opt.Client == nil {
+		opt.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	base := strings.TrimRight(opt.Target, "/")
+	if opt.Requests <= 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.Duration)
+		defer cancel()
+	}
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		res       = &LoadResult{StatusCodes: map[int]int{}, PerPath: map[string]int{}}
+		issued    int
+		wg        sync.WaitGroup
+	)
+	next := func() (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.Err() != nil || (opt.Requests > 0 && issued >= opt.Requests) {
+			return "", false
+		}
+		p := opt.Paths[issued%len(opt.Paths)]
+		issued++
+		return p, true
+	}
+	start := time.Now()
+	for i := 0; i < opt.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				p, ok := next()
+				if !ok {
+					return
+				}
+				t0 := time.Now()
+				code, err := doRequest(ctx, opt.Client, base+p)
+				d := time.Since(t0)
+				mu.Lock()
+				res.Requests++
+				res.PerPath[p]++
+				if err != nil {
+					if ctx.Err() == nil {
+						res.Errors++
+					}
+				} else {
+					res.StatusCodes[code]++
+					if code >= 400 {
+						res.Errors++
+					}
+					latencies = append(latencies, d)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	res.Elapsed = time.Since(start)
+	if res.Elapsed > 0 {
+		res.RPS = float64(len(latencies)) / res.Elapsed.Seconds()
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	res.P50Ms = ms(percentile(latencies, 0.50))
+	res.P90Ms = ms(percentile(latencies, 0.90))
+	res.P99Ms = ms(percentile(latencies, 0.99))
+	if n := len(latencies); n > 0 {
+		res.MaxMs = ms(latencies[n-1])
+	}
+	return res, nil
+}
+
+func doRequest(ctx context.Context, c *http.Client, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// percentile expects sorted input (nearest-rank).
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(q*float64(len(sorted)) + 0.5)
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	if idx > 0 {
+		idx--
+	}
+	return sorted[idx]
+}
+
+func ms(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+var (
+	refreshWaiting = metrics.Default.NewGauge(
+		"lumera_supply_refresh_waiting",
+		"Callers waiting on an in-flight snapshot refresh.",
+	)
+	refreshCoalesced = metrics.Default.NewCounter(
+		"lumera_supply_refresh_coalesced_total",
+		"Refreshes that joined one already in flight instead of computing a snapshot.",
+	)
+	timeToFresh = metrics.Default.NewGauge(
+		"lumera_supply_refresh_time_to_fresh_seconds",
+		"Time from the start of the latest successful refresh until its snapshot was cached.",
+	)
+)
+
+// flight is one in-progress refresh that concurrent callers for the same denom share.
+type flight struct {
+	done chan struct{}
+	snap *types.SupplySnapshot
+	err  error
+}
+
+// flights coalesces refreshes per denom, so a burst of requests arriving while the
+// snapshot is stale triggers one computation rather than one per request.
+type flights struct {
+	mu          sync.Mutex
+	inFlight    map[string]*flight
+	waiting     int
+	coalesced   uint64
+	timeToFresh time.Duration
+}
+
+// Coalescing describes refresh sharing for /status.
+type Coalescing struct {
+	// InFlight is the number of refreshes running; Waiting the callers blocked on them.
+	InFlight int
+	Waiting  int
+	// Coalesced counts callers that shared another caller's refresh since startup.
+	Coalesced uint64
+	// TimeToFresh is how long the latest successful refresh took to cache its snapshot.
+	TimeToFresh time.Duration
+}
+
+// Coalescing reports the current refresh queue and the latest time to fresh snapshot.
+func (c *SnapshotCache) Coalescing() Coalescing {
+	f := &c.flights
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return Coalescing{InFlight: len(f.inFlight), Waiting: f.waiting, Coalesced: f.coalesced, TimeToFresh: f.timeToFresh}
+}
+
+// Update computes and caches a new snapshot for denom. Callers arriving while a refresh
+// for denom is running wait for it and share its result. The computation belongs to the
+// cache rather than to the caller that started it: a caller whose ctx is done stops
+// waiting with ctx's error, and the refresh carries on for the others until Close.
+func (c *SnapshotCache) Update(ctx context.Context, denom string) (*types.SupplySnapshot, error) {
+	f := &c.flights
+	fl, started := c.takeOff(denom)
+	if started {
+		return fl.wait(ctx)
+	}
+	f.mu.Lock()
+	f.waiting++
+	f.coalesced++
+	refreshWaiting.Set(float64(f.waiting))
+	refreshCoalesced.Inc()
+	f.mu.Unlock()
+	snap, err := fl.wait(ctx)
+	f.mu.Lock()
+	f.waiting--
+	refreshWaiting.Set(float64(f.waiting))
+	f.mu.Unlock()
+	return snap, err
+}
+
+// takeOff returns the refresh in flight for denom, starting one (started) if none is.
+func (c *SnapshotCache) takeOff(denom string) (fl *flight, started bool) {
+	f := &c.flights
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if fl := f.inFlight[denom]; fl != nil {
+		return fl, false
+	}
+	if f.inFlight == nil {
+		f.inFlight = map[string]*flight{}
+	}
+	fl = &flight{done: make(chan struct{})}
+	f.inFlight[denom] = fl
+	go c.fly(denom, fl)
+	return fl, true
+}
+
+// fly runs the refresh fl for denom under the cache's context.
+func (c *SnapshotCache) fly(denom string, fl *flight) {
+	f := &c.flights
+	start := time.Now()
+	fl.snap, fl.err = c.update(c.ctx, denom)
+	observeRefresh(time.Since(start), fl.snap, fl.err)
+	f.mu.Lock()
+	delete(f.inFlight, denom)
+	if fl.err == nil {
+		f.timeToFresh = time.Since(start)
+		timeToFresh.Set(f.timeToFresh.Seconds())
+	}
+	f.mu.Unlock()
+	close(fl.done)
+}
+
+// wait returns fl's result, or ctx's error if ctx is done first.
+func (fl *flight) wait(ctx context.Context) (*types.SupplySnapshot, error) {
+	select {
+	case <-fl.done:
+		return fl.snap, fl.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+var (
+	refreshDuration = metrics.Default.NewHistogram(
+		"lumera_supply_refresh_duration_seconds",
+		"Snapshot computations by result (ok|error); successful ones carry the snapshot's etag and height as exemplars.",
+		[]float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		"result",
+	)
+	supplyAmount = metrics.Default.NewGauge(
+		"lumera_supply_amount",
+		"Latest snapshot's supply figures in base units by denom and kind (total|circulating|non_circulating, and bonded|not_bonded for the bond denom).",
+		"denom", "kind",
+	)
+	policyLoaded = metrics.Default.NewGauge(
+		"lumera_supply_policy_loaded",
+		"Whether the latest snapshot was computed with a policy (1) or without one (0), which overstates circulating supply.",
+		"denom",
+	)
+	snapshotsPublished = metrics.Default.NewCounter(
+		"lumera_supply_snapshots_published_total",
+		"Snapshots whose ETag changed, with the new snapshot's etag and height as exemplar.",
+		"denom",
+	)
+)
+
+// snapshotExemplar identifies snap in OpenMetrics exemplars.
+func snapshotExemplar(snap *types.SupplySnapshot) metrics.Exemplar {
+	return metrics.Exemplar{Labels: map[string]string{"etag": snap.ETag, "height": strconv.FormatInt(snap.Height, 10)}}
+}
+
+// observeRefresh records one snapshot computation that took d.
+func observeRefresh(d time.Duration, snap *types.SupplySnapshot, err error) {
+	if err != nil {
+		refreshDuration.Observe(d.Seconds(), "error")
+		return
+	}
+	refreshDuration.ObserveExemplar(d.Seconds(), snapshotExemplar(snap), "ok")
+}
+
+// observeSnapshot updates the supply gauges from a cached snapshot and, when it
+// replaced one with another ETag, counts it with its exemplar. Gauges cannot carry
+// exemplars, so a jump in lumera_supply_amount is traced to its snapshot through the
+// counter's exemplar at the same time.
+func observeSnapshot(snap *types.SupplySnapshot, changed bool) {
+	kinds := map[string]string{"total": snap.Total, "circulating": snap.Circulating, "non_circulating": snap.NonCirculating.Sum}
+	if snap.Staked != nil {
+		kinds["bonded"], kinds["not_bonded"] = snap.Staked.Bonded, snap.Staked.NotBonded
+	}
+	for kind, v := range kinds {
+		if f, ok := new(big.Float).SetString(v); ok {
+			x, _ := f.Float64()
+			supplyAmount.Set(x, snap.Denom, kind)
+		}
+	}
+	loaded := 0.0
+	if snap.PolicyLoaded() {
+		loaded = 1
+	}
+	policyLoaded.Set(loaded, snap.Denom)
+	if changed {
+		snapshotsPublished.AddExemplar(1, snapshotExemplar(snap), snap.Denom)
+	}
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// ErrTooStale is returned by Revalidate when the cached snapshot is older than
+// Options.MaxStale: too old to serve, even while a refresh runs.
+var ErrTooStale = errors.New("cached snapshot is too stale to serve")
+
+var revalidations = metrics.Default.NewCounter(
+	"lumera_supply_revalidations_total",
+	"Requests that found the snapshot past its TTL and started or joined a background refresh, by outcome (served|too_stale).",
+	"outcome",
+)
+
+// Revalidate implements stale-while-revalidate. For a snapshot of denom past its TTL it
+// starts a background refresh (or joins the one in flight) and returns the cached
+// snapshot at once, with its age: the time since it was computed. Past Options.MaxStale
+// it fails with ErrTooStale instead, the refresh still started. ok is false when there
+// is nothing to revalidate: stale-while-revalidate is off (MaxStale 0), no snapshot of
+// denom is cached, or it is still fresh; callers then refresh within the request.
+func (c *SnapshotCache) Revalidate(denom string) (snap *types.SupplySnapshot, age time.Duration, ok bool, err error) {
+	c.mu.RLock()
+	snap, maxStale, computed := c.snap, c.maxStale, c.lastSuccess
+	c.mu.RUnlock()
+	if maxStale <= 0 || snap == nil || snap.Denom != denom || time.Since(snap.UpdatedAt) <= c.TTL() {
+		return nil, 0, false, nil
+	}
+	c.takeOff(denom)
+	age = time.Since(computed)
+	if age > maxStale {
+		revalidations.Inc("too_stale")
+		return nil, age, true, ErrTooStale
+	}
+	revalidations.Inc("served")
+	return snap, age, true, nil
+}
+
+// MaxStale returns how old a snapshot Revalidate still serves (0: it is off).
+func (c *SnapshotCache) MaxStale() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.maxStale
+}
@@ -1,16 +1,29 @@
 package cache
 
 import (
+	"context"
+	"errors"
 	"log"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/lumera-labs/lumera-supply/pkg/recovery"
+	"github.com/lumera-labs/lumera-supply/pkg/store"
 	"github.com/lumera-labs/lumera-supply/pkg/supply"
 	"github.com/lumera-labs/lumera-supply/pkg/types"
 )
 
 type Options struct {
 	TTL time.Duration
+	// Store, when set, receives cohort items so only sums stay in memory.
+	Store *store.FileStore
+	// OffloadItems is the per-cohort item count above which items are moved to Store (default 500).
+	OffloadItems int
+	// MaxStale turns on stale-while-revalidate (see Revalidate): a snapshot past its TTL
+	// is served while a background refresh runs, until it was computed longer ago than
+	// this. 0 leaves callers to refresh within their request.
+	MaxStale time.Duration
 }
 
 type SnapshotCache struct {
@@ -19,13 +32,84 @@ type SnapshotCache struct {
 	etag string
 	ttl  time.Duration
 	comp *supply.Computer
+
+	maxStale time.Duration
+
+	store        *store.FileStore
+	offloadItems int
+	prevETag     string
+
+	// refresh outcome, guarded by mu
+	lastSuccess  time.Time
+	lastErr      error
+	failingSince time.Time
+
+	subsMu    sync.Mutex
+	subs      []func(*types.SupplySnapshot)
+	refreshFn []func(err error, failingSince time.Time)
+
+	flights flights
+
+	// computations run under ctx until Close cancels it
+	ctx   context.Context
+	close context.CancelFunc
+
+	// closed by the first successful Update
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+// Ready returns a channel closed once the first snapshot has been computed.
+func (c *SnapshotCache) Ready() <-chan struct{} { return c.ready }
+
+// Subscribe registers fn to receive each snapshot whose ETag differs from the previous
+// one, with offloaded items hydrated. fn runs on its own goroutine.
+func (c *SnapshotCache) Subscribe(fn func(*types.SupplySnapshot)) {
+	c.subsMu.Lock()
+	c.subs = append(c.subs, fn)
+	c.subsMu.Unlock()
+}
+
+// OnRefresh registers fn to run after every background refresh. err is nil on success;
+// otherwise failingSince is when the current run of failures began.
+func (c *SnapshotCache) OnRefresh(fn func(err error, failingSince time.Time)) {
+	c.subsMu.Lock()
+	c.refreshFn = append(c.refreshFn, fn)
+	c.subsMu.Unlock()
+}
+
+func (c *SnapshotCache) publish(s *types.SupplySnapshot) {
+	c.subsMu.Lock()
+	subs := slices.Clone(c.subs)
+	c.subsMu.Unlock()
+	if len(subs) == 0 {
+		return
+	}
+	full, err := c.Hydrate(s)
+	if err != nil {
+		log.Printf("warn: hydrate snapshot for subscribers: %v", err)
+		full = s
+	}
+	for _, fn := range subs {
+		go recovery.Guard("subscriber", func() error { fn(full); return nil })
+	}
 }
 
 func NewSnapshotCache(comp *supply.Computer, opt Options) *SnapshotCache {
 	if opt.TTL <= 0 {
 		opt.TTL = 60 * time.Second
 	}
-	return &SnapshotCache{ttl: opt.TTL, comp: comp}
+	if opt.OffloadItems <= 0 {
+		opt.OffloadItems = 500
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SnapshotCache{ttl: opt.TTL, maxStale: opt.MaxStale, comp: comp, store: opt.Store, offloadItems: opt.OffloadItems, ready: make(chan struct{}), ctx: ctx, close: cancel}
+}
+
+// Close cancels the computation in flight, if any, and fails later Updates with
+// context.Canceled, for shutdown. The cached snapshot stays readable.
+func (c *SnapshotCache) Close() {
+	c.close()
 }
 
 func (c *SnapshotCache) Get() (*types.SupplySnapshot, bool) {
@@ -35,30 +119,172 @@ func (c *SnapshotCache) Get() (*types.SupplySnapshot, bool) {
 	if s == nil {
 		return nil, false
 	}
-	if time.Since(s.UpdatedAt) > c.ttl {
+	if time.Since(s.UpdatedAt) > c.TTL() {
 		return s, false
 	}
 	return s, true
 }
 
-func (c *SnapshotCache) Update(denom string) (*types.SupplySnapshot, error) {
-	s, err := c.comp.ComputeSnapshot(denom)
+// TTL returns the refresh interval.
+func (c *SnapshotCache) TTL() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ttl
+}
+
+// SetTTL changes the refresh interval. The refresher picks it up after its current sleep.
+func (c *SnapshotCache) SetTTL(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	c.mu.Lock()
+	c.ttl = d
+	c.mu.Unlock()
+}
+
+// Health describes the outcome of recent snapshot computations.
+type Health struct {
+	LastSuccess time.Time
+	// LastError is the error of the latest computation, nil when it succeeded.
+	LastError error
+	// FailingSince is when the current run of failures began (zero when healthy).
+	FailingSince time.Time
+}
+
+// Health reports the outcome of recent Update calls (background or on request).
+func (c *SnapshotCache) Health() Health {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Health{LastSuccess: c.lastSuccess, LastError: c.lastErr, FailingSince: c.failingSince}
+}
+
+func (c *SnapshotCache) update(ctx context.Context, denom string) (*types.SupplySnapshot, error) {
+	// a panic while computing fails this update like any other error
+	var s *types.SupplySnapshot
+	err := recovery.Guard("compute", func() (err error) {
+		s, err = c.comp.ComputeSnapshot(ctx, denom)
+		return err
+	})
+	if errors.Is(err, context.Canceled) {
+		// shutting down: not an upstream failure
+		return nil, err
+	}
 	if err != nil {
+		c.mu.Lock()
+		c.lastErr = err
+		if c.failingSince.IsZero() {
+			c.failingSince = time.Now()
+		}
+		c.mu.Unlock()
 		return nil, err
 	}
+	c.offload(s)
 	c.mu.Lock()
+	c.lastSuccess, c.lastErr, c.failingSince = time.Now(), nil, time.Time{}
 	c.snap = s
+	changed := c.etag != s.ETag
+	if changed {
+		c.prevETag = c.etag
+	}
 	c.etag = s.ETag
+	keep := []string{c.etag, c.prevETag}
 	c.mu.Unlock()
+	c.readyOnce.Do(func() { close(c.ready) })
+	observeSnapshot(s, changed)
+	if changed {
+		c.publish(s)
+	}
+	if c.store != nil {
+		// keep the previous set too, for requests still hydrating it
+		if err := c.store.PruneItems(keep...); err != nil {
+			log.Printf("warn: prune stored items: %v", err)
+		}
+	}
 	return s, nil
 }
 
-// RunRefresher refreshes the snapshot every TTL seconds.
-func (c *SnapshotCache) RunRefresher(denom string) {
-	for {
-		if _, err := c.Update(denom); err != nil {
-			log.Printf("refresher error: %v", err)
+// offload moves large cohort item lists to the store, leaving ItemCount and Amount in memory.
+// Cohorts whose items fail to persist keep them in memory.
+func (c *SnapshotCache) offload(s *types.SupplySnapshot) {
+	if c.store == nil {
+		return
+	}
+	for i := range s.NonCirculating.Cohorts {
+		coh := &s.NonCirculating.Cohorts[i]
+		if len(coh.Items) <= c.offloadItems {
+			continue
+		}
+		if err := c.store.PutItems(s.ETag, coh.Name, coh.Items); err != nil {
+			log.Printf("warn: offload items for %s: %v", coh.Name, err)
+			continue
 		}
-		time.Sleep(c.ttl)
+		coh.Items = nil
+	}
+}
+
+// Hydrate returns snap with any offloaded cohort items loaded from the store.
+// The cached snapshot is not modified; a shallow copy is returned when loading is needed.
+func (c *SnapshotCache) Hydrate(snap *types.SupplySnapshot) (*types.SupplySnapshot, error) {
+	if c.store == nil || snap == nil {
+		return snap, nil
+	}
+	var out *types.SupplySnapshot
+	for i, coh := range snap.NonCirculating.Cohorts {
+		if coh.Items != nil || coh.ItemCount == 0 {
+			continue
+		}
+		items, err := c.store.Items(snap.ETag, coh.Name)
+		if err != nil {
+			return nil, err
+		}
+		if out == nil {
+			cp := *snap
+			cp.NonCirculating.Cohorts = append([]types.CohortEntry(nil), snap.NonCirculating.Cohorts...)
+			out = &cp
+		}
+		out.NonCirculating.Cohorts[i].Items = items
+	}
+	if out == nil {
+		return snap, nil
+	}
+	return out, nil
+}
+
+// CohortItems returns up to limit items of snap's named cohort starting at offset. Offloaded
+// items are read from the store by range rather than hydrating the whole list.
+func (c *SnapshotCache) CohortItems(snap *types.SupplySnapshot, cohort string, offset, limit int) ([]types.AddressItem, error) {
+	for _, coh := range snap.NonCirculating.Cohorts {
+		if coh.Name != cohort {
+			continue
+		}
+		if coh.Items == nil && coh.ItemCount > 0 && c.store != nil {
+			return c.store.ItemsRange(snap.ETag, coh.Name, offset, limit)
+		}
+		start := min(offset, len(coh.Items))
+		return coh.Items[start:min(start+limit, len(coh.Items))], nil
+	}
+	return nil, nil
+}
+
+// Refresh is one run of the refresh job (scheduled every TTL): it updates denom's
+// snapshot and reports the outcome to the OnRefresh callbacks. A refresh cancelled by
+// ctx or Close is not reported.
+func (c *SnapshotCache) Refresh(ctx context.Context, denom string) error {
+	s, err := c.Update(ctx, denom)
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	if err != nil {
+		log.Printf("refresher error: %v", err)
+	} else {
+		log.Printf("debug: refreshed %s snapshot at height %d (%s)", denom, s.Height, s.ETag)
+	}
+	failingSince := c.Health().FailingSince
+	c.subsMu.Lock()
+	fns := slices.Clone(c.refreshFn)
+	c.subsMu.Unlock()
+	for _, fn := range fns {
+		fn(err, failingSince)
 	}
+	return err
 }
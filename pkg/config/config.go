@@ -0,0 +1,374 @@
+// Package config loads the optional service config file (JSON). Flags and environment
+// variables cover the common settings; the file holds structured sections.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/logging"
+)
+
+// File is the service config file.
+type File struct {
+	// Endpoints are custom responses rendered from Go templates over the snapshot.
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+	// Exports write snapshot tables for data warehouses on a schedule.
+	Exports []Export `json:"exports,omitempty"`
+	// Notifications routes service and unlock events to email.
+	Notifications Notifications `json:"notifications,omitempty"`
+	// SLO sets availability and latency objectives per endpoint.
+	SLO SLO `json:"slo,omitempty"`
+	// Tuning sets refresh, rate limiting and upstream timeouts; flags and environment
+	// variables take precedence.
+	Tuning Tuning `json:"tuning,omitempty"`
+}
+
+// Tuning is the config file form of Limits (durations are Go duration strings).
+type Tuning struct {
+	RefreshTTL  string `json:"refresh_ttl,omitempty"`
+	RatePerMin  int    `json:"rate_per_min,omitempty"`
+	Burst       int    `json:"burst,omitempty"`
+	LCDTimeout  string `json:"lcd_timeout,omitempty"`
+	Concurrency int    `json:"concurrency,omitempty"`
+	// LogLevel is debug, info or warn.
+	LogLevel string `json:"log_level,omitempty"`
+}
+
+// Limits are the effective refresh, rate limiting and upstream timeout settings.
+type Limits struct {
+	// RefreshTTL is the snapshot refresh interval.
+	RefreshTTL time.Duration
+	// RatePerMin and Burst size each client IP's token bucket.
+	RatePerMin int
+	Burst      int
+	// LCDTimeout bounds each LCD/RPC request.
+	LCDTimeout time.Duration
+	// Concurrency caps the upstream lookups one request (e.g., /balances) or snapshot
+	// (per-address vesting lookups) runs in parallel.
+	Concurrency int
+}
+
+// DefaultLimits are used when neither flags, environment nor the config file set a value.
+var DefaultLimits = Limits{RefreshTTL: 60 * time.Second, RatePerMin: 60, Burst: 120, LCDTimeout: 5 * time.Second, Concurrency: 8}
+
+// Validate rejects settings that would break refresh or serving.
+func (l Limits) Validate() error {
+	var errs []error
+	if l.RefreshTTL < time.Second {
+		errs = append(errs, fmt.Errorf("refresh ttl %s: must be at least 1s", l.RefreshTTL))
+	}
+	if l.RatePerMin <= 0 || l.RatePerMin > 60000 {
+		errs = append(errs, fmt.Errorf("rate per minute %d: must be in 1..60000", l.RatePerMin))
+	}
+	if l.Burst <= 0 || l.Burst > 100000 {
+		errs = append(errs, fmt.Errorf("burst %d: must be in 1..100000", l.Burst))
+	}
+	if l.LCDTimeout <= 0 || l.LCDTimeout > 5*time.Minute {
+		errs = append(errs, fmt.Errorf("lcd timeout %s: must be in (0, 5m]", l.LCDTimeout))
+	}
+	if l.Concurrency <= 0 || l.Concurrency > 64 {
+		errs = append(errs, fmt.Errorf("concurrency %d: must be in 1..64", l.Concurrency))
+	}
+	return errors.Join(errs...)
+}
+
+// Merge returns t with the non-zero fields of o.
+func (t Tuning) Merge(o Tuning) Tuning {
+	if o.RefreshTTL != "" {
+		t.RefreshTTL = o.RefreshTTL
+	}
+	if o.RatePerMin != 0 {
+		t.RatePerMin = o.RatePerMin
+	}
+	if o.Burst != 0 {
+		t.Burst = o.Burst
+	}
+	if o.LCDTimeout != "" {
+		t.LCDTimeout = o.LCDTimeout
+	}
+	if o.Concurrency != 0 {
+		t.Concurrency = o.Concurrency
+	}
+	if o.LogLevel != "" {
+		t.LogLevel = o.LogLevel
+	}
+	return t
+}
+
+// Apply returns l with the non-zero fields of t, validated. LogLevel is not a limit and
+// is ignored.
+func (l Limits) Apply(t Tuning) (Limits, error) {
+	var errs []error
+	for _, d := range []struct {
+		name string
+		v    string
+		dst  *time.Duration
+	}{{"refresh_ttl", t.RefreshTTL, &l.RefreshTTL}, {"lcd_timeout", t.LCDTimeout, &l.LCDTimeout}} {
+		if d.v == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q", d.name, d.v))
+			continue
+		}
+		*d.dst = v
+	}
+	if err := errors.Join(errs...); err != nil {
+		return l, err
+	}
+	if t.RatePerMin != 0 {
+		l.RatePerMin = t.RatePerMin
+	}
+	if t.Burst != 0 {
+		l.Burst = t.Burst
+	}
+	if t.Concurrency != 0 {
+		l.Concurrency = t.Concurrency
+	}
+	return l, l.Validate()
+}
+
+// SLO holds per-endpoint objectives keyed by route path (e.g., "/circulating").
+// Endpoints not listed use Default, or the built-in 99.9% / 500ms at 99%.
+type SLO struct {
+	Default   *SLOTarget           `json:"default,omitempty"`
+	Endpoints map[string]SLOTarget `json:"endpoints,omitempty"`
+}
+
+// SLOTarget is one endpoint's objectives: Availability is the fraction of non-5xx
+// responses and LatencyObjective the fraction answered within LatencyMS.
+type SLOTarget struct {
+	Availability     float64 `json:"availability"`
+	LatencyMS        int64   `json:"latency_ms"`
+	LatencyObjective float64 `json:"latency_objective"`
+}
+
+func (t SLOTarget) validate() error {
+	if t.Availability <= 0 || t.Availability >= 1 || t.LatencyObjective <= 0 || t.LatencyObjective >= 1 || t.LatencyMS <= 0 {
+		return errors.New("availability and latency_objective must be in (0,1) and latency_ms positive")
+	}
+	return nil
+}
+
+// Notifications configures event delivery beyond the default webhook.
+type Notifications struct {
+	SMTP *SMTP `json:"smtp,omitempty"`
+	// Email routes; each receives the listed event kinds (all kinds when empty).
+	Email []EmailRoute `json:"email,omitempty"`
+	// PagerDuty and Opsgenie page on-call for failing subsystems (quorum, refresh).
+	PagerDuty *PagerDuty `json:"pagerduty,omitempty"`
+	Opsgenie  *Opsgenie  `json:"opsgenie,omitempty"`
+	// RefreshFailureAfter is how long refreshes must fail continuously before a
+	// refresh_failing event (Go duration, default 10m).
+	RefreshFailureAfter string `json:"refresh_failure_after,omitempty"`
+}
+
+// SMTP is the relay used by email routes. The password is read from the environment
+// variable named by PasswordEnv so it need not live in the file.
+type SMTP struct {
+	Addr        string `json:"addr"` // host:port
+	Username    string `json:"username,omitempty"`
+	PasswordEnv string `json:"password_env,omitempty"`
+	From        string `json:"from"`
+}
+
+// PagerDuty configures the Events API v2 integration; the routing key is read from the
+// environment variable named by RoutingKeyEnv.
+type PagerDuty struct {
+	RoutingKeyEnv string `json:"routing_key_env"`
+	URL           string `json:"url,omitempty"`
+}
+
+// Opsgenie configures the Alert API integration; the API key is read from the
+// environment variable named by APIKeyEnv. URL selects the region (default US).
+type Opsgenie struct {
+	APIKeyEnv string `json:"api_key_env"`
+	URL       string `json:"url,omitempty"`
+}
+
+// EmailRoute sends the listed events to To, rendered with optional Go text/templates
+// over the event (see the README for fields).
+type EmailRoute struct {
+	To      []string `json:"to"`
+	Events  []string `json:"events,omitempty"`
+	Subject string   `json:"subject,omitempty"`
+	Body    string   `json:"body,omitempty"`
+}
+
+// RefreshFailureWindow returns RefreshFailureAfter parsed, or the default.
+func (n Notifications) RefreshFailureWindow() time.Duration {
+	if d, err := time.ParseDuration(n.RefreshFailureAfter); err == nil {
+		return d
+	}
+	return 10 * time.Minute
+}
+
+// Endpoint defines a template-rendered GET endpoint, e.g. a partner-specific shape.
+type Endpoint struct {
+	Path string `json:"path"`
+	// ContentType defaults to application/json.
+	ContentType string `json:"content_type,omitempty"`
+	// Template is a Go text/template; see the README for fields and funcs.
+	Template string `json:"template"`
+}
+
+// Export sinks and formats.
+const (
+	ExportSinkDir  = "dir"
+	ExportSinkHTTP = "http"
+
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+)
+
+// Export periodically writes the latest snapshot as warehouse tables (see package
+// export). Objects are named <table>/dt=<date>/<denom>-<height>-<etag>.<format>.
+type Export struct {
+	// Name labels the export in logs and metrics.
+	Name string `json:"name"`
+	// Sink is "dir" (files under Path, e.g. a mounted bucket) or "http" (PUT to URL
+	// followed by the object name, e.g. a bucket's HTTP API).
+	Sink string `json:"sink"`
+	Path string `json:"path,omitempty"`
+	URL  string `json:"url,omitempty"`
+	// TokenEnv names the environment variable holding a bearer token for http sinks.
+	TokenEnv string `json:"token_env,omitempty"`
+	// Format is ndjson (default) or csv.
+	Format string `json:"format,omitempty"`
+	// Every is how often the latest snapshot is exported when its ETag changed (Go
+	// duration, default 1h).
+	Every string `json:"every,omitempty"`
+	// SkipItems leaves out the item-level table.
+	SkipItems bool `json:"skip_items,omitempty"`
+}
+
+// Interval returns Every parsed, or the default.
+func (e Export) Interval() time.Duration {
+	if d, err := time.ParseDuration(e.Every); err == nil && d > 0 {
+		return d
+	}
+	return time.Hour
+}
+
+func (e Export) validate() error {
+	var errs []error
+	switch e.Sink {
+	case ExportSinkDir:
+		if e.Path == "" {
+			errs = append(errs, errors.New("dir sink needs path"))
+		}
+	case ExportSinkHTTP:
+		if !strings.HasPrefix(e.URL, "http://") && !strings.HasPrefix(e.URL, "https://") {
+			errs = append(errs, errors.New("http sink needs an http(s) url"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown sink %q (dir or http)", e.Sink))
+	}
+	if e.Format != "" && e.Format != ExportFormatNDJSON && e.Format != ExportFormatCSV {
+		errs = append(errs, fmt.Errorf("unknown format %q (ndjson or csv)", e.Format))
+	}
+	if e.Every != "" {
+		if d, err := time.ParseDuration(e.Every); err != nil || d < time.Minute {
+			errs = append(errs, fmt.Errorf("invalid every %q (at least 1m)", e.Every))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Load reads and validates the config file at path. An empty path yields an empty config.
+func Load(path string) (*File, error) {
+	var f File
+	if path == "" {
+		return &f, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if err := f.Validate(); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Validate checks structural constraints that do not need other packages.
+func (f *File) Validate() error {
+	var errs []error
+	seen := map[string]bool{}
+	for i, e := range f.Endpoints {
+		switch {
+		case !strings.HasPrefix(e.Path, "/") || strings.ContainsAny(e.Path, " {}?#"):
+			errs = append(errs, fmt.Errorf("endpoints[%d]: invalid path %q", i, e.Path))
+		case seen[e.Path]:
+			errs = append(errs, fmt.Errorf("endpoints[%d]: duplicate path %q", i, e.Path))
+		case e.Template == "":
+			errs = append(errs, fmt.Errorf("endpoints[%d]: empty template", i))
+		}
+		seen[e.Path] = true
+	}
+	names := map[string]bool{}
+	for i, e := range f.Exports {
+		if e.Name == "" || names[e.Name] {
+			errs = append(errs, fmt.Errorf("exports[%d]: name missing or duplicate", i))
+		}
+		names[e.Name] = true
+		if err := e.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("exports[%d]: %w", i, err))
+		}
+	}
+	n := f.Notifications
+	if len(n.Email) > 0 && (n.SMTP == nil || n.SMTP.Addr == "" || n.SMTP.From == "") {
+		errs = append(errs, errors.New("notifications: email routes need smtp.addr and smtp.from"))
+	}
+	for i, r := range n.Email {
+		if len(r.To) == 0 {
+			errs = append(errs, fmt.Errorf("notifications.email[%d]: no recipients", i))
+		}
+	}
+	if n.PagerDuty != nil && n.PagerDuty.RoutingKeyEnv == "" {
+		errs = append(errs, errors.New("notifications.pagerduty: routing_key_env is required"))
+	}
+	if n.Opsgenie != nil && n.Opsgenie.APIKeyEnv == "" {
+		errs = append(errs, errors.New("notifications.opsgenie: api_key_env is required"))
+	}
+	if d := f.SLO.Default; d != nil {
+		if err := d.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("slo.default: %w", err))
+		}
+	}
+	for path, t := range f.SLO.Endpoints {
+		if err := t.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("slo.endpoints[%q]: %w", path, err))
+		}
+	}
+	for _, d := range [][2]string{{"refresh_ttl", f.Tuning.RefreshTTL}, {"lcd_timeout", f.Tuning.LCDTimeout}} {
+		if _, err := time.ParseDuration(d[1]); d[1] != "" && err != nil {
+			errs = append(errs, fmt.Errorf("tuning: invalid %s %q", d[0], d[1]))
+		}
+	}
+	if f.Tuning.RatePerMin < 0 || f.Tuning.Burst < 0 || f.Tuning.Concurrency < 0 {
+		errs = append(errs, errors.New("tuning: rate_per_min, burst and concurrency must not be negative"))
+	}
+	if f.Tuning.LogLevel != "" {
+		if _, err := logging.ParseLevel(f.Tuning.LogLevel); err != nil {
+			errs = append(errs, fmt.Errorf("tuning: %w", err))
+		}
+	}
+	if n.RefreshFailureAfter != "" {
+		if d, err := time.ParseDuration(n.RefreshFailureAfter); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("notifications: invalid refresh_failure_after %q", n.RefreshFailureAfter))
+		}
+	}
+	return errors.Join(errs...)
+}
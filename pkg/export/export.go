@@ -0,0 +1,326 @@
+// Package export writes snapshots as warehouse tables: one snapshot row, one row per
+// cohort and one row per locked position, as NDJSON or CSV objects in a directory or
+// behind an HTTP PUT endpoint. Objects land under Hive-style date partitions, which
+// BigQuery and most warehouses load or query directly from a bucket.
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+var (
+	exportRuns = metrics.Default.NewCounter(
+		"lumera_supply_export_runs_total",
+		"Warehouse export runs by result (ok or error).",
+		"export", "result",
+	)
+	exportHeight = metrics.Default.NewGauge(
+		"lumera_supply_export_height",
+		"Height of the last snapshot each warehouse export wrote.",
+		"export",
+	)
+)
+
+// Tables written per snapshot.
+const (
+	TableSnapshots = "snapshots"
+	TableCohorts   = "cohorts"
+	TableItems     = "items"
+)
+
+// SnapshotRow is the snapshots table: the headline figures of one snapshot.
+type SnapshotRow struct {
+	Denom          string    `json:"denom"`
+	Height         int64     `json:"height"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	ETag           string    `json:"etag"`
+	PolicyETag     string    `json:"policy_etag"`
+	Total          string    `json:"total"`
+	Circulating    string    `json:"circulating"`
+	NonCirculating string    `json:"non_circulating"`
+	Max            string    `json:"max"`
+	Anomaly        string    `json:"anomaly"`
+}
+
+// CohortRow is the cohorts table: each cohort's sum.
+type CohortRow struct {
+	Denom      string `json:"denom"`
+	Height     int64  `json:"height"`
+	ETag       string `json:"etag"`
+	Cohort     string `json:"cohort"`
+	CohortID   int    `json:"cohort_id"`
+	CohortSlug string `json:"cohort_slug"`
+	Address    string `json:"address"`
+	Amount     string `json:"amount"`
+	ItemCount  int    `json:"item_count"`
+	Tags       string `json:"tags"`
+}
+
+// ItemRow is the items table: one locked position (cohort item).
+type ItemRow struct {
+	Denom      string `json:"denom"`
+	Height     int64  `json:"height"`
+	ETag       string `json:"etag"`
+	Cohort     string `json:"cohort"`
+	CohortID   int    `json:"cohort_id"`
+	CohortSlug string `json:"cohort_slug"`
+	Address    string `json:"address"`
+	Amount     string `json:"amount"`
+	EndDate    string `json:"end_date"`
+	EndUnix    int64  `json:"end_unix"`
+	Permanent  bool   `json:"permanent"`
+}
+
+var (
+	snapshotColumns = []string{"denom", "height", "updated_at", "etag", "policy_etag", "total", "circulating", "non_circulating", "max", "anomaly"}
+	cohortColumns   = []string{"denom", "height", "etag", "cohort", "cohort_id", "cohort_slug", "address", "amount", "item_count", "tags"}
+	itemColumns     = []string{"denom", "height", "etag", "cohort", "cohort_id", "cohort_slug", "address", "amount", "end_date", "end_unix", "permanent"}
+)
+
+func (r SnapshotRow) record() []string {
+	return []string{r.Denom, itoa(r.Height), r.UpdatedAt.Format(time.RFC3339), r.ETag, r.PolicyETag, r.Total, r.Circulating, r.NonCirculating, r.Max, r.Anomaly}
+}
+
+func (r CohortRow) record() []string {
+	return []string{r.Denom, itoa(r.Height), r.ETag, r.Cohort, strconv.Itoa(r.CohortID), r.CohortSlug, r.Address, r.Amount, strconv.Itoa(r.ItemCount), r.Tags}
+}
+
+func (r ItemRow) record() []string {
+	return []string{r.Denom, itoa(r.Height), r.ETag, r.Cohort, strconv.Itoa(r.CohortID), r.CohortSlug, r.Address, r.Amount, r.EndDate, itoa(r.EndUnix), strconv.FormatBool(r.Permanent)}
+}
+
+func itoa(v int64) string { return strconv.FormatInt(v, 10) }
+
+// Rows flattens snap (with its items hydrated) into the three tables.
+func Rows(snap *types.SupplySnapshot) (SnapshotRow, []CohortRow, []ItemRow) {
+	s := SnapshotRow{Denom: snap.Denom, Height: snap.Height, UpdatedAt: snap.UpdatedAt.UTC(), ETag: snap.ETag, PolicyETag: snap.PolicyETag,
+		Total: snap.Total, Circulating: snap.Circulating, NonCirculating: snap.NonCirculating.Sum}
+	if snap.Max != nil {
+		s.Max = *snap.Max
+	}
+	if snap.Anomaly != nil {
+		s.Anomaly = snap.Anomaly.Kind
+	}
+	var cohorts []CohortRow
+	var items []ItemRow
+	for _, c := range snap.NonCirculating.Cohorts {
+		n := c.ItemCount
+		if n == 0 {
+			n = len(c.Items)
+		}
+		cohorts = append(cohorts, CohortRow{Denom: snap.Denom, Height: snap.Height, ETag: snap.ETag, Cohort: c.Name, CohortID: c.ID, CohortSlug: c.Slug, Address: c.Address,
+			Amount: c.Amount, ItemCount: n, Tags: strings.Join(c.Tags, ",")})
+		for _, it := range c.Items {
+			items = append(items, ItemRow{Denom: snap.Denom, Height: snap.Height, ETag: snap.ETag, Cohort: c.Name, CohortID: c.ID, CohortSlug: c.Slug, Address: it.Address,
+				Amount: it.Amount, EndDate: it.EndDate, EndUnix: it.EndUnix, Permanent: it.Permanent})
+		}
+	}
+	return s, cohorts, items
+}
+
+type row interface{ record() []string }
+
+// encode renders rows as NDJSON, or CSV with a header line.
+func encode(format string, columns []string, rows []row) ([]byte, error) {
+	var buf bytes.Buffer
+	if format == config.ExportFormatCSV {
+		w := csv.NewWriter(&buf)
+		_ = w.Write(columns)
+		for _, r := range rows {
+			_ = w.Write(r.record())
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	}
+	enc := json.NewEncoder(&buf)
+	for _, r := range rows {
+		if err := enc.Encode(r); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Sink stores one exported object.
+type Sink interface {
+	Put(name, contentType string, body []byte) error
+}
+
+// DirSink writes objects as files under Dir (e.g. a mounted bucket), atomically.
+type DirSink struct{ Dir string }
+
+func (d DirSink) Put(name, _ string, body []byte) error {
+	p := filepath.Join(d.Dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, body, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+// HTTPSink PUTs objects to URL followed by the object name, with an optional bearer
+// token.
+type HTTPSink struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+func (h HTTPSink) Put(name, contentType string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPut, strings.TrimRight(h.URL, "/")+"/"+name, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+	client := h.Client
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("put %s: status %d", name, resp.StatusCode)
+	}
+	return nil
+}
+
+// Exporter writes the latest snapshot to a sink on a schedule, skipping snapshots it
+// already wrote. Object names are derived from the snapshot, so a repeated export
+// (e.g. after a restart) overwrites rather than duplicates.
+type Exporter struct {
+	name    string
+	sink    Sink
+	format  string
+	every   time.Duration
+	items   bool
+	hydrate func(*types.SupplySnapshot) (*types.SupplySnapshot, error)
+
+	mu   sync.Mutex
+	last string
+}
+
+// New builds the exporter configured by e. hydrate loads offloaded cohort items (see
+// cache.SnapshotCache.Hydrate) and may be nil.
+func New(e config.Export, hydrate func(*types.SupplySnapshot) (*types.SupplySnapshot, error)) (*Exporter, error) {
+	x := &Exporter{name: e.Name, format: e.Format, every: e.Interval(), items: !e.SkipItems, hydrate: hydrate}
+	if x.format == "" {
+		x.format = config.ExportFormatNDJSON
+	}
+	switch e.Sink {
+	case config.ExportSinkDir:
+		x.sink = DirSink{Dir: e.Path}
+	case config.ExportSinkHTTP:
+		var token string
+		if e.TokenEnv != "" {
+			if token = os.Getenv(e.TokenEnv); token == "" {
+				return nil, fmt.Errorf("export %s: %s is not set", e.Name, e.TokenEnv)
+			}
+		}
+		x.sink = HTTPSink{URL: e.URL, Token: token}
+	default:
+		return nil, fmt.Errorf("export %s: unknown sink %q", e.Name, e.Sink)
+	}
+	return x, nil
+}
+
+// ObjectName is where table's rows for snap are stored.
+func ObjectName(table, format string, snap *types.SupplySnapshot) string {
+	return fmt.Sprintf("%s/dt=%s/%s-%d-%s.%s", table, snap.UpdatedAt.UTC().Format(time.DateOnly), snap.Denom, snap.Height, snap.ETag, format)
+}
+
+// Export writes snap's tables unless snap was the last one exported. The snapshots
+// table is written last, so its row signals a complete export.
+func (x *Exporter) Export(snap *types.SupplySnapshot) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if snap == nil || snap.ETag == x.last {
+		return nil
+	}
+	err := x.write(snap)
+	if err != nil {
+		exportRuns.Inc(x.name, "error")
+		return fmt.Errorf("export %s: %w", x.name, err)
+	}
+	exportRuns.Inc(x.name, "ok")
+	exportHeight.Set(float64(snap.Height), x.name)
+	x.last = snap.ETag
+	return nil
+}
+
+func (x *Exporter) write(snap *types.SupplySnapshot) error {
+	if x.hydrate != nil && x.items {
+		full, err := x.hydrate(snap)
+		if err != nil {
+			return err
+		}
+		snap = full
+	}
+	s, cohorts, items := Rows(snap)
+	contentType := "application/x-ndjson"
+	if x.format == config.ExportFormatCSV {
+		contentType = "text/csv"
+	}
+	type table struct {
+		name    string
+		columns []string
+		rows    []row
+	}
+	tables := []table{{TableCohorts, cohortColumns, asRows(cohorts)}}
+	if x.items {
+		tables = append(tables, table{TableItems, itemColumns, asRows(items)})
+	}
+	tables = append(tables, table{TableSnapshots, snapshotColumns, []row{s}})
+	for _, t := range tables {
+		body, err := encode(x.format, t.columns, t.rows)
+		if err != nil {
+			return err
+		}
+		if err := x.sink.Put(ObjectName(t.name, x.format, snap), contentType, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func asRows[T row](rs []T) []row {
+	out := make([]row, len(rs))
+	for i, r := range rs {
+		out[i] = r
+	}
+	return out
+}
+
+// Every is the wait before the next export, for the scheduler: the configured interval,
+// but at most 30s until an export succeeds, so startup does not wait a whole interval
+// for the first snapshot.
+func (x *Exporter) Every() time.Duration {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.last == "" {
+		return min(x.every, 30*time.Second)
+	}
+	return x.every
+}
@@ -0,0 +1,121 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+func testSnapshot() *types.SupplySnapshot {
+	return &types.SupplySnapshot{
+		Denom: "ulume", Height: 42, UpdatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), ETag: "abc", PolicyETag: "p1",
+		Total: "1000", Circulating: "700",
+		NonCirculating: types.NonCircBreakdown{Sum: "300", Cohorts: []types.CohortEntry{
+			{Name: "claim_delayed", Amount: "200", ItemCount: 2, Tags: []string{"community", "claims"}},
+			{Name: "module:gov", Address: "lumera1gov", Amount: "100"},
+		}},
+	}
+}
+
+// hydrate stands in for cache.SnapshotCache.Hydrate: the items were offloaded.
+func hydrate(s *types.SupplySnapshot) (*types.SupplySnapshot, error) {
+	cp := *s
+	cp.NonCirculating.Cohorts = append([]types.CohortEntry(nil), s.NonCirculating.Cohorts...)
+	cp.NonCirculating.Cohorts[0].Items = []types.AddressItem{
+		{Address: "lumera1a", Amount: "150", EndDate: "2027-01-01T00:00:00Z", EndUnix: 1798761600},
+		{Address: "lumera1b", Amount: "50", Permanent: true, EndDate: "forever"},
+	}
+	return &cp, nil
+}
+
+func TestExportDirNDJSON(t *testing.T) {
+	dir := t.TempDir()
+	x, err := New(config.Export{Name: "local", Sink: config.ExportSinkDir, Path: dir}, hydrate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap := testSnapshot()
+	if err := x.Export(snap); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "items/dt=2026-03-01/ulume-42-abc.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("items: %q", b)
+	}
+	var it ItemRow
+	if err := json.Unmarshal([]byte(lines[1]), &it); err != nil || it.Address != "lumera1b" || !it.Permanent || it.Cohort != "claim_delayed" || it.Height != 42 {
+		t.Fatalf("item row %+v, %v", it, err)
+	}
+	b, err = os.ReadFile(filepath.Join(dir, "snapshots/dt=2026-03-01/ulume-42-abc.ndjson"))
+	var s SnapshotRow
+	if err != nil || json.Unmarshal(b, &s) != nil || s.Circulating != "700" || s.NonCirculating != "300" || s.Max != "" {
+		t.Fatalf("snapshot row %s, %v", b, err)
+	}
+	b, _ = os.ReadFile(filepath.Join(dir, "cohorts/dt=2026-03-01/ulume-42-abc.ndjson"))
+	if n := strings.Count(string(b), "\n"); n != 2 || !strings.Contains(string(b), `"tags":"community,claims"`) {
+		t.Fatalf("cohorts: %s", b)
+	}
+
+	// the same snapshot is not written again
+	if err := os.RemoveAll(filepath.Join(dir, "items")); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.Export(snap); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "items")); !os.IsNotExist(err) {
+		t.Fatalf("expected no re-export, stat: %v", err)
+	}
+}
+
+func TestExportHTTPCSV(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer tok" || r.Header.Get("Content-Type") != "text/csv" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got[r.URL.Path] = string(b)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	t.Setenv("EXPORT_TEST_TOKEN", "tok")
+	x, err := New(config.Export{Name: "bucket", Sink: config.ExportSinkHTTP, URL: srv.URL + "/warehouse/", TokenEnv: "EXPORT_TEST_TOKEN",
+		Format: config.ExportFormatCSV, SkipItems: true}, hydrate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := x.Export(testSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("objects: %v", got)
+	}
+	recs, err := csv.NewReader(strings.NewReader(got["/warehouse/cohorts/dt=2026-03-01/ulume-42-abc.csv"])).ReadAll()
+	if err != nil || len(recs) != 3 || strings.Join(recs[0], ",") != strings.Join(cohortColumns, ",") || recs[2][6] != "lumera1gov" {
+		t.Fatalf("cohorts csv %v, %v", recs, err)
+	}
+
+	if _, err := New(config.Export{Name: "bucket", Sink: config.ExportSinkHTTP, URL: srv.URL, TokenEnv: "EXPORT_TEST_UNSET"}, nil); err == nil {
+		t.Fatal("expected an error for an unset token variable")
+	}
+}
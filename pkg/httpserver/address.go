@@ -0,0 +1,93 @@
+package httpserver
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/lumera-labs/lumera-supply/pkg/bech32"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+	"github.com/lumera-labs/lumera-supply/pkg/units"
+)
+
+// address/{addr}: the address's account type, vesting schedule, locked and spendable
+// amounts as of the current snapshot, and the cohorts counting it as non-circulating
+func (s *Server) handleAddress(w http.ResponseWriter, r *http.Request) {
+	addr, err := bech32.Normalize(strings.TrimPrefix(r.URL.Path, "/address/"))
+	if err != nil {
+		http.Error(w, "invalid address", http.StatusBadRequest)
+		return
+	}
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	if s.rejectHistorical(w, r) {
+		return
+	}
+	if s.cfg.Computer == nil {
+		http.Error(w, "computer not configured", http.StatusServiceUnavailable)
+		return
+	}
+	resp, status, err := s.snapshot(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, "/address", err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	snap := resp.snap
+	// the account as of the snapshot, so its lock lines up with the cohort amounts
+	acct, err := s.cfg.Computer.InspectAccount(r.Context(), snap.Denom, addr, snap.Height, snap.EvaluationTime())
+	if err != nil {
+		log.Printf("/address %s: %v", addr, err)
+		http.Error(w, "upstream error", http.StatusBadGateway)
+		return
+	}
+	s.writeJSON(w, r, snap, cacheKey("address:"+addr, r), func(buf io.Writer) error {
+		srv, err := s.hydrated(snap)
+		if err != nil {
+			return err
+		}
+		out := addressPayload{Denom: srv.Denom, Decimals: displayOf(snap).Decimals, Height: srv.Height, UpdatedAt: srv.UpdatedAt,
+			EvaluatedAt: snap.EvaluationTime(), ETag: srv.ETag, PolicyETag: srv.PolicyETag, Account: acct, Cohorts: []addressCohort{}}
+		eachMatch(srv, addr, func(c *cohortEntry, m searchMatch) {
+			out.Cohorts = append(out.Cohorts, addressCohort{Cohort: m.Cohort, CohortID: m.CohortID, CohortSlug: m.CohortSlug, Reason: c.Reason,
+				Amount: m.Amount, EndDate: m.EndDate, EndUnix: m.EndUnix, Permanent: m.Permanent, Source: m.Source, Tier: m.Tier})
+		})
+		out.NonCirculating = len(out.Cohorts) > 0
+		out.Why = addressWhy(acct, out.Cohorts, displayOf(snap))
+		return encodeIndented(out)(buf)
+	})
+}
+
+// addressWhy says how the snapshot counts the address with account acct.
+func addressWhy(acct *types.AccountInspection, cohorts []addressCohort, u displayUnit) string {
+	amount := func(base string) string { return units.Format(base, u.Decimals) + " " + u.Denom }
+	if len(cohorts) > 0 {
+		parts := make([]string, 0, len(cohorts))
+		for _, c := range cohorts {
+			p := fmt.Sprintf("%s in %s (%s", amount(c.Amount), c.Cohort, c.Reason)
+			switch {
+			case c.Permanent:
+				p += ", locked permanently"
+			case c.EndDate != "":
+				p += ", until " + c.EndDate
+			}
+			parts = append(parts, p+")")
+		}
+		return "Counted as non-circulating: " + strings.Join(parts, "; ") + "."
+	}
+	switch {
+	case acct.AccountType == "":
+		return "Not counted as non-circulating: the chain has no account for this address."
+	case acct.Locked != "0":
+		return fmt.Sprintf("Not counted as non-circulating: the account's vesting schedule locks %s, but no cohort of the supply policy includes the address, so its balance counts as circulating.", amount(acct.Locked))
+	}
+	return "Not counted as non-circulating: no cohort includes the address and nothing in it is locked, so its balance circulates."
+}
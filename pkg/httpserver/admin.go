@@ -0,0 +1,209 @@
+package httpserver
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/notify"
+)
+
+// admin guards operator-only endpoints (/debug/*, /admin/*) with the configured bearer token. Without a
+// token the endpoints are disabled and answer 404.
+func (s *Server) admin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.cfg.AdminToken == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="lumera-supply admin"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		next(w, r)
+	}
+}
+
+// authorized reports whether r presents the admin token; always false without one.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.cfg.AdminToken == "" {
+		return false
+	}
+	tok, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(tok), []byte(s.cfg.AdminToken)) == 1
+}
+
+// debugLCDPrefixes are the LCD routes /debug/lcd may proxy: the ones the service itself
+// queries to build snapshots.
+var debugLCDPrefixes = []string{
+	"/cosmos/base/tendermint/v1beta1/blocks/",
+	"/cosmos/bank/v1beta1/supply",
+	"/cosmos/bank/v1beta1/balances/",
+	"/cosmos/auth/v1beta1/accounts/",
+	"/cosmos/auth/v1beta1/module_accounts",
+	"/cosmos/distribution/v1beta1/community_pool",
+	"/ibc/apps/transfer/v1/",
+	"/ibc/core/channel/v1/channels",
+	"/LumeraProtocol/lumera/claim/",
+	"/lumera/claim/",
+}
+
+// debug/lcd?path=/cosmos/...[&height=N]: raw LCD response as the service's client sees it
+func (s *Server) handleDebugLCD(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.LCD == nil {
+		http.Error(w, "lcd client not configured", http.StatusServiceUnavailable)
+		return
+	}
+	path := r.URL.Query().Get("path")
+	if !debugPathAllowed(path) {
+		http.Error(w, "path not allowed", http.StatusBadRequest)
+		return
+	}
+	client := s.cfg.LCD.WithContext(r.Context())
+	if h := r.URL.Query().Get("height"); h != "" {
+		n, err := strconv.ParseInt(h, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid height", http.StatusBadRequest)
+			return
+		}
+		client = client.AtHeight(n)
+	}
+	raw, err := client.Raw(path)
+	if err != nil {
+		log.Printf("/debug/lcd %s: %v", path, err)
+		http.Error(w, "upstream error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", raw.ContentType)
+	w.Header().Set("X-Upstream-Status", strconv.Itoa(raw.Status))
+	w.Header().Set("X-Upstream-URL", s.cfg.LCD.Base()+path)
+	if raw.Truncated {
+		w.Header().Set("X-Truncated", "true")
+	}
+	w.WriteHeader(raw.Status)
+	_, _ = w.Write(raw.Body)
+}
+
+func debugPathAllowed(path string) bool {
+	if !strings.HasPrefix(path, "/") || strings.Contains(path, "..") || strings.Contains(path, "//") {
+		return false
+	}
+	p, _, _ := strings.Cut(path, "?")
+	for _, pre := range debugLCDPrefixes {
+		if strings.HasPrefix(p, pre) {
+			return true
+		}
+	}
+	return false
+}
+
+// admin/watches: GET lists subscriptions, POST {address, threshold?, webhook?} adds
+// one, DELETE ?address= removes one
+func (s *Server) handleWatches(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.Watchlist == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	switch r.Method {
+	case http.MethodGet:
+		_ = encodeIndented(s.cfg.Watchlist.List())(w)
+	case http.MethodPost:
+		var sub notify.Subscription
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&sub); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		saved, err := s.cfg.Watchlist.Add(sub)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = encodeIndented(saved)(w)
+	case http.MethodDelete:
+		ok, err := s.cfg.Watchlist.Remove(r.URL.Query().Get("address"))
+		if err != nil {
+			log.Printf("/admin/watches delete: %v", err)
+		}
+		if !ok {
+			http.Error(w, "unknown address", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// admin/chaos (chaos mode only): GET lists injected upstream faults, PUT [fault...]
+// replaces them, DELETE clears them
+func (s *Server) handleChaos(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.Chaos == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	switch r.Method {
+	case http.MethodGet:
+		_ = encodeIndented(s.cfg.Chaos.Faults())(w)
+	case http.MethodPut:
+		var faults []lcd.Fault
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&faults); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		for _, f := range faults {
+			if f.Status != 0 && (f.Status < 100 || f.Status > 599) || f.LatencyMS < 0 || f.Rate < 0 || f.Rate > 1 {
+				http.Error(w, "invalid fault for path "+strconv.Quote(f.Path), http.StatusBadRequest)
+				return
+			}
+		}
+		s.cfg.Chaos.SetFaults(faults)
+		log.Printf("warn: chaos faults set: %d rule(s)", len(faults))
+		_ = encodeIndented(s.cfg.Chaos.Faults())(w)
+	case http.MethodDelete:
+		s.cfg.Chaos.SetFaults(nil)
+		log.Printf("chaos faults cleared")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// admin/jobs: every scheduled job with its last run; POST /admin/jobs/{name} runs one
+// now (or right after its current run) and answers 202
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.Jobs == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/admin/jobs"), "/")
+	switch {
+	case name == "" && r.Method == http.MethodGet:
+		_ = encodeIndented(s.cfg.Jobs.Jobs())(w)
+	case name != "" && r.Method == http.MethodPost:
+		if err := s.cfg.Jobs.Trigger(name); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("job %s triggered through /admin/jobs", name)
+		w.WriteHeader(http.StatusAccepted)
+	case name == "":
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
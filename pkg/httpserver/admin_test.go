@@ -0,0 +1,85 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+)
+
+func TestDebugLCD(t *testing.T) {
+	var hits []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.RequestURI())
+		switch r.URL.Path {
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1"}}`))
+		case "/cosmos/bank/v1beta1/balances/big":
+			_, _ = w.Write([]byte(strings.Repeat("x", 5<<20)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(upstream.Close)
+	srv := newTestServer(t, nil)
+	srv.cfg.LCD = lcd.NewClient(upstream.URL, upstream.Client())
+
+	debug := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/debug/lcd?path="+url.QueryEscape(path), nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := debug("/cosmos/bank/v1beta1/supply/by_denom", "tok"); rec.Code != http.StatusNotFound {
+		t.Fatalf("without an admin token: %d, want 404", rec.Code)
+	}
+	srv.cfg.AdminToken = "tok"
+	for _, token := range []string{"", "wrong"} {
+		if rec := debug("/cosmos/bank/v1beta1/supply/by_denom", token); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("token %q: %d, want 401", token, rec.Code)
+		}
+	}
+
+	rec := debug("/cosmos/bank/v1beta1/supply/by_denom?denom=ulume", "tok")
+	if rec.Code != 200 || !strings.Contains(rec.Body.String(), `"amount":"1"`) || rec.Header().Get("X-Upstream-Status") != "200" {
+		t.Fatalf("allowed path: %d %s", rec.Code, rec.Body)
+	}
+	if hits[len(hits)-1] != "/cosmos/bank/v1beta1/supply/by_denom?denom=ulume" {
+		t.Fatalf("upstream saw %s", hits[len(hits)-1])
+	}
+
+	// the upstream status is passed through
+	if rec := debug("/cosmos/bank/v1beta1/balances/none", "tok"); rec.Code != 404 || rec.Header().Get("X-Upstream-Status") != "404" {
+		t.Fatalf("upstream 404: %d", rec.Code)
+	}
+
+	rec = debug("/cosmos/bank/v1beta1/balances/big", "tok")
+	if rec.Code != 200 || rec.Header().Get("X-Truncated") != "true" || rec.Body.Len() != 4<<20 {
+		t.Fatalf("oversized body: %d, X-Truncated %q, %d bytes", rec.Code, rec.Header().Get("X-Truncated"), rec.Body.Len())
+	}
+
+	n := len(hits)
+	for _, path := range []string{
+		"/cosmos/bank/v1beta1/supply/../../../staking/v1beta1/pool",
+		"/cosmos/bank/v1beta1/supply//x",
+		"//cosmos/bank/v1beta1/supply",
+		"/cosmos/staking/v1beta1/pool",
+		"cosmos/bank/v1beta1/supply",
+		"http://evil.example/cosmos/bank/v1beta1/supply",
+		"",
+	} {
+		if rec := debug(path, "tok"); rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: %d, want 400", path, rec.Code)
+		}
+	}
+	if len(hits) != n {
+		t.Errorf("rejected paths reached the upstream: %v", hits[n:])
+	}
+}
@@ -0,0 +1,117 @@
+package httpserver
+
+import (
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/recovery"
+)
+
+// balanceCohorts are the cohorts whose items are policy-listed addresses; claim
+// records are excluded (they are not policy-referenced and can number thousands).
+var balanceCohorts = map[string]bool{"foundation_genesis": true, "supernode_bootstraps": true}
+
+// balances: raw reconciliation table for every policy-referenced address, queried at
+// the snapshot height so the figures line up with the cohort amounts
+func (s *Server) handleBalances(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.LCD == nil {
+		http.Error(w, "lcd not configured", http.StatusNotImplemented)
+		return
+	}
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	if s.rejectHistorical(w, r) {
+		return
+	}
+	resp, status, err := s.snapshot(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, "/balances", err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	snap := resp.snap
+	s.writeJSON(w, r, snap, cacheKey("balances", r), func(buf io.Writer) error {
+		srv, err := s.hydrated(snap)
+		if err != nil {
+			return err
+		}
+		var rows []addressBalance
+		for _, c := range srv.NonCirc.Cohorts {
+			if c.Address != "" {
+				rows = append(rows, addressBalance{Address: c.Address, Cohort: c.Name, CohortID: c.ID, CohortSlug: c.Slug, Locked: c.Amount})
+			}
+			if balanceCohorts[c.Name] {
+				for _, it := range c.Items {
+					rows = append(rows, addressBalance{Address: it.Address, Cohort: c.Name, CohortID: c.ID, CohortSlug: c.Slug, Locked: it.Amount})
+				}
+			}
+		}
+		fillBalances(s.cfg.LCD.WithContext(r.Context()).AtHeight(snap.Height), snap.Denom, rows, s.limits().Concurrency)
+		out := balancesPayload{Denom: srv.Denom, Height: srv.Height, UpdatedAt: srv.UpdatedAt, ETag: srv.ETag, PolicyETag: srv.PolicyETag,
+			Addresses: rows}
+		if out.Addresses == nil {
+			out.Addresses = []addressBalance{}
+		}
+		return encodeIndented(out)(buf)
+	})
+}
+
+// fillBalances queries each row's account state with up to n requests in flight. A
+// failed lookup is reported in the row's error and leaves the remaining fields empty.
+func fillBalances(l *lcd.Client, denom string, rows []addressBalance, n int) {
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i := range rows {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(row *addressBalance) {
+			defer func() { <-sem; wg.Done() }()
+			if err := recovery.Guard("balances", func() error { return fillBalance(l, denom, row) }); err != nil {
+				log.Printf("warn: /balances %s: %v", row.Address, err)
+				row.Error = err.Error()
+			}
+		}(&rows[i])
+	}
+	wg.Wait()
+}
+
+func fillBalance(l *lcd.Client, denom string, row *addressBalance) error {
+	bal, err := l.BalanceByDenom(row.Address, denom)
+	if err != nil {
+		return err
+	}
+	spendable, err := l.SpendableBalance(row.Address, denom)
+	if err != nil {
+		return err
+	}
+	dels, err := l.Delegations(row.Address, denom)
+	if err != nil {
+		return err
+	}
+	unbonding, err := l.UnbondingTotal(row.Address)
+	if err != nil {
+		return err
+	}
+	rewards, err := l.DelegatorRewards(row.Address, denom)
+	if err != nil {
+		return err
+	}
+	delegated := new(big.Int)
+	for _, d := range dels {
+		if v, ok := new(big.Int).SetString(d.Amount, 10); ok {
+			delegated.Add(delegated, v)
+		}
+	}
+	row.Balance, row.Spendable, row.Delegated, row.Unbonding, row.Rewards = bal, spendable, delegated.String(), unbonding, rewards
+	return nil
+}
@@ -0,0 +1,75 @@
+package httpserver
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+)
+
+// Default per-IP limits of the bulk downloads (see Config.BulkRatePerMin).
+const (
+	DefaultBulkRatePerMin = 6
+	DefaultBulkBurst      = 6
+)
+
+var bulkRequests = metrics.Default.NewCounter(
+	"lumera_supply_bulk_requests_total",
+	"Bulk download requests by endpoint and result: full, partial (Range), or limited (rejected by the bulk rate limit).",
+	"endpoint", "result",
+)
+
+// bulk wraps a bulk download endpoint. Besides the general limit, each request spends a
+// token of the stricter per-IP bulk bucket, so a few researchers pulling exports cannot
+// crowd out the regular endpoints.
+func (s *Server) bulk(next http.HandlerFunc) http.HandlerFunc {
+	return s.wrap(func(w http.ResponseWriter, r *http.Request) {
+		if !s.bulkLimiter.Allow(r) {
+			bulkRequests.Inc(routeOf(r), "limited")
+			w.Header().Del("Cache-Control")
+			w.Header().Set("Retry-After", strconv.Itoa((60+s.bulkRate-1)/s.bulkRate))
+			http.Error(w, "bulk download rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// serveBulk writes content, a bulk download identified by etag and last modified at
+// modtime, with Range support: the bytes for an ETag never change, so an interrupted
+// download resumes with Range (and If-Range set to the ETag or Last-Modified) instead of
+// starting over. If-Range is resolved here, since ServeContent only compares quoted
+// ETags; a stale one gets the whole body. A Repr-Digest already set is repeated as
+// Content-Digest on full (200) responses.
+func (s *Server) serveBulk(w http.ResponseWriter, r *http.Request, etag string, modtime time.Time, content io.ReadSeeker) {
+	if ir := r.Header.Get("If-Range"); ir != "" {
+		t, err := http.ParseTime(ir)
+		sameETag := etag != "" && strings.Trim(ir, `"`) == strings.Trim(etag, `"`)
+		if !sameETag && (err != nil || modtime.IsZero() || !modtime.Truncate(time.Second).Equal(t)) {
+			r.Header.Del("Range")
+		}
+		r.Header.Del("If-Range")
+	}
+	result := "partial"
+	if r.Header.Get("Range") == "" {
+		result = "full"
+	}
+	bulkRequests.Inc(routeOf(r), result)
+	http.ServeContent(&digestWriter{ResponseWriter: w}, r, "", modtime, content)
+}
+
+// digestWriter repeats Repr-Digest as Content-Digest when the status is 200: only then
+// is the content the whole representation.
+type digestWriter struct {
+	http.ResponseWriter
+}
+
+func (w *digestWriter) WriteHeader(code int) {
+	if d := w.Header().Get("Repr-Digest"); d != "" && code == http.StatusOK {
+		w.Header().Set("Content-Digest", d)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
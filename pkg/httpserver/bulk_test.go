@@ -0,0 +1,89 @@
+package httpserver
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/ratelimit"
+)
+
+// newBulkServer is newTestServer with a bulk limit the tests do not reach.
+func newBulkServer(t *testing.T) *Server {
+	t.Helper()
+	srv := newTestServer(t, nil)
+	srv.bulkLimiter = ratelimit.New(600, 600)
+	return srv
+}
+
+func serveBulkRequest(srv *Server, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBulkRange(t *testing.T) {
+	srv := newBulkServer(t)
+	for _, path := range []string{"/snapshot.json", "/non_circulating/items.ndjson"} {
+		full := serveBulkRequest(srv, path, nil)
+		if full.Code != 200 || full.Body.Len() < 20 {
+			t.Fatalf("%s: %d with %d bytes", path, full.Code, full.Body.Len())
+		}
+		etag := full.Header().Get("ETag")
+		body := full.Body.Bytes()
+
+		// a matching If-Range resumes
+		rec := serveBulkRequest(srv, path, map[string]string{"Range": "bytes=10-", "If-Range": `"` + etag + `"`})
+		if rec.Code != http.StatusPartialContent || !bytes.Equal(rec.Body.Bytes(), body[10:]) {
+			t.Fatalf("%s with a matching If-Range: %d, want 206 with the rest of the body", path, rec.Code)
+		}
+		if rec.Header().Get("Content-Digest") != "" {
+			t.Errorf("%s: Content-Digest on a 206", path)
+		}
+
+		// a stale If-Range gets the whole current body
+		rec = serveBulkRequest(srv, path, map[string]string{"Range": "bytes=10-", "If-Range": `"stale"`})
+		if rec.Code != 200 || !bytes.Equal(rec.Body.Bytes(), body) {
+			t.Fatalf("%s with a stale If-Range: %d, want 200 with the whole body", path, rec.Code)
+		}
+	}
+
+	full := serveBulkRequest(srv, "/snapshot.json", nil)
+	if d := full.Header().Get("Content-Digest"); d == "" || d != full.Header().Get("Repr-Digest") {
+		t.Errorf("/snapshot.json 200: Content-Digest %q, want the Repr-Digest", d)
+	}
+
+	// the unfiltered export was spilled to the store, under the snapshot's items
+	etag := full.Header().Get("ETag")
+	f, err := srv.cfg.Store.Export(etag, "items.ndjson", func(io.Writer) error { return errors.New("not written") })
+	if err != nil {
+		t.Errorf("export not in the store: %v", err)
+	} else {
+		f.Close()
+	}
+
+	// filtered exports are streamed: no Range
+	rec := serveBulkRequest(srv, "/non_circulating/items.ndjson?ends_after=2000-01-01", map[string]string{"Range": "bytes=10-"})
+	if rec.Code != 200 || rec.Header().Get("Accept-Ranges") != "none" {
+		t.Errorf("filtered export: %d, Accept-Ranges %q", rec.Code, rec.Header().Get("Accept-Ranges"))
+	}
+}
+
+func TestHistoryIsBulk(t *testing.T) {
+	srv := newBulkServer(t)
+	srv.bulkLimiter = ratelimit.New(1, 1)
+	// without a history store /history answers 501, but still spends the bulk token
+	if rec := serveBulkRequest(srv, "/history", nil); rec.Code != http.StatusNotImplemented {
+		t.Fatalf("/history: %d", rec.Code)
+	}
+	if rec := serveBulkRequest(srv, "/history", nil); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("/history past the bulk limit: %d, want 429", rec.Code)
+	}
+}
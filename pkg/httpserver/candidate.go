@@ -0,0 +1,47 @@
+package httpserver
+
+import (
+	"math/big"
+	"net/http"
+
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+)
+
+// policy/candidate: the candidate policy's figures next to the active ones at the same
+// height, with the cohort-level diff from active to candidate
+func (s *Server) handleCandidate(w http.ResponseWriter, r *http.Request) {
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	var c *supply.Candidate
+	if s.cfg.Computer != nil {
+		c = s.cfg.Computer.Candidate(denom)
+	}
+	if c == nil {
+		http.Error(w, "no candidate policy evaluation (none loaded, or no snapshot since it was)", http.StatusNotFound)
+		return
+	}
+	etag := `"` + c.Active.ETag + "+" + c.Candidate.PolicyETag + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	a, _ := new(big.Int).SetString(c.Active.Circulating, 10)
+	b, _ := new(big.Int).SetString(c.Candidate.Circulating, 10)
+	out := candidatePayload{
+		Denom:     c.Active.Denom,
+		Height:    c.Active.Height,
+		UpdatedAt: c.Active.UpdatedAt,
+		Active:    policyFigures{PolicyETag: c.Active.PolicyETag, Circulating: c.Active.Circulating, NonCirculating: c.Active.NonCirculating.Sum},
+		Candidate: policyFigures{PolicyETag: c.Candidate.PolicyETag, Circulating: c.Candidate.Circulating, NonCirculating: c.Candidate.NonCirculating.Sum},
+		Diff:      supply.Diff(c.Active, c.Candidate),
+	}
+	if a != nil && b != nil {
+		out.CirculatingDelta = new(big.Int).Sub(b, a).String()
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("ETag", etag)
+	_ = encodeIndented(out)(w)
+}
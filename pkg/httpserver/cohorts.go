@@ -0,0 +1,31 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+)
+
+// cohorts: the cohorts of the latest snapshot with their identifiers, reasons, sources
+// and item counts but no amounts, for UIs building filters. It reads the cached
+// snapshot without refreshing it, so it is exempt from the rate limit.
+func (s *Server) handleCohorts(w http.ResponseWriter, r *http.Request) {
+	snap, _ := s.cfg.Cache.Get()
+	if snap == nil {
+		w.Header().Del("Cache-Control")
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, "no snapshot computed yet", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Header.Get("If-None-Match") == snap.ETag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	out := cohortsPayload{Height: snap.Height, UpdatedAt: snap.UpdatedAt, ETag: snap.ETag, PolicyETag: snap.PolicyETag,
+		Cohorts: make([]cohortInfo, 0, len(snap.NonCirculating.Cohorts))}
+	for _, c := range snap.NonCirculating.Cohorts {
+		out.Cohorts = append(out.Cohorts, cohortInfo{Name: c.Name, ID: c.ID, Slug: c.Slug, Reason: c.Reason, Source: supply.CohortSource(c.Name),
+			Address: c.Address, ItemCount: c.ItemCount, Tags: c.Tags, DisclosureURL: c.DisclosureURL, Notes: c.Notes})
+	}
+	s.writeJSON(w, r, snap, cacheKey("cohorts", r), encodeIndented(out))
+}
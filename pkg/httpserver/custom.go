@@ -0,0 +1,130 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+)
+
+// customData is the value custom endpoint templates execute against. Amounts are
+// base-unit strings; use the template funcs to format them.
+type customData struct {
+	Denom          string
+	Symbol         string
+	Decimals       int
+	Height         int64
+	UpdatedAt      time.Time
+	ETag           string
+	PolicyETag     string
+	Total          string
+	Circulating    string
+	NonCirculating string
+	Max            string // empty when there is no max supply
+	Cohorts        []cohortEntry
+}
+
+// customFuncs extends the /summary funcs with helpers for machine-readable shapes,
+// formatting amounts in u.
+func customFuncs(u displayUnit) template.FuncMap {
+	fm := summaryFuncs(u)
+	// decimal: display amount without separators (1234.5)
+	fm["decimal"] = func(base string) string { return decimalAmount(base, u.Decimals) }
+	// json: v encoded as a JSON value (quoted and escaped for strings)
+	fm["json"] = func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	return fm
+}
+
+// CompileEndpoint parses a custom endpoint's template; main uses it to fail fast.
+func CompileEndpoint(e config.Endpoint) (*template.Template, error) {
+	t, err := template.New(e.Path).Funcs(customFuncs(defaultUnit)).Parse(e.Template)
+	if err != nil {
+		return nil, fmt.Errorf("endpoint %s: %w", e.Path, err)
+	}
+	return t, nil
+}
+
+// registerCustom mounts the configured template endpoints. Endpoints that fail to
+// compile or collide with a built-in route are logged and skipped.
+func (s *Server) registerCustom(eps []config.Endpoint) {
+	for _, e := range eps {
+		t, err := CompileEndpoint(e)
+		if err != nil {
+			log.Printf("warn: %v", err)
+			continue
+		}
+		// any route already serving the path counts, e.g. /non_circulating/foo is a cohort
+		if _, pattern := s.mux.Handler(&http.Request{Method: http.MethodGet, URL: &url.URL{Path: e.Path}}); pattern != "" {
+			log.Printf("warn: endpoint %s collides with the built-in route %s; skipped", e.Path, pattern)
+			continue
+		}
+		ct := e.ContentType
+		if ct == "" {
+			ct = "application/json; charset=utf-8"
+		}
+		s.mux.HandleFunc(e.Path, s.wrap(s.handleCustom(e.Path, ct, t)))
+	}
+}
+
+// usesCohorts reports whether any template defined in t (the root and every {{define}})
+// refers to .Cohorts, so the cohort items must be loaded before executing it.
+func usesCohorts(t *template.Template) bool {
+	for _, tt := range t.Templates() {
+		if tt.Tree != nil && strings.Contains(tt.Tree.Root.String(), ".Cohorts") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) handleCustom(path, contentType string, t *template.Template) http.HandlerFunc {
+	cohorts := usesCohorts(t)
+	return func(w http.ResponseWriter, r *http.Request) {
+		denom, ok := s.parseDenom(r)
+		if !ok {
+			http.Error(w, "invalid denom", http.StatusBadRequest)
+			return
+		}
+		resp, status, err := s.snapshot(w, r, denom)
+		if err != nil {
+			s.snapshotFailed(w, path, err)
+			return
+		}
+		if status == http.StatusNotModified {
+			w.WriteHeader(status)
+			return
+		}
+		snap := resp.snap
+		w.Header().Set("Content-Type", contentType)
+		s.writeJSON(w, r, snap, cacheKey("custom:"+path, r), func(buf io.Writer) error {
+			srv := s.project(snap)
+			if cohorts {
+				if srv, err = s.hydrated(snap); err != nil {
+					return err
+				}
+			}
+			u := displayOf(snap)
+			tu, err := withUnit(t, customFuncs, u)
+			if err != nil {
+				return err
+			}
+			d := customData{Denom: srv.Denom, Symbol: u.symbol(), Decimals: u.Decimals, Height: srv.Height, UpdatedAt: srv.UpdatedAt,
+				ETag: srv.ETag, PolicyETag: srv.PolicyETag, Total: srv.Total, Circulating: srv.Circulating, NonCirculating: srv.NonCirc.Sum,
+				Cohorts: srv.NonCirc.Cohorts}
+			if srv.Max != nil {
+				d.Max = *srv.Max
+			}
+			return tu.Execute(buf, d)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package httpserver
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+)
+
+func TestCustomEndpoints(t *testing.T) {
+	srv := newTestServer(t, nil)
+	srv.registerCustom([]config.Endpoint{
+		// .Cohorts only in a defined template: the items must still be loaded
+		{Path: "/partner/items", ContentType: "text/plain", Template: `{{define "items"}}{{range .Cohorts}}{{len .Items}},{{end}}{{end}}{{template "items" .}}`},
+		{Path: "/partner/total", ContentType: "text/plain", Template: `{{.Total}}`},
+		{Path: "/non_circulating/partner", Template: `{}`},
+		{Path: "/address/partner", Template: `{}`},
+		{Path: "/total", Template: `{}`},
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/partner/items", nil))
+	if rec.Code != 200 {
+		t.Fatalf("/partner/items: %d %s", rec.Code, rec.Body)
+	}
+	var items int
+	for _, n := range strings.Split(strings.TrimSuffix(rec.Body.String(), ","), ",") {
+		if n != "0" {
+			items++
+		}
+	}
+	if items == 0 {
+		t.Fatalf("no cohort items loaded: %s", rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/partner/total", nil))
+	if total := get(t, srv, "/total")["total"]; rec.Code != 200 || rec.Body.String() != total {
+		t.Fatalf("/partner/total: %d %q, want %v", rec.Code, rec.Body, total)
+	}
+
+	// built-in routes keep serving the paths they cover
+	for path, want := range map[string]int{"/non_circulating/partner": 404, "/address/partner": 400} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want || rec.Body.String() == "{}" {
+			t.Errorf("%s: %d %q, want the built-in %d", path, rec.Code, rec.Body, want)
+		}
+	}
+	if _, ok := get(t, srv, "/total")["total"]; !ok {
+		t.Error("/total was replaced")
+	}
+}
@@ -0,0 +1,24 @@
+package httpserver
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// dashboardHTML is a self-contained page (no external assets) that polls /total,
+// /non_circulating and /status with relative URLs, so it also works under a prefix.
+//
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// dashboard: human view of the supply figures, cohort composition and refresh health
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if !s.limiter.Allow(r) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_, _ = w.Write(dashboardHTML)
+}
@@ -0,0 +1,33 @@
+package httpserver
+
+import (
+	"net/http"
+)
+
+// diagnostics (admin): what the latest snapshot computation for the denom found, and the
+// latest one breaking an invariant: invariant violations, failed cohort fetches and
+// addresses counted by several cohorts, with the snapshot currently served. It answers
+// "why do the numbers look wrong?", including for rejected snapshots that never reach
+// the cache.
+func (s *Server) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	if s.cfg.Computer == nil {
+		http.Error(w, "computer not configured", http.StatusServiceUnavailable)
+		return
+	}
+	latest, last := s.cfg.Computer.Diagnostics(denom)
+	if latest == nil {
+		http.Error(w, "no snapshot computed for this denom yet", http.StatusNotFound)
+		return
+	}
+	out := diagnosticsPayload{Denom: denom, Latest: latest, LastViolation: last}
+	if snap, _ := s.cfg.Cache.Get(); snap != nil && snap.Denom == denom {
+		out.ServedHeight, out.ServedETag = snap.Height, snap.ETag
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = encodeIndented(out)(w)
+}
@@ -0,0 +1,88 @@
+package httpserver
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/lumera-labs/lumera-supply/pkg/store"
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// diff?from=<etag|height>&to=<etag|height>: cohort/item-level changes between stored
+// snapshots; to defaults to the current snapshot
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.History == nil {
+		http.Error(w, "history store not configured", http.StatusNotImplemented)
+		return
+	}
+	q := r.URL.Query()
+	if q.Get("from") == "" {
+		http.Error(w, "missing from (etag or height)", http.StatusBadRequest)
+		return
+	}
+	from, status, msg := s.resolveRef(q.Get("from"))
+	if status != http.StatusOK {
+		http.Error(w, "from: "+msg, status)
+		return
+	}
+	var to *types.SupplySnapshot
+	if q.Get("to") != "" {
+		if to, status, msg = s.resolveRef(q.Get("to")); status != http.StatusOK {
+			http.Error(w, "to: "+msg, status)
+			return
+		}
+	} else {
+		resp, _, err := s.snapshot(w, withoutConditional(r), from.Denom)
+		if err != nil || resp == nil {
+			s.snapshotFailed(w, "/diff", err)
+			return
+		}
+		if to, err = s.cfg.Cache.Hydrate(resp.snap); err != nil {
+			log.Printf("/diff hydrate: %v", err)
+			http.Error(w, "store error", http.StatusInternalServerError)
+			return
+		}
+	}
+	if from.Denom != to.Denom {
+		http.Error(w, "snapshots have different denoms", http.StatusBadRequest)
+		return
+	}
+	etag := `"` + from.ETag + ".." + to.ETag + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	_ = encodeIndented(supply.Diff(from, to))(w)
+}
+
+// withoutConditional drops If-None-Match so s.snapshot always returns a body; /diff
+// has its own ETag.
+func withoutConditional(r *http.Request) *http.Request {
+	r2 := r.Clone(r.Context())
+	r2.Header.Del("If-None-Match")
+	return r2
+}
+
+// resolveRef loads a stored snapshot by ETag or, for an all-digit ref, the latest one at
+// or below that height. It returns an HTTP status and message on failure.
+func (s *Server) resolveRef(ref string) (*types.SupplySnapshot, int, string) {
+	var snap *types.SupplySnapshot
+	var err error
+	if h, perr := strconv.ParseInt(ref, 10, 64); perr == nil && len(ref) < 20 {
+		snap, err = s.cfg.History.AtHeight(h)
+	} else {
+		snap, err = s.cfg.History.ByETag(ref)
+	}
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return nil, http.StatusNotFound, "no stored snapshot for " + ref
+	case err != nil:
+		log.Printf("/diff load %s: %v", ref, err)
+		return nil, http.StatusInternalServerError, "store error"
+	}
+	return snap, http.StatusOK, ""
+}
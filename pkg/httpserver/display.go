@@ -0,0 +1,65 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+	"github.com/lumera-labs/lumera-supply/pkg/units"
+)
+
+// defaultDecimals is the display exponent assumed for denoms the chain has no bank
+// metadata for.
+const defaultDecimals = 6
+
+// displayUnit is the unit amounts of a denom are shown in.
+type displayUnit struct {
+	Denom    string
+	Decimals int
+}
+
+// key is the display denom as a JSON field suffix ("lume", "atom").
+func (u displayUnit) key() string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(u.Denom))
+}
+
+// symbol is the display denom as written next to amounts ("LUME").
+func (u displayUnit) symbol() string { return strings.ToUpper(u.Denom) }
+
+// displayOf returns the unit snap's amounts are shown in: the one the computer read
+// from the chain's bank metadata, or the denom's symbol with defaultDecimals when the
+// chain has none.
+func displayOf(snap *types.SupplySnapshot) displayUnit {
+	if snap.Display != nil {
+		return displayUnit{Denom: snap.Display.Denom, Decimals: snap.Display.Decimals}
+	}
+	return displayUnit{Denom: strings.ToLower(units.Symbol(snap.Denom)), Decimals: defaultDecimals}
+}
+
+// wantsDisplay reports whether the request asked for ?display=1.
+func wantsDisplay(r *http.Request) bool {
+	v := r.URL.Query().Get("display")
+	return v == "1" || v == "true"
+}
+
+// displaySibling adds "<field>_<display denom>" next to string amounts: the amount in
+// display units as an exact decimal string ("circulating_lume": "12345.678901").
+func displaySibling(u displayUnit) siblingFunc {
+	suffix := "_" + u.key()
+	return func(key, value string) (string, []byte, bool) {
+		if !amountFields[key] {
+			return "", nil, false
+		}
+		if _, ok := parseAmount(value); !ok {
+			return "", nil, false
+		}
+		b, _ := json.Marshal(decimalAmount(value, u.Decimals))
+		return key + suffix, b, true
+	}
+}
@@ -0,0 +1,90 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/cache"
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+	"github.com/lumera-labs/lumera-supply/pkg/units"
+)
+
+// newDisplayServer serves a snapshot computed from a minimal LCD whose bank metadata
+// shows ulume as display with the given exponent.
+func newDisplayServer(t *testing.T, display string, decimals int) *Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-06-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"123456789000"}}`))
+		case "/cosmos/bank/v1beta1/denoms_metadata/ulume":
+			fmt.Fprintf(w, `{"metadata":{"base":"ulume","display":%q,"denom_units":[{"denom":"ulume","exponent":0},{"denom":%q,"exponent":%d}]}}`, display, display, decimals)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	comp := supply.NewComputer(lcd.NewClient(ts.URL, ts.Client()), nil)
+	c := cache.NewSnapshotCache(comp, cache.Options{TTL: time.Hour})
+	if _, err := c.Update(context.Background(), "ulume"); err != nil {
+		t.Fatal(err)
+	}
+	return New(Config{Cache: c, Computer: comp, DefaultDenom: "ulume"})
+}
+
+// get serves path on srv and decodes the JSON body.
+func get(t *testing.T, srv *Server, path string) map[string]any {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+	if rec.Code != 200 {
+		t.Fatalf("%s: %d %s", path, rec.Code, rec.Body)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return out
+}
+
+func TestDisplaySiblingsOptIn(t *testing.T) {
+	// an exponent other than the default shows the chain's metadata is used
+	srv := newDisplayServer(t, "lume", 8)
+
+	plain := get(t, srv, "/circulating")
+	if _, ok := plain["circulating_lume"]; ok {
+		t.Fatalf("display sibling without ?display=1: %v", plain)
+	}
+	if plain["decimals"] != 8.0 {
+		t.Fatalf("decimals %v", plain["decimals"])
+	}
+	out := get(t, srv, "/circulating?display=1")
+	circ, _ := out["circulating"].(string)
+	if want := decimalAmount(circ, 8); out["circulating_lume"] != want {
+		t.Fatalf("circulating_lume = %v, want %s", out["circulating_lume"], want)
+	}
+	// the plain body is not served from the cached ?display=1 one
+	if again := get(t, srv, "/circulating"); len(again) != len(plain) {
+		t.Fatalf("fields %v after ?display=1, want %v", again, plain)
+	}
+}
+
+func TestSummaryDisplayUnit(t *testing.T) {
+	srv := newDisplayServer(t, "klume", 9)
+	snap, _ := srv.cfg.Cache.Get()
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/summary", nil))
+	want := "Circulating: " + units.Human(snap.Circulating, 9) + " KLUME"
+	if got := rec.Body.String(); !strings.HasPrefix(got, want) {
+		t.Fatalf("summary %q, want prefix %q", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package httpserver
+
+import (
+	"log"
+	"net/http"
+)
+
+// admin/evaluate?now=<RFC3339|YYYY-MM-DD>[&verbose=1]: what-if figures for the latest chain
+// state with every lock evaluated at now instead of the block time. Computed on each
+// request and never cached; public endpoints reject ?now= (see rejectHistorical).
+func (s *Server) handleEvaluate(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.Computer == nil {
+		http.Error(w, "computer not configured", http.StatusServiceUnavailable)
+		return
+	}
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	at, ok := parseFilterTime(r.URL.Query().Get("now"))
+	if !ok || at.IsZero() {
+		http.Error(w, "invalid now (RFC3339 or YYYY-MM-DD expected)", http.StatusBadRequest)
+		return
+	}
+	snap, err := s.cfg.Computer.ComputeSnapshotAt(r.Context(), denom, at)
+	if err != nil {
+		log.Printf("/admin/evaluate error: %v", err)
+		http.Error(w, "upstream error", http.StatusBadGateway)
+		return
+	}
+	srv := toTypesSnapshot(snap)
+	breakdown := srv.NonCirc
+	if v := r.URL.Query().Get("verbose"); v != "1" && v != "true" {
+		breakdown.Cohorts = withoutItems(breakdown.Cohorts)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = encodeIndented(evaluatePayload{
+		Denom:          snap.Denom,
+		Decimals:       displayOf(snap).Decimals,
+		Height:         snap.Height,
+		UpdatedAt:      snap.UpdatedAt,
+		EvaluatedAt:    snap.EvaluatedAt,
+		PolicyETag:     snap.PolicyETag,
+		Total:          snap.Total,
+		Circulating:    snap.Circulating,
+		NonCirculating: breakdown,
+		Anomaly:        snap.Anomaly,
+	})(w)
+}
@@ -0,0 +1,34 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// explained answers ?explain=1 on /non_circulating and /non_circulating/{cohort}: it
+// replaces snap with its recomputation at the same height carrying, per cohort and per
+// item, the data source used, the raw inputs and the formula applied (see
+// supply.Computer.ExplainSnapshot). Traces show every input the service reads and
+// cost a full computation, so they need the admin token. Without ?explain= snap is
+// returned as is; ok is false once an error has been written.
+func (s *Server) explained(w http.ResponseWriter, r *http.Request, route string, snap *types.SupplySnapshot) (*types.SupplySnapshot, bool) {
+	if v := r.URL.Query().Get("explain"); v == "" || v == "0" || v == "false" {
+		return snap, true
+	}
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="lumera-supply admin"`)
+		http.Error(w, "explain requires the admin token", http.StatusUnauthorized)
+		return nil, false
+	}
+	if s.cfg.Computer == nil {
+		http.Error(w, "computer not configured", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	ex, err := s.cfg.Computer.ExplainSnapshot(r.Context(), snap.Denom, snap.Height)
+	if err != nil {
+		s.snapshotFailed(w, route+"?explain", err)
+		return nil, false
+	}
+	return ex, true
+}
@@ -0,0 +1,134 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// itemFilter selects cohort items by address, end date and claim tier (?address=,
+// ?ends_before=, ?ends_after=, ?tier=). The zero value matches everything.
+type itemFilter struct {
+	addresses map[string]bool
+	before    time.Time
+	after     time.Time
+	tiers     map[int]bool
+}
+
+// itemFilterParams are the query parameters read by parseItemFilter (for cache keys).
+var itemFilterParams = []string{"address", "ends_before", "ends_after", "tier"}
+
+// parseItemFilter reads the item filters; address and tier take comma-separated lists
+// and the dates accept RFC3339 or YYYY-MM-DD (UTC midnight).
+func parseItemFilter(r *http.Request) (itemFilter, string) {
+	q := r.URL.Query()
+	var f itemFilter
+	if v := q.Get("address"); v != "" {
+		f.addresses = map[string]bool{}
+		for _, a := range strings.Split(v, ",") {
+			// bech32 addresses are case-insensitive; snapshots hold the lowercase form
+			if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+				f.addresses[a] = true
+			}
+		}
+	}
+	var ok bool
+	if f.before, ok = parseFilterTime(q.Get("ends_before")); !ok {
+		return f, "invalid ends_before (RFC3339 or YYYY-MM-DD expected)"
+	}
+	if f.after, ok = parseFilterTime(q.Get("ends_after")); !ok {
+		return f, "invalid ends_after (RFC3339 or YYYY-MM-DD expected)"
+	}
+	if v := q.Get("tier"); v != "" {
+		f.tiers = map[int]bool{}
+		for _, t := range strings.Split(v, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(t))
+			if err != nil || n < 1 || n > 4 {
+				return f, "invalid tier (1 to 4 expected)"
+			}
+			f.tiers[n] = true
+		}
+	}
+	return f, ""
+}
+
+func parseFilterTime(v string) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+func (f itemFilter) active() bool {
+	return f.addresses != nil || !f.before.IsZero() || !f.after.IsZero() || f.tiers != nil
+}
+
+func (f itemFilter) dated() bool { return !f.before.IsZero() || !f.after.IsZero() }
+
+// match reports whether an item passes the filter. Permanent locks never end, so they
+// fail ends_before and pass ends_after; items without an end date fail any date filter,
+// and items without a tier any tier filter.
+func (f itemFilter) match(it addressItem) bool {
+	if f.addresses != nil && !f.addresses[it.Address] {
+		return false
+	}
+	if f.tiers != nil && !f.tiers[it.Tier] {
+		return false
+	}
+	if !f.dated() {
+		return true
+	}
+	if it.Permanent {
+		return f.before.IsZero()
+	}
+	if it.EndUnix == 0 {
+		return false
+	}
+	end := time.Unix(it.EndUnix, 0)
+	if !f.before.IsZero() && !end.Before(f.before) {
+		return false
+	}
+	if !f.after.IsZero() && !end.After(f.after) {
+		return false
+	}
+	return true
+}
+
+// items returns the matching items (all of them when the filter is inactive).
+func (f itemFilter) items(items []addressItem) []addressItem {
+	if !f.active() {
+		return items
+	}
+	var out []addressItem
+	for _, it := range items {
+		if f.match(it) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// apply returns the cohorts with only matching items. Cohorts left without items are
+// dropped, except single-address cohorts whose address matches an address-only filter.
+func (f itemFilter) apply(cohorts []cohortEntry) []cohortEntry {
+	if !f.active() {
+		return cohorts
+	}
+	out := make([]cohortEntry, 0, len(cohorts))
+	for _, c := range cohorts {
+		items := f.items(c.Items)
+		if len(items) == 0 && !(c.Address != "" && !f.dated() && f.tiers == nil && f.addresses[c.Address]) {
+			continue
+		}
+		c.Items = items
+		out = append(out, c)
+	}
+	return out
+}
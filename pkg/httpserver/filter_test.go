@@ -0,0 +1,125 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func mustFilter(t *testing.T, query string) itemFilter {
+	t.Helper()
+	f, msg := parseItemFilter(httptest.NewRequest("GET", "/non_circulating?"+query, nil))
+	if msg != "" {
+		t.Fatalf("%s: %s", query, msg)
+	}
+	return f
+}
+
+func TestItemFilterMatch(t *testing.T) {
+	end := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	dated := addressItem{Address: "lumera1a", Amount: "1", EndUnix: end, Tier: 2}
+	permanent := addressItem{Address: "lumera1b", Amount: "1", Permanent: true}
+	undated := addressItem{Address: "lumera1c", Amount: "1"}
+
+	for _, tc := range []struct {
+		query string
+		it    addressItem
+		want  bool
+	}{
+		{"", undated, true},
+		{"ends_before=2027-01-01", dated, true},
+		{"ends_before=2026-01-01", dated, false},
+		{"ends_after=2025-12-31", dated, true},
+		{"ends_after=2026-01-01", dated, false},
+		{"ends_after=2025-01-01&ends_before=2027-01-01", dated, true},
+		// permanent locks never end
+		{"ends_before=2100-01-01", permanent, false},
+		{"ends_after=2100-01-01", permanent, true},
+		// items without an end date fail any date filter
+		{"ends_before=2100-01-01", undated, false},
+		{"ends_after=2000-01-01", undated, false},
+		{"address=LUMERA1A", dated, true},
+		{"address=lumera1x,lumera1b", dated, false},
+		{"tier=1,2", dated, true},
+		{"tier=3", dated, false},
+		{"tier=1", undated, false},
+	} {
+		if got := mustFilter(t, tc.query).match(tc.it); got != tc.want {
+			t.Errorf("%q on %+v: %v, want %v", tc.query, tc.it, got, tc.want)
+		}
+	}
+}
+
+func TestItemFilterApply(t *testing.T) {
+	cohorts := []cohortEntry{
+		{Name: "module", Address: "lumera1m"},
+		{Name: "vesting", Items: []addressItem{
+			{Address: "lumera1a", Amount: "1", EndUnix: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Unix()},
+			{Address: "lumera1b", Amount: "2", Permanent: true},
+		}},
+	}
+	for _, tc := range []struct {
+		query string
+		want  map[string]int // cohort name to kept items
+	}{
+		{"", map[string]int{"module": 0, "vesting": 2}},
+		// a single-address cohort is kept for an address-only filter naming it
+		{"address=lumera1m", map[string]int{"module": 0}},
+		{"address=lumera1m&ends_after=2000-01-01", nil},
+		{"address=lumera1a,lumera1m", map[string]int{"module": 0, "vesting": 1}},
+		{"ends_before=2027-01-01", map[string]int{"vesting": 1}},
+		{"ends_after=2027-01-01", map[string]int{"vesting": 1}},
+		{"tier=1", nil},
+	} {
+		got := mustFilter(t, tc.query).apply(cohorts)
+		if len(got) != len(tc.want) {
+			t.Errorf("%q: %d cohorts, want %d", tc.query, len(got), len(tc.want))
+			continue
+		}
+		for _, c := range got {
+			if n, ok := tc.want[c.Name]; !ok || n != len(c.Items) {
+				t.Errorf("%q: %s with %d items, want %v", tc.query, c.Name, len(c.Items), tc.want)
+			}
+		}
+	}
+	if len(cohorts[1].Items) != 2 {
+		t.Fatalf("apply changed its input: %+v", cohorts[1])
+	}
+}
+
+func TestItemFilterInvalid(t *testing.T) {
+	for _, q := range []string{"ends_before=soon", "ends_after=2025-13-01", "tier=5", "tier=x"} {
+		if _, msg := parseItemFilter(httptest.NewRequest("GET", "/non_circulating?"+q, nil)); msg == "" {
+			t.Errorf("%s accepted", q)
+		}
+	}
+}
+
+func TestCohortEndpoint(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	all := get(t, srv, "/non_circulating/foundation_genesis")
+	items := all["cohort"].(map[string]any)["items"].([]any)
+	if len(items) == 0 {
+		t.Fatalf("no items: %v", all)
+	}
+	// the mock's vesting ends after the snapshot: a date before it keeps none
+	none := get(t, srv, "/non_circulating/foundation_genesis?ends_before=2025-01-01")
+	if got, _ := none["cohort"].(map[string]any)["items"].([]any); len(got) != 0 {
+		t.Errorf("ends_before=2025-01-01 kept %d items", len(got))
+	}
+
+	for path, want := range map[string]int{
+		"/non_circulating/no_such_cohort":                         http.StatusNotFound,
+		"/non_circulating/foundation_genesis?ends_before=soon":    http.StatusBadRequest,
+		"/non_circulating/foundation_genesis?ends_after=2025-1-1": http.StatusBadRequest,
+		"/non_circulating/foundation_genesis?tier=9":              http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: %d, want %d", path, rec.Code, want)
+		}
+	}
+}
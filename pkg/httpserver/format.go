@@ -0,0 +1,102 @@
+package httpserver
+
+import (
+	"encoding/csv"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lumera-labs/lumera-supply/pkg/units"
+)
+
+// Response formats of the supply endpoints besides JSON: text is the bare decimal
+// amount that CoinMarketCap and CoinGecko expect, csv one row per locked position.
+const (
+	formatJSON = "json"
+	formatText = "text"
+	formatCSV  = "csv"
+)
+
+var (
+	formatContentTypes = map[string]string{formatText: "text/plain; charset=utf-8", formatCSV: "text/csv; charset=utf-8"}
+	mediaTypeFormats   = map[string]string{"application/json": formatJSON, "text/plain": formatText, "text/csv": formatCSV}
+)
+
+// negotiateFormat picks the response format among JSON and offered: ?format= when set,
+// else the first media type of the Accept header naming one of them, else JSON. It sets
+// the Content-Type of non-JSON formats. A ?format= not offered is answered with 400
+// and ok false.
+func negotiateFormat(w http.ResponseWriter, r *http.Request, offered ...string) (format string, ok bool) {
+	w.Header().Add("Vary", "Accept")
+	format = r.URL.Query().Get("format")
+	switch {
+	case format == "":
+		format = acceptedFormat(r.Header.Get("Accept"), offered)
+	case format != formatJSON && !slices.Contains(offered, format):
+		http.Error(w, "invalid format (json, "+strings.Join(offered, ", ")+")", http.StatusBadRequest)
+		return "", false
+	}
+	if ct, ok := formatContentTypes[format]; ok {
+		w.Header().Set("Content-Type", ct)
+	}
+	return format, true
+}
+
+// acceptedFormat returns the format of the first media type in accept that is JSON or
+// offered, ignoring q=0 entries; wildcards and unknown types mean JSON.
+func acceptedFormat(accept string, offered []string) string {
+	for _, mr := range strings.Split(accept, ",") {
+		mt, params, _ := strings.Cut(mr, ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		f := mediaTypeFormats[strings.ToLower(strings.TrimSpace(mt))]
+		if f == formatJSON || (f != "" && slices.Contains(offered, f)) {
+			return f
+		}
+	}
+	return formatJSON
+}
+
+// decimalAmount renders a base-unit amount in display units without separators
+// (1234500000 with 6 decimals -> 1234.5).
+func decimalAmount(base string, decimals int) string {
+	return strings.ReplaceAll(units.Format(base, decimals), ",", "")
+}
+
+// encodeText returns an encoder writing amount as a bare decimal number in u.
+func encodeText(amount string, u displayUnit) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, decimalAmount(amount, u.Decimals))
+		return err
+	}
+}
+
+var itemCSVColumns = []string{"denom", "height", "etag", "cohort", "cohort_id", "cohort_slug", "address", "amount", "amount_decimal", "end_date", "end_unix", "permanent", "source", "tier"}
+
+// encodeItemsCSV writes the itemLines of srv's cohorts that match filter as CSV with a
+// header row, adding each amount in display units u.
+func encodeItemsCSV(w io.Writer, srv *typesSnapshot, filter itemFilter, u displayUnit) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(itemCSVColumns)
+	err := eachItemLine(srv, nil, filter, func(l itemLine) error {
+		var end, tier string
+		if l.EndUnix != 0 {
+			end = strconv.FormatInt(l.EndUnix, 10)
+		}
+		if l.Tier != 0 {
+			tier = strconv.Itoa(l.Tier)
+		}
+		return cw.Write([]string{l.Denom, itoa64(l.Height), l.ETag, l.Cohort, strconv.Itoa(l.CohortID), l.CohortSlug, l.Address, l.Amount,
+			decimalAmount(l.Amount, u.Decimals), l.EndDate, end, strconv.FormatBool(l.Permanent), l.Source, tier})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
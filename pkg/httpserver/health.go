@@ -0,0 +1,107 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// HealthSchemaVersion versions the /readyz and /status contract. Field names and the
+// enumerated states only change together with a version bump.
+const HealthSchemaVersion = 1
+
+// Overall readiness states.
+const (
+	stateReady    = "ready"
+	stateDegraded = "degraded"
+	stateNotReady = "not_ready"
+)
+
+// Check states.
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// healthChecks evaluates deep health from the cached snapshot and the cache's refresh
+// outcome without calling upstream, so probes stay cheap and never trigger a refresh.
+// It returns the overall state and the cached snapshot (nil before the first one).
+func (s *Server) healthChecks() (string, []healthCheck, *types.SupplySnapshot) {
+	snap, _ := s.cfg.Cache.Get()
+	h := s.cfg.Cache.Health()
+	limit := s.cfg.HaltAfter
+	if limit <= 0 {
+		limit = defaultHaltAfter
+	}
+	var checks []healthCheck
+
+	if snap == nil {
+		checks = append(checks, healthCheck{Name: "snapshot", Status: checkFail, Message: "no snapshot computed yet"})
+	} else if !snap.PolicyLoaded() {
+		// the figures look normal but count every cohort as circulating
+		checks = append(checks, healthCheck{Name: "snapshot", Status: checkWarn, Message: fmt.Sprintf("height %d: computed without a policy; circulating supply is overstated", snap.Height)})
+	} else if snap.Anomaly != nil {
+		// published by configuration, but consumers should not trust the figures
+		checks = append(checks, healthCheck{Name: "snapshot", Status: checkWarn, Message: fmt.Sprintf("height %d: %s", snap.Height, snap.Anomaly.Message)})
+	} else {
+		checks = append(checks, healthCheck{Name: "snapshot", Status: checkPass, Message: fmt.Sprintf("height %d", snap.Height)})
+	}
+
+	switch {
+	case h.LastError == nil:
+		checks = append(checks, healthCheck{Name: "refresh", Status: checkPass})
+	case lcd.StatusOf(h.LastError) != 0 && !lcd.IsRetryable(h.LastError):
+		// a 4xx (e.g. a route this node lacks) repeats until the node or config changes,
+		// so it fails at once instead of waiting out the halt limit
+		checks = append(checks, healthCheck{Name: "refresh", Status: checkFail,
+			Message: fmt.Sprintf("upstream rejects the query: %v", h.LastError)})
+	case time.Since(h.FailingSince) > limit:
+		checks = append(checks, healthCheck{Name: "refresh", Status: checkFail,
+			Message: fmt.Sprintf("failing since %s: %v", h.FailingSince.UTC().Format(time.RFC3339), h.LastError)})
+	default:
+		checks = append(checks, healthCheck{Name: "refresh", Status: checkWarn, Message: h.LastError.Error()})
+	}
+
+	if snap != nil {
+		lag, halted := s.chainLag(snap)
+		c := healthCheck{Name: "chain", Status: checkPass, Message: fmt.Sprintf("lag %ds", int64(lag.Seconds()))}
+		if halted {
+			// a halted chain is not this instance's fault, so it degrades rather than fails
+			c.Status = checkWarn
+			c.Message = fmt.Sprintf("no new block for %ds; possibly halted", int64(lag.Seconds()))
+		}
+		checks = append(checks, c)
+	}
+
+	state := stateReady
+	for _, c := range checks {
+		switch {
+		case c.Status == checkFail:
+			state = stateNotReady
+		case c.Status == checkWarn && state == stateReady:
+			state = stateDegraded
+		}
+	}
+	return state, checks, snap
+}
+
+// readyz: deep health for orchestration; 503 when not_ready
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	state, checks, snap := s.healthChecks()
+	out := readyPayload{SchemaVersion: HealthSchemaVersion, Status: state, Time: time.Now().UTC(), Checks: checks}
+	if snap != nil {
+		out.Height = snap.Height
+		out.ETag = snap.ETag
+		out.PolicyETag = snap.PolicyETag
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if state == stateNotReady {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = encodeIndented(out)(w)
+}
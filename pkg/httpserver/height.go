@@ -0,0 +1,78 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// maxHeightSnapshots bounds the ?height= snapshots kept in memory.
+const maxHeightSnapshots = 32
+
+// heightCache keeps recently requested ?height= snapshots. Figures at a height never
+// change under a policy, so entries only leave to make room (oldest first) or when the
+// policy changes, which changes their ETag.
+type heightCache struct {
+	mu    sync.Mutex
+	order []string
+	snaps map[string]*types.SupplySnapshot
+}
+
+func (c *heightCache) get(key string) *types.SupplySnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.snaps[key]
+}
+
+func (c *heightCache) put(key string, snap *types.SupplySnapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.snaps == nil {
+		c.snaps = make(map[string]*types.SupplySnapshot)
+	}
+	if _, ok := c.snaps[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.snaps[key] = snap
+	for len(c.order) > maxHeightSnapshots {
+		delete(c.snaps, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// pinnedHeight reports whether r asks for the figures at a past height. Only endpoints
+// guarded by acceptHeight get this far with ?height= set.
+func pinnedHeight(r *http.Request) bool { return r.URL.Query().Get("height") != "" }
+
+// setPinnedHeaders marks a ?height= response: it is not the latest snapshot, so the lag
+// headers do not apply, and it can be cached for longer.
+func setPinnedHeaders(w http.ResponseWriter) {
+	w.Header().Del("X-Chain-Lag-Seconds")
+	w.Header().Del("X-Possibly-Stale")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+}
+
+// snapshotAt is snapshot for the endpoints that serve ?height= (checked by
+// acceptHeight first): with a height it computes (or reuses) the snapshot at that
+// height, otherwise it returns the latest.
+func (s *Server) snapshotAt(w http.ResponseWriter, r *http.Request, denom string) (*response, int, error) {
+	if !pinnedHeight(r) {
+		return s.snapshot(w, r, denom)
+	}
+	height, _ := strconv.ParseInt(r.URL.Query().Get("height"), 10, 64)
+	key := denom + "@" + strconv.FormatInt(height, 10) + "/" + s.cfg.Computer.PolicyETag()
+	snap := s.heights.get(key)
+	if snap == nil {
+		var err error
+		if snap, err = s.cfg.Computer.ComputeSnapshotAtHeight(r.Context(), denom, height); err != nil {
+			return nil, 0, err
+		}
+		s.heights.put(key, snap)
+	}
+	if r.Header.Get("If-None-Match") == snap.ETag {
+		return nil, http.StatusNotModified, nil
+	}
+	return &response{snap: snap}, http.StatusOK, nil
+}
@@ -0,0 +1,47 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+)
+
+func TestHeightBelowEarliest(t *testing.T) {
+	// a node keeping blocks and state from height 10
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rpc":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"sync_info":{"earliest_block_height":"10","latest_block_height":"12","latest_block_time":"2025-06-15T12:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply":
+			_, _ = w.Write([]byte(`{"supply":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer node.Close()
+	client := lcd.NewClient(node.URL, node.Client())
+	client.SetRPC(node.URL + "/rpc")
+	client.DetectCapabilities("ulume")
+
+	srv := newTestServer(t, nil)
+	srv.cfg.LCD = client
+	for path, want := range map[string]int{
+		"/total?height=9":     http.StatusNotImplemented,
+		"/total?height=99999": http.StatusBadRequest,
+		"/total?height=x":     http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: %d %s, want %d", path, rec.Code, rec.Body, want)
+		}
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/total?height=9", nil))
+	if !strings.Contains(rec.Body.String(), "earliest block (10)") {
+		t.Errorf("below earliest: %s", rec.Body)
+	}
+}
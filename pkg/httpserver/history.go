@@ -0,0 +1,123 @@
+package httpserver
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// maxHistoryPoints caps a /history response; longer ranges need a coarser interval.
+const maxHistoryPoints = 10000
+
+// history?from=&to=&interval=: the stored supply figures over time, for charts. from and
+// to take RFC3339 or YYYY-MM-DD; interval (a Go duration or Nd) keeps the last point of
+// each UTC-aligned bucket. It is a bulk download: Range requests resume it (see serveBulk).
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.History == nil {
+		http.Error(w, "history store not configured", http.StatusNotImplemented)
+		return
+	}
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	from, ok := parseFilterTime(q.Get("from"))
+	if !ok {
+		http.Error(w, "invalid from (RFC3339 or YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	to, ok := parseFilterTime(q.Get("to"))
+	if !ok {
+		http.Error(w, "invalid to (RFC3339 or YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	if !to.IsZero() && len(q.Get("to")) == len(time.DateOnly) {
+		// a bare date includes that whole day
+		to = to.Add(24*time.Hour - time.Nanosecond)
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		http.Error(w, "to is before from", http.StatusBadRequest)
+		return
+	}
+	interval, ok := parseInterval(q.Get("interval"))
+	if !ok {
+		http.Error(w, "invalid interval (e.g. 1h or 1d, at least 1m)", http.StatusBadRequest)
+		return
+	}
+	points := downsample(s.cfg.History.Points(denom, from, to), interval)
+	if len(points) > maxHistoryPoints {
+		http.Error(w, "too many points: narrow from/to or widen interval", http.StatusBadRequest)
+		return
+	}
+	p := historyPayload{Denom: denom, Interval: q.Get("interval"), Points: points}
+	if !from.IsZero() {
+		p.From = &from
+	}
+	if !to.IsZero() {
+		p.To = &to
+	}
+	if p.Points == nil {
+		p.Points = []types.SupplyPoint{}
+	}
+	var etag string
+	var modtime time.Time
+	if n := len(points); n > 0 {
+		// the series only changes with a new point
+		etag = `"history-` + points[n-1].ETag + "-" + strconv.Itoa(n) + `"`
+		modtime = points[n-1].Time
+		w.Header().Set("ETag", etag)
+	}
+	b, err := encodeToBytes(encodeIndented(p))
+	if err != nil {
+		log.Printf("encode /history: %v", err)
+		http.Error(w, "encode error", http.StatusInternalServerError)
+		return
+	}
+	s.serveBulk(w, r, etag, modtime, bytes.NewReader(b))
+}
+
+// parseInterval accepts a Go duration or a whole number of days ("7d"); "" means every
+// point.
+func parseInterval(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, true
+	}
+	var d time.Duration
+	if n, ok := strings.CutSuffix(v, "d"); ok {
+		days, err := strconv.Atoi(n)
+		if err != nil || days > 3660 {
+			return 0, false
+		}
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(v); err != nil {
+			return 0, false
+		}
+	}
+	return d, d >= time.Minute
+}
+
+// downsample keeps the last point of each interval bucket. Buckets are counted from
+// Go's zero time, so day buckets are UTC days and series fetched at different times
+// line up.
+func downsample(points []types.SupplyPoint, interval time.Duration) []types.SupplyPoint {
+	if interval <= 0 {
+		return points
+	}
+	var out []types.SupplyPoint
+	for i, p := range points {
+		if i+1 < len(points) && points[i+1].Time.Truncate(interval).Equal(p.Time.Truncate(interval)) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+package httpserver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+	"github.com/lumera-labs/lumera-supply/pkg/units"
+)
+
+const (
+	defaultICSDays = 365
+	maxICSDays     = 3650
+)
+
+// unlocks.ics?days=365&tz=: iCalendar feed of upcoming unlock dates
+func (s *Server) handleUnlocksICS(w http.ResponseWriter, r *http.Request) {
+	var loc *time.Location
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			http.Error(w, "invalid tz", http.StatusBadRequest)
+			return
+		}
+	}
+	days := defaultICSDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		var err error
+		if days, err = strconv.Atoi(v); err != nil || days <= 0 || days > maxICSDays {
+			http.Error(w, "invalid days (1-3650)", http.StatusBadRequest)
+			return
+		}
+	}
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	resp, status, err := s.snapshot(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, "/unlocks.ics", err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	snap := resp.snap
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	// the window starts at the snapshot's evaluation instant (block time) so the body is
+	// stable per ETag
+	s.writeJSON(w, r, snap, cacheKey("unlocks.ics", r, "days", "tz"), func(buf io.Writer) error {
+		full, err := s.cfg.Cache.Hydrate(snap)
+		if err != nil {
+			return err
+		}
+		from := snap.EvaluationTime()
+		events := supply.UnlockSchedule(full, from, from.AddDate(0, 0, days))
+		return writeICS(buf, snap, events, displayOf(snap), loc)
+	})
+}
+
+// writeICS renders events as an RFC 5545 calendar with one VEVENT per unlock instant,
+// amounts in u. Times are UTC; with loc (optional) descriptions also state the local time.
+func writeICS(w io.Writer, snap *types.SupplySnapshot, events []types.UnlockEvent, u displayUnit, loc *time.Location) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) { writeICSLine(bw, s) }
+	symbol := u.symbol()
+	stamp := snap.UpdatedAt.UTC().Format("20060102T150405Z")
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Lumera//lumera-supply//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + icsEscape(symbol+" unlocks"))
+	for _, ev := range events {
+		start := ev.Time.UTC().Format("20060102T150405Z")
+		var desc strings.Builder
+		fmt.Fprintf(&desc, "%s %s unlocking across %d address(es) (snapshot height %d).\n", units.Format(ev.Amount, u.Decimals), symbol, ev.Addresses, snap.Height)
+		if loc != nil {
+			fmt.Fprintf(&desc, "Local time: %s\n", ev.Time.In(loc).Format(localDateLayout))
+		}
+		for _, c := range ev.Cohorts {
+			fmt.Fprintf(&desc, "%s: %s %s\n", c.Cohort, units.Format(c.Amount, u.Decimals), symbol)
+		}
+		line("BEGIN:VEVENT")
+		line(fmt.Sprintf("UID:%d-%s@lumera-supply", ev.Time.Unix(), icsEscape(snap.Denom)))
+		line("DTSTAMP:" + stamp)
+		line("DTSTART:" + start)
+		line("DTEND:" + start)
+		line("SUMMARY:" + icsEscape(fmt.Sprintf("Unlock: %s %s", units.Format(ev.Amount, u.Decimals), symbol)))
+		line("DESCRIPTION:" + icsEscape(strings.TrimRight(desc.String(), "\n")))
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// writeICSLine writes a content line with CRLF, folding at 75 octets without splitting
+// UTF-8 sequences.
+func writeICSLine(w *bufio.Writer, s string) {
+	n := 0
+	for i := 0; i < len(s); {
+		r := s[i:]
+		size := 1
+		for size < len(r) && r[size]&0xC0 == 0x80 {
+			size++
+		}
+		if n+size > 75 {
+			_, _ = w.WriteString("\r\n ")
+			n = 1
+		}
+		_, _ = w.WriteString(r[:size])
+		n += size
+		i += size
+	}
+	_, _ = w.WriteString("\r\n")
+}
+
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,134 @@
+package httpserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// non_circulating/items.ndjson: every locked position as newline-delimited JSON, one
+// itemLine per cohort item plus one per single-address cohort (module accounts), for
+// bulk loads into warehouses. Aggregate cohorts without an address (ibc_escrow,
+// community_pool) have no lines. ?cohort= (comma-separated) and the item filters
+// narrow the export. The whole export is fixed per ETag and, with a store, served from
+// a file there, so Range requests resume interrupted downloads (see serveBulk).
+func (s *Server) handleItemsNDJSON(w http.ResponseWriter, r *http.Request) {
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	if s.rejectHistorical(w, r) {
+		return
+	}
+	filter, msg := parseItemFilter(r)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	var cohorts map[string]bool
+	if v := r.URL.Query().Get("cohort"); v != "" {
+		cohorts = map[string]bool{}
+		for _, c := range strings.Split(v, ",") {
+			cohorts[strings.TrimSpace(c)] = true
+		}
+	}
+	resp, status, err := s.snapshot(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, "/non_circulating/items.ndjson", err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	snap := resp.snap
+	if cohorts == nil && !filter.active() && s.cfg.Store != nil {
+		// the unfiltered export is what bulk clients fetch and resume: it is spilled
+		// to the store once per ETag and served from there
+		f, err := s.cfg.Store.Export(snap.ETag, "items.ndjson", func(w io.Writer) error {
+			srv, err := s.hydrated(snap)
+			if err != nil {
+				return err
+			}
+			return writeItemLines(w, srv, nil, filter)
+		})
+		if err != nil {
+			log.Printf("/non_circulating/items.ndjson export: %v", err)
+			http.Error(w, "store error", http.StatusInternalServerError)
+			return
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			log.Printf("/non_circulating/items.ndjson export: %v", err)
+			http.Error(w, "store error", http.StatusInternalServerError)
+			return
+		}
+		s.setSnapshotHeaders(w, snap)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		s.serveBulk(w, r, snap.ETag, snap.UpdatedAt, io.NewSectionReader(f, 0, fi.Size()))
+		return
+	}
+	// filtered exports (and any without a store) are streamed and cannot be resumed
+	srv, err := s.hydrated(snap)
+	if err != nil {
+		log.Printf("/non_circulating/items.ndjson hydrate: %v", err)
+		http.Error(w, "store error", http.StatusInternalServerError)
+		return
+	}
+	s.setSnapshotHeaders(w, snap)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Accept-Ranges", "none")
+	bulkRequests.Inc(routeOf(r), "full")
+	cw := &countingWriter{w: w}
+	if err := writeItemLines(cw, srv, cohorts, filter); err != nil {
+		log.Printf("/non_circulating/items.ndjson: %v", err)
+		// once the body has started, the status is sent; the client sees it truncated
+		if cw.n == 0 {
+			http.Error(w, "encode error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// writeItemLines streams the itemLines of srv's cohorts (all when cohorts is nil) that
+// match filter to w, one JSON document per line.
+func writeItemLines(w io.Writer, srv *typesSnapshot, cohorts map[string]bool, filter itemFilter) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := eachItemLine(srv, cohorts, filter, func(l itemLine) error { return enc.Encode(l) }); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// eachItemLine calls fn with the itemLine of every cohort item, and of every
+// single-address cohort, of srv's cohorts (all when cohorts is nil) that matches filter.
+func eachItemLine(srv *typesSnapshot, cohorts map[string]bool, filter itemFilter, fn func(itemLine) error) error {
+	line := itemLine{Denom: srv.Denom, Height: srv.Height, ETag: srv.ETag}
+	for _, c := range srv.NonCirc.Cohorts {
+		if cohorts != nil && !cohorts[c.Name] {
+			continue
+		}
+		line.Cohort, line.CohortID, line.CohortSlug = c.Name, c.ID, c.Slug
+		if c.Address != "" && filter.match(addressItem{Address: c.Address}) {
+			line.Address, line.Amount, line.EndDate, line.EndUnix, line.Permanent, line.Source, line.Tier = c.Address, c.Amount, "", 0, false, "", 0
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+		for _, it := range c.Items {
+			if !filter.match(it) {
+				continue
+			}
+			line.Address, line.Amount, line.EndDate, line.EndUnix, line.Permanent, line.Source, line.Tier = it.Address, it.Amount, it.EndDate, it.EndUnix, it.Permanent, it.Source, it.Tier
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package httpserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+const defaultHaltAfter = 5 * time.Minute
+
+var chainLagGauge = metrics.Default.NewGauge(
+	"lumera_supply_chain_lag_seconds",
+	"Wall clock minus the block time of the latest cached snapshot.",
+)
+
+// chainLag reports how far the snapshot's block time trails the wall clock and whether
+// that exceeds the halt threshold. It also updates the chain lag gauge.
+func (s *Server) chainLag(snap *types.SupplySnapshot) (time.Duration, bool) {
+	lag := time.Since(snap.UpdatedAt)
+	if lag < 0 {
+		lag = 0
+	}
+	chainLagGauge.Set(lag.Seconds())
+	limit := s.cfg.HaltAfter
+	if limit <= 0 {
+		limit = defaultHaltAfter
+	}
+	return lag, lag > limit
+}
+
+// setLagHeaders flags responses built from a snapshot whose chain appears halted.
+// Headers are used so cached bodies stay byte-identical per ETag.
+func (s *Server) setLagHeaders(w http.ResponseWriter, snap *types.SupplySnapshot) {
+	lag, halted := s.chainLag(snap)
+	w.Header().Set("X-Chain-Lag-Seconds", itoa64(int64(lag.Seconds())))
+	if halted {
+		w.Header().Set("X-Possibly-Stale", "true")
+	}
+}
+
+// handleMetrics refreshes the chain lag, snapshot age and SLO burn rate gauges before serving the
+// registry, so they keep moving while no API traffic arrives.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if snap, _ := s.cfg.Cache.Get(); snap != nil {
+		s.chainLag(snap)
+	}
+	if s.cfg.SLO != nil {
+		s.cfg.SLO.Summary()
+	}
+	s.observeSnapshotAge()
+	metrics.Default.Handler().ServeHTTP(w, r)
+}
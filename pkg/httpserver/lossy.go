@@ -0,0 +1,51 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// amountFields are the JSON keys holding base-unit amounts as strings.
+var amountFields = map[string]bool{
+	"amount": true, "total": true, "circulating": true, "non_circulating": true, "sum": true, "max": true,
+	"locked": true, "balance": true, "spendable": true, "delegated": true, "unbonding": true,
+	"rewards": true, "slashed_locked": true, "bonded": true, "not_bonded": true,
+}
+
+// wantsLossyNumbers reports whether the request asked for ?lossy_numbers=1.
+func wantsLossyNumbers(r *http.Request) bool {
+	v := r.URL.Query().Get("lossy_numbers")
+	return v == "1" || v == "true"
+}
+
+// numericSibling adds "<field>_numeric" next to string amounts, as a JSON number for
+// tools that cannot parse string-encoded integers. float64 is exact only up to 2^53, so
+// these values are lossy for large amounts; the string stays authoritative.
+func numericSibling(key, value string) (string, []byte, bool) {
+	if !amountFields[key] {
+		return "", nil, false
+	}
+	f, ok := parseAmount(value)
+	if !ok {
+		return "", nil, false
+	}
+	return key + "_numeric", strconv.AppendFloat(nil, f, 'f', -1, 64), true
+}
+
+// parseAmount converts an integer string (optionally negative) to float64.
+func parseAmount(s string) (float64, bool) {
+	digits := s
+	if len(digits) > 0 && digits[0] == '-' {
+		digits = digits[1:]
+	}
+	if digits == "" {
+		return 0, false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
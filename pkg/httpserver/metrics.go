@@ -0,0 +1,49 @@
+package httpserver
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+)
+
+var (
+	httpRequests = metrics.Default.NewCounter(
+		"lumera_supply_http_requests_total",
+		"Public API requests by route and status code (rate-limited ones included).",
+		"route", "code",
+	)
+	httpDuration = metrics.Default.NewHistogram(
+		"lumera_supply_http_request_duration_seconds",
+		"Public API request durations by route.",
+		[]float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 10},
+		"route",
+	)
+	snapshotLookups = metrics.Default.NewCounter(
+		"lumera_supply_snapshot_cache_lookups_total",
+		"Snapshot lookups by API requests: hit (fresh cached snapshot) stale (past its TTL, served while a background refresh runs) or miss (computed on the request).",
+		"result",
+	)
+	respCacheLookups = metrics.Default.NewCounter(
+		"lumera_supply_response_cache_lookups_total",
+		"Pre-serialized response cache lookups by result (hit|miss).",
+		"result",
+	)
+	snapshotAge = metrics.Default.NewGauge(
+		"lumera_supply_snapshot_age_seconds",
+		"Seconds since the cached snapshot was last computed successfully.",
+	)
+)
+
+// observeRequest records a public API request on route that answered code after d.
+func observeRequest(route string, code int, d time.Duration) {
+	httpRequests.Inc(route, strconv.Itoa(code))
+	httpDuration.Observe(d.Seconds(), route)
+}
+
+// observeSnapshotAge updates the snapshot age gauge at scrape time.
+func (s *Server) observeSnapshotAge() {
+	if last := s.cfg.Cache.Health().LastSuccess; !last.IsZero() {
+		snapshotAge.Set(time.Since(last).Seconds())
+	}
+}
@@ -0,0 +1,60 @@
+package httpserver
+
+import (
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// module_accounts: every chain module account, marked with the cohort that counts it
+// as non-circulating (if any)
+func (s *Server) handleModuleAccounts(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.LCD == nil {
+		http.Error(w, "lcd not configured", http.StatusNotImplemented)
+		return
+	}
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	if s.rejectHistorical(w, r) {
+		return
+	}
+	resp, status, err := s.snapshot(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, "/module_accounts", err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	mods, err := s.cfg.LCD.WithContext(r.Context()).ModuleAccounts()
+	if err != nil {
+		log.Printf("/module_accounts lcd: %v", err)
+		http.Error(w, "upstream error", http.StatusBadGateway)
+		return
+	}
+	snap := resp.snap
+	s.writeJSON(w, r, snap, cacheKey("module_accounts", r), func(buf io.Writer) error {
+		cohorts := map[string]types.CohortEntry{}
+		for _, c := range snap.NonCirculating.Cohorts {
+			if c.Address != "" {
+				cohorts[c.Address] = c
+			}
+		}
+		out := moduleAccountsPayload{Denom: snap.Denom, Height: snap.Height, UpdatedAt: snap.UpdatedAt, ETag: snap.ETag, PolicyETag: snap.PolicyETag,
+			Accounts: make([]moduleAccountEntry, 0, len(mods))}
+		for _, m := range mods {
+			e := moduleAccountEntry{Name: m.Name, Address: m.Address, Permissions: m.Permissions}
+			if c, ok := cohorts[m.Address]; ok {
+				e.Cohort, e.CohortID, e.CohortSlug = c.Name, c.ID, c.Slug
+			}
+			out.Accounts = append(out.Accounts, e)
+		}
+		return encodeIndented(out)(buf)
+	})
+}
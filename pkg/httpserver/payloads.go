@@ -0,0 +1,499 @@
+package httpserver
+
+import (
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/logging"
+	"github.com/lumera-labs/lumera-supply/pkg/slo"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+	"github.com/lumera-labs/lumera-supply/schema"
+)
+
+// Response payloads returned by the public endpoints. These are named (rather than
+// anonymous per-handler structs) so JSON Schema artifacts can be generated from them.
+
+type totalPayload struct {
+	Denom      string    `json:"denom"`
+	Decimals   int       `json:"decimals"`
+	Height     int64     `json:"height"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	ETag       string    `json:"etag"`
+	PolicyETag string    `json:"policy-etag"`
+	// PolicyLoaded is false when the figures were computed without a policy.
+	PolicyLoaded   bool    `json:"policy_loaded"`
+	Total          string  `json:"total"`
+	Circulating    string  `json:"circulating"`
+	NonCirculating string  `json:"non_circulating"`
+	Max            *string `json:"max"`
+}
+
+type circulatingPayload struct {
+	Denom          string    `json:"denom"`
+	Decimals       int       `json:"decimals"`
+	Height         int64     `json:"height"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	ETag           string    `json:"etag"`
+	PolicyETag     string    `json:"policy-etag"`
+	PolicyLoaded   bool      `json:"policy_loaded"`
+	Circulating    string    `json:"circulating"`
+	NonCirculating string    `json:"non_circulating"`
+}
+
+type nonCircPayload struct {
+	Denom        string    `json:"denom"`
+	Decimals     int       `json:"decimals"`
+	Height       int64     `json:"height"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	ETag         string    `json:"etag"`
+	PolicyETag   string    `json:"policy-etag"`
+	PolicyLoaded bool      `json:"policy_loaded"`
+	// ComputeStats is included with verbose=1.
+	ComputeStats *types.ComputeStats `json:"compute_stats,omitempty"`
+	// Breakdown must stay the last field (see streamNonCirc).
+	Breakdown nonCirc `json:"non_circulating"`
+}
+
+type cohortPayload struct {
+	Denom      string      `json:"denom"`
+	Decimals   int         `json:"decimals"`
+	Height     int64       `json:"height"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+	ETag       string      `json:"etag"`
+	PolicyETag string      `json:"policy-etag"`
+	Cohort     cohortEntry `json:"cohort"`
+	// Page is set when the items are paged (?limit=, or more than Config.MaxCohortItems).
+	Page *pageInfo `json:"page,omitempty"`
+}
+
+type searchPayload struct {
+	Denom      string    `json:"denom"`
+	Decimals   int       `json:"decimals"`
+	Height     int64     `json:"height"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	ETag       string    `json:"etag"`
+	PolicyETag string    `json:"policy-etag"`
+	Address    string    `json:"address"`
+	// ModuleAccount is null when the LCD lookup failed.
+	ModuleAccount *bool         `json:"module_account"`
+	Total         string        `json:"total"`
+	Matches       []searchMatch `json:"matches"`
+}
+
+type moduleAccountsPayload struct {
+	Denom      string               `json:"denom"`
+	Height     int64                `json:"height"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+	ETag       string               `json:"etag"`
+	PolicyETag string               `json:"policy-etag"`
+	Accounts   []moduleAccountEntry `json:"accounts"`
+}
+
+// moduleAccountEntry is a chain module account; Cohort names the non-circulating
+// cohort counting its balance and is empty when the balance circulates.
+type moduleAccountEntry struct {
+	Name        string   `json:"name"`
+	Address     string   `json:"address"`
+	Permissions []string `json:"permissions"`
+	Cohort      string   `json:"cohort,omitempty"`
+	CohortID    int      `json:"cohort_id,omitempty"`
+	CohortSlug  string   `json:"cohort_slug,omitempty"`
+}
+
+type balancesPayload struct {
+	Denom      string           `json:"denom"`
+	Height     int64            `json:"height"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	ETag       string           `json:"etag"`
+	PolicyETag string           `json:"policy-etag"`
+	Addresses  []addressBalance `json:"addresses"`
+}
+
+// addressBalance is one policy-referenced address's account state next to the amount
+// its cohort counts as locked. An address in several cohorts appears once per cohort.
+type addressBalance struct {
+	Address    string `json:"address"`
+	Cohort     string `json:"cohort"`
+	CohortID   int    `json:"cohort_id,omitempty"`
+	CohortSlug string `json:"cohort_slug"`
+	Locked     string `json:"locked"`
+	Balance    string `json:"balance,omitempty"`
+	Spendable  string `json:"spendable,omitempty"`
+	Delegated  string `json:"delegated,omitempty"`
+	Unbonding  string `json:"unbonding,omitempty"`
+	Rewards    string `json:"rewards,omitempty"`
+	// Error is set when the address's lookups failed.
+	Error string `json:"error,omitempty"`
+}
+
+// searchMatch is one cohort item (or single-address cohort) mentioning the address.
+type searchMatch struct {
+	Cohort     string `json:"cohort"`
+	CohortID   int    `json:"cohort_id,omitempty"`
+	CohortSlug string `json:"cohort_slug"`
+	Amount     string `json:"amount"`
+	EndDate    string `json:"end_date,omitempty"`
+	EndUnix    int64  `json:"end_unix,omitempty"`
+	Permanent  bool   `json:"permanent"`
+	Source     string `json:"source,omitempty"`
+	Tier       int    `json:"tier,omitempty"`
+}
+
+// addressPayload is /address/{addr}: the address's account as of the snapshot and the
+// cohorts counting it.
+type addressPayload struct {
+	Denom       string                   `json:"denom"`
+	Decimals    int                      `json:"decimals"`
+	Height      int64                    `json:"height"`
+	UpdatedAt   time.Time                `json:"updated_at"`
+	EvaluatedAt time.Time                `json:"evaluated_at"`
+	ETag        string                   `json:"etag"`
+	PolicyETag  string                   `json:"policy-etag"`
+	Account     *types.AccountInspection `json:"account"`
+	// NonCirculating is whether any cohort counts the address; Cohorts lists them.
+	NonCirculating bool            `json:"non_circulating"`
+	Cohorts        []addressCohort `json:"cohorts"`
+	// Why says in a sentence how the address is counted, for support answers.
+	Why string `json:"why"`
+}
+
+// addressCohort is a cohort counting an address, with the cohort's reason.
+type addressCohort struct {
+	Cohort     string `json:"cohort"`
+	CohortID   int    `json:"cohort_id,omitempty"`
+	CohortSlug string `json:"cohort_slug"`
+	Reason     string `json:"reason"`
+	Amount     string `json:"amount"`
+	EndDate    string `json:"end_date,omitempty"`
+	EndUnix    int64  `json:"end_unix,omitempty"`
+	Permanent  bool   `json:"permanent"`
+	Source     string `json:"source,omitempty"`
+	Tier       int    `json:"tier,omitempty"`
+}
+
+type topPayload struct {
+	Denom      string        `json:"denom"`
+	Decimals   int           `json:"decimals"`
+	Height     int64         `json:"height"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	ETag       string        `json:"etag"`
+	PolicyETag string        `json:"policy-etag"`
+	Positions  []topPosition `json:"positions"`
+}
+
+type topPosition struct {
+	Address    string `json:"address"`
+	Cohort     string `json:"cohort"`
+	CohortID   int    `json:"cohort_id,omitempty"`
+	CohortSlug string `json:"cohort_slug"`
+	Amount     string `json:"amount"`
+	EndDate    string `json:"end_date,omitempty"`
+	EndUnix    int64  `json:"end_unix,omitempty"`
+	Permanent  bool   `json:"permanent"`
+}
+
+// itemLine is one line of /non_circulating/items.ndjson; the snapshot identity is
+// repeated so lines can be loaded without their response.
+type itemLine struct {
+	Denom      string `json:"denom"`
+	Height     int64  `json:"height"`
+	ETag       string `json:"etag"`
+	Cohort     string `json:"cohort"`
+	CohortID   int    `json:"cohort_id,omitempty"`
+	CohortSlug string `json:"cohort_slug"`
+	Address    string `json:"address"`
+	Amount     string `json:"amount"`
+	EndDate    string `json:"end_date,omitempty"`
+	EndUnix    int64  `json:"end_unix,omitempty"`
+	Permanent  bool   `json:"permanent"`
+	// Source and Tier are set on cohort items (see addressItem), not on single-address
+	// cohorts.
+	Source string `json:"source,omitempty"`
+	Tier   int    `json:"tier,omitempty"`
+}
+
+// cohortsPayload lists the latest snapshot's cohorts without amounts.
+type cohortsPayload struct {
+	Height     int64        `json:"height"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	ETag       string       `json:"etag"`
+	PolicyETag string       `json:"policy-etag"`
+	Cohorts    []cohortInfo `json:"cohorts"`
+}
+
+type cohortInfo struct {
+	Name   string `json:"name"`
+	ID     int    `json:"id,omitempty"`
+	Slug   string `json:"slug"`
+	Reason string `json:"reason"`
+	// Source is the chain data the cohort is computed from.
+	Source    string   `json:"source" enum:"ibc_total_escrow,community_pool,module_account,disclosed_lockups,claim_records,staking_pool"`
+	Address   string   `json:"address,omitempty"`
+	ItemCount int      `json:"item_count"`
+	Tags      []string `json:"tags,omitempty"`
+	// DisclosureURL and Notes justify the cohort's classification (from the policy).
+	DisclosureURL string `json:"disclosure_url,omitempty"`
+	Notes         string `json:"notes,omitempty"`
+}
+
+type maxPayload struct {
+	Denom      string    `json:"denom"`
+	Decimals   int       `json:"decimals"`
+	Height     int64     `json:"height"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	ETag       string    `json:"etag"`
+	PolicyETag string    `json:"policy-etag"`
+	Max        *string   `json:"max"`
+}
+
+// stakedPayload is /staked: the staking pool at the snapshot height. BondedRatio is
+// Bonded over Total as a decimal string.
+type stakedPayload struct {
+	Denom       string    `json:"denom"`
+	Decimals    int       `json:"decimals"`
+	Height      int64     `json:"height"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	ETag        string    `json:"etag"`
+	PolicyETag  string    `json:"policy-etag"`
+	Total       string    `json:"total"`
+	Bonded      string    `json:"bonded"`
+	NotBonded   string    `json:"not_bonded"`
+	BondedRatio string    `json:"bonded_ratio"`
+}
+
+type statusPayload struct {
+	SchemaVersion int       `json:"schema_version" enum:"1"`
+	Status        string    `json:"status" enum:"ok,stale"`
+	Height        int64     `json:"height"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	// EvaluatedAt is the instant locks were evaluated at: always the block time
+	// (UpdatedAt) for published snapshots.
+	EvaluatedAt time.Time `json:"evaluated_at"`
+	ETag        string    `json:"etag"`
+	PolicyETag  string    `json:"policy-etag"`
+	// PolicyLoaded is false when the snapshot was computed without a policy: every
+	// cohort is then missing and circulating supply is overstated.
+	PolicyLoaded bool `json:"policy_loaded"`
+	// ChainLagSeconds is wall clock minus the snapshot's block time; PossiblyStale is set
+	// (and Status is "stale") when it exceeds the halt threshold.
+	ChainLagSeconds int64 `json:"chain_lag_seconds"`
+	PossiblyStale   bool  `json:"possibly_stale"`
+	// Readiness and Checks are the /readyz evaluation at the time of the request.
+	Readiness string            `json:"readiness" enum:"ready,degraded,not_ready"`
+	Checks    []healthCheck     `json:"checks"`
+	Node      *lcd.Capabilities `json:"node,omitempty"`
+	// LCDEndpoints are the failover endpoints' health, when -lcd lists several.
+	LCDEndpoints []lcd.EndpointStatus `json:"lcd_endpoints,omitempty"`
+	ComputeStats *types.ComputeStats  `json:"compute_stats,omitempty"`
+	Limits       limitsStatus         `json:"limits"`
+	// Overrides are the settings changed at runtime through /admin/tuning.
+	Overrides *config.Tuning `json:"overrides,omitempty"`
+	Refresh   refreshStatus  `json:"refresh"`
+	// Burns are the burn tracker's totals at the snapshot (with -track-burns).
+	Burns *types.BurnTotals `json:"burns,omitempty"`
+	// Overlaps are addresses counted by several cohorts (see the policy's overlaps mode).
+	Overlaps []types.CohortOverlap `json:"overlaps,omitempty"`
+	// Anomaly is set when an inconsistent snapshot was published (-negative-circulating=publish).
+	Anomaly *types.SupplyAnomaly `json:"anomaly,omitempty"`
+	// ResponseCache counts lookups in the serialized response cache.
+	ResponseCache responseCacheStatus `json:"response_cache"`
+}
+
+// responseCacheStatus reports response cache hits and misses since start.
+type responseCacheStatus struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+// refreshStatus reports refresh coalescing: concurrent callers share one in-flight
+// refresh per denom.
+type refreshStatus struct {
+	InFlight       int    `json:"in_flight"`
+	Waiting        int    `json:"waiting"`
+	CoalescedTotal uint64 `json:"coalesced_total"`
+	TimeToFreshMS  int64  `json:"time_to_fresh_ms"`
+}
+
+// limitsStatus reports the effective refresh, rate limiting, timeout, concurrency and
+// log settings.
+type limitsStatus struct {
+	RefreshTTLSeconds int64 `json:"refresh_ttl_seconds"`
+	RatePerMin        int   `json:"rate_per_min"`
+	Burst             int   `json:"burst"`
+	LCDTimeoutMS      int64 `json:"lcd_timeout_ms"`
+	// LCDEndpointTimeoutsMS are per-endpoint limits replacing lcd_timeout_ms for
+	// matching requests (see -lcd-timeouts).
+	LCDEndpointTimeoutsMS map[string]int64 `json:"lcd_endpoint_timeouts_ms,omitempty"`
+	Concurrency           int              `json:"concurrency"`
+	LogLevel              string           `json:"log_level" enum:"debug,info,warn"`
+}
+
+func limitsOf(l config.Limits, endpoints map[string]time.Duration, level logging.Level) limitsStatus {
+	ls := limitsStatus{RefreshTTLSeconds: int64(l.RefreshTTL / time.Second), RatePerMin: l.RatePerMin, Burst: l.Burst,
+		LCDTimeoutMS: l.LCDTimeout.Milliseconds(), Concurrency: l.Concurrency, LogLevel: level.String()}
+	if len(endpoints) > 0 {
+		ls.LCDEndpointTimeoutsMS = make(map[string]int64, len(endpoints))
+		for k, d := range endpoints {
+			ls.LCDEndpointTimeoutsMS[k] = d.Milliseconds()
+		}
+	}
+	return ls
+}
+
+// tuningPayload is the /admin/tuning response. Persisted is false without a store, in
+// which case overrides last until restart.
+type tuningPayload struct {
+	Limits    limitsStatus  `json:"limits"`
+	Overrides config.Tuning `json:"overrides"`
+	Persisted bool          `json:"persisted"`
+}
+
+// evaluatePayload is the /admin/evaluate what-if response: the latest chain state with
+// locks evaluated at EvaluatedAt rather than the block time (UpdatedAt).
+type evaluatePayload struct {
+	Denom          string               `json:"denom"`
+	Decimals       int                  `json:"decimals"`
+	Height         int64                `json:"height"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	EvaluatedAt    time.Time            `json:"evaluated_at"`
+	PolicyETag     string               `json:"policy-etag"`
+	Total          string               `json:"total"`
+	Circulating    string               `json:"circulating"`
+	NonCirculating nonCirc              `json:"non_circulating"`
+	Anomaly        *types.SupplyAnomaly `json:"anomaly,omitempty"`
+}
+
+// readyPayload is the /readyz contract (see HealthSchemaVersion).
+type readyPayload struct {
+	SchemaVersion int       `json:"schema_version" enum:"1"`
+	Status        string    `json:"status" enum:"ready,degraded,not_ready"`
+	Time          time.Time `json:"time"`
+	// Height and ETag identify the cached snapshot; zero/empty before the first one.
+	Height     int64         `json:"height"`
+	ETag       string        `json:"etag"`
+	PolicyETag string        `json:"policy-etag,omitempty"`
+	Checks     []healthCheck `json:"checks"`
+}
+
+// healthCheck is one named readiness check: snapshot, refresh or chain.
+type healthCheck struct {
+	Name    string `json:"name" enum:"snapshot,refresh,chain"`
+	Status  string `json:"status" enum:"pass,warn,fail"`
+	Message string `json:"message,omitempty"`
+}
+
+type sloPayload struct {
+	Time      time.Time    `json:"time"`
+	Endpoints []slo.Status `json:"endpoints"`
+}
+
+type candidatePayload struct {
+	Denom     string        `json:"denom"`
+	Height    int64         `json:"height"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Active    policyFigures `json:"active"`
+	Candidate policyFigures `json:"candidate"`
+	// CirculatingDelta is candidate minus active circulating supply.
+	CirculatingDelta string             `json:"circulating_delta"`
+	Diff             types.SnapshotDiff `json:"diff"`
+}
+
+// unlocksPayload is /unlocks: the unlocks in (from, until], earliest first.
+type unlocksPayload struct {
+	Denom      string    `json:"denom"`
+	Decimals   int       `json:"decimals"`
+	Height     int64     `json:"height"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	ETag       string    `json:"etag"`
+	PolicyETag string    `json:"policy-etag"`
+	From       time.Time `json:"from"`
+	Until      time.Time `json:"until"`
+	// GroupBy is "month" when events are monthly sums, each at the start of its month.
+	GroupBy string `json:"group_by,omitempty" enum:"month"`
+	// Total is the sum of the events' amounts.
+	Total  string              `json:"total"`
+	Events []types.UnlockEvent `json:"events"`
+}
+
+type diagnosticsPayload struct {
+	Denom string `json:"denom"`
+	// ServedHeight and ServedETag identify the snapshot currently served, which is
+	// older than Latest when Latest was rejected (0 and empty before the first).
+	ServedHeight int64              `json:"served_height"`
+	ServedETag   string             `json:"served_etag,omitempty"`
+	Latest       *types.Diagnostics `json:"latest"`
+	// LastViolation is the latest computation since startup that broke an invariant.
+	LastViolation *types.Diagnostics `json:"last_violation,omitempty"`
+}
+
+type policyFigures struct {
+	PolicyETag     string `json:"policy-etag"`
+	Circulating    string `json:"circulating"`
+	NonCirculating string `json:"non_circulating"`
+}
+
+type versionPayload struct {
+	GitHash    string `json:"github-hash"`
+	GitTag     string `json:"git-tag"`
+	PolicyETag string `json:"policy_etag"`
+}
+
+type healthPayload struct {
+	Status string `json:"status"`
+	Time   string `json:"time"`
+}
+
+type historyPayload struct {
+	Denom    string     `json:"denom"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	Interval string     `json:"interval,omitempty"`
+	// Points are in ascending height order.
+	Points []types.SupplyPoint `json:"points"`
+}
+
+// SchemaSiblings describes the fields ?lossy_numbers, ?display and ?tz add to JSON
+// responses, for the schema generator: "<field>_numeric" (a number) and
+// "<field>_<display denom>" (a string) next to amounts, "<field>_local" next to dates.
+func SchemaSiblings() []schema.Sibling {
+	var out []schema.Sibling
+	for f := range amountFields {
+		out = append(out, schema.Sibling{Field: f, Suffix: "[a-z0-9_]+", Schema: map[string]any{"type": []string{"number", "string"}}})
+	}
+	for f := range dateFields {
+		out = append(out, schema.Sibling{Field: f, Suffix: "local", Schema: map[string]any{"type": "string"}})
+	}
+	return out
+}
+
+// ResponseTypes returns a zero value of every public response payload keyed by
+// endpoint name. It is consumed by the schema generator (see schema/gen).
+func ResponseTypes() map[string]any {
+	return map[string]any{
+		"total":           totalPayload{},
+		"circulating":     circulatingPayload{},
+		"non_circulating": nonCircPayload{},
+		"cohort":          cohortPayload{},
+		"search":          searchPayload{},
+		"address":         addressPayload{},
+		"top":             topPayload{},
+		"items":           itemLine{},
+		"module_accounts": moduleAccountsPayload{},
+		"balances":        balancesPayload{},
+		"diff":            types.SnapshotDiff{},
+		"history":         historyPayload{},
+		"max":             maxPayload{},
+		"staked":          stakedPayload{},
+		"cohorts":         cohortsPayload{},
+		"snapshot":        types.SupplySnapshot{},
+		"status":          statusPayload{},
+		"readyz":          readyPayload{},
+		"slo":             sloPayload{},
+		"candidate":       candidatePayload{},
+		"diagnostics":     diagnosticsPayload{},
+		"unlocks":         unlocksPayload{},
+		"version":         versionPayload{},
+		"healthz":         healthPayload{},
+	}
+}
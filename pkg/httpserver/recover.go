@@ -0,0 +1,27 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/lumera-labs/lumera-supply/pkg/recovery"
+)
+
+// recoverPanic answers a request whose handler panicked with v: the panic is reported
+// and, unless the response was already started, a 500 goes out instead of the
+// connection being dropped. http.ErrAbortHandler is re-raised, since handlers use it to
+// abort a response on purpose. Call it as the first thing in a deferred function.
+func recoverPanic(w *statusRecorder, r *http.Request, v any) {
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+	_ = recovery.Report("http:"+routeOf(r), v)
+	if w.code != 0 {
+		return
+	}
+	h := w.Header()
+	for _, k := range []string{"ETag", "Last-Modified", "Content-Length", "Repr-Digest"} {
+		h.Del(k)
+	}
+	h.Set("Cache-Control", "no-store")
+	http.Error(w, "internal error", http.StatusInternalServerError)
+}
@@ -0,0 +1,138 @@
+package httpserver
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// maxRespCacheBytes bounds the bytes of the bodies kept per ETag; the least recently
+// used are evicted first.
+const maxRespCacheBytes = 64 << 20
+
+// respCache holds the final encoded bytes per (endpoint, params) for the current
+// snapshot ETag. Entries for older ETags are dropped as soon as a new ETag is seen.
+type respCache struct {
+	mu      sync.Mutex
+	etag    string
+	entries map[string]*list.Element // of *respEntry, in lru
+	lru     list.List                // most recently used first
+	size    int
+	// max bounds size; 0 takes maxRespCacheBytes
+	max int
+	// lookups by result, reported in /status (and process-wide in respCacheLookups)
+	hits, misses uint64
+}
+
+type respEntry struct {
+	key string
+	b   []byte
+}
+
+func (c *respCache) get(etag, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || c.etag != etag {
+		c.misses++
+		respCacheLookups.Inc("miss")
+		return nil, false
+	}
+	c.hits++
+	respCacheLookups.Inc("hit")
+	c.lru.MoveToFront(e)
+	return e.Value.(*respEntry).b, true
+}
+
+// stats returns the lookup counts since start.
+func (c *respCache) stats() responseCacheStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return responseCacheStatus{Hits: c.hits, Misses: c.misses}
+}
+
+func (c *respCache) put(etag, key string, b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.etag != etag || c.entries == nil {
+		c.etag = etag
+		c.entries = make(map[string]*list.Element)
+		c.lru.Init()
+		c.size = 0
+	}
+	limit := c.max
+	if limit == 0 {
+		limit = maxRespCacheBytes
+	}
+	if len(b) > limit {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		c.size -= len(e.Value.(*respEntry).b)
+		c.lru.Remove(e)
+	}
+	c.entries[key] = c.lru.PushFront(&respEntry{key: key, b: b})
+	c.size += len(b)
+	for c.size > limit {
+		old := c.lru.Back()
+		c.lru.Remove(old)
+		ent := old.Value.(*respEntry)
+		delete(c.entries, ent.key)
+		c.size -= len(ent.b)
+	}
+}
+
+// perRequestParams select a slice of a snapshot (item filters and pages). There are too
+// many such responses, each rarely repeated, for them to be worth caching.
+var perRequestParams = append([]string{"offset", "limit"}, itemFilterParams...)
+
+// cacheable reports whether the response to r is worth keeping in the response cache:
+// not when it is filtered or paged, or about one address.
+func cacheable(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, "/address/") {
+		return false
+	}
+	q := r.URL.Query()
+	for _, p := range perRequestParams {
+		if q.Has(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheKey identifies a projection by endpoint and the query params that shape it.
+// The denom is already part of the snapshot ETag.
+func cacheKey(endpoint string, r *http.Request, params ...string) string {
+	var b strings.Builder
+	b.WriteString(endpoint)
+	q := r.URL.Query()
+	for _, p := range params {
+		b.WriteByte('|')
+		b.WriteString(p)
+		b.WriteByte('=')
+		b.WriteString(q.Get(p))
+	}
+	return b.String()
+}
+
+// encodeIndented returns an encoder func writing v as indented JSON.
+func encodeIndented(v any) func(io.Writer) error {
+	return func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+}
+
+func encodeToBytes(encode func(io.Writer) error) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
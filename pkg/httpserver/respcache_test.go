@@ -0,0 +1,68 @@
+package httpserver
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRespCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := &respCache{max: 10}
+	c.put("e1", "a", []byte("aaaa"))
+	c.put("e1", "b", []byte("bbbb"))
+	if _, ok := c.get("e1", "a"); !ok {
+		t.Fatal("a missing")
+	}
+	// over 10 bytes: b is the least recently used
+	c.put("e1", "c", []byte("cccc"))
+	if _, ok := c.get("e1", "b"); ok {
+		t.Error("b should have been evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok := c.get("e1", k); !ok {
+			t.Errorf("%s evicted", k)
+		}
+	}
+	if c.size != 8 {
+		t.Errorf("size %d, want 8", c.size)
+	}
+	// larger than the whole cache: not kept
+	c.put("e1", "big", make([]byte, 11))
+	if _, ok := c.get("e1", "big"); ok {
+		t.Error("an oversized body was cached")
+	}
+	// a new ETag drops everything
+	c.put("e2", "d", []byte("d"))
+	if _, ok := c.get("e2", "a"); ok || c.size != 1 {
+		t.Errorf("entries of the old ETag kept (size %d)", c.size)
+	}
+}
+
+func TestFilteredResponsesAreNotCached(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/non_circulating?verbose=1":                        true,
+		"/unlocks?months=3":                                 true,
+		"/non_circulating?verbose=1&ends_before=2026-01-01": false,
+		"/non_circulating/claim_delayed?offset=100":         false,
+		"/non_circulating/claim_delayed?tier=2":             false,
+		"/search?address=lumera1a":                          false,
+		"/address/lumera1a":                                 false,
+	} {
+		if got := cacheable(httptest.NewRequest("GET", path, nil)); got != want {
+			t.Errorf("cacheable(%s) = %v, want %v", path, got, want)
+		}
+	}
+
+	srv := newTestServer(t, nil)
+	for range 2 {
+		get(t, srv, "/non_circulating?items=0&ends_after=2000-01-01")
+	}
+	if st := srv.resp.stats(); st != (responseCacheStatus{}) {
+		t.Errorf("filtered requests used the response cache: %+v", st)
+	}
+	for range 2 {
+		get(t, srv, "/non_circulating?items=0")
+	}
+	if st := srv.resp.stats(); st != (responseCacheStatus{Hits: 1, Misses: 1}) {
+		t.Errorf("unfiltered requests: %+v, want a miss and a hit", st)
+	}
+}
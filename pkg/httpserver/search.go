@@ -0,0 +1,77 @@
+package httpserver
+
+import (
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+// search?address=lumera1...: every cohort item mentioning the address in the cached snapshot
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	addr := strings.ToLower(r.URL.Query().Get("address"))
+	if addr == "" || len(addr) > 128 {
+		http.Error(w, "invalid address", http.StatusBadRequest)
+		return
+	}
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	if s.rejectHistorical(w, r) {
+		return
+	}
+	resp, status, err := s.snapshot(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, "/search", err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	snap := resp.snap
+	s.writeJSON(w, r, snap, cacheKey("search", r, "address"), func(buf io.Writer) error {
+		srv, err := s.hydrated(snap)
+		if err != nil {
+			return err
+		}
+		out := searchPayload{Denom: srv.Denom, Decimals: displayOf(snap).Decimals, Height: srv.Height, UpdatedAt: srv.UpdatedAt, ETag: srv.ETag, PolicyETag: srv.PolicyETag,
+			Address: addr, Matches: []searchMatch{}}
+		sum := new(big.Int)
+		add := func(m searchMatch) {
+			out.Matches = append(out.Matches, m)
+			if v, ok := new(big.Int).SetString(m.Amount, 10); ok {
+				sum.Add(sum, v)
+			}
+		}
+		eachMatch(srv, addr, func(_ *cohortEntry, m searchMatch) { add(m) })
+		out.Total = sum.String()
+		if s.cfg.LCD != nil {
+			if mod, err := s.cfg.LCD.WithContext(r.Context()).IsModuleAccount(addr); err == nil {
+				out.ModuleAccount = &mod
+			} else {
+				log.Printf("warn: /search module account lookup %s: %v", addr, err)
+			}
+		}
+		return encodeIndented(out)(buf)
+	})
+}
+
+// eachMatch calls fn for every place srv counts addr: single-address cohorts at addr,
+// and cohort items of addr.
+func eachMatch(srv *typesSnapshot, addr string, fn func(c *cohortEntry, m searchMatch)) {
+	for i := range srv.NonCirc.Cohorts {
+		c := &srv.NonCirc.Cohorts[i]
+		if c.Address == addr {
+			fn(c, searchMatch{Cohort: c.Name, CohortID: c.ID, CohortSlug: c.Slug, Amount: c.Amount})
+		}
+		for _, it := range c.Items {
+			if it.Address == addr {
+				fn(c, searchMatch{Cohort: c.Name, CohortID: c.ID, CohortSlug: c.Slug, Amount: it.Amount, EndDate: it.EndDate, EndUnix: it.EndUnix, Permanent: it.Permanent, Source: it.Source, Tier: it.Tier})
+			}
+		}
+	}
+}
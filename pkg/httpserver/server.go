@@ -2,13 +2,27 @@ package httpserver
 
 import (
 	"encoding/json"
+	"io"
 	"log"
+	"math/big"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/lumera-labs/lumera-supply/pkg/cache"
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/logging"
+	"github.com/lumera-labs/lumera-supply/pkg/notify"
 	"github.com/lumera-labs/lumera-supply/pkg/ratelimit"
+	"github.com/lumera-labs/lumera-supply/pkg/scheduler"
+	"github.com/lumera-labs/lumera-supply/pkg/slo"
+	"github.com/lumera-labs/lumera-supply/pkg/store"
 	"github.com/lumera-labs/lumera-supply/pkg/supply"
 	"github.com/lumera-labs/lumera-supply/pkg/types"
 	"github.com/lumera-labs/lumera-supply/schema"
@@ -17,33 +31,157 @@ import (
 type Config struct {
 	Cache        *cache.SnapshotCache
 	Computer     *supply.Computer
+	LCD          *lcd.Client
 	DefaultDenom string
-	RatePerMin   int
-	Burst        int
-	GitTag       string
-	GitCommit    string
+	// Limits are the startup refresh, rate limiting, timeout and concurrency settings;
+	// /admin/tuning overrides them at runtime and /status reports the effective values.
+	Limits config.Limits
+	// LCDTimeouts bound the LCD/RPC clients' requests and follow Limits.LCDTimeout changes.
+	LCDTimeouts []*lcd.Timeout
+	// RequirePolicy makes /circulating answer 503 for snapshots computed without a
+	// policy instead of reporting every cohort as circulating.
+	RequirePolicy bool
+	// Failover, when several LCD endpoints are configured, reports their health in /status.
+	Failover *lcd.Failover
+	// Store persists /admin/tuning overrides across restarts and holds the resumable
+	// items.ndjson export (optional).
+	Store     *store.FileStore
+	GitTag    string
+	GitCommit string
+	// ServeStale is how old the last good snapshot may be and still be served, with
+	// Warning and X-Stale-Age headers, when a refresh fails. Zero answers 502 instead.
+	ServeStale time.Duration
+	// HaltAfter is the block-time lag beyond which the chain is treated as possibly
+	// halted and responses are flagged stale (default 5m).
+	HaltAfter time.Duration
+	// AdminToken enables the /debug/* endpoints for callers presenting it as a bearer
+	// token. Empty disables them.
+	AdminToken string
+	// Watchlist backs /admin/watches unlock subscriptions (optional).
+	Watchlist *notify.Watchlist
+	// SummaryTemplate is the text/template for /summary (DefaultSummaryTemplate when empty).
+	SummaryTemplate string
+	// Endpoints are custom template-rendered endpoints from the config file.
+	Endpoints []config.Endpoint
+	// History holds stored snapshots for /diff (optional; requires a store).
+	History *store.History
+	// Chaos, when set (chaos mode), is managed through /admin/chaos.
+	Chaos *lcd.Chaos
+	// SLO tracks per-endpoint availability and latency for /slo and burn-rate metrics (optional).
+	SLO *slo.Tracker
+	// SlackSigningSecret enables POST /integrations/slack for the /supply slash command.
+	// Empty disables it.
+	SlackSigningSecret string
+	// BulkRatePerMin and BulkBurst size the per-IP token bucket of the bulk downloads
+	// (/snapshot.json, /non_circulating/items.ndjson, /history), spent on top of the general one.
+	// Zero takes DefaultBulkRatePerMin and DefaultBulkBurst.
+	BulkRatePerMin int
+	BulkBurst      int
+	// Jobs runs the periodic jobs listed and triggered by /admin/jobs (optional).
+	Jobs *scheduler.Scheduler
+	// MaxCohortItems is the item count above which /non_circulating summarizes a cohort
+	// and /non_circulating/{cohort} pages its items. Zero takes DefaultMaxCohortItems.
+	MaxCohortItems int
 }
 
 type Server struct {
 	cfg     Config
 	mux     *http.ServeMux
 	limiter *ratelimit.Limiter
+	tune    tuning
+	// stricter limiter for bulk downloads, at bulkRate per minute
+	bulkLimiter *ratelimit.Limiter
+	bulkRate    int
+
+	// projected form of the latest snapshot, reused until its ETag changes
+	projMu sync.Mutex
+	proj   *typesSnapshot
+	// serialized response bodies for the current ETag
+	resp respCache
+	// parsed /summary template
+	summary *template.Template
+	// snapshots computed for ?height= (see snapshotAt)
+	heights heightCache
 }
 
 func New(cfg Config) *Server {
-	lim := ratelimit.New(cfg.RatePerMin, cfg.Burst)
-	s := &Server{cfg: cfg, mux: http.NewServeMux(), limiter: lim}
+	// unset limits take the defaults, as the rate limiter and cache do
+	d := config.DefaultLimits
+	if cfg.Limits.RefreshTTL <= 0 {
+		cfg.Limits.RefreshTTL = d.RefreshTTL
+	}
+	if cfg.Limits.RatePerMin <= 0 {
+		cfg.Limits.RatePerMin = d.RatePerMin
+	}
+	if cfg.Limits.Burst <= 0 {
+		cfg.Limits.Burst = d.Burst
+	}
+	if cfg.Limits.LCDTimeout <= 0 {
+		cfg.Limits.LCDTimeout = d.LCDTimeout
+	}
+	if cfg.Limits.Concurrency <= 0 {
+		cfg.Limits.Concurrency = d.Concurrency
+	}
+	if cfg.MaxCohortItems <= 0 {
+		cfg.MaxCohortItems = DefaultMaxCohortItems
+	}
+	if cfg.BulkRatePerMin <= 0 {
+		cfg.BulkRatePerMin = DefaultBulkRatePerMin
+	}
+	if cfg.BulkBurst <= 0 {
+		cfg.BulkBurst = DefaultBulkBurst
+	}
+	lim := ratelimit.New(cfg.Limits.RatePerMin, cfg.Limits.Burst)
+	s := &Server{cfg: cfg, mux: http.NewServeMux(), limiter: lim, summary: parseSummaryTemplate(cfg.SummaryTemplate),
+		bulkLimiter: ratelimit.New(cfg.BulkRatePerMin, cfg.BulkBurst), bulkRate: cfg.BulkRatePerMin}
+	s.tune.base, s.tune.limits, s.tune.baseLevel = cfg.Limits, cfg.Limits, logging.CurrentLevel()
+	s.loadTuning()
 	// public endpoints
 	s.mux.HandleFunc("/healthz", s.healthz)
+	s.mux.HandleFunc("/readyz", s.handleReadyz)
 	s.mux.HandleFunc("/status", s.wrap(s.handleStatus))
 	s.mux.HandleFunc("/version", s.wrap(s.handleVersion))
 	s.mux.HandleFunc("/total", s.wrap(s.handleTotal))
 	s.mux.HandleFunc("/circulating", s.wrap(s.handleCirculating))
 	s.mux.HandleFunc("/non_circulating", s.wrap(s.handleNonCirc))
+	s.mux.HandleFunc("/non_circulating/", s.wrap(s.handleCohort))
+	s.mux.HandleFunc("/non_circulating/top", s.wrap(s.handleTop))
+	s.mux.HandleFunc("/non_circulating/items.ndjson", s.bulk(s.handleItemsNDJSON))
 	s.mux.HandleFunc("/max", s.wrap(s.handleMax))
+	s.mux.HandleFunc("/staked", s.wrap(s.handleStaked))
+	s.mux.HandleFunc("/cohorts", s.unlimited(s.handleCohorts))
+	s.mux.HandleFunc("/snapshot.json", s.bulk(s.handleSnapshotJSON))
+	s.mux.HandleFunc("/search", s.wrap(s.handleSearch))
+	s.mux.HandleFunc("/address/", s.wrap(s.handleAddress))
+	s.mux.HandleFunc("/module_accounts", s.wrap(s.handleModuleAccounts))
+	s.mux.HandleFunc("/balances", s.wrap(s.handleBalances))
+	s.mux.HandleFunc("/unlocks", s.wrap(s.handleUnlocks))
+	s.mux.HandleFunc("/unlocks.ics", s.wrap(s.handleUnlocksICS))
+	s.mux.HandleFunc("/summary", s.wrap(s.handleSummary))
+	s.mux.HandleFunc("/diff", s.wrap(s.handleDiff))
+	s.mux.HandleFunc("/history", s.bulk(s.handleHistory))
+	s.mux.HandleFunc("/policy/candidate", s.wrap(s.handleCandidate))
+	s.mux.HandleFunc("/slo", s.handleSLO)
 	// swagger/openapi
 	s.mux.HandleFunc("/openapi.yaml", s.handleOpenAPI)
 	s.mux.HandleFunc("/docs", s.handleDocs)
+	s.mux.HandleFunc("/dashboard", s.handleDashboard)
+	s.mux.HandleFunc("/schema/", s.handleSchema)
+	// admin (bearer token)
+	s.mux.HandleFunc("/debug/lcd", s.admin(s.handleDebugLCD))
+	s.mux.HandleFunc("/admin/watches", s.admin(s.handleWatches))
+	s.mux.HandleFunc("/admin/chaos", s.admin(s.handleChaos))
+	s.mux.HandleFunc("/admin/tuning", s.admin(s.handleTuning))
+	s.mux.HandleFunc("/admin/evaluate", s.admin(s.handleEvaluate))
+	s.mux.HandleFunc("/diagnostics", s.admin(s.handleDiagnostics))
+	s.mux.HandleFunc("/admin/jobs", s.admin(s.handleJobs))
+	s.mux.HandleFunc("/admin/jobs/", s.admin(s.handleJobs))
+	// integrations (request signatures)
+	s.mux.HandleFunc("/integrations/slack", s.wrap(s.handleSlack))
+	// Prometheus metrics
+	s.mux.HandleFunc("/metrics", s.handleMetrics)
+	// custom endpoints last, so collisions with built-in routes are detected
+	s.registerCustom(cfg.Endpoints)
 	return s
 }
 
@@ -54,6 +192,14 @@ func (s *Server) Mux() *http.ServeMux { return s.mux }
 // to the internal mux. This allows running behind proxies that do not rewrite
 // the URI (e.g., serving under /supply without nginx "proxy_pass .../" semantics).
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// routes outside s.wrap (admin, metrics, docs) recover here
+	rec := &statusRecorder{ResponseWriter: w}
+	defer func() {
+		if v := recover(); v != nil {
+			recoverPanic(rec, r, v)
+		}
+	}()
+	w = rec
 	pfx := r.Header.Get("X-Forwarded-Prefix")
 	if pfx != "" && pfx != "/" {
 		if !strings.HasPrefix(pfx, "/") {
@@ -77,8 +223,32 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return s.instrument(next, true)
+}
+
+// unlimited is wrap without the per-client rate limit, for endpoints cheap enough to
+// poll freely.
+func (s *Server) unlimited(next http.HandlerFunc) http.HandlerFunc {
+	return s.instrument(next, false)
+}
+
+func (s *Server) instrument(next http.HandlerFunc, limit bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if !s.limiter.Allow(r) {
+		rec := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+		defer func() {
+			// recovered here rather than in ServeHTTP, so the 500 is what gets measured
+			if v := recover(); v != nil {
+				recoverPanic(rec, r, v)
+			}
+			route, took := routeOf(r), time.Since(start)
+			observeRequest(route, rec.status(), took)
+			if s.cfg.SLO != nil {
+				s.cfg.SLO.Observe(route, rec.status(), took)
+			}
+		}()
+		w = rec
+		if limit && !s.limiter.Allow(r) {
 			w.Header().Set("Retry-After", "1")
 			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
 			return
@@ -100,17 +270,96 @@ func (s *Server) parseDenom(r *http.Request) (string, bool) {
 	return denom, true
 }
 
-func (s *Server) snapshot(r *http.Request, denom string) (*response, int, error) {
+// rejectHistorical answers ?height= / ?at= requests with a clear status instead of an
+// opaque upstream error, and reports whether the request was rejected: 400 for malformed
+// values, 501 when the node is pruned (or capabilities are unknown), the height is below
+// the node's earliest block or historical evaluation is not available. ?now= is refused too: published figures are always
+// evaluated at the snapshot's block time, and what-if instants are admin-only.
+func (s *Server) rejectHistorical(w http.ResponseWriter, r *http.Request) bool {
+	return s.checkHistorical(w, r, false)
+}
+
+// acceptHeight is rejectHistorical for the endpoints that serve ?height= (see
+// snapshotAt): a valid height is let through when the node keeps archive state, and
+// one above the latest snapshot gets 400.
+func (s *Server) acceptHeight(w http.ResponseWriter, r *http.Request) bool {
+	return s.checkHistorical(w, r, true)
+}
+
+func (s *Server) checkHistorical(w http.ResponseWriter, r *http.Request, heightOK bool) bool {
+	q := r.URL.Query()
+	if q.Get("now") != "" {
+		http.Error(w, "now= is only available on /admin/evaluate: figures are evaluated at the snapshot's block time", http.StatusBadRequest)
+		return true
+	}
+	h, at := q.Get("height"), q.Get("at")
+	if h == "" && at == "" {
+		return false
+	}
+	var n int64
+	if h != "" {
+		var err error
+		if n, err = strconv.ParseInt(h, 10, 64); err != nil || n <= 0 {
+			http.Error(w, "invalid height", http.StatusBadRequest)
+			return true
+		}
+	}
+	if at != "" {
+		if _, err := time.Parse(time.RFC3339, at); err != nil {
+			http.Error(w, "invalid at (RFC3339 expected)", http.StatusBadRequest)
+			return true
+		}
+	}
+	if s.cfg.LCD == nil || !s.cfg.LCD.Capabilities().Archive {
+		http.Error(w, "historical queries unavailable: upstream node is pruned (no archive state)", http.StatusNotImplemented)
+		return true
+	}
+	if earliest := s.cfg.LCD.Capabilities().EarliestHeight; n > 0 && n < earliest {
+		http.Error(w, "historical queries unavailable: height is below the node's earliest block ("+itoa64(earliest)+")", http.StatusNotImplemented)
+		return true
+	}
+	if heightOK && at == "" && s.cfg.Computer != nil {
+		if latest, _ := s.cfg.Cache.Get(); latest != nil && n > latest.Height {
+			http.Error(w, "height is above the latest block ("+itoa64(latest.Height)+")", http.StatusBadRequest)
+			return true
+		}
+		return false
+	}
+	http.Error(w, "historical queries are not supported yet", http.StatusNotImplemented)
+	return true
+}
+
+// snapshot returns the snapshot for denom, refreshing it when the cached one is not
+// fresh. A failed refresh falls back to the last good snapshot within Config.ServeStale.
+func (s *Server) snapshot(w http.ResponseWriter, r *http.Request, denom string) (*response, int, error) {
 	ifNone := r.Header.Get("If-None-Match")
 	if snap, fresh := s.cfg.Cache.Get(); snap != nil && fresh && ifNone == snap.ETag && snap.Denom == denom {
+		snapshotLookups.Inc("hit")
 		return nil, http.StatusNotModified, nil
 	}
 	// Use cache if fresh, else recompute and refresh
 	if snap, fresh := s.cfg.Cache.Get(); snap != nil && fresh && snap.Denom == denom {
+		snapshotLookups.Inc("hit")
 		return &response{snap: snap}, http.StatusOK, nil
 	}
-	snap, err := s.cfg.Cache.Update(denom)
+	// past its TTL: serve it while a background refresh runs, if configured
+	if snap, age, ok, err := s.cfg.Cache.Revalidate(denom); ok {
+		if err != nil {
+			return nil, 0, err
+		}
+		snapshotLookups.Inc("stale")
+		markStale(w, age)
+		if ifNone == snap.ETag {
+			return nil, http.StatusNotModified, nil
+		}
+		return &response{snap: snap}, http.StatusOK, nil
+	}
+	snapshotLookups.Inc("miss")
+	snap, err := s.cfg.Cache.Update(r.Context(), denom)
 	if err != nil {
+		if resp, ok := s.serveStale(w, r, denom, err); ok {
+			return resp, http.StatusOK, nil
+		}
 		return nil, 0, err
 	}
 	return &response{snap: snap}, http.StatusOK, nil
@@ -135,32 +384,75 @@ type typesSnapshot struct {
 type nonCirc struct {
 	Sum     string        `json:"sum"`
 	Cohorts []cohortEntry `json:"cohorts,omitempty"`
+	Groups  []tagGroup    `json:"groups,omitempty"`
+}
+
+// tagGroup aggregates cohort amounts sharing a policy tag. A cohort with several
+// tags contributes to each of them, so group amounts may sum to more than Sum.
+type tagGroup struct {
+	Tag     string   `json:"tag"`
+	Amount  string   `json:"amount"`
+	Cohorts []string `json:"cohorts"`
 }
 
 type addressItem struct {
-	Address string `json:"address"`
-	Amount  string `json:"amount"`
-	EndDate string `json:"end_date,omitempty"`
+	Address   string `json:"address"`
+	Amount    string `json:"amount"`
+	EndDate   string `json:"end_date,omitempty"`
+	EndUnix   int64  `json:"end_unix,omitempty"`
+	Permanent bool   `json:"permanent"`
+	// Source is the on-chain schedule or the fallback the amount comes from.
+	Source string `json:"source,omitempty"`
+	// Tier is the claim tier of claim_delayed items.
+	Tier int `json:"tier,omitempty"`
+	// AddressGroup names the policy address group described in the cohort's address_groups.
+	AddressGroup string `json:"address_group,omitempty"`
+	// JailedValidators and SlashedLocked flag stake affected by slashing (see supply/slashing.go);
+	// Rewards are unclaimed staking rewards, which circulate.
+	JailedValidators []string `json:"jailed_validators,omitempty"`
+	SlashedLocked    string   `json:"slashed_locked,omitempty"`
+	Rewards          string   `json:"rewards,omitempty"`
+	// Explain is set on ?explain=1 responses only (see explain.go).
+	Explain *types.Explanation `json:"explain,omitempty"`
 }
 
 type cohortEntry struct {
-	Name    string        `json:"name"`
-	Reason  string        `json:"reason"`
-	Address string        `json:"address,omitempty"`
-	Items   []addressItem `json:"items,omitempty"`
-	Amount  string        `json:"amount"`
+	Name      string        `json:"name"`
+	ID        int           `json:"id,omitempty"`
+	Slug      string        `json:"slug"`
+	Reason    string        `json:"reason"`
+	Address   string        `json:"address,omitempty"`
+	Items     []addressItem `json:"items,omitempty"`
+	ItemCount int           `json:"item_count,omitempty"`
+	// ItemSummary replaces Items when there are more than Config.MaxCohortItems.
+	ItemSummary *itemSummary `json:"items_summary,omitempty"`
+	Amount      string       `json:"amount"`
+	// Tiers splits Amount by claim tier (claim_delayed); item filters leave it whole.
+	Tiers []types.TierTotal `json:"tiers,omitempty"`
+	// AddressGroups is the shared policy metadata (custody, notes, disclosure links) of
+	// the address groups the items name.
+	AddressGroups map[string]types.AddressGroup `json:"address_groups,omitempty"`
+	Tags          []string                      `json:"tags,omitempty"`
+	// DisclosureURL and Notes justify the cohort's classification (from the policy).
+	DisclosureURL string `json:"disclosure_url,omitempty"`
+	Notes         string `json:"notes,omitempty"`
+	// AsOfHeight and AsOfTime trail the snapshot for cohorts with a policy refresh_interval.
+	AsOfHeight int64              `json:"as_of_height,omitempty"`
+	AsOfTime   *time.Time         `json:"as_of_time,omitempty"`
+	Explain    *types.Explanation `json:"explain,omitempty"`
 }
 
 // projection helper
 func toTypesSnapshot(s *types.SupplySnapshot) *typesSnapshot {
 	coh := make([]cohortEntry, 0, len(s.NonCirculating.Cohorts))
 	for _, c := range s.NonCirculating.Cohorts {
-		// map items
-		items := make([]addressItem, 0, len(c.Items))
-		for _, it := range c.Items {
-			items = append(items, addressItem{Address: it.Address, Amount: it.Amount, EndDate: it.EndDate})
+		e := cohortEntry{Name: c.Name, ID: c.ID, Slug: c.Slug, Reason: c.Reason, Address: c.Address, Items: toAddressItems(c.Items), ItemCount: c.ItemCount, Amount: c.Amount, Tiers: c.Tiers, AddressGroups: c.AddressGroups, Tags: c.Tags,
+			DisclosureURL: c.DisclosureURL, Notes: c.Notes, Explain: c.Explain}
+		if c.AsOfHeight > 0 {
+			asOf := c.AsOfTime
+			e.AsOfHeight, e.AsOfTime = c.AsOfHeight, &asOf
 		}
-		coh = append(coh, cohortEntry{Name: c.Name, Reason: c.Reason, Address: c.Address, Items: items, Amount: c.Amount})
+		coh = append(coh, e)
 	}
 	return &typesSnapshot{
 		Denom:       s.Denom,
@@ -175,14 +467,96 @@ func toTypesSnapshot(s *types.SupplySnapshot) *typesSnapshot {
 	}
 }
 
-func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, snap *types.SupplySnapshot, project func(*typesSnapshot) any) {
+// toAddressItems maps items to their projected form.
+func toAddressItems(in []types.AddressItem) []addressItem {
+	items := make([]addressItem, 0, len(in))
+	for _, it := range in {
+		items = append(items, addressItem{Address: it.Address, Amount: it.Amount, EndDate: it.EndDate, EndUnix: it.EndUnix, Permanent: it.Permanent, Source: it.Source, Tier: it.Tier, AddressGroup: it.AddressGroup,
+			JailedValidators: it.JailedValidators, SlashedLocked: it.SlashedLocked, Rewards: it.Rewards, Explain: it.Explain})
+	}
+	return items
+}
+
+// project returns the projected form of snap, cached per ETag so hot paths do not
+// re-project thousands of items per request. The result must be treated as read-only.
+func (s *Server) project(snap *types.SupplySnapshot) *typesSnapshot {
+	if snap.Explained() {
+		return toTypesSnapshot(snap)
+	}
+	s.projMu.Lock()
+	defer s.projMu.Unlock()
+	if s.proj != nil && s.proj.ETag == snap.ETag && s.proj.Denom == snap.Denom {
+		return s.proj
+	}
+	p := toTypesSnapshot(snap)
+	// a ?height= snapshot must not replace the latest one's projection
+	if latest, _ := s.cfg.Cache.Get(); latest == snap {
+		s.proj = p
+	}
+	return p
+}
+
+// writeJSON sets the snapshot headers and writes the response body, reusing the bytes
+// serialized earlier for the same (ETag, key) so cache hits are a plain byte copy.
+// JSON bodies get extra fields with ?lossy_numbers=1, ?display=1 and ?tz= (see
+// siblings.go).
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, snap *types.SupplySnapshot, key string, encode func(io.Writer) error) {
+	// amounts are rendered in the display unit, which the ETag does not cover
+	u := displayOf(snap)
+	key += "|unit=" + u.Denom + ":" + strconv.Itoa(u.Decimals)
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		fns, suffix, msg := responseSiblings(r, u)
+		if msg != "" {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+		if len(fns) > 0 {
+			key += suffix
+			encode = withSiblings(encode, fns)
+		}
+	}
+	s.setSnapshotHeaders(w, snap)
+	// ?height= and ?explain= responses are rendered per request: the response cache
+	// holds the latest snapshot's only, and not its filtered or paged slices
+	var b []byte
+	var ok bool
+	pinned, explained := pinnedHeight(r), snap.Explained()
+	if pinned {
+		setPinnedHeaders(w)
+	}
+	if explained {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	cache := !pinned && !explained && cacheable(r)
+	if cache {
+		b, ok = s.resp.get(snap.ETag, key)
+	}
+	if !ok {
+		var err error
+		if b, err = encodeToBytes(encode); err != nil {
+			log.Printf("encode %s: %v", key, err)
+			http.Error(w, "encode error", http.StatusInternalServerError)
+			return
+		}
+		if cache {
+			s.resp.put(snap.ETag, key, b)
+		}
+	}
+	_, _ = w.Write(b)
+}
+
+// setSnapshotHeaders sets the headers identifying the snapshot behind a response.
+func (s *Server) setSnapshotHeaders(w http.ResponseWriter, snap *types.SupplySnapshot) {
 	w.Header().Set("ETag", snap.ETag)
 	w.Header().Set("X-Block-Height", itoa64(snap.Height))
 	w.Header().Set("X-Updated-At", snap.UpdatedAt.Format(time.RFC3339))
-	payload := project(toTypesSnapshot(snap))
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	_ = enc.Encode(payload)
+	if snap.Anomaly != nil {
+		w.Header().Set("X-Supply-Anomaly", snap.Anomaly.Kind)
+	}
+	if !snap.PolicyLoaded() {
+		w.Header().Set("X-Policy-Loaded", "false")
+	}
+	s.setLagHeaders(w, snap)
 }
 
 func (s *Server) handleTotal(w http.ResponseWriter, r *http.Request) {
@@ -191,10 +565,16 @@ func (s *Server) handleTotal(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid denom", http.StatusBadRequest)
 		return
 	}
-	resp, status, err := s.snapshot(r, denom)
+	format, ok := negotiateFormat(w, r, formatText)
+	if !ok {
+		return
+	}
+	if s.acceptHeight(w, r) {
+		return
+	}
+	resp, status, err := s.snapshotAt(w, r, denom)
 	if err != nil {
-		log.Printf("/total error: %v", err)
-		http.Error(w, "upstream error", http.StatusBadGateway)
+		s.snapshotFailed(w, "/total", err)
 		return
 	}
 	if status == http.StatusNotModified {
@@ -202,26 +582,15 @@ func (s *Server) handleTotal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	snap := resp.snap
-	// output minimal fields
-	srv := toTypesSnapshot(snap)
-	out := struct {
-		Denom          string    `json:"denom"`
-		Decimals       int       `json:"decimals"`
-		Height         int64     `json:"height"`
-		UpdatedAt      time.Time `json:"updated_at"`
-		ETag           string    `json:"etag"`
-		PolicyETag     string    `json:"policy-etag"`
-		Total          string    `json:"total"`
-		Circulating    string    `json:"circulating"`
-		NonCirculating string    `json:"non_circulating"`
-		Max            *string   `json:"max"`
-	}{srv.Denom, 6, srv.Height, srv.UpdatedAt, srv.ETag, srv.PolicyETag, srv.Total, srv.Circulating, srv.NonCirc.Sum, srv.Max}
-	w.Header().Set("ETag", srv.ETag)
-	w.Header().Set("X-Block-Height", itoa64(srv.Height))
-	w.Header().Set("X-Updated-At", srv.UpdatedAt.Format(time.RFC3339))
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	_ = enc.Encode(out)
+	if format == formatText {
+		s.writeJSON(w, r, snap, cacheKey("total", r)+"|text", encodeText(snap.Total, displayOf(snap)))
+		return
+	}
+	s.writeJSON(w, r, snap, cacheKey("total", r), func(buf io.Writer) error {
+		// output minimal fields
+		srv := s.project(snap)
+		return encodeIndented(totalPayload{srv.Denom, displayOf(snap).Decimals, srv.Height, srv.UpdatedAt, srv.ETag, srv.PolicyETag, snap.PolicyLoaded(), srv.Total, srv.Circulating, srv.NonCirc.Sum, srv.Max})(buf)
+	})
 }
 
 func (s *Server) handleMax(w http.ResponseWriter, r *http.Request) {
@@ -230,10 +599,16 @@ func (s *Server) handleMax(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid denom", http.StatusBadRequest)
 		return
 	}
-	resp, status, err := s.snapshot(r, denom)
+	format, ok := negotiateFormat(w, r, formatText)
+	if !ok {
+		return
+	}
+	if s.rejectHistorical(w, r) {
+		return
+	}
+	resp, status, err := s.snapshot(w, r, denom)
 	if err != nil {
-		log.Printf("/max error: %v", err)
-		http.Error(w, "upstream error", http.StatusBadGateway)
+		s.snapshotFailed(w, "/max", err)
 		return
 	}
 	if status == http.StatusNotModified {
@@ -241,20 +616,15 @@ func (s *Server) handleMax(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	snap := resp.snap
-	w.Header().Set("ETag", snap.ETag)
-	w.Header().Set("X-Block-Height", itoa64(snap.Height))
-	w.Header().Set("X-Updated-At", snap.UpdatedAt.Format(time.RFC3339))
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	_ = enc.Encode(struct {
-		Denom      string    `json:"denom"`
-		Decimals   int       `json:"decimals"`
-		Height     int64     `json:"height"`
-		UpdatedAt  time.Time `json:"updated_at"`
-		ETag       string    `json:"etag"`
-		PolicyETag string    `json:"policy-etag"`
-		Max        *string   `json:"max"`
-	}{snap.Denom, 6, snap.Height, snap.UpdatedAt, snap.ETag, snap.PolicyETag, snap.Max})
+	if format == formatText {
+		if snap.Max == nil {
+			http.Error(w, "no max supply", http.StatusNotFound)
+			return
+		}
+		s.writeJSON(w, r, snap, cacheKey("max", r)+"|text", encodeText(*snap.Max, displayOf(snap)))
+		return
+	}
+	s.writeJSON(w, r, snap, cacheKey("max", r), encodeIndented(maxPayload{snap.Denom, displayOf(snap).Decimals, snap.Height, snap.UpdatedAt, snap.ETag, snap.PolicyETag, snap.Max}))
 }
 
 func (s *Server) handleCirculating(w http.ResponseWriter, r *http.Request) {
@@ -263,10 +633,16 @@ func (s *Server) handleCirculating(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid denom", http.StatusBadRequest)
 		return
 	}
-	resp, status, err := s.snapshot(r, denom)
+	format, ok := negotiateFormat(w, r, formatText)
+	if !ok {
+		return
+	}
+	if s.acceptHeight(w, r) {
+		return
+	}
+	resp, status, err := s.snapshotAt(w, r, denom)
 	if err != nil {
-		log.Printf("/circulating error: %v", err)
-		http.Error(w, "upstream error", http.StatusBadGateway)
+		s.snapshotFailed(w, "/circulating", err)
 		return
 	}
 	if status == http.StatusNotModified {
@@ -274,23 +650,21 @@ func (s *Server) handleCirculating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	snap := resp.snap
-	srv := toTypesSnapshot(snap)
-	out := struct {
-		Denom          string    `json:"denom"`
-		Decimals       int       `json:"decimals"`
-		Height         int64     `json:"height"`
-		UpdatedAt      time.Time `json:"updated_at"`
-		ETag           string    `json:"etag"`
-		PolicyETag     string    `json:"policy-etag"`
-		Circulating    string    `json:"circulating"`
-		NonCirculating string    `json:"non_circulating"`
-	}{srv.Denom, 6, srv.Height, srv.UpdatedAt, srv.ETag, srv.PolicyETag, srv.Circulating, srv.NonCirc.Sum}
-	w.Header().Set("ETag", srv.ETag)
-	w.Header().Set("X-Block-Height", itoa64(srv.Height))
-	w.Header().Set("X-Updated-At", srv.UpdatedAt.Format(time.RFC3339))
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	_ = enc.Encode(out)
+	if s.cfg.RequirePolicy && !snap.PolicyLoaded() {
+		// aggregators must not cache the refusal
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Policy-Loaded", "false")
+		http.Error(w, "policy not loaded: circulating supply unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if format == formatText {
+		s.writeJSON(w, r, snap, cacheKey("circulating", r)+"|text", encodeText(snap.Circulating, displayOf(snap)))
+		return
+	}
+	s.writeJSON(w, r, snap, cacheKey("circulating", r), func(buf io.Writer) error {
+		srv := s.project(snap)
+		return encodeIndented(circulatingPayload{srv.Denom, displayOf(snap).Decimals, srv.Height, srv.UpdatedAt, srv.ETag, srv.PolicyETag, snap.PolicyLoaded(), srv.Circulating, srv.NonCirc.Sum})(buf)
+	})
 }
 
 func (s *Server) handleNonCirc(w http.ResponseWriter, r *http.Request) {
@@ -299,48 +673,235 @@ func (s *Server) handleNonCirc(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid denom", http.StatusBadRequest)
 		return
 	}
-	resp, status, err := s.snapshot(r, denom)
+	format, ok := negotiateFormat(w, r, formatText, formatCSV)
+	if !ok {
+		return
+	}
+	if s.acceptHeight(w, r) {
+		return
+	}
+	resp, status, err := s.snapshotAt(w, r, denom)
 	if err != nil {
-		log.Printf("/non_circulating error: %v", err)
-		http.Error(w, "upstream error", http.StatusBadGateway)
+		s.snapshotFailed(w, "/non_circulating", err)
 		return
 	}
 	if status == http.StatusNotModified {
 		w.WriteHeader(status)
 		return
 	}
-	snap := resp.snap
-	srv := toTypesSnapshot(snap)
-	// verbose handling (default 0): when 0, omit cohorts
+	snap, ok := s.explained(w, r, "/non_circulating", resp.snap)
+	if !ok {
+		return
+	}
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy != "" && groupBy != "tag" {
+		http.Error(w, "invalid group_by", http.StatusBadRequest)
+		return
+	}
+	filter, msg := parseItemFilter(r)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	// verbose handling (default 0): when 0, omit cohorts; item filters and explain imply verbose
 	v := r.URL.Query().Get("verbose")
-	breakdown := srv.NonCirc
-	if v == "" || v == "0" || v == "false" || v == "False" {
-		breakdown.Cohorts = nil
-	}
-	out := struct {
-		Denom      string    `json:"denom"`
-		Decimals   int       `json:"decimals"`
-		Height     int64     `json:"height"`
-		UpdatedAt  time.Time `json:"updated_at"`
-		ETag       string    `json:"etag"`
-		PolicyETag string    `json:"policy-etag"`
-		Breakdown  nonCirc   `json:"non_circulating"`
-	}{srv.Denom, 6, srv.Height, srv.UpdatedAt, srv.ETag, srv.PolicyETag, breakdown}
-	w.Header().Set("ETag", srv.ETag)
-	w.Header().Set("X-Block-Height", itoa64(srv.Height))
-	w.Header().Set("X-Updated-At", srv.UpdatedAt.Format(time.RFC3339))
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	_ = enc.Encode(out)
+	verbose := !(v == "" || v == "0" || v == "false" || v == "False") || filter.active() || snap.Explained()
+	// items=0 keeps verbose cohorts to their sums and item counts
+	withItems := r.URL.Query().Get("items") != "0"
+	key := cacheKey("non_circulating", r, append([]string{"verbose", "group_by", "items", "denom"}, itemFilterParams...)...)
+	switch format {
+	case formatText:
+		s.writeJSON(w, r, snap, key+"|text", encodeText(snap.NonCirculating.Sum, displayOf(snap)))
+		return
+	case formatCSV:
+		// one row per locked position, whatever verbose and items say
+		s.writeJSON(w, r, snap, key+"|csv", func(buf io.Writer) error {
+			srv, err := s.hydrated(snap)
+			if err != nil {
+				return err
+			}
+			return encodeItemsCSV(buf, srv, filter, displayOf(snap))
+		})
+		return
+	}
+	write := s.writeJSON
+	if verbose && withItems {
+		// item lists can be large: stream them instead of caching whole bodies
+		write = s.streamJSON
+	}
+	write(w, r, snap, key, func(buf io.Writer) error {
+		srv := s.project(snap)
+		if verbose && (withItems || filter.active()) {
+			var err error
+			if srv, err = s.hydrated(snap); err != nil {
+				return err
+			}
+		}
+		breakdown := srv.NonCirc
+		if groupBy == "tag" {
+			breakdown.Groups = groupByTag(breakdown.Cohorts)
+		}
+		if !verbose {
+			breakdown.Cohorts = nil
+		} else {
+			breakdown.Cohorts = filter.apply(breakdown.Cohorts)
+		}
+		if !withItems {
+			breakdown.Cohorts = withoutItems(breakdown.Cohorts)
+		} else {
+			breakdown.Cohorts = summarize(breakdown.Cohorts, s.cfg.MaxCohortItems, r.URL.Query())
+		}
+		out := nonCircPayload{srv.Denom, displayOf(snap).Decimals, srv.Height, srv.UpdatedAt, srv.ETag, srv.PolicyETag, snap.PolicyLoaded(), nil, breakdown}
+		if verbose {
+			out.ComputeStats = snap.ComputeStats
+		}
+		return streamNonCirc(buf, out)
+	})
+}
+
+// hydrated projects snap with any offloaded cohort items loaded from the store. The
+// hydrated copy is not cached per ETag; only serialized responses are.
+func (s *Server) hydrated(snap *types.SupplySnapshot) (*typesSnapshot, error) {
+	full, err := s.cfg.Cache.Hydrate(snap)
+	if err != nil {
+		return nil, err
+	}
+	if full != snap {
+		return toTypesSnapshot(full), nil
+	}
+	return s.project(snap), nil
+}
+
+// non_circulating/{cohort}: a single cohort with its items (item filters apply)
+func (s *Server) handleCohort(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/non_circulating/")
+	if name == "" || strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	if s.rejectHistorical(w, r) {
+		return
+	}
+	filter, msg := parseItemFilter(r)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	offset, limit, msg := parsePage(r)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	resp, status, err := s.snapshot(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, name, err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	snap, ok := s.explained(w, r, name, resp.snap)
+	if !ok {
+		return
+	}
+	found := false
+	for _, c := range snap.NonCirculating.Cohorts {
+		if c.Name == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		http.Error(w, "unknown cohort", http.StatusNotFound)
+		return
+	}
+	s.writeJSON(w, r, snap, cacheKey("cohort:"+name, r, append([]string{"limit", "offset"}, itemFilterParams...)...), func(buf io.Writer) error {
+		srv := s.project(snap)
+		i := slices.IndexFunc(srv.NonCirc.Cohorts, func(c cohortEntry) bool { return c.Name == name })
+		if i < 0 {
+			return nil
+		}
+		c := srv.NonCirc.Cohorts[i]
+		out := cohortPayload{Denom: srv.Denom, Decimals: displayOf(snap).Decimals, Height: srv.Height, UpdatedAt: srv.UpdatedAt, ETag: srv.ETag, PolicyETag: srv.PolicyETag, Cohort: c}
+		if filter.active() {
+			// filters need every item
+			full, err := s.hydrated(snap)
+			if err != nil {
+				return err
+			}
+			items := filter.items(full.NonCirc.Cohorts[i].Items)
+			out.Cohort.Items = items
+			// large cohorts are paged even when no limit is given
+			if n := pageLimit(limit, len(items), s.cfg.MaxCohortItems); n > 0 {
+				out.Cohort.Items, out.Page = page(items, offset, n)
+			}
+			return encodeIndented(out)(buf)
+		}
+		// unfiltered pages are read from the store by range, not hydrated in full
+		total := max(c.ItemCount, len(c.Items))
+		from, n := 0, total
+		if size := pageLimit(limit, total, s.cfg.MaxCohortItems); size > 0 {
+			from, n = offset, size
+			out.Page = pageOf(offset, size, total)
+		}
+		items, err := s.cfg.Cache.CohortItems(snap, name, from, n)
+		if err != nil {
+			return err
+		}
+		out.Cohort.Items = toAddressItems(items)
+		return encodeIndented(out)(buf)
+	})
+}
+
+// withoutItems returns copies of cohorts without their items.
+func withoutItems(cohorts []cohortEntry) []cohortEntry {
+	out := make([]cohortEntry, len(cohorts))
+	for i, c := range cohorts {
+		c.Items = nil
+		out[i] = c
+	}
+	return out
+}
+
+// groupByTag sums cohort amounts per tag; untagged cohorts are reported under "untagged".
+func groupByTag(cohorts []cohortEntry) []tagGroup {
+	sums := map[string]*big.Int{}
+	names := map[string][]string{}
+	for _, c := range cohorts {
+		tags := c.Tags
+		if len(tags) == 0 {
+			tags = []string{"untagged"}
+		}
+		amt, ok := new(big.Int).SetString(c.Amount, 10)
+		if !ok {
+			amt = big.NewInt(0)
+		}
+		for _, t := range tags {
+			if sums[t] == nil {
+				sums[t] = big.NewInt(0)
+			}
+			sums[t].Add(sums[t], amt)
+			names[t] = append(names[t], c.Name)
+		}
+	}
+	out := make([]tagGroup, 0, len(sums))
+	for t, v := range sums {
+		out = append(out, tagGroup{Tag: t, Amount: v.String(), Cohorts: names[t]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tag < out[j].Tag })
+	return out
 }
 
 func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	enc := json.NewEncoder(w)
-	_ = enc.Encode(struct {
-		Status string `json:"status"`
-		Time   string `json:"time"`
-	}{"ok", time.Now().UTC().Format(time.RFC3339)})
+	_ = enc.Encode(healthPayload{"ok", time.Now().UTC().Format(time.RFC3339)})
 }
 
 // status: { status, height, updated_at, policy_etag, etag }
@@ -350,10 +911,9 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid denom", http.StatusBadRequest)
 		return
 	}
-	resp, status, err := s.snapshot(r, denom)
+	resp, status, err := s.snapshot(w, r, denom)
 	if err != nil {
-		log.Printf("/status error: %v", err)
-		http.Error(w, "upstream error", http.StatusBadGateway)
+		s.snapshotFailed(w, "/status", err)
 		return
 	}
 	if status == http.StatusNotModified {
@@ -361,35 +921,46 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	snap := resp.snap
+	lag, halted := s.chainLag(snap)
+	readiness, checks, _ := s.healthChecks()
+	out := statusPayload{SchemaVersion: HealthSchemaVersion, Status: "ok", Readiness: readiness, Checks: checks, Height: snap.Height, UpdatedAt: snap.UpdatedAt, EvaluatedAt: snap.EvaluationTime(), ETag: snap.ETag, PolicyETag: snap.PolicyETag, PolicyLoaded: snap.PolicyLoaded(),
+		ChainLagSeconds: int64(lag.Seconds()), PossiblyStale: halted, ComputeStats: snap.ComputeStats, Limits: limitsOf(s.limits(), s.endpointTimeouts(), logging.CurrentLevel()), Burns: snap.Burns, Overlaps: snap.Overlaps, Anomaly: snap.Anomaly, ResponseCache: s.resp.stats()}
+	if o := s.overrides(); o != (config.Tuning{}) {
+		out.Overrides = &o
+	}
+	co := s.cfg.Cache.Coalescing()
+	out.Refresh = refreshStatus{InFlight: co.InFlight, Waiting: co.Waiting, CoalescedTotal: co.Coalesced, TimeToFreshMS: co.TimeToFresh.Milliseconds()}
+	if halted {
+		out.Status = "stale"
+	}
+	if s.cfg.LCD != nil {
+		caps := s.cfg.LCD.Capabilities()
+		out.Node = &caps
+	}
+	if s.cfg.Failover != nil {
+		out.LCDEndpoints = s.cfg.Failover.Endpoints()
+	}
+	// node capabilities and the cache counters change independently of the snapshot, so
+	// /status is not served from the response cache
 	w.Header().Set("ETag", snap.ETag)
 	w.Header().Set("X-Block-Height", itoa64(snap.Height))
 	w.Header().Set("X-Updated-At", snap.UpdatedAt.Format(time.RFC3339))
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	_ = enc.Encode(struct {
-		Status     string    `json:"status"`
-		Height     int64     `json:"height"`
-		UpdatedAt  time.Time `json:"updated_at"`
-		ETag       string    `json:"etag"`
-		PolicyETag string    `json:"policy-etag"`
-	}{"ok", snap.Height, snap.UpdatedAt, snap.ETag, snap.PolicyETag})
+	s.setLagHeaders(w, snap)
+	_ = encodeIndented(out)(w)
 }
 
 // version: { github-hash, git-tag, policy_etag }
 func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
-	// We don't need a fresh snapshot; policy ETag can be taken from last cached if present
-	snap, _ := s.cfg.Cache.Get()
+	// the loaded policy, even before the first snapshot computed with it
 	policyETag := ""
-	if snap != nil {
+	if s.cfg.Computer != nil {
+		policyETag = s.cfg.Computer.PolicyETag()
+	} else if snap, _ := s.cfg.Cache.Get(); snap != nil {
 		policyETag = snap.PolicyETag
 	}
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
-	_ = enc.Encode(struct {
-		GitHash    string `json:"github-hash"`
-		GitTag     string `json:"git-tag"`
-		PolicyETag string `json:"policy_etag"`
-	}{s.cfg.GitCommit, s.cfg.GitTag, policyETag})
+	_ = enc.Encode(versionPayload{s.cfg.GitCommit, s.cfg.GitTag, policyETag})
 }
 
 func itoa64(n int64) string {
@@ -572,3 +1143,25 @@ func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Cache-Control", "public, max-age=300")
 	_, _ = w.Write([]byte(swaggerUIHTML))
 }
+
+// handleSchema serves the generated JSON Schema for a response payload at /schema/{endpoint}.json.
+func (s *Server) handleSchema(w http.ResponseWriter, r *http.Request) {
+	if !s.limiter.Allow(r) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/schema/")
+	if name == "" || strings.Contains(name, "/") || !strings.HasSuffix(name, ".json") {
+		http.NotFound(w, r)
+		return
+	}
+	b, err := schema.Responses.ReadFile("json/" + name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_, _ = w.Write(b)
+}
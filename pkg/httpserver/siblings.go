@@ -0,0 +1,134 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// siblingFunc derives an extra field from a string field of a JSON response: for key
+// and value it returns the new field's name and its encoded JSON value.
+type siblingFunc func(key, value string) (name string, raw []byte, ok bool)
+
+// responseSiblings returns the sibling fields requested by ?lossy_numbers, ?display
+// (amounts in u) and ?tz, and a suffix distinguishing the variant in the response cache.
+// The string is an error message when a parameter is invalid.
+func responseSiblings(r *http.Request, u displayUnit) ([]siblingFunc, string, string) {
+	var fns []siblingFunc
+	var key string
+	if wantsLossyNumbers(r) {
+		fns = append(fns, numericSibling)
+		key += "|lossy_numbers"
+	}
+	if wantsDisplay(r) {
+		fns = append(fns, displaySibling(u))
+		key += "|display"
+	}
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		fn, err := localSibling(tz)
+		if err != nil {
+			return nil, "", "invalid tz"
+		}
+		fns = append(fns, fn)
+		key += "|tz=" + tz
+	}
+	return fns, key, ""
+}
+
+// withSiblings wraps encode so the fields derived by fns follow their source fields.
+// Field order is otherwise preserved and the output stays indented.
+func withSiblings(encode func(io.Writer) error, fns []siblingFunc) func(io.Writer) error {
+	return func(w io.Writer) error {
+		var src bytes.Buffer
+		if err := encode(&src); err != nil {
+			return err
+		}
+		compact, err := addSiblings(src.Bytes(), fns)
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, compact, "", "  "); err != nil {
+			return err
+		}
+		out.WriteByte('\n')
+		_, err = w.Write(out.Bytes())
+		return err
+	}
+}
+
+// addSiblings re-emits the JSON document src compactly, adding the derived fields.
+func addSiblings(src []byte, fns []siblingFunc) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+	type frame struct {
+		object bool
+		n      int // tokens written; in objects keys and values alternate
+	}
+	var (
+		out   bytes.Buffer
+		stack []frame
+		key   string
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			out.WriteByte(byte(d))
+			continue
+		}
+		var top *frame
+		isKey, isField := false, false
+		if len(stack) > 0 {
+			top = &stack[len(stack)-1]
+			isKey = top.object && top.n%2 == 0
+			isField = top.object && !isKey
+			switch {
+			case isField:
+				out.WriteByte(':')
+			case top.n > 0:
+				out.WriteByte(',')
+			}
+			top.n++
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			out.WriteByte(byte(v))
+			stack = append(stack, frame{object: v == '{'})
+		case string:
+			b, _ := json.Marshal(v)
+			out.Write(b)
+			if isKey {
+				key = v
+				continue
+			}
+			if !isField {
+				continue
+			}
+			for _, fn := range fns {
+				if name, raw, ok := fn(key, v); ok {
+					b, _ := json.Marshal(name)
+					out.WriteByte(',')
+					out.Write(b)
+					out.WriteByte(':')
+					out.Write(raw)
+					top.n += 2
+				}
+			}
+		case json.Number:
+			out.WriteString(v.String())
+		case bool:
+			out.WriteString(strconv.FormatBool(v))
+		case nil:
+			out.WriteString("null")
+		}
+	}
+}
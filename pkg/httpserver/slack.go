@@ -0,0 +1,99 @@
+package httpserver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// slackMaxSkew bounds the age of a signed Slack request to stop replays.
+const slackMaxSkew = 5 * time.Minute
+
+const slackHelp = "Usage: `/supply` posts the current circulating supply to the channel; `/supply me` shows it only to you."
+
+type slackResponse struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// integrations/slack: answers the /supply slash command with the summary line
+func (s *Server) handleSlack(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.SlackSigningSecret == "" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if !slackSignatureValid(s.cfg.SlackSigningSecret, r.Header, body, time.Now()) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	out := slackResponse{ResponseType: "in_channel"}
+	switch arg := strings.TrimSpace(form.Get("text")); arg {
+	case "help":
+		out = slackResponse{ResponseType: "ephemeral", Text: slackHelp}
+	case "", "me":
+		if arg == "me" {
+			out.ResponseType = "ephemeral"
+		}
+		// Slack shows non-200 answers as a generic failure, so errors are reported in-band
+		resp, _, err := s.snapshot(w, r, s.cfg.DefaultDenom)
+		if err != nil {
+			log.Printf("/integrations/slack error: %v", err)
+			out = slackResponse{ResponseType: "ephemeral", Text: "Supply data is temporarily unavailable, please try again shortly."}
+			break
+		}
+		var b strings.Builder
+		if err := s.renderSummary(&b, resp.snap); err != nil {
+			log.Printf("/integrations/slack summary: %v", err)
+			out = slackResponse{ResponseType: "ephemeral", Text: "Supply summary could not be rendered."}
+			break
+		}
+		out.Text = b.String()
+	default:
+		out = slackResponse{ResponseType: "ephemeral", Text: "Unknown option `" + arg + "`. " + slackHelp}
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// slackSignatureValid checks X-Slack-Signature (v0=HMAC-SHA256 of "v0:<ts>:<body>") and
+// that X-Slack-Request-Timestamp is recent.
+func slackSignatureValid(secret string, h http.Header, body []byte, now time.Time) bool {
+	ts := h.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > slackMaxSkew || d < -slackMaxSkew {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(want), []byte(h.Get("X-Slack-Signature")))
+}
@@ -0,0 +1,88 @@
+package httpserver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Slack's published example (api.slack.com/authentication/verifying-requests-from-slack)
+const (
+	slackExampleSecret    = "8f742231b10e8888abcd99yyyzzz85a5"
+	slackExampleTimestamp = "1531420618"
+	slackExampleBody      = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+	slackExampleSignature = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+)
+
+func TestSlackSignatureValid(t *testing.T) {
+	sent := time.Unix(1531420618, 0)
+	for _, tc := range []struct {
+		name      string
+		timestamp string
+		signature string
+		body      string
+		now       time.Time
+		want      bool
+	}{
+		{"published example", slackExampleTimestamp, slackExampleSignature, slackExampleBody, sent, true},
+		{"within skew", slackExampleTimestamp, slackExampleSignature, slackExampleBody, sent.Add(slackMaxSkew), true},
+		{"tampered body", slackExampleTimestamp, slackExampleSignature, strings.Replace(slackExampleBody, "text=", "text=me", 1), sent, false},
+		{"missing timestamp", "", slackExampleSignature, slackExampleBody, sent, false},
+		{"garbage timestamp", "soon", slackExampleSignature, slackExampleBody, sent, false},
+		{"too old", slackExampleTimestamp, slackExampleSignature, slackExampleBody, sent.Add(slackMaxSkew + time.Second), false},
+		{"from the future", slackExampleTimestamp, slackExampleSignature, slackExampleBody, sent.Add(-slackMaxSkew - time.Second), false},
+		{"wrong version prefix", slackExampleTimestamp, "v1=" + strings.TrimPrefix(slackExampleSignature, "v0="), slackExampleBody, sent, false},
+		{"bare digest", slackExampleTimestamp, strings.TrimPrefix(slackExampleSignature, "v0="), slackExampleBody, sent, false},
+		{"missing signature", slackExampleTimestamp, "", slackExampleBody, sent, false},
+	} {
+		h := http.Header{}
+		h.Set("X-Slack-Request-Timestamp", tc.timestamp)
+		h.Set("X-Slack-Signature", tc.signature)
+		if got := slackSignatureValid(slackExampleSecret, h, []byte(tc.body), tc.now); got != tc.want {
+			t.Errorf("%s: %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSlackHandlerRejects(t *testing.T) {
+	srv := newTestServer(t, nil)
+	send := func(method, timestamp, signature, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/integrations/slack", strings.NewReader(body))
+		req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		req.Header.Set("X-Slack-Signature", signature)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	post := func(method, signature string) int {
+		return send(method, slackExampleTimestamp, signature, slackExampleBody).Code
+	}
+	if code := post("POST", slackExampleSignature); code != http.StatusNotFound {
+		t.Fatalf("without a signing secret: %d, want 404", code)
+	}
+	srv.cfg.SlackSigningSecret = slackExampleSecret
+	if code := post("GET", slackExampleSignature); code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: %d, want 405", code)
+	}
+	// the example is years old, so even its own signature is out of the skew window
+	for _, sig := range []string{slackExampleSignature, "v0=00"} {
+		if code := post("POST", sig); code != http.StatusUnauthorized {
+			t.Errorf("signature %q: %d, want 401", sig, code)
+		}
+	}
+
+	// a fresh, correctly signed command is answered
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(slackExampleSecret))
+	mac.Write([]byte("v0:" + ts + ":" + slackExampleBody))
+	rec := send("POST", ts, "v0="+hex.EncodeToString(mac.Sum(nil)), slackExampleBody)
+	if rec.Code != 200 || !strings.Contains(rec.Body.String(), `"response_type":"in_channel"`) {
+		t.Errorf("signed command: %d %s", rec.Code, rec.Body)
+	}
+}
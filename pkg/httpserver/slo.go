@@ -0,0 +1,60 @@
+package httpserver
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// statusRecorder captures the response status for SLO accounting.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
+
+func (r *statusRecorder) status() int {
+	if r.code == 0 {
+		return http.StatusOK
+	}
+	return r.code
+}
+
+// routeOf maps a request to its route so per-cohort and per-address paths share one
+// SLO series.
+func routeOf(r *http.Request) string {
+	p := r.URL.Path
+	if strings.HasPrefix(p, "/non_circulating/") && p != "/non_circulating/top" && p != "/non_circulating/items.ndjson" {
+		return "/non_circulating/{cohort}"
+	}
+	if strings.HasPrefix(p, "/address/") {
+		return "/address/{addr}"
+	}
+	return p
+}
+
+// slo: per-endpoint attainment and burn rates over rolling windows
+func (s *Server) handleSLO(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.SLO == nil {
+		http.Error(w, "slo tracking not configured", http.StatusNotImplemented)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = encodeIndented(sloPayload{Time: time.Now().UTC(), Endpoints: s.cfg.SLO.Summary()})(w)
+}
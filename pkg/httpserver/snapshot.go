@@ -0,0 +1,60 @@
+package httpserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// snapshot.json: the full snapshot (every cohort with its items, overlaps, anomaly,
+// burns and compute stats) as one canonical document for audit mirrors. The bytes are
+// types.SupplySnapshot.CanonicalJSON, unchanged for an ETag while the instance runs, and
+// carry their SHA-256 in Repr-Digest, Content-Digest and X-Snapshot-SHA256 so copies can
+// be verified. Range requests resume interrupted downloads (see serveBulk). Response
+// options such as ?lossy_numbers= do not apply.
+func (s *Server) handleSnapshotJSON(w http.ResponseWriter, r *http.Request) {
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	if s.rejectHistorical(w, r) {
+		return
+	}
+	resp, status, err := s.snapshot(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, "/snapshot.json", err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	snap := resp.snap
+	key := cacheKey("snapshot.json", r)
+	b, ok := s.resp.get(snap.ETag, key)
+	if !ok {
+		full, err := s.cfg.Cache.Hydrate(snap)
+		if err == nil {
+			b, err = full.CanonicalJSON()
+		}
+		if err != nil {
+			log.Printf("encode %s: %v", key, err)
+			http.Error(w, "encode error", http.StatusInternalServerError)
+			return
+		}
+		s.resp.put(snap.ETag, key, b)
+	}
+	sum := sha256.Sum256(b)
+	s.setSnapshotHeaders(w, snap)
+	// Repr-Digest covers the whole document; serveBulk adds Content-Digest, which covers
+	// only the bytes sent, on full responses
+	w.Header().Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":")
+	w.Header().Set("X-Snapshot-SHA256", hex.EncodeToString(sum[:]))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="lumera-supply-%s-%d.json"`, snap.Denom, snap.Height))
+	s.serveBulk(w, r, snap.ETag, snap.UpdatedAt, bytes.NewReader(b))
+}
@@ -0,0 +1,59 @@
+package httpserver
+
+import (
+	"math/big"
+	"net/http"
+)
+
+// bondedRatioDigits is the precision of stakedPayload.BondedRatio.
+const bondedRatioDigits = 6
+
+// staked: the staking pool for the bond denom. Bonded tokens are the "staked supply"
+// market data providers list; the policy's staking_bonded toggle decides whether they
+// also count as non-circulating.
+func (s *Server) handleStaked(w http.ResponseWriter, r *http.Request) {
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	format, ok := negotiateFormat(w, r, formatText)
+	if !ok {
+		return
+	}
+	if s.acceptHeight(w, r) {
+		return
+	}
+	resp, status, err := s.snapshotAt(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, "/staked", err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	snap := resp.snap
+	if snap.Staked == nil {
+		http.Error(w, "no staking pool for "+denom+" (not the bond denom, or the pool could not be fetched)", http.StatusNotFound)
+		return
+	}
+	if format == formatText {
+		s.writeJSON(w, r, snap, cacheKey("staked", r)+"|text", encodeText(snap.Staked.Bonded, displayOf(snap)))
+		return
+	}
+	s.writeJSON(w, r, snap, cacheKey("staked", r), encodeIndented(stakedPayload{
+		snap.Denom, displayOf(snap).Decimals, snap.Height, snap.UpdatedAt, snap.ETag, snap.PolicyETag,
+		snap.Total, snap.Staked.Bonded, snap.Staked.NotBonded, ratio(snap.Staked.Bonded, snap.Total),
+	}))
+}
+
+// ratio returns part/whole as a decimal string, "0" when whole is zero or invalid.
+func ratio(part, whole string) string {
+	p, ok1 := new(big.Rat).SetString(part)
+	w, ok2 := new(big.Rat).SetString(whole)
+	if !ok1 || !ok2 || w.Sign() <= 0 {
+		return "0"
+	}
+	return new(big.Rat).Quo(p, w).FloatString(bondedRatioDigits)
+}
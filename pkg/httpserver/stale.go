@@ -0,0 +1,59 @@
+package httpserver
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/cache"
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+)
+
+var staleServed = metrics.Default.NewCounter(
+	"lumera_supply_stale_served_total",
+	"Responses served from the last good snapshot after a failed refresh, by endpoint.",
+	"endpoint",
+)
+
+// serveStale returns the cached snapshot for denom in place of a failed refresh when
+// it is no older than Config.ServeStale, and marks the response with Warning: 110 and
+// X-Stale-Age (seconds since the snapshot was computed).
+func (s *Server) serveStale(w http.ResponseWriter, r *http.Request, denom string, refreshErr error) (*response, bool) {
+	if s.cfg.ServeStale <= 0 {
+		return nil, false
+	}
+	snap, _ := s.cfg.Cache.Get()
+	if snap == nil || snap.Denom != denom {
+		return nil, false
+	}
+	age := time.Since(s.cfg.Cache.Health().LastSuccess)
+	if age > s.cfg.ServeStale {
+		return nil, false
+	}
+	markStale(w, age)
+	staleServed.Inc(routeOf(r))
+	log.Printf("warn: %s: serving snapshot %s computed %ds ago: %v", r.URL.Path, snap.ETag, int64(age.Seconds()), refreshErr)
+	return &response{snap: snap}, true
+}
+
+// markStale flags a response built from a snapshot computed age ago that is past its
+// TTL.
+func markStale(w http.ResponseWriter, age time.Duration) {
+	w.Header().Set("Warning", `110 - "Response is Stale"`)
+	w.Header().Set("X-Stale-Age", itoa64(int64(age.Seconds())))
+}
+
+// snapshotFailed answers a request whose snapshot could not be had: 503 while the cached
+// one is too stale to serve and a refresh runs (retry after about the time the latest
+// refresh took), 502 for upstream failures.
+func (s *Server) snapshotFailed(w http.ResponseWriter, route string, err error) {
+	log.Printf("%s error: %v", route, err)
+	if errors.Is(err, cache.ErrTooStale) {
+		retry := int64(s.cfg.Cache.Coalescing().TimeToFresh.Seconds()) + 1
+		w.Header().Set("Retry-After", itoa64(retry))
+		http.Error(w, "snapshot too stale, refresh in progress", http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, "upstream error", http.StatusBadGateway)
+}
@@ -0,0 +1,93 @@
+package httpserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// streamJSON is writeJSON for bodies too large to hold in memory: encode writes straight
+// to the response, which skips the response cache. Sibling fields (?lossy_numbers,
+// ?display, ?tz) rewrite the whole document, so those requests take writeJSON.
+func (s *Server) streamJSON(w http.ResponseWriter, r *http.Request, snap *types.SupplySnapshot, key string, encode func(io.Writer) error) {
+	if fns, _, msg := responseSiblings(r, displayOf(snap)); len(fns) > 0 || msg != "" {
+		s.writeJSON(w, r, snap, key, encode)
+		return
+	}
+	s.setSnapshotHeaders(w, snap)
+	if pinnedHeight(r) {
+		setPinnedHeaders(w)
+	}
+	if snap.Explained() {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	cw := &countingWriter{w: w}
+	if err := encode(cw); err != nil {
+		log.Printf("encode %s: %v", key, err)
+		// once the body has started, the status is sent; the client sees it truncated
+		if cw.n == 0 {
+			http.Error(w, "encode error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// closing of the non_circulating object and the envelope in an indented nonCircPayload
+var nonCircTail = []byte("\n  }\n}")
+
+// streamNonCirc writes out as indented JSON, encoding cohorts one at a time through a
+// buffered writer so verbose breakdowns are never materialized as a single document.
+func streamNonCirc(w io.Writer, out nonCircPayload) error {
+	cohorts := out.Breakdown.Cohorts
+	out.Breakdown.Cohorts = nil
+	head, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	if len(cohorts) == 0 {
+		head = append(head, '\n')
+		_, err = w.Write(head)
+		return err
+	}
+	// non_circulating is the last field, so the envelope ends with nonCircTail
+	if !bytes.HasSuffix(head, nonCircTail) {
+		return errors.New("unexpected non_circulating envelope shape")
+	}
+	bw := bufio.NewWriterSize(w, 32<<10)
+	bw.Write(head[:len(head)-len(nonCircTail)])
+	bw.WriteString(",\n    \"cohorts\": [")
+	// one reusable chunk buffer; Encode's trailing newline is trimmed per chunk
+	var chunk bytes.Buffer
+	enc := json.NewEncoder(&chunk)
+	enc.SetIndent("      ", "  ")
+	for i, c := range cohorts {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString("\n      ")
+		chunk.Reset()
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+		bw.Write(bytes.TrimSuffix(chunk.Bytes(), []byte("\n")))
+	}
+	bw.WriteString("\n    ]\n  }\n}\n")
+	return bw.Flush()
+}
@@ -0,0 +1,30 @@
+package httpserver
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+func TestStreamJSONSkipsCache(t *testing.T) {
+	s := New(Config{})
+	snap := &types.SupplySnapshot{Denom: "ulume", ETag: "e1", Height: 7}
+	body := encodeIndented(map[string]string{"a": "b"})
+	req := httptest.NewRequest("GET", "/non_circulating?verbose=1", nil)
+
+	rec := httptest.NewRecorder()
+	s.streamJSON(rec, req, snap, "non_circulating|verbose=1", body)
+	if rec.Code != 200 || rec.Body.String() != "{\n  \"a\": \"b\"\n}\n" || rec.Header().Get("ETag") != "e1" {
+		t.Fatalf("streamed %d %q %v", rec.Code, rec.Body, rec.Header())
+	}
+	if len(s.resp.entries) != 0 {
+		t.Fatalf("streamed body cached: %v", s.resp.entries)
+	}
+
+	s.writeJSON(httptest.NewRecorder(), req, snap, "total", body)
+	s.writeJSON(httptest.NewRecorder(), req, snap, "total", body)
+	if st := s.resp.stats(); st.Hits != 1 || st.Misses != 1 {
+		t.Fatalf("cache stats %+v, want one hit and one miss", st)
+	}
+}
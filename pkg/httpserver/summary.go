@@ -0,0 +1,120 @@
+package httpserver
+
+import (
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+	"github.com/lumera-labs/lumera-supply/pkg/units"
+)
+
+// DefaultSummaryTemplate renders e.g. "Circulating: 123.4M LUME (41.2% of max) at block 1,234,567".
+const DefaultSummaryTemplate = `Circulating: {{human .Circulating}} {{.Symbol}}{{if .Max}} ({{pct .Circulating .Max}} of max){{end}} at block {{commas .Height}}`
+
+// summaryData is the value the /summary template executes against. Amounts are
+// base-unit strings; use the template funcs to format them.
+type summaryData struct {
+	Denom          string
+	Symbol         string
+	Height         int64
+	UpdatedAt      time.Time
+	Total          string
+	Circulating    string
+	NonCirculating string
+	Max            string // empty when there is no max supply
+}
+
+// summaryFuncs returns the /summary template funcs, formatting amounts in u. Templates
+// are parsed with the default unit and executed with the snapshot's (see withUnit).
+func summaryFuncs(u displayUnit) template.FuncMap {
+	return template.FuncMap{
+		// human: 123456789000000 -> 123.5M (display units)
+		"human": func(base string) string { return units.Human(base, u.Decimals) },
+		// units: full display amount with separators (1,234.5)
+		"units": func(base string) string { return units.Format(base, u.Decimals) },
+		// pct: a as a percentage of b with one decimal (41.2%)
+		"pct": func(a, b string) string {
+			x, ok1 := new(big.Rat).SetString(a)
+			y, ok2 := new(big.Rat).SetString(b)
+			if !ok1 || !ok2 || y.Sign() == 0 {
+				return "n/a"
+			}
+			return new(big.Rat).Mul(new(big.Rat).Quo(x, y), big.NewRat(100, 1)).FloatString(1) + "%"
+		},
+		// commas: 1234567 -> 1,234,567
+		"commas": func(n int64) string { return units.Format(strconv.FormatInt(n, 10), 0) },
+	}
+}
+
+// defaultUnit is the unit templates are parsed with.
+var defaultUnit = displayUnit{Decimals: defaultDecimals}
+
+// withUnit returns a copy of t whose funcs format amounts in u.
+func withUnit(t *template.Template, funcs func(displayUnit) template.FuncMap, u displayUnit) (*template.Template, error) {
+	c, err := t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return c.Funcs(funcs(u)), nil
+}
+
+// parseSummaryTemplate parses tmpl (DefaultSummaryTemplate when empty); a broken
+// template is logged and replaced by the default.
+func parseSummaryTemplate(tmpl string) *template.Template {
+	if tmpl == "" {
+		tmpl = DefaultSummaryTemplate
+	}
+	t, err := template.New("summary").Funcs(summaryFuncs(defaultUnit)).Parse(tmpl)
+	if err != nil {
+		log.Printf("warn: invalid summary template, using default: %v", err)
+		t = template.Must(template.New("summary").Funcs(summaryFuncs(defaultUnit)).Parse(DefaultSummaryTemplate))
+	}
+	return t
+}
+
+// summary: one pre-formatted line for bots
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	resp, status, err := s.snapshot(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, "/summary", err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	snap := resp.snap
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	s.writeJSON(w, r, snap, cacheKey("summary", r), func(buf io.Writer) error {
+		if err := s.renderSummary(buf, snap); err != nil {
+			return err
+		}
+		_, err := io.WriteString(buf, "\n")
+		return err
+	})
+}
+
+// renderSummary executes the summary template for snap, in snap's display unit.
+func (s *Server) renderSummary(w io.Writer, snap *types.SupplySnapshot) error {
+	u := displayOf(snap)
+	t, err := withUnit(s.summary, summaryFuncs, u)
+	if err != nil {
+		return err
+	}
+	d := summaryData{Denom: snap.Denom, Symbol: u.symbol(), Height: snap.Height, UpdatedAt: snap.UpdatedAt,
+		Total: snap.Total, Circulating: snap.Circulating, NonCirculating: snap.NonCirculating.Sum}
+	if snap.Max != nil {
+		d.Max = *snap.Max
+	}
+	return t.Execute(w, d)
+}
@@ -0,0 +1,149 @@
+package httpserver
+
+import (
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+const (
+	// DefaultMaxCohortItems is the item count above which responses summarize a cohort
+	// instead of listing its items.
+	DefaultMaxCohortItems = 1000
+	// summaryTopItems is how many of a summarized cohort's largest items are listed.
+	summaryTopItems = 10
+	// maxPageItems caps ?limit= on /non_circulating/{cohort}.
+	maxPageItems = 10000
+)
+
+// itemSummary stands in for the items of a cohort too large to list in full. Count and
+// Amount cover every item (matching the item filters, if any); the full list is paged
+// through Href.
+type itemSummary struct {
+	Count  int    `json:"count"`
+	Amount string `json:"amount"`
+	// Top are the largest items, largest first.
+	Top  []addressItem `json:"top"`
+	Href string        `json:"href"`
+}
+
+// pageInfo describes one page of a cohort's items. NextOffset is unset on the last page.
+type pageInfo struct {
+	Offset     int  `json:"offset"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+// summarize replaces the items of cohorts holding more than max with an itemSummary
+// whose Href pages through them, keeping q's item filters.
+func summarize(cohorts []cohortEntry, max int, q url.Values) []cohortEntry {
+	var out []cohortEntry
+	for i, c := range cohorts {
+		if len(c.Items) <= max {
+			continue
+		}
+		if out == nil {
+			// the cohorts may belong to the cached projection
+			out = append([]cohortEntry(nil), cohorts...)
+		}
+		out[i].Items = nil
+		out[i].ItemSummary = summarizeItems(c, max, q)
+	}
+	if out == nil {
+		return cohorts
+	}
+	return out
+}
+
+func summarizeItems(c cohortEntry, max int, q url.Values) *itemSummary {
+	sum := new(big.Int)
+	for _, it := range c.Items {
+		if v, ok := new(big.Int).SetString(it.Amount, 10); ok {
+			sum.Add(sum, v)
+		}
+	}
+	href := url.Values{"limit": {strconv.Itoa(max)}}
+	for _, p := range append([]string{"denom"}, itemFilterParams...) {
+		if v := q.Get(p); v != "" {
+			href.Set(p, v)
+		}
+	}
+	return &itemSummary{Count: len(c.Items), Amount: sum.String(), Top: largestItems(c.Items, summaryTopItems),
+		Href: "/non_circulating/" + url.PathEscape(c.Name) + "?" + href.Encode()}
+}
+
+// largestItems returns the n largest items, ties broken by address.
+func largestItems(items []addressItem, n int) []addressItem {
+	type ranked struct {
+		it  addressItem
+		amt *big.Int
+	}
+	all := make([]ranked, 0, len(items))
+	for _, it := range items {
+		v, ok := new(big.Int).SetString(it.Amount, 10)
+		if !ok {
+			v = new(big.Int)
+		}
+		all = append(all, ranked{it, v})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if c := all[i].amt.Cmp(all[j].amt); c != 0 {
+			return c > 0
+		}
+		return all[i].it.Address < all[j].it.Address
+	})
+	out := make([]addressItem, 0, min(n, len(all)))
+	for _, r := range all[:min(n, len(all))] {
+		out = append(out, r.it)
+	}
+	return out
+}
+
+// parsePage reads ?limit= and ?offset= for /non_circulating/{cohort}. msg is set for
+// invalid values; limit is 0 when not given.
+func parsePage(r *http.Request) (offset, limit int, msg string) {
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxPageItems {
+			return 0, 0, "invalid limit (1-" + strconv.Itoa(maxPageItems) + ")"
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, "invalid offset"
+		}
+		offset = n
+	}
+	return offset, limit, ""
+}
+
+// pageLimit returns the page size for a cohort of total items: limit when given, max
+// when the cohort is larger than max, 0 (not paged) otherwise.
+func pageLimit(limit, total, max int) int {
+	if limit == 0 && total > max {
+		return max
+	}
+	return limit
+}
+
+// page returns items[offset:offset+limit] and its pageInfo.
+func page(items []addressItem, offset, limit int) ([]addressItem, *pageInfo) {
+	start := min(offset, len(items))
+	end := min(start+limit, len(items))
+	return items[start:end], pageOf(offset, limit, len(items))
+}
+
+// pageOf describes the page of limit items at offset in a list of total items.
+func pageOf(offset, limit, total int) *pageInfo {
+	p := &pageInfo{Offset: offset, Limit: limit, Total: total}
+	if end := min(offset, total) + limit; end < total {
+		p.NextOffset = &end
+	}
+	return p
+}
@@ -0,0 +1,157 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testItems(amounts ...string) []addressItem {
+	items := make([]addressItem, len(amounts))
+	for i, a := range amounts {
+		items[i] = addressItem{Address: fmt.Sprintf("lumera1%c", 'a'+i), Amount: a}
+	}
+	return items
+}
+
+func TestSummarize(t *testing.T) {
+	small := cohortEntry{Name: "small", Items: testItems("1", "2")}
+	large := cohortEntry{Name: "large", Items: testItems("5", "30", "7", "30", "bad", "100")}
+	cohorts := []cohortEntry{small, large}
+
+	out := summarize(cohorts, 3, url.Values{"ends_after": {"2025-01-01"}, "verbose": {"1"}})
+	if len(out[0].Items) != 2 || out[0].ItemSummary != nil {
+		t.Fatalf("cohort under the limit summarized: %+v", out[0])
+	}
+	sum := out[1].ItemSummary
+	if out[1].Items != nil || sum == nil {
+		t.Fatalf("cohort over the limit not summarized: %+v", out[1])
+	}
+	// unparseable amounts count as items but add nothing
+	if sum.Count != 6 || sum.Amount != "172" {
+		t.Errorf("count %d amount %s, want 6 and 172", sum.Count, sum.Amount)
+	}
+	var top []string
+	for _, it := range sum.Top {
+		top = append(top, it.Address+"="+it.Amount)
+	}
+	// largest first, ties by address
+	want := []string{"lumera1f=100", "lumera1b=30", "lumera1d=30", "lumera1c=7", "lumera1a=5", "lumera1e=bad"}
+	if !reflect.DeepEqual(top, want) {
+		t.Errorf("top %v, want %v", top, want)
+	}
+	if sum.Href != "/non_circulating/large?ends_after=2025-01-01&limit=3" {
+		t.Errorf("href %s", sum.Href)
+	}
+	// the input, which may be the cached projection, is left alone
+	if len(cohorts[1].Items) != 6 || cohorts[1].ItemSummary != nil {
+		t.Fatalf("summarize changed its input: %+v", cohorts[1])
+	}
+
+	if got := largestItems(large.Items, 2); len(got) != 2 || got[0].Amount != "100" || got[1].Address != "lumera1b" {
+		t.Errorf("largest 2: %+v", got)
+	}
+}
+
+func TestPage(t *testing.T) {
+	items := testItems("1", "2", "3", "4", "5")
+	for _, tc := range []struct {
+		offset, limit int
+		want          int // items on the page
+		next          int // -1 for the last page
+	}{
+		{0, 2, 2, 2},
+		{2, 2, 2, 4},
+		{4, 2, 1, -1},
+		{3, 2, 2, -1},
+		{0, 5, 5, -1},
+		{5, 2, 0, -1},
+		{9, 2, 0, -1},
+	} {
+		got, p := page(items, tc.offset, tc.limit)
+		if len(got) != tc.want || p.Total != 5 || p.Offset != tc.offset || p.Limit != tc.limit {
+			t.Errorf("page(%d, %d): %d items, %+v", tc.offset, tc.limit, len(got), p)
+		}
+		if tc.next < 0 && p.NextOffset != nil || tc.next >= 0 && (p.NextOffset == nil || *p.NextOffset != tc.next) {
+			t.Errorf("page(%d, %d): next_offset %v, want %d", tc.offset, tc.limit, p.NextOffset, tc.next)
+		}
+		if tc.want > 0 && got[0].Address != items[tc.offset].Address {
+			t.Errorf("page(%d, %d) starts at %s", tc.offset, tc.limit, got[0].Address)
+		}
+	}
+}
+
+func TestCohortPages(t *testing.T) {
+	srv := newTestServer(t, nil)
+	srv.cfg.MaxCohortItems = 10
+
+	// the summary in /non_circulating links to the pages
+	nc := get(t, srv, "/non_circulating?verbose=1")
+	var href string
+	for _, c := range nc["non_circulating"].(map[string]any)["cohorts"].([]any) {
+		if c := c.(map[string]any); c["name"] == "foundation_genesis" {
+			sum := c["items_summary"].(map[string]any)
+			if sum["count"] != float64(31) || len(sum["top"].([]any)) != summaryTopItems {
+				t.Fatalf("summary %v", sum)
+			}
+			href = sum["href"].(string)
+		}
+	}
+	if href != "/non_circulating/foundation_genesis?limit=10" {
+		t.Fatalf("href %q", href)
+	}
+
+	// paging through the cohort, read from the store by range, gives every item once
+	var paged []any
+	for path := href; ; {
+		out := get(t, srv, path)
+		paged = append(paged, out["cohort"].(map[string]any)["items"].([]any)...)
+		p := out["page"].(map[string]any)
+		if p["total"] != float64(31) {
+			t.Fatalf("%s: page %v", path, p)
+		}
+		next, ok := p["next_offset"]
+		if !ok {
+			break
+		}
+		path = fmt.Sprintf("%s&offset=%v", href, next)
+	}
+	snap, _ := srv.cfg.Cache.Get()
+	full, err := srv.hydrated(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []addressItem
+	for _, c := range full.NonCirc.Cohorts {
+		if c.Name == "foundation_genesis" {
+			want = c.Items
+		}
+	}
+	b, _ := json.Marshal(paged)
+	w, _ := json.Marshal(want)
+	if string(b) != string(w) {
+		t.Fatalf("paged items differ from the hydrated cohort:\n%s\n%s", b, w)
+	}
+
+	// without a limit, a large cohort gets the first page
+	first := get(t, srv, "/non_circulating/foundation_genesis")
+	if items := first["cohort"].(map[string]any)["items"].([]any); len(items) != 10 {
+		t.Errorf("unpaged request: %d items", len(items))
+	}
+	// past the end: no items, no next_offset
+	past := get(t, srv, "/non_circulating/foundation_genesis?offset=100&limit=10")
+	if _, ok := past["cohort"].(map[string]any)["items"]; ok || past["page"].(map[string]any)["next_offset"] != nil {
+		t.Errorf("offset past the end: %v", past)
+	}
+	for _, q := range []string{"limit=0", "limit=10001", "offset=-1", "offset=x"} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest("GET", "/non_circulating/foundation_genesis?"+q, nil))
+		if rec.Code != 400 || !strings.Contains(rec.Body.String(), "invalid") {
+			t.Errorf("%s: %d %s", q, rec.Code, rec.Body)
+		}
+	}
+}
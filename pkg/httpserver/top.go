@@ -0,0 +1,91 @@
+package httpserver
+
+import (
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const (
+	defaultTopN = 20
+	maxTopN     = 1000
+)
+
+// non_circulating/top?n=20: largest locked positions (items and single-address cohorts)
+func (s *Server) handleTop(w http.ResponseWriter, r *http.Request) {
+	n := defaultTopN
+	if v := r.URL.Query().Get("n"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n <= 0 || n > maxTopN {
+			http.Error(w, "invalid n (1-1000)", http.StatusBadRequest)
+			return
+		}
+	}
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	if s.rejectHistorical(w, r) {
+		return
+	}
+	resp, status, err := s.snapshot(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, "/non_circulating/top", err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	snap := resp.snap
+	s.writeJSON(w, r, snap, cacheKey("top", r, "n"), func(buf io.Writer) error {
+		srv, err := s.hydrated(snap)
+		if err != nil {
+			return err
+		}
+		out := topPayload{srv.Denom, displayOf(snap).Decimals, srv.Height, srv.UpdatedAt, srv.ETag, srv.PolicyETag, topPositions(srv.NonCirc.Cohorts, n)}
+		return encodeIndented(out)(buf)
+	})
+}
+
+// topPositions returns the n largest positions by amount; ties are broken by cohort
+// then address so the order is stable across snapshots.
+func topPositions(cohorts []cohortEntry, n int) []topPosition {
+	type ranked struct {
+		pos topPosition
+		amt *big.Int
+	}
+	var all []ranked
+	push := func(p topPosition) {
+		v, ok := new(big.Int).SetString(p.Amount, 10)
+		if !ok || v.Sign() <= 0 {
+			return
+		}
+		all = append(all, ranked{p, v})
+	}
+	for _, c := range cohorts {
+		if c.Address != "" {
+			push(topPosition{Address: c.Address, Cohort: c.Name, CohortID: c.ID, CohortSlug: c.Slug, Amount: c.Amount})
+		}
+		for _, it := range c.Items {
+			push(topPosition{Address: it.Address, Cohort: c.Name, CohortID: c.ID, CohortSlug: c.Slug, Amount: it.Amount, EndDate: it.EndDate, EndUnix: it.EndUnix, Permanent: it.Permanent})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if c := all[i].amt.Cmp(all[j].amt); c != 0 {
+			return c > 0
+		}
+		if all[i].pos.Cohort != all[j].pos.Cohort {
+			return all[i].pos.Cohort < all[j].pos.Cohort
+		}
+		return all[i].pos.Address < all[j].pos.Address
+	})
+	out := make([]topPosition, 0, min(n, len(all)))
+	for _, r := range all[:min(n, len(all))] {
+		out = append(out, r.pos)
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+	"github.com/lumera-labs/lumera-supply/pkg/logging"
+)
+
+// tuningDoc is the store document holding /admin/tuning overrides.
+const tuningDoc = "tuning"
+
+// tuning is the startup configuration and the runtime overrides applied on top of it.
+type tuning struct {
+	mu        sync.RWMutex
+	base      config.Limits
+	baseLevel logging.Level
+	overrides config.Tuning
+	limits    config.Limits
+}
+
+// limits returns the effective limits.
+func (s *Server) limits() config.Limits {
+	s.tune.mu.RLock()
+	defer s.tune.mu.RUnlock()
+	return s.tune.limits
+}
+
+// overrides returns the runtime overrides in effect.
+func (s *Server) overrides() config.Tuning {
+	s.tune.mu.RLock()
+	defer s.tune.mu.RUnlock()
+	return s.tune.overrides
+}
+
+// loadTuning applies overrides persisted by an earlier process. A document that no longer
+// validates is logged and ignored.
+func (s *Server) loadTuning() {
+	if s.cfg.Store == nil {
+		return
+	}
+	var o config.Tuning
+	ok, err := s.cfg.Store.Doc(tuningDoc, &o)
+	if err == nil && ok {
+		_, err = s.applyTuning(func(config.Tuning) config.Tuning { return o })
+	}
+	if err != nil {
+		log.Printf("warn: load tuning overrides: %v", err)
+		return
+	}
+	if ok && o != (config.Tuning{}) {
+		b, _ := json.Marshal(o)
+		log.Printf("tuning overrides restored: %s", b)
+	}
+}
+
+// applyTuning replaces the overrides with update(current overrides), under the lock so
+// concurrent changes are not lost, and pushes the resulting limits to the rate limiter,
+// snapshot cache, computer, LCD timeouts and log filter. It returns the new overrides;
+// nothing changes when they are invalid.
+func (s *Server) applyTuning(update func(config.Tuning) config.Tuning) (config.Tuning, error) {
+	s.tune.mu.Lock()
+	defer s.tune.mu.Unlock()
+	o := update(s.tune.overrides)
+	l, err := s.tune.base.Apply(o)
+	if err != nil {
+		return o, err
+	}
+	level := s.tune.baseLevel
+	if o.LogLevel != "" {
+		if level, err = logging.ParseLevel(o.LogLevel); err != nil {
+			return o, err
+		}
+	}
+	prev := s.tune.limits
+	if l.RatePerMin != prev.RatePerMin || l.Burst != prev.Burst {
+		s.limiter.SetRate(l.RatePerMin, l.Burst)
+	}
+	if s.cfg.Cache != nil {
+		s.cfg.Cache.SetTTL(l.RefreshTTL)
+	}
+	if s.cfg.Computer != nil && l.Concurrency != prev.Concurrency {
+		s.cfg.Computer.SetConcurrency(l.Concurrency)
+	}
+	for _, t := range s.cfg.LCDTimeouts {
+		t.Set(l.LCDTimeout)
+	}
+	logging.SetLevel(level)
+	s.tune.limits, s.tune.overrides = l, o
+	return o, nil
+}
+
+// endpointTimeouts returns the per-endpoint LCD limits, shared by all LCDTimeouts.
+func (s *Server) endpointTimeouts() map[string]time.Duration {
+	if len(s.cfg.LCDTimeouts) == 0 {
+		return nil
+	}
+	return s.cfg.LCDTimeouts[0].Endpoints()
+}
+
+func (s *Server) tuningStatus() tuningPayload {
+	return tuningPayload{Limits: limitsOf(s.limits(), s.endpointTimeouts(), logging.CurrentLevel()), Overrides: s.overrides(), Persisted: s.cfg.Store != nil}
+}
+
+// admin/tuning: GET shows the effective limits and overrides, PATCH {refresh_ttl?,
+// rate_per_min?, burst?, lcd_timeout?, concurrency?, log_level?} changes them, DELETE
+// restores the startup configuration
+func (s *Server) handleTuning(w http.ResponseWriter, r *http.Request) {
+	// DELETE clears every override
+	update := func(config.Tuning) config.Tuning { return config.Tuning{} }
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = encodeIndented(s.tuningStatus())(w)
+		return
+	case http.MethodPatch:
+		var o config.Tuning
+		dec := json.NewDecoder(io.LimitReader(r.Body, 1<<16))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&o); err != nil {
+			http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if o.RatePerMin < 0 || o.Burst < 0 || o.Concurrency < 0 {
+			http.Error(w, "rate_per_min, burst and concurrency must not be negative", http.StatusBadRequest)
+			return
+		}
+		update = func(cur config.Tuning) config.Tuning { return cur.Merge(o) }
+	case http.MethodDelete:
+	default:
+		w.Header().Set("Allow", "GET, PATCH, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	next, err := s.applyTuning(update)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b, _ := json.Marshal(next)
+	log.Printf("warn: tuning overrides set: %s", b)
+	if s.cfg.Store != nil {
+		if err := s.cfg.Store.PutDoc(tuningDoc, next); err != nil {
+			// applied, but a restart will not keep it
+			log.Printf("warn: save tuning overrides: %v", err)
+			http.Error(w, "applied but not persisted: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if r.Method == http.MethodDelete {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = encodeIndented(s.tuningStatus())(w)
+}
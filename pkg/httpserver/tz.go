@@ -0,0 +1,35 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// dateFields are the JSON keys holding RFC3339 timestamps.
+var dateFields = map[string]bool{
+	"end_date": true, "updated_at": true, "as_of_time": true, "last_success": true,
+	"time": true, "from": true, "until": true,
+}
+
+// localDateLayout is the human-readable format of "<field>_local" dates.
+const localDateLayout = "2006-01-02 15:04:05 MST"
+
+// localSibling returns a siblingFunc adding "<field>_local" next to timestamps, rendered
+// in the IANA time zone tz (e.g. "Europe/Berlin") for human-facing reports.
+func localSibling(tz string) (siblingFunc, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+	return func(key, value string) (string, []byte, bool) {
+		if !dateFields[key] {
+			return "", nil, false
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return "", nil, false
+		}
+		b, _ := json.Marshal(t.In(loc).Format(localDateLayout))
+		return key + "_local", b, true
+	}, nil
+}
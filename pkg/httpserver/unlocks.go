@@ -0,0 +1,74 @@
+package httpserver
+
+import (
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+)
+
+const (
+	defaultUnlockMonths = 12
+	maxUnlockMonths     = 120
+)
+
+// unlocks?months=12&group_by=month: the forward unlock timeline of the snapshot's dated
+// cohort items (disclosed lockups and claim records), as /unlocks.ics lists it
+func (s *Server) handleUnlocks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	months := defaultUnlockMonths
+	if v := q.Get("months"); v != "" {
+		var err error
+		if months, err = strconv.Atoi(v); err != nil || months <= 0 || months > maxUnlockMonths {
+			http.Error(w, "invalid months (1-120)", http.StatusBadRequest)
+			return
+		}
+	}
+	var bucket func(time.Time) time.Time
+	switch q.Get("group_by") {
+	case "":
+	case "month":
+		bucket = supply.MonthStart
+	default:
+		http.Error(w, "invalid group_by (month)", http.StatusBadRequest)
+		return
+	}
+	denom, ok := s.parseDenom(r)
+	if !ok {
+		http.Error(w, "invalid denom", http.StatusBadRequest)
+		return
+	}
+	resp, status, err := s.snapshot(w, r, denom)
+	if err != nil {
+		s.snapshotFailed(w, "/unlocks", err)
+		return
+	}
+	if status == http.StatusNotModified {
+		w.WriteHeader(status)
+		return
+	}
+	snap := resp.snap
+	// the window starts at the snapshot's evaluation instant (block time) so the body is
+	// stable per ETag
+	s.writeJSON(w, r, snap, cacheKey("unlocks", r, "months", "group_by"), func(buf io.Writer) error {
+		full, err := s.cfg.Cache.Hydrate(snap)
+		if err != nil {
+			return err
+		}
+		from := snap.EvaluationTime().UTC()
+		until := from.AddDate(0, months, 0)
+		out := unlocksPayload{Denom: snap.Denom, Decimals: displayOf(snap).Decimals, Height: snap.Height, UpdatedAt: snap.UpdatedAt, ETag: snap.ETag,
+			PolicyETag: snap.PolicyETag, From: from, Until: until, GroupBy: q.Get("group_by"), Events: supply.UnlockScheduleBy(full, from, until, bucket)}
+		total := new(big.Int)
+		for _, ev := range out.Events {
+			if v, ok := new(big.Int).SetString(ev.Amount, 10); ok {
+				total.Add(total, v)
+			}
+		}
+		out.Total = total.String()
+		return encodeIndented(out)(buf)
+	})
+}
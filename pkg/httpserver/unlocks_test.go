@@ -0,0 +1,95 @@
+package httpserver
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/cache"
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/mockchain"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/store"
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+)
+
+// newTestServer serves a snapshot of the mock chain computed with the repository policy,
+// after edit (optional) adjusted the chain, with a store.
+func newTestServer(t *testing.T, edit func(*mockchain.State)) *Server {
+	t.Helper()
+	pol, err := policy.Load("../../policy.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	st := mockchain.Default(now, 12)
+	st.Time = &now
+	st.AddPolicy(pol, now)
+	if edit != nil {
+		edit(st)
+	}
+	mock, err := mockchain.New(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(mock)
+	t.Cleanup(ts.Close)
+	comp := supply.NewComputer(lcd.NewClient(ts.URL, ts.Client()), pol)
+	fs, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every item list is offloaded, so handlers that need items must hydrate
+	c := cache.NewSnapshotCache(comp, cache.Options{TTL: time.Hour, Store: fs, OffloadItems: 1})
+	if _, err := c.Update(context.Background(), "ulume"); err != nil {
+		t.Fatal(err)
+	}
+	return New(Config{Cache: c, Computer: comp, DefaultDenom: "ulume", Store: fs})
+}
+
+func TestUnlocks(t *testing.T) {
+	// the mock policy's foundation accounts vest continuously
+	srv := newTestServer(t, nil)
+
+	out := get(t, srv, "/unlocks?months=3")
+	from, _ := time.Parse(time.RFC3339, out["from"].(string))
+	until, _ := time.Parse(time.RFC3339, out["until"].(string))
+	if !until.Equal(from.AddDate(0, 3, 0)) {
+		t.Fatalf("window %s to %s", from, until)
+	}
+	events, _ := out["events"].([]any)
+	if len(events) < 3 {
+		t.Fatalf("continuous vesting should release every month: %v", events)
+	}
+	for _, e := range events {
+		at, _ := time.Parse(time.RFC3339, e.(map[string]any)["time"].(string))
+		if !at.After(from) || at.After(until) {
+			t.Fatalf("event at %s outside (%s, %s]", at, from, until)
+		}
+	}
+
+	// the snapshot is taken mid-month: the first month is dated at from, not before it
+	monthly := get(t, srv, "/unlocks?months=3&group_by=month")
+	events, _ = monthly["events"].([]any)
+	if len(events) == 0 || events[0].(map[string]any)["time"] != out["from"] {
+		t.Fatalf("first monthly event %v, want it at %v", events, out["from"])
+	}
+	if monthly["total"] != out["total"] {
+		t.Fatalf("monthly total %v, want %v", monthly["total"], out["total"])
+	}
+
+	local := get(t, srv, "/unlocks?months=3&tz=Europe/Berlin")
+	events, _ = local["events"].([]any)
+	if local["from_local"] == nil || local["until_local"] == nil || events[0].(map[string]any)["time_local"] == nil {
+		t.Fatalf("?tz= left dates without local siblings: %v", local)
+	}
+
+	for _, path := range []string{"/unlocks?months=0", "/unlocks?months=121", "/unlocks?group_by=week"} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != 400 {
+			t.Errorf("%s: %d, want 400", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package lcd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// batchSize caps the number of abci_query calls per JSON-RPC batch request.
+const batchSize = 100
+
+// SetRPC configures a CometBFT JSON-RPC endpoint used to batch per-address queries
+// and as a fallback source of latest height/time (see rpc.go).
+// An empty URL disables both.
+func (c *Client) SetRPC(rpcURL string) {
+	c.rpc = strings.TrimRight(rpcURL, "/")
+}
+
+// BalancesByDenom returns balances for many addresses. When an RPC endpoint is set it
+// issues JSON-RPC batches of abci_query bank Balance calls; if the node rejects batches
+// it transparently falls back to one LCD request per address (and stops trying batches).
+func (c *Client) BalancesByDenom(addresses []string, denom string) (map[string]string, error) {
+	out := make(map[string]string, len(addresses))
+	batched := c.rpc != "" && !c.batchUnsupported.Load()
+	if batched {
+		for start := 0; start < len(addresses); start += batchSize {
+			end := min(start+batchSize, len(addresses))
+			got, err := c.batchBalances(addresses[start:end], denom)
+			if err != nil {
+				log.Printf("warn: rpc batch balances unsupported, falling back to LCD: %v", err)
+				c.batchUnsupported.Store(true)
+				break
+			}
+			for k, v := range got {
+				out[k] = v
+			}
+		}
+	}
+	var errs []error
+	for _, a := range addresses {
+		if _, ok := out[a]; ok {
+			continue
+		}
+		if batched {
+			c.stats.retry()
+		}
+		bal, err := c.BalanceByDenom(a, denom)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", a, err))
+			continue
+		}
+		out[a] = bal
+	}
+	return out, errors.Join(errs...)
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type abciQueryParams struct {
+	Path   string `json:"path"`
+	Data   string `json:"data"`
+	Height string `json:"height,omitempty"`
+	Prove  bool   `json:"prove"`
+}
+
+type rpcResponse struct {
+	ID     int `json:"id"`
+	Result struct {
+		Response struct {
+			Code  uint32 `json:"code"`
+			Log   string `json:"log"`
+			Value string `json:"value"`
+		} `json:"response"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// batchBalances issues a single JSON-RPC batch. Per-entry failures are omitted from the
+// result (and retried via LCD by the caller); transport or shape errors fail the batch.
+func (c *Client) batchBalances(addresses []string, denom string) (map[string]string, error) {
+	reqs := make([]rpcRequest, len(addresses))
+	for i, a := range addresses {
+		params := abciQueryParams{
+			Path: "/cosmos.bank.v1beta1.Query/Balance",
+			Data: hex.EncodeToString(encodeBalanceRequest(a, denom)),
+		}
+		if c.height > 0 {
+			params.Height = strconv.FormatInt(c.height, 10)
+		}
+		reqs[i] = rpcRequest{JSONRPC: "2.0", ID: i, Method: "abci_query", Params: params}
+	}
+	body, err := json.Marshal(reqs)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.newRequest(http.MethodPost, c.rpc, "rpc batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, transportError("rpc batch", c.rpc, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, statusError("rpc batch", resp)
+	}
+	c.guard(resp, "rpc batch")
+	var results []rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("rpc batch: %w", err)
+	}
+	out := make(map[string]string, len(results))
+	for _, r := range results {
+		if r.ID < 0 || r.ID >= len(addresses) || r.Error != nil || r.Result.Response.Code != 0 {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(r.Result.Response.Value)
+		if err != nil {
+			continue
+		}
+		amt, err := decodeBalanceResponse(raw)
+		if err != nil {
+			continue
+		}
+		out[addresses[r.ID]] = amt
+	}
+	return out, nil
+}
+
+// encodeBalanceRequest encodes cosmos.bank.v1beta1.QueryBalanceRequest{address=1, denom=2}.
+func encodeBalanceRequest(address, denom string) []byte {
+	var b []byte
+	b = appendBytesField(b, 1, []byte(address))
+	b = appendBytesField(b, 2, []byte(denom))
+	return b
+}
+
+// decodeBalanceResponse extracts balance.amount from QueryBalanceResponse{balance=1: Coin{denom=1, amount=2}}.
+// A missing balance decodes as "0".
+func decodeBalanceResponse(b []byte) (string, error) {
+	coin, err := protoField(b, 1)
+	if err != nil || coin == nil {
+		return "0", err
+	}
+	amt, err := protoField(coin, 2)
+	if err != nil {
+		return "", err
+	}
+	if len(amt) == 0 {
+		return "0", nil
+	}
+	return string(amt), nil
+}
+
+func appendBytesField(b []byte, field int, v []byte) []byte {
+	b = appendVarint(b, uint64(field<<3|2))
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func readVarint(b []byte) (uint64, int, error) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7f) << (7 * i)
+		if b[i] < 0x80 {
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, errors.New("proto: bad varint")
+}
+
+// protoField returns the payload of the first length-delimited field with the given number.
+func protoField(b []byte, field int) ([]byte, error) {
+	for len(b) > 0 {
+		key, n, err := readVarint(b)
+		if err != nil {
+			return nil, err
+		}
+		b = b[n:]
+		num, wire := int(key>>3), key&7
+		switch wire {
+		case 0:
+			_, n, err := readVarint(b)
+			if err != nil {
+				return nil, err
+			}
+			b = b[n:]
+		case 2:
+			l, n, err := readVarint(b)
+			if err != nil {
+				return nil, err
+			}
+			b = b[n:]
+			if uint64(len(b)) < l {
+				return nil, errors.New("proto: truncated field")
+			}
+			if num == field {
+				return b[:l], nil
+			}
+			b = b[l:]
+		case 1:
+			if len(b) < 8 {
+				return nil, errors.New("proto: truncated fixed64")
+			}
+			b = b[8:]
+		case 5:
+			if len(b) < 4 {
+				return nil, errors.New("proto: truncated fixed32")
+			}
+			b = b[4:]
+		default:
+			return nil, fmt.Errorf("proto: unsupported wire type %d", wire)
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,147 @@
+package lcd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBalancesByDenom_RPCBatch(t *testing.T) {
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []struct {
+			ID     int `json:"id"`
+			Params struct {
+				Data string `json:"data"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Fatalf("decode batch: %v", err)
+		}
+		out := make([]map[string]any, 0, len(reqs))
+		for _, q := range reqs {
+			data, _ := hex.DecodeString(q.Params.Data)
+			addr, _ := protoField(data, 1)
+			coin := appendBytesField(appendBytesField(nil, 1, []byte("ulume")), 2, []byte("7"+string(addr[len(addr)-1])))
+			val := base64.StdEncoding.EncodeToString(appendBytesField(nil, 1, coin))
+			out = append(out, map[string]any{"id": q.ID, "result": map[string]any{"response": map[string]any{"code": 0, "value": val}}})
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	}))
+	defer rpc.Close()
+	lcdSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected LCD call %s", r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer lcdSrv.Close()
+
+	c := NewClient(lcdSrv.URL, lcdSrv.Client())
+	c.SetRPC(rpc.URL)
+	got, err := c.BalancesByDenom([]string{"lumera1a", "lumera1b"}, "ulume")
+	if err != nil {
+		t.Fatalf("BalancesByDenom: %v", err)
+	}
+	if got["lumera1a"] != "7a" || got["lumera1b"] != "7b" {
+		t.Fatalf("unexpected balances: %v", got)
+	}
+}
+
+func TestBalancesByDenom_FallbackToLCD(t *testing.T) {
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "batch not supported", http.StatusBadRequest)
+	}))
+	defer rpc.Close()
+	lcdSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"42"}}`))
+	}))
+	defer lcdSrv.Close()
+
+	c := NewClient(lcdSrv.URL, lcdSrv.Client())
+	c.SetRPC(rpc.URL)
+	got, err := c.BalancesByDenom([]string{"lumera1a"}, "ulume")
+	if err != nil {
+		t.Fatalf("BalancesByDenom: %v", err)
+	}
+	if got["lumera1a"] != "42" {
+		t.Fatalf("unexpected balances: %v", got)
+	}
+	if !c.batchUnsupported.Load() {
+		t.Fatalf("expected batching to be disabled after failure")
+	}
+}
+
+func TestLatestHeight_RPCFallback(t *testing.T) {
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"sync_info":{"latest_block_height":"120","latest_block_time":"2025-01-02T03:04:05Z"}}}`))
+	}))
+	defer rpc.Close()
+	lcdSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusBadGateway)
+	}))
+	defer lcdSrv.Close()
+
+	c := NewClient(lcdSrv.URL, lcdSrv.Client())
+	if _, _, err := c.LatestHeight(); err == nil {
+		t.Fatal("expected error without rpc fallback")
+	}
+	c.SetRPC(rpc.URL)
+	h, ts, err := c.LatestHeight()
+	if err != nil || h != 120 || ts.Year() != 2025 {
+		t.Fatalf("LatestHeight via rpc: %d %v %v", h, ts, err)
+	}
+}
+
+func TestAtHeight_PinsLCDAndRPCBatch(t *testing.T) {
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []struct {
+			ID     int `json:"id"`
+			Params struct {
+				Height string `json:"height"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Fatalf("decode batch: %v", err)
+		}
+		out := make([]map[string]any, 0, len(reqs))
+		for _, q := range reqs {
+			if q.Params.Height != "42" {
+				t.Errorf("abci_query height %q, want 42", q.Params.Height)
+			}
+			coin := appendBytesField(appendBytesField(nil, 1, []byte("ulume")), 2, []byte("5"))
+			val := base64.StdEncoding.EncodeToString(appendBytesField(nil, 1, coin))
+			out = append(out, map[string]any{"id": q.ID, "result": map[string]any{"response": map[string]any{"code": 0, "value": val}}})
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	}))
+	defer rpc.Close()
+	lcdSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/42":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"42","time":"2025-01-01T00:00:00Z"}}}`))
+		default:
+			if h := r.Header.Get("x-cosmos-block-height"); h != "42" {
+				t.Errorf("%s: x-cosmos-block-height %q, want 42", r.URL.Path, h)
+			}
+			_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"5"}}`))
+		}
+	}))
+	defer lcdSrv.Close()
+
+	c := NewClient(lcdSrv.URL, lcdSrv.Client())
+	c.SetRPC(rpc.URL)
+	if h, tm, err := c.Block(42); err != nil || h != 42 || tm.Year() != 2025 {
+		t.Fatalf("Block(42): %d %s %v", h, tm, err)
+	}
+	at := c.AtHeight(42)
+	if at.Height() != 42 || at.WithStats(&CallStats{}).Height() != 42 {
+		t.Fatal("height not carried over")
+	}
+	if got, err := at.BalancesByDenom([]string{"lumera1a"}, "ulume"); err != nil || got["lumera1a"] != "5" {
+		t.Fatalf("BalancesByDenom: %v, %v", got, err)
+	}
+	if bal, err := at.BalanceByDenom("lumera1a", "ulume"); err != nil || bal != "5" {
+		t.Fatalf("BalanceByDenom: %q, %v", bal, err)
+	}
+}
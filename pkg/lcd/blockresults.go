@@ -0,0 +1,153 @@
+package lcd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Event is an ABCI event with its attributes in emission order.
+type Event struct {
+	Type       string
+	Attributes []EventAttribute
+}
+
+// EventAttribute is one key/value pair of an Event.
+type EventAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Attr returns the value of the first attribute named key.
+func (e Event) Attr(key string) string {
+	for _, a := range e.Attributes {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// BlockResults returns the events of the block at height via the RPC block_results
+// method: block-level events (begin/end block, or finalize block on CometBFT 0.38)
+// followed by the events of successful transactions. Attributes that older nodes
+// return base64-encoded are decoded.
+func (c *Client) BlockResults(height int64) ([]Event, error) {
+	type rawEvent struct {
+		Type       string           `json:"type"`
+		Attributes []EventAttribute `json:"attributes"`
+	}
+	var out struct {
+		TxsResults []struct {
+			Code   uint32     `json:"code"`
+			Events []rawEvent `json:"events"`
+		} `json:"txs_results"`
+		BeginBlockEvents    []rawEvent `json:"begin_block_events"`
+		EndBlockEvents      []rawEvent `json:"end_block_events"`
+		FinalizeBlockEvents []rawEvent `json:"finalize_block_events"`
+	}
+	if err := c.rpcCall("block_results", map[string]any{"height": strconv.FormatInt(height, 10)}, &out); err != nil {
+		return nil, err
+	}
+	var events []Event
+	add := func(raw []rawEvent) {
+		for _, e := range raw {
+			ev := Event{Type: e.Type, Attributes: make([]EventAttribute, 0, len(e.Attributes))}
+			for _, a := range e.Attributes {
+				if isBase64Key(a.Key) {
+					k, _ := base64.StdEncoding.DecodeString(a.Key)
+					v, _ := base64.StdEncoding.DecodeString(a.Value)
+					a = EventAttribute{Key: string(k), Value: string(v)}
+				}
+				ev.Attributes = append(ev.Attributes, a)
+			}
+			events = append(events, ev)
+		}
+	}
+	add(out.BeginBlockEvents)
+	add(out.FinalizeBlockEvents)
+	for _, tx := range out.TxsResults {
+		if tx.Code == 0 {
+			add(tx.Events)
+		}
+	}
+	add(out.EndBlockEvents)
+	return events, nil
+}
+
+// isBase64Key reports whether an attribute key is base64 (CometBFT < 0.37), recognised
+// by decoding to a plain lowercase identifier; plain keys like "amount" are not valid
+// base64, and the few that are decode to binary.
+func isBase64Key(k string) bool {
+	b, err := base64.StdEncoding.DecodeString(k)
+	if err != nil || len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if (c < 'a' || c > 'z') && c != '_' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// SupplyEvents are the supply-relevant amounts of one denom found in a block's events.
+type SupplyEvents struct {
+	// Minted and Burned come from the bank module's coinbase and burn events.
+	Minted *big.Int
+	Burned *big.Int
+	// BurnTransferred is sent to burn addresses: still counted in total supply but
+	// unspendable.
+	BurnTransferred *big.Int
+}
+
+// ExtractSupplyEvents sums denom's mints, burns and transfers to burnAddrs, which are
+// lowercase bech32 (see policy.NormalizeAddresses).
+func ExtractSupplyEvents(events []Event, denom string, burnAddrs map[string]bool) (SupplyEvents, error) {
+	out := SupplyEvents{Minted: new(big.Int), Burned: new(big.Int), BurnTransferred: new(big.Int)}
+	for _, e := range events {
+		var dst *big.Int
+		switch {
+		case e.Type == "coinbase":
+			dst = out.Minted
+		case e.Type == "burn":
+			dst = out.Burned
+		case e.Type == "transfer" && burnAddrs[strings.ToLower(e.Attr("recipient"))]:
+			dst = out.BurnTransferred
+		default:
+			continue
+		}
+		amt, err := coinAmount(e.Attr("amount"), denom)
+		if err != nil {
+			return out, fmt.Errorf("%s event: %w", e.Type, err)
+		}
+		dst.Add(dst, amt)
+	}
+	return out, nil
+}
+
+// coinAmount returns denom's amount in a coins string such as "100ulume,5uatom".
+func coinAmount(coins, denom string) (*big.Int, error) {
+	sum := new(big.Int)
+	for _, c := range strings.Split(coins, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		i := strings.IndexFunc(c, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid coin %q", c)
+		}
+		if c[i:] != denom {
+			continue
+		}
+		v, ok := new(big.Int).SetString(c[:i], 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid coin %q", c)
+		}
+		sum.Add(sum, v)
+	}
+	return sum, nil
+}
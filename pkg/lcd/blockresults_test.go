@@ -0,0 +1,50 @@
+package lcd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBlockResultsSupplyEvents(t *testing.T) {
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		var req rpcRequest
+		_ = json.Unmarshal(b, &req)
+		if req.Method != "block_results" {
+			t.Errorf("unexpected method %q", req.Method)
+		}
+		// CometBFT 0.34 style: begin/end block events with base64 attributes
+		// ("amount" = YW1vdW50, "500ulume" = NTAwdWx1bWU=)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{
+			"begin_block_events":[{"type":"coinbase","attributes":[{"key":"bWludGVy","value":"bHVtZXJhMW1pbnQ="},{"key":"YW1vdW50","value":"NTAwdWx1bWU="}]}],
+			"txs_results":[
+				{"code":0,"events":[
+					{"type":"burn","attributes":[{"key":"burner","value":"lumera1x"},{"key":"amount","value":"30ulume,7uatom"}]},
+					{"type":"transfer","attributes":[{"key":"recipient","value":"lumera1dead"},{"key":"sender","value":"lumera1x"},{"key":"amount","value":"12ulume"}]},
+					{"type":"transfer","attributes":[{"key":"recipient","value":"lumera1y"},{"key":"amount","value":"99ulume"}]}]},
+				{"code":5,"events":[{"type":"burn","attributes":[{"key":"amount","value":"1000ulume"}]}]}],
+			"end_block_events":[]}}`))
+	}))
+	defer rpc.Close()
+	c := NewClient("http://unused", rpc.Client())
+	c.SetRPC(rpc.URL)
+
+	events, err := c.BlockResults(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 4 || events[0].Attr("amount") != "500ulume" || events[0].Attr("minter") != "lumera1mint" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	ev, err := ExtractSupplyEvents(events, "ulume", map[string]bool{"lumera1dead": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the failed tx's burn is ignored
+	if ev.Minted.String() != "500" || ev.Burned.String() != "30" || ev.BurnTransferred.String() != "12" {
+		t.Fatalf("unexpected supply events: minted %s burned %s sent %s", ev.Minted, ev.Burned, ev.BurnTransferred)
+	}
+}
@@ -0,0 +1,154 @@
+package lcd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Capabilities describes what the configured node can serve, as probed at runtime.
+type Capabilities struct {
+	CheckedAt time.Time `json:"checked_at"`
+	// Archive is true when the node answered a state query at ArchiveProbeHeight,
+	// i.e. it retains historical state for ?height= queries.
+	Archive            bool   `json:"archive"`
+	ArchiveProbeHeight int64  `json:"archive_probe_height"`
+	ArchiveError       string `json:"archive_error,omitempty"`
+	// EarliestHeight is the oldest block the node keeps (earliest_block_height in the
+	// RPC status), 0 when unknown. It is the archive probe height when known.
+	EarliestHeight int64 `json:"earliest_height,omitempty"`
+	// Features maps optional LCD routes (Feature* constants) to whether the node serves them.
+	// Routes whose probe failed for transport reasons are absent (unknown).
+	Features map[string]bool `json:"features,omitempty"`
+	// ClaimPrefix is the claim module route prefix in use (see SetClaimPrefixes).
+	ClaimPrefix string `json:"claim_prefix,omitempty"`
+}
+
+// Optional LCD routes whose availability differs across SDK / module versions.
+const (
+	FeatureSupplyByDenom       = "supply_by_denom"        // /cosmos/bank/v1beta1/supply/by_denom (SDK >= 0.46)
+	FeatureIBCTotalEscrow      = "ibc_total_escrow"       // /ibc/apps/transfer/v1/denoms/{denom}/total_escrow (ibc-go >= 7)
+	FeatureModuleAccountByName = "module_account_by_name" // /cosmos/auth/v1beta1/module_accounts/{name} (SDK >= 0.46)
+	FeatureCommunityPool       = "community_pool"         // /cosmos/distribution/v1beta1/community_pool
+	FeatureClaimListClaimed    = "claim_list_claimed"     // {claim prefix}/list_claimed/{tier}, see ClaimPrefix
+)
+
+// supports reports false only when the feature was probed and found missing,
+// so callers try the primary route when capabilities are unknown.
+func (c *Client) supports(feature string) bool {
+	p := c.caps.Load()
+	if p == nil {
+		return true
+	}
+	ok, known := p.Features[feature]
+	return ok || !known
+}
+
+// archiveProbeHeight is old enough that default-pruned nodes no longer keep its state.
+// It is used when the RPC node does not report its earliest height.
+const archiveProbeHeight = 1
+
+// Capabilities returns the result of the last DetectCapabilities call (zero value if never run).
+func (c *Client) Capabilities() Capabilities {
+	if p := c.caps.Load(); p != nil {
+		return *p
+	}
+	return Capabilities{}
+}
+
+// DetectCapabilities probes the node and records the result for Capabilities.
+// denom is used for denom-scoped routes (e.g., total_escrow).
+func (c *Client) DetectCapabilities(denom string) Capabilities {
+	caps := Capabilities{CheckedAt: time.Now().UTC(), ArchiveProbeHeight: archiveProbeHeight, Features: map[string]bool{}}
+	if h, err := c.RPCEarliestHeight(); err == nil && h > 0 {
+		caps.EarliestHeight, caps.ArchiveProbeHeight = h, h
+	} else if err != nil && !errors.Is(err, errNoRPC) {
+		log.Printf("warn: rpc earliest height: %v", err)
+	}
+	if err := c.probeStateAt(caps.ArchiveProbeHeight); err != nil {
+		caps.ArchiveError = err.Error()
+	} else {
+		caps.Archive = true
+	}
+	if _, err := c.supplyFrom(c.base+"/cosmos/bank/v1beta1/supply/by_denom?denom="+url.QueryEscape(denom), denom); err == nil {
+		caps.Features[FeatureSupplyByDenom] = true
+	} else if errors.Is(err, errRouteMissing) {
+		caps.Features[FeatureSupplyByDenom] = false
+		log.Printf("warn: lcd route %s unavailable; using /supply/{denom}", FeatureSupplyByDenom)
+	}
+	probes := map[string]string{
+		FeatureIBCTotalEscrow:      "/ibc/apps/transfer/v1/denoms/" + url.PathEscape(denom) + "/total_escrow",
+		FeatureModuleAccountByName: "/cosmos/auth/v1beta1/module_accounts/distribution",
+		FeatureCommunityPool:       "/cosmos/distribution/v1beta1/community_pool",
+	}
+	for f, path := range probes {
+		supported, known := c.probeRoute(path)
+		if !known {
+			continue
+		}
+		caps.Features[f] = supported
+		if !supported {
+			log.Printf("warn: lcd route %s unavailable (%s); using alternate strategy where one exists", f, path)
+		}
+	}
+	// the claim routes are probed under every configured prefix
+	resp, err := c.claimGet("/list_claimed/1", "lcd claim list_claimed")
+	switch status := StatusOf(err); {
+	case err == nil:
+		resp.Body.Close()
+		caps.Features[FeatureClaimListClaimed] = true
+	case errors.Is(err, errRouteMissing):
+		caps.Features[FeatureClaimListClaimed] = false
+		log.Printf("warn: lcd route %s unavailable: %v", FeatureClaimListClaimed, err)
+	case status != 0 && status < 500:
+		// the route answered; this query just failed
+		caps.Features[FeatureClaimListClaimed] = true
+	}
+	caps.ClaimPrefix = c.ClaimPrefix()
+	c.caps.Store(&caps)
+	return caps
+}
+
+// probeRoute classifies a GET: 404/501 means the route is missing; other HTTP answers
+// mean it exists (even if the specific query failed); transport errors are unknown.
+func (c *Client) probeRoute(path string) (supported, known bool) {
+	req, err := http.NewRequestWithContext(c.Context(), http.MethodGet, c.base+path, nil)
+	if err != nil {
+		return false, false
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, false
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusNotImplemented:
+		return false, true
+	default:
+		return resp.StatusCode < 500, resp.StatusCode < 500
+	}
+}
+
+// probeStateAt issues a cheap bank query pinned to height via x-cosmos-block-height.
+func (c *Client) probeStateAt(height int64) error {
+	req, err := http.NewRequestWithContext(c.Context(), http.MethodGet, c.base+"/cosmos/bank/v1beta1/supply?pagination.limit=1", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-cosmos-block-height", fmt.Sprintf("%d", height))
+	endpoint := fmt.Sprintf("lcd state at height %d", height)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return transportError(endpoint, req.URL.String(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return statusError(endpoint, resp)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package lcd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// legacyNode mimics an SDK 0.45 LCD: /supply/by_denom resolves as /supply/{denom}
+// and module accounts can only be listed.
+func legacyNode() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"by_denom","amount":"0"}}`))
+		case "/cosmos/bank/v1beta1/supply/ulume":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1000"}}`))
+		case "/cosmos/auth/v1beta1/module_accounts":
+			_, _ = w.Write([]byte(`{"accounts":[{"name":"claim","base_account":{"address":"lumera1claim"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestLegacyStrategies(t *testing.T) {
+	srv := legacyNode()
+	defer srv.Close()
+	c := NewClient(srv.URL, srv.Client())
+
+	// without a probe, the primary route is tried first and falls back
+	if got, err := c.TotalSupplyByDenom("ulume"); err != nil || got != "1000" {
+		t.Fatalf("supply before probe: %q, %v", got, err)
+	}
+
+	caps := c.DetectCapabilities("ulume")
+	if caps.Features[FeatureSupplyByDenom] || caps.Features[FeatureModuleAccountByName] {
+		t.Fatalf("legacy routes reported as supported: %v", caps.Features)
+	}
+	if got, err := c.TotalSupplyByDenom("ulume"); err != nil || got != "1000" {
+		t.Fatalf("supply after probe: %q, %v", got, err)
+	}
+	if got, err := c.ModuleAddressByName("claim"); err != nil || got != "lumera1claim" {
+		t.Fatalf("module address: %q, %v", got, err)
+	}
+}
+
+func TestIBCEscrowFromChannels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ibc/core/channel/v1/channels":
+			if r.URL.Query().Get("pagination.key") == "" {
+				_, _ = w.Write([]byte(`{"channels":[{"port_id":"transfer","channel_id":"channel-0"},{"port_id":"icahost","channel_id":"channel-1"}],"pagination":{"next_key":"k2"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"channels":[{"port_id":"transfer","channel_id":"channel-2"}],"pagination":{"next_key":null}}`))
+		case "/ibc/apps/transfer/v1/channels/channel-0/ports/transfer/escrow_address":
+			_, _ = w.Write([]byte(`{"escrow_address":"lumera1esc0"}`))
+		case "/ibc/apps/transfer/v1/channels/channel-2/ports/transfer/escrow_address":
+			_, _ = w.Write([]byte(`{"escrow_address":"lumera1esc2"}`))
+		case "/cosmos/bank/v1beta1/balances/lumera1esc0/by_denom":
+			_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"40"}}`))
+		case "/cosmos/bank/v1beta1/balances/lumera1esc2/by_denom":
+			_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"2"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, srv.Client())
+	got, err := c.IBCTotalEscrow("ulume")
+	if err != nil || got != "42" {
+		t.Fatalf("IBCTotalEscrow fallback: %q, %v", got, err)
+	}
+}
+
+// prunedNode keeps blocks and state from height 100 and serves the RPC under /rpc.
+func prunedNode() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rpc":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"sync_info":{"earliest_block_height":"100","latest_block_height":"500","latest_block_time":"2025-01-02T03:04:05Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply":
+			if h, _ := parseInt(r.Header.Get("x-cosmos-block-height")); h < 100 {
+				http.Error(w, `{"code":3,"message":"version does not exist"}`, http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte(`{"supply":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestArchiveProbeAtEarliestHeight(t *testing.T) {
+	srv := prunedNode()
+	defer srv.Close()
+
+	// without RPC the probe asks for block 1, which the node has pruned
+	c := NewClient(srv.URL, srv.Client())
+	if caps := c.DetectCapabilities("ulume"); caps.Archive || caps.ArchiveProbeHeight != 1 || caps.EarliestHeight != 0 {
+		t.Fatalf("without rpc: %+v", caps)
+	}
+
+	c.SetRPC(srv.URL + "/rpc")
+	caps := c.DetectCapabilities("ulume")
+	if !caps.Archive || caps.ArchiveProbeHeight != 100 || caps.EarliestHeight != 100 {
+		t.Fatalf("with rpc: %+v", caps)
+	}
+}
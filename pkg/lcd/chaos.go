@@ -0,0 +1,118 @@
+package lcd
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+)
+
+var chaosInjected = metrics.Default.NewCounter(
+	"lumera_supply_chaos_injected_total",
+	"Upstream faults injected by chaos mode, by kind (latency, status, error).",
+	"kind",
+)
+
+// ErrChaos is the transport error returned for injected connection failures.
+var ErrChaos = errors.New("chaos: injected upstream failure")
+
+// Fault is an upstream fault injected into requests whose URL path starts with Path.
+type Fault struct {
+	// Path is a path prefix, e.g. "/cosmos/bank/"; empty matches every request.
+	Path string `json:"path"`
+	// LatencyMS delays matching requests before they are sent (or failed).
+	LatencyMS int64 `json:"latency_ms,omitempty"`
+	// Status answers with a synthetic HTTP status (e.g., 503) instead of calling upstream.
+	Status int `json:"status,omitempty"`
+	// Error fails the request at the transport level, like a refused connection.
+	Error bool `json:"error,omitempty"`
+	// Rate is the fraction of matching requests affected (0 or 1 = all).
+	Rate float64 `json:"rate,omitempty"`
+}
+
+// Chaos is a RoundTripper that injects configured faults. It exists so operators can
+// rehearse fail-closed behaviour and alerting in staging; see main for the env gate.
+type Chaos struct {
+	next   http.RoundTripper
+	mu     sync.RWMutex
+	faults []Fault
+}
+
+// NewChaos wraps next (http.DefaultTransport when nil) with no faults configured.
+func NewChaos(next http.RoundTripper) *Chaos {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Chaos{next: next}
+}
+
+// SetFaults replaces the active faults; the first matching fault applies.
+func (c *Chaos) SetFaults(faults []Fault) {
+	c.mu.Lock()
+	c.faults = append([]Fault(nil), faults...)
+	c.mu.Unlock()
+}
+
+// Faults returns the active faults.
+func (c *Chaos) Faults() []Fault {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Fault{}, c.faults...)
+}
+
+func (c *Chaos) match(path string) (Fault, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, f := range c.faults {
+		if strings.HasPrefix(path, f.Path) {
+			if f.Rate > 0 && f.Rate < 1 && rand.Float64() >= f.Rate {
+				return Fault{}, false
+			}
+			return f, true
+		}
+	}
+	return Fault{}, false
+}
+
+func (c *Chaos) RoundTrip(r *http.Request) (*http.Response, error) {
+	f, ok := c.match(r.URL.Path)
+	if !ok {
+		return c.next.RoundTrip(r)
+	}
+	if f.LatencyMS > 0 {
+		chaosInjected.Inc("latency")
+		t := time.NewTimer(time.Duration(f.LatencyMS) * time.Millisecond)
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+			t.Stop()
+			return nil, r.Context().Err()
+		}
+	}
+	switch {
+	case f.Error:
+		chaosInjected.Inc("error")
+		return nil, ErrChaos
+	case f.Status > 0:
+		chaosInjected.Inc("status")
+		body := "chaos: injected status " + strconv.Itoa(f.Status)
+		return &http.Response{
+			Status:        strconv.Itoa(f.Status) + " " + http.StatusText(f.Status),
+			StatusCode:    f.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"text/plain"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       r,
+		}, nil
+	}
+	return c.next.RoundTrip(r)
+}
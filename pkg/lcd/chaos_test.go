@@ -0,0 +1,35 @@
+package lcd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChaosFaults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"block":{"header":{"height":"7","time":"2026-01-01T00:00:00Z"}}}`))
+	}))
+	defer srv.Close()
+	chaos := NewChaos(srv.Client().Transport)
+	c := NewClient(srv.URL, &http.Client{Transport: chaos, Timeout: time.Second})
+
+	if h, _, err := c.LatestHeight(); err != nil || h != 7 {
+		t.Fatalf("no faults: got %d, %v", h, err)
+	}
+	chaos.SetFaults([]Fault{{Path: "/cosmos/bank/", Error: true}, {Path: "/cosmos/base/", Status: 503}})
+	if _, _, err := c.LatestHeight(); err == nil {
+		t.Fatal("expected injected 503 to fail")
+	}
+	chaos.SetFaults([]Fault{{Path: "/cosmos/base/", Error: true}})
+	if _, _, err := c.LatestHeight(); !errors.Is(err, ErrChaos) {
+		t.Fatalf("expected ErrChaos, got %v", err)
+	}
+	chaos.SetFaults([]Fault{{LatencyMS: 50}})
+	start := time.Now()
+	if _, _, err := c.LatestHeight(); err != nil || time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("expected delayed success, got %v after %s", err, time.Since(start))
+	}
+}
@@ -0,0 +1,125 @@
+package lcd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultClaimPrefixes are the claim module route prefixes tried in order: the current
+// Lumera route and the versioned layout newer chain releases may serve.
+var DefaultClaimPrefixes = []string{"/LumeraProtocol/lumera/claim", "/lumera/claim/v1"}
+
+// claimRoutes tracks which claim route prefix the node serves. It is shared by the
+// clients derived with AtHeight and WithStats, so one discovery serves them all.
+type claimRoutes struct {
+	mu       sync.Mutex
+	prefixes []string
+	active   string
+}
+
+func newClaimRoutes(prefixes []string) *claimRoutes {
+	r := &claimRoutes{}
+	for _, p := range prefixes {
+		if p = strings.TrimRight(strings.TrimSpace(p), "/"); p != "" {
+			if !strings.HasPrefix(p, "/") {
+				p = "/" + p
+			}
+			r.prefixes = append(r.prefixes, p)
+		}
+	}
+	if len(r.prefixes) == 0 {
+		r.prefixes = append(r.prefixes, DefaultClaimPrefixes...)
+	}
+	return r
+}
+
+// candidates returns the prefixes to try: the one last seen serving first.
+func (r *claimRoutes) candidates() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]string, 0, len(r.prefixes))
+	if r.active != "" {
+		out = append(out, r.active)
+	}
+	for _, p := range r.prefixes {
+		if p != r.active {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (r *claimRoutes) use(prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if prefix == r.active {
+		return
+	}
+	if r.active != "" {
+		log.Printf("warn: lcd claim routes moved from %s to %s", r.active, prefix)
+	}
+	r.active = prefix
+}
+
+// SetClaimPrefixes replaces the claim module route prefixes to try, in order (e.g.
+// "/LumeraProtocol/lumera/claim"). An empty list restores DefaultClaimPrefixes.
+func (c *Client) SetClaimPrefixes(prefixes []string) {
+	next := newClaimRoutes(prefixes).prefixes
+	c.claim.mu.Lock()
+	c.claim.prefixes, c.claim.active = next, ""
+	c.claim.mu.Unlock()
+}
+
+// ClaimPrefix returns the claim route prefix the node was last seen serving, or the
+// first configured one before any claim query succeeded.
+func (c *Client) ClaimPrefix() string {
+	return c.claim.candidates()[0]
+}
+
+// claimGet GETs path under the claim route prefix and returns the response when it
+// is a 200 (see get). A prefix answering 404 or 501 may have moved, so the other
+// configured prefixes are tried and the first one serving the route is used from then
+// on. When none does, the first answer's error is returned, marked errRouteMissing
+// unless a prefix had served claims before (a 404 from a known route is then a query
+// result, not a missing route).
+func (c *Client) claimGet(path, endpoint string) (*http.Response, error) {
+	prefixes := c.claim.candidates()
+	var first *Error
+	for i, p := range prefixes {
+		u := c.base + p + path
+		req, err := c.newRequest(http.MethodGet, u, endpoint, nil)
+		if err != nil {
+			return nil, transportError(endpoint, u, err)
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, transportError(endpoint, u, err)
+		}
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+			e := statusError(endpoint, resp)
+			resp.Body.Close()
+			if i == 0 {
+				first = e
+			}
+			continue
+		}
+		c.claim.use(p)
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			return nil, statusError(endpoint, resp)
+		}
+		c.guard(resp, endpoint)
+		return resp, nil
+	}
+	c.claim.mu.Lock()
+	known := c.claim.active != ""
+	c.claim.mu.Unlock()
+	if known {
+		return nil, first
+	}
+	first.Err = errRouteMissing
+	return nil, fmt.Errorf("%w (tried %s)", first, strings.Join(prefixes, ", "))
+}
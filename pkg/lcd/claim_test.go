@@ -0,0 +1,53 @@
+package lcd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClaimRoutePrefixFallback(t *testing.T) {
+	var served atomic.Value
+	served.Store("/lumera/claim/v1")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == served.Load().(string)+"/list_claimed/1" {
+			_, _ = w.Write([]byte(`{"claims":[{"destAddress":"lumera1a","balance":[{"denom":"ulume","amount":"5"}]}]}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, ts.Client())
+
+	recs, err := c.ClaimListClaimed(1, "ulume")
+	if err != nil || len(recs) != 1 || recs[0].Amount != "5" {
+		t.Fatalf("expected the record via the alternate prefix: %+v %v", recs, err)
+	}
+	if p := c.ClaimPrefix(); p != "/lumera/claim/v1" {
+		t.Fatalf("ClaimPrefix() = %s", p)
+	}
+	// derived clients share the discovered prefix
+	if p := c.AtHeight(5).ClaimPrefix(); p != "/lumera/claim/v1" {
+		t.Fatalf("AtHeight(5).ClaimPrefix() = %s", p)
+	}
+
+	// the chain moves the routes back
+	served.Store("/LumeraProtocol/lumera/claim")
+	if _, err := c.ClaimListClaimed(1, "ulume"); err != nil || c.ClaimPrefix() != "/LumeraProtocol/lumera/claim" {
+		t.Fatalf("expected a switch back: prefix %s, %v", c.ClaimPrefix(), err)
+	}
+	// once known, a 404 is a query error rather than a missing route
+	if _, err := c.ClaimListClaimed(2, "ulume"); err == nil || errors.Is(err, errRouteMissing) {
+		t.Fatalf("expected a plain error, got %v", err)
+	}
+
+	c.SetClaimPrefixes([]string{"custom/claim/"})
+	if _, err := c.ClaimListClaimed(1, "ulume"); !errors.Is(err, errRouteMissing) {
+		t.Fatalf("expected errRouteMissing under an unserved prefix, got %v", err)
+	}
+	if p := c.ClaimPrefix(); p != "/custom/claim" {
+		t.Fatalf("ClaimPrefix() = %s", p)
+	}
+}
@@ -1,18 +1,44 @@
 package lcd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
 type Client struct {
 	base   string
 	client *http.Client
+
+	// optional CometBFT JSON-RPC endpoint for batched queries (see batch.go)
+	rpc              string
+	batchUnsupported atomic.Bool
+
+	// last probed node capabilities (see capabilities.go)
+	caps atomic.Pointer[Capabilities]
+
+	// optional per-unit-of-work counters (see stats.go)
+	stats *CallStats
+
+	// claim module route prefixes (see claim.go)
+	claim *claimRoutes
+
+	// response body size and JSON depth limits (see limits.go)
+	limits responseLimits
+
+	// block height every query is pinned to; 0 is the latest (see height.go)
+	height int64
+
+	// context every request is made under; nil is context.Background (see context.go)
+	ctx context.Context
 }
 
 // decToIntString truncates a decimal string to its integer part (no rounding).
@@ -29,21 +55,36 @@ func decToIntString(s string) string {
 }
 
 func NewClient(base string, httpClient *http.Client) *Client {
-	return &Client{base: strings.TrimRight(base, "/"), client: httpClient}
+	return &Client{base: strings.TrimRight(base, "/"), client: httpClient, claim: newClaimRoutes(nil)}
 }
 
-// LatestHeight returns the latest block height and time from LCD.
+// LatestHeight returns the latest block height and time from LCD. When the LCD fails
+// and an RPC endpoint is configured, the CometBFT status is used instead.
 func (c *Client) LatestHeight() (int64, time.Time, error) {
-	u := c.base + "/cosmos/base/tendermint/v1beta1/blocks/latest"
-	resp, err := c.client.Get(u)
+	h, t, err := c.lcdLatestHeight()
+	if err == nil || c.rpc == "" {
+		return h, t, err
+	}
+	rh, rt, rerr := c.RPCStatus()
+	if rerr != nil {
+		return 0, time.Time{}, errors.Join(err, rerr)
+	}
+	log.Printf("warn: lcd latest block failed, using rpc status: %v", err)
+	return rh, rt, nil
+}
+
+func (c *Client) lcdLatestHeight() (int64, time.Time, error) {
+	return c.lcdBlock("latest", "lcd latest block")
+}
+
+// lcdBlock reads the header of block ("latest" or a height).
+func (c *Client) lcdBlock(block, endpoint string) (int64, time.Time, error) {
+	u := c.base + "/cosmos/base/tendermint/v1beta1/blocks/" + block
+	resp, err := c.get(u, endpoint)
 	if err != nil {
 		return 0, time.Time{}, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		b, _ := io.ReadAll(resp.Body)
-		return 0, time.Time{}, fmt.Errorf("lcd latest block: %s", string(b))
-	}
 	var out struct {
 		Block struct {
 			Header struct {
@@ -63,17 +104,30 @@ func (c *Client) LatestHeight() (int64, time.Time, error) {
 }
 
 // TotalSupplyByDenom returns the total on-chain supply for a denom.
+// Nodes without the by_denom route (SDK < 0.46) are queried via /supply/{denom}.
 func (c *Client) TotalSupplyByDenom(denom string) (string, error) {
-	u := c.base + "/cosmos/bank/v1beta1/supply/by_denom?denom=" + url.QueryEscape(denom)
-	resp, err := c.client.Get(u)
+	legacy := c.base + "/cosmos/bank/v1beta1/supply/" + url.PathEscape(denom)
+	if !c.supports(FeatureSupplyByDenom) {
+		return c.supplyFrom(legacy, denom)
+	}
+	amt, err := c.supplyFrom(c.base+"/cosmos/bank/v1beta1/supply/by_denom?denom="+url.QueryEscape(denom), denom)
+	if errors.Is(err, errRouteMissing) {
+		return c.supplyFrom(legacy, denom)
+	}
+	return amt, err
+}
+
+// errRouteMissing marks an LCD route that this node version does not serve.
+var errRouteMissing = errors.New("lcd route not available")
+
+// supplyFrom reads a bank supply response. Older nodes match /supply/by_denom as
+// /supply/{denom} with denom "by_denom", so a mismatched denom counts as a missing route.
+func (c *Client) supplyFrom(u, denom string) (string, error) {
+	resp, err := c.get(u, "lcd supply")
 	if err != nil {
-		return "", err
+		return "", markRouteMissing(err)
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("lcd supply: %s", string(b))
-	}
 	var out struct {
 		Amount struct {
 			Denom  string `json:"denom"`
@@ -83,21 +137,27 @@ func (c *Client) TotalSupplyByDenom(denom string) (string, error) {
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
 		return "", err
 	}
+	if out.Amount.Denom != "" && out.Amount.Denom != denom {
+		return "", fmt.Errorf("lcd supply: %w: got denom %q", errRouteMissing, out.Amount.Denom)
+	}
 	return out.Amount.Amount, nil
 }
 
 // IBCTotalEscrow returns the total amount of a denom escrowed in IBC transfer module.
+// Nodes without total_escrow (ibc-go < 7) are served by summing channel escrow accounts.
 func (c *Client) IBCTotalEscrow(denom string) (string, error) {
+	if !c.supports(FeatureIBCTotalEscrow) {
+		return c.ibcEscrowFromChannels(denom)
+	}
 	u := c.base + "/ibc/apps/transfer/v1/denoms/" + url.PathEscape(denom) + "/total_escrow"
-	resp, err := c.client.Get(u)
+	resp, err := c.get(u, "lcd ibc escrow")
+	if IsNotFound(err) {
+		return c.ibcEscrowFromChannels(denom)
+	}
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("lcd ibc escrow: %s", string(b))
-	}
 	var out struct {
 		Amount struct {
 			Amount string `json:"amount"`
@@ -112,15 +172,11 @@ func (c *Client) IBCTotalEscrow(denom string) (string, error) {
 // CommunityPool returns the community pool balance for the given denom as an integer string (truncated).
 func (c *Client) CommunityPool(denom string) (string, error) {
 	u := c.base + "/cosmos/distribution/v1beta1/community_pool"
-	resp, err := c.client.Get(u)
+	resp, err := c.get(u, "lcd community pool")
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("lcd community pool: %s", string(b))
-	}
 	var out struct {
 		Pool []struct {
 			Denom  string `json:"denom"`
@@ -141,15 +197,11 @@ func (c *Client) CommunityPool(denom string) (string, error) {
 // BalanceByDenom returns balance for address/denom
 func (c *Client) BalanceByDenom(address, denom string) (string, error) {
 	u := c.base + "/cosmos/bank/v1beta1/balances/" + url.PathEscape(address) + "/by_denom?denom=" + url.QueryEscape(denom)
-	resp, err := c.client.Get(u)
+	resp, err := c.get(u, "lcd balance")
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("lcd balance: %s", string(b))
-	}
 	var out struct {
 		Balance struct {
 			Amount string `json:"amount"`
@@ -161,18 +213,31 @@ func (c *Client) BalanceByDenom(address, denom string) (string, error) {
 	return out.Balance.Amount, nil
 }
 
+// SpendableBalance returns address's spendable (unlocked, undelegated) balance of denom.
+func (c *Client) SpendableBalance(address, denom string) (string, error) {
+	var out struct {
+		Balance struct {
+			Amount string `json:"amount"`
+		} `json:"balance"`
+	}
+	u := c.base + "/cosmos/bank/v1beta1/spendable_balances/" + url.PathEscape(address) + "/by_denom?denom=" + url.QueryEscape(denom)
+	if err := c.getJSON(u, "lcd spendable balance", &out); err != nil {
+		return "", err
+	}
+	if out.Balance.Amount == "" {
+		return "0", nil
+	}
+	return out.Balance.Amount, nil
+}
+
 // IsModuleAccount makes a shallow check if account is a module account by querying account type string.
 func (c *Client) IsModuleAccount(address string) (bool, error) {
 	u := c.base + "/cosmos/auth/v1beta1/accounts/" + url.PathEscape(address)
-	resp, err := c.client.Get(u)
+	resp, err := c.get(u, "lcd account")
 	if err != nil {
 		return false, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		b, _ := io.ReadAll(resp.Body)
-		return false, fmt.Errorf("lcd account: %s", string(b))
-	}
 	var out struct {
 		Account struct {
 			Type string `json:"@type"`
@@ -185,17 +250,17 @@ func (c *Client) IsModuleAccount(address string) (bool, error) {
 }
 
 // ModuleAddressByName resolves a module account name to its address via LCD.
+// Nodes without the by-name route are served by scanning the module account list.
 func (c *Client) ModuleAddressByName(name string) (string, error) {
+	if !c.supports(FeatureModuleAccountByName) {
+		return c.moduleAddressFromList(name)
+	}
 	u := c.base + "/cosmos/auth/v1beta1/module_accounts/" + url.PathEscape(name)
-	resp, err := c.client.Get(u)
+	resp, err := c.get(u, "lcd module account by name")
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("lcd module account by name: %s", string(b))
-	}
 	var out struct {
 		Account struct {
 			BaseAccount struct {
@@ -209,18 +274,61 @@ func (c *Client) ModuleAddressByName(name string) (string, error) {
 	return out.Account.BaseAccount.Address, nil
 }
 
+// ModuleAccount is a chain module account from /cosmos/auth/v1beta1/module_accounts.
+type ModuleAccount struct {
+	Name        string   `json:"name"`
+	Address     string   `json:"address"`
+	Permissions []string `json:"permissions"`
+}
+
+// ModuleAccounts lists every module account on the chain.
+func (c *Client) ModuleAccounts() ([]ModuleAccount, error) {
+	resp, err := c.get(c.base+"/cosmos/auth/v1beta1/module_accounts", "lcd module accounts")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Accounts []struct {
+			Name        string   `json:"name"`
+			Permissions []string `json:"permissions"`
+			BaseAccount struct {
+				Address string `json:"address"`
+			} `json:"base_account"`
+		} `json:"accounts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	mods := make([]ModuleAccount, 0, len(out.Accounts))
+	for _, a := range out.Accounts {
+		mods = append(mods, ModuleAccount{Name: a.Name, Address: a.BaseAccount.Address, Permissions: a.Permissions})
+	}
+	return mods, nil
+}
+
+// moduleAddressFromList resolves a module name from /cosmos/auth/v1beta1/module_accounts.
+func (c *Client) moduleAddressFromList(name string) (string, error) {
+	mods, err := c.ModuleAccounts()
+	if err != nil {
+		return "", err
+	}
+	for _, a := range mods {
+		if a.Name == name {
+			return a.Address, nil
+		}
+	}
+	return "", fmt.Errorf("lcd module accounts: %q not found", name)
+}
+
 // AuthAccount fetches the raw account JSON and its type string for a given address.
 func (c *Client) AuthAccount(address string) (json.RawMessage, string, error) {
 	u := c.base + "/cosmos/auth/v1beta1/accounts/" + url.PathEscape(address)
-	resp, err := c.client.Get(u)
+	resp, err := c.get(u, "lcd account")
 	if err != nil {
 		return nil, "", err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		b, _ := io.ReadAll(resp.Body)
-		return nil, "", fmt.Errorf("lcd account: %s", string(b))
-	}
 	var outer struct {
 		Account json.RawMessage `json:"account"`
 	}
@@ -244,19 +352,20 @@ type ClaimRecord struct {
 // ClaimListClaimed fetches claimed accounts for a tier (1..4). Best-effort parsing.
 // It extracts the amount for the provided denom when available.
 func (c *Client) ClaimListClaimed(tier int, denom string) ([]ClaimRecord, error) {
-	u := fmt.Sprintf("%s/LumeraProtocol/lumera/claim/list_claimed/%d", c.base, tier)
-	resp, err := c.client.Get(u)
+	resp, err := c.claimGet(fmt.Sprintf("/list_claimed/%d", tier), "lcd claim list_claimed")
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("lcd claim list_claimed: %s", string(b))
-	}
+	return parseClaimList(resp.Body, denom)
+}
+
+// parseClaimList decodes a list_claimed answer in any of the shapes the claim module
+// has served, keeping records with an address.
+func parseClaimList(body io.Reader, denom string) ([]ClaimRecord, error) {
 	// Try multiple shapes (backward-compatible):
 	var raw map[string]json.RawMessage
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
 		return nil, err
 	}
 	// New shape: top-level "claims" with fields including destAddress, claimTime, and balance array
@@ -275,14 +384,7 @@ func (c *Client) ClaimListClaimed(tier int, denom string) ([]ClaimRecord, error)
 				if it.DestAddress == "" {
 					continue
 				}
-				var tptr *time.Time
-				if it.ClaimTime != "" {
-					var sec int64
-					if _, err := fmt.Sscan(it.ClaimTime, &sec); err == nil && sec > 0 {
-						t := time.Unix(sec, 0).UTC()
-						tptr = &t
-					}
-				}
+				tptr := unixTime(it.ClaimTime)
 				amt := ""
 				for _, b := range it.Balance {
 					if b.Denom == denom {
@@ -320,25 +422,16 @@ func (c *Client) ClaimListClaimed(tier int, denom string) ([]ClaimRecord, error)
 				tptr = &tt
 			} else {
 				// try seconds since epoch as string
-				var sec int64
-				if _, err := fmt.Sscan(ts, &sec); err == nil && sec > 0 {
-					t := time.Unix(sec, 0).UTC()
-					tptr = &t
-				}
+				tptr = unixTime(ts)
 			}
 		} else if ts, ok := item["time"].(string); ok {
 			if tt, err := time.Parse(time.RFC3339, ts); err == nil {
 				tptr = &tt
 			} else {
-				var sec int64
-				if _, err := fmt.Sscan(ts, &sec); err == nil && sec > 0 {
-					t := time.Unix(sec, 0).UTC()
-					tptr = &t
-				}
+				tptr = unixTime(ts)
 			}
-		} else if f, ok := item["time"].(float64); ok {
-			sec := int64(f)
-			t := time.Unix(sec, 0).UTC()
+		} else if f, ok := item["time"].(float64); ok && f > 0 && f < maxUnix {
+			t := time.Unix(int64(f), 0).UTC()
 			tptr = &t
 		}
 		// try to parse amount from balance array
@@ -364,6 +457,20 @@ func (c *Client) ClaimListClaimed(tier int, denom string) ([]ClaimRecord, error)
 	return recs, nil
 }
 
+// maxUnix is the last second of year 9999, beyond which times no longer format as
+// RFC3339.
+const maxUnix = 253402300799
+
+// unixTime parses seconds since the epoch; nil when s is not a time after the epoch.
+func unixTime(s string) *time.Time {
+	var sec int64
+	if _, err := fmt.Sscan(s, &sec); err != nil || sec <= 0 || sec > maxUnix {
+		return nil
+	}
+	t := time.Unix(sec, 0).UTC()
+	return &t
+}
+
 func parseInt(s string) (int64, error) {
 	var n int64
 	_, err := fmt.Sscan(s, &n)
@@ -1,7 +1,10 @@
 package lcd
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -76,3 +79,49 @@ func TestClaimListClaimed_NewShape(t *testing.T) {
 		t.Fatalf("unexpected time: got %s want %s", recs[0].Time, want)
 	}
 }
+
+func TestWithContextCancelsRequests(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1000"}}`))
+	}))
+	defer ts.Close()
+	client := NewClient(ts.URL, ts.Client())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bound := client.WithContext(ctx)
+	if amt, err := bound.TotalSupplyByDenom("ulume"); err != nil || amt != "1000" {
+		t.Fatalf("before cancel: %q %v", amt, err)
+	}
+	cancel()
+	// derived clients keep the context
+	if _, err := bound.AtHeight(5).TotalSupplyByDenom("ulume"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("after cancel: %v", err)
+	}
+	if _, err := client.TotalSupplyByDenom("ulume"); err != nil {
+		t.Fatalf("the original client must not be affected: %v", err)
+	}
+}
+
+// FuzzParseClaimList feeds list_claimed answers to the parser; the seeds in
+// testdata/fuzz are payloads the claim module has served.
+func FuzzParseClaimList(f *testing.F) {
+	f.Add([]byte(`{"claims":[{"destAddress":"lumera1a","claimTime":"1748960103","balance":[{"denom":"ulume","amount":"5"}]}]}`), "ulume")
+	f.Add([]byte(`{"claims":{"destAddress":"lumera1a"},"records":[{"address":"lumera1b","time":-5}]}`), "ulume")
+	f.Fuzz(func(t *testing.T, body []byte, denom string) {
+		recs, err := parseClaimList(bytes.NewReader(body), denom)
+		if err != nil {
+			return
+		}
+		for _, r := range recs {
+			if r.Address == "" {
+				t.Fatalf("record without an address: %+v", r)
+			}
+			if r.Time != nil {
+				ts := r.Time.Format(time.RFC3339)
+				if _, err := time.Parse(time.RFC3339, ts); err != nil {
+					t.Fatalf("claim time %s does not round-trip: %v", ts, err)
+				}
+			}
+		}
+	})
+}
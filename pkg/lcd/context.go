@@ -0,0 +1,37 @@
+package lcd
+
+import (
+	"context"
+	"net/http"
+)
+
+// WithContext returns a client whose requests are made under ctx, so cancelling ctx
+// (a closed HTTP request, a shutdown) aborts them. Clients derived from it with
+// AtHeight or WithStats keep ctx.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cp := c.clone(c.client)
+	cp.ctx = ctx
+	return cp
+}
+
+// Context returns the context the client's requests are made under.
+func (c *Client) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// clone copies c's settings and shared state onto a client using hc.
+func (c *Client) clone(hc *http.Client) *Client {
+	cp := NewClient(c.base, hc)
+	cp.rpc = c.rpc
+	cp.batchUnsupported.Store(c.batchUnsupported.Load())
+	cp.height = c.height
+	cp.caps.Store(c.caps.Load())
+	cp.claim = c.claim
+	cp.limits = c.limits
+	cp.stats = c.stats
+	cp.ctx = c.ctx
+	return cp
+}
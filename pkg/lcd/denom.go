@@ -0,0 +1,58 @@
+package lcd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DenomMetadata is the display unit the bank module registers for a base denom.
+type DenomMetadata struct {
+	Base string `json:"base"`
+	// Display is the denom amounts are usually shown in (e.g. "lume" for "ulume").
+	Display string `json:"display"`
+	// Decimals is the display unit's exponent: one display unit is 10^Decimals base units.
+	Decimals int `json:"decimals"`
+}
+
+// DenomMetadata returns the bank metadata of denom. Denoms with a slash (IBC, factory)
+// are looked up through the query string route.
+func (c *Client) DenomMetadata(denom string) (DenomMetadata, error) {
+	u := c.base + "/cosmos/bank/v1beta1/denoms_metadata/" + url.PathEscape(denom)
+	if strings.Contains(denom, "/") {
+		u = c.base + "/cosmos/bank/v1beta1/denoms_metadata_by_query_string?denom=" + url.QueryEscape(denom)
+	}
+	var out struct {
+		Metadata struct {
+			Base       string `json:"base"`
+			Display    string `json:"display"`
+			DenomUnits []struct {
+				Denom    string   `json:"denom"`
+				Exponent int      `json:"exponent"`
+				Aliases  []string `json:"aliases"`
+			} `json:"denom_units"`
+		} `json:"metadata"`
+	}
+	if err := c.getJSON(u, "lcd denom metadata", &out); err != nil {
+		return DenomMetadata{}, err
+	}
+	m := out.Metadata
+	for _, du := range m.DenomUnits {
+		if du.Denom == m.Display || (m.Display != "" && containsFold(du.Aliases, m.Display)) {
+			if du.Exponent < 0 || du.Exponent > 36 {
+				return DenomMetadata{}, fmt.Errorf("lcd denom metadata: %s: bad exponent %d", denom, du.Exponent)
+			}
+			return DenomMetadata{Base: m.Base, Display: m.Display, Decimals: du.Exponent}, nil
+		}
+	}
+	return DenomMetadata{}, fmt.Errorf("lcd denom metadata: %s: no unit for display denom %q", denom, m.Display)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package lcd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDenomMetadata(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/cosmos/bank/v1beta1/denoms_metadata/ulume":
+			_, _ = w.Write([]byte(`{"metadata":{"base":"ulume","display":"lume","denom_units":[{"denom":"ulume","exponent":0},{"denom":"mlume","exponent":3},{"denom":"lume","exponent":6}]}}`))
+		case r.URL.Path == "/cosmos/bank/v1beta1/denoms_metadata_by_query_string" && r.URL.Query().Get("denom") == "ibc/ABC":
+			_, _ = w.Write([]byte(`{"metadata":{"base":"ibc/ABC","display":"ATOM","denom_units":[{"denom":"ibc/ABC","exponent":0},{"denom":"uatom","exponent":6,"aliases":["atom"]}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, ts.Client())
+
+	m, err := c.DenomMetadata("ulume")
+	if err != nil || m != (DenomMetadata{Base: "ulume", Display: "lume", Decimals: 6}) {
+		t.Fatalf("ulume: %+v %v", m, err)
+	}
+	// the display denom may be an alias; slashed denoms use the query string route
+	if m, err = c.DenomMetadata("ibc/ABC"); err != nil || m.Decimals != 6 || m.Display != "ATOM" {
+		t.Fatalf("ibc/ABC: %+v %v", m, err)
+	}
+	if _, err := c.DenomMetadata("unknown"); StatusOf(err) != http.StatusNotFound {
+		t.Fatalf("unknown denom: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package lcd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+)
+
+var (
+	dnsChanges = metrics.Default.NewCounter(
+		"lumera_supply_lcd_dns_changes_total",
+		"Re-resolutions of an upstream hostname that returned a different address set.",
+		"host",
+	)
+	endpointSwitches = metrics.Default.NewCounter(
+		"lumera_supply_lcd_endpoint_switches_total",
+		"New upstream connections made to a different IP than the previous one.",
+		"host",
+	)
+	dialFailures = metrics.Default.NewCounter(
+		"lumera_supply_lcd_dial_failures_total",
+		"Failed dials to a resolved upstream IP.",
+		"host",
+	)
+)
+
+// badFor is how long an IP that failed to dial is tried after healthy ones.
+const badFor = 30 * time.Second
+
+// dnsDialer re-resolves upstream hostnames on an interval and dials their addresses
+// healthy-first, so rotating load balancers are followed instead of pinning the IP
+// that was current at startup.
+type dnsDialer struct {
+	dialer *net.Dialer
+	lookup func(ctx context.Context, host string) ([]string, error)
+	every  time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	addrs    []string
+	resolved time.Time
+	bad      map[string]time.Time
+	last     string
+}
+
+func newDNSDialer(every time.Duration) *dnsDialer {
+	return &dnsDialer{
+		dialer: &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		lookup: net.DefaultResolver.LookupHost,
+		every:  every,
+		hosts:  map[string]*hostState{},
+	}
+}
+
+func (d *dnsDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, address)
+	}
+	addrs, err := d.candidates(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, ip := range addrs {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			d.mark(host, ip, true)
+			return conn, nil
+		}
+		d.mark(host, ip, false)
+		dialFailures.Inc(host)
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// candidates returns host's addresses, re-resolving when the cached set is older than
+// the refresh interval, with recently failed IPs moved to the end.
+func (d *dnsDialer) candidates(ctx context.Context, host string) ([]string, error) {
+	d.mu.Lock()
+	st := d.hosts[host]
+	expired := st == nil || time.Since(st.resolved) > d.every
+	d.mu.Unlock()
+	if expired {
+		if _, err := d.resolve(ctx, host); err != nil {
+			d.mu.Lock()
+			st = d.hosts[host]
+			d.mu.Unlock()
+			if st == nil {
+				return nil, err
+			}
+			// keep dialing the last known addresses while DNS is failing
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st = d.hosts[host]
+	now := time.Now()
+	var healthy, bad []string
+	for _, a := range st.addrs {
+		if until, ok := st.bad[a]; ok && now.Before(until) {
+			bad = append(bad, a)
+		} else {
+			healthy = append(healthy, a)
+		}
+	}
+	return append(healthy, bad...), nil
+}
+
+// resolve looks host up and records the result; it reports whether the set changed.
+func (d *dnsDialer) resolve(ctx context.Context, host string) (bool, error) {
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return false, err
+	}
+	slices.Sort(addrs)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st := d.hosts[host]
+	if st == nil {
+		d.hosts[host] = &hostState{addrs: addrs, resolved: time.Now(), bad: map[string]time.Time{}}
+		return false, nil
+	}
+	changed := !slices.Equal(st.addrs, addrs)
+	st.addrs, st.resolved = addrs, time.Now()
+	if changed {
+		dnsChanges.Inc(host)
+	}
+	return changed, nil
+}
+
+func (d *dnsDialer) mark(host, ip string, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st := d.hosts[host]
+	if st == nil {
+		return
+	}
+	if !ok {
+		st.bad[ip] = time.Now().Add(badFor)
+		return
+	}
+	delete(st.bad, ip)
+	if st.last != "" && st.last != ip {
+		endpointSwitches.Inc(host)
+	}
+	st.last = ip
+}
+
+// run re-resolves known hosts every interval until ctx is done and drops idle
+// connections when a host's addresses change, so the next request dials a current backend.
+func (d *dnsDialer) run(ctx context.Context, t *http.Transport) {
+	tick := time.NewTicker(d.every)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+		d.mu.Lock()
+		hosts := make([]string, 0, len(d.hosts))
+		for h := range d.hosts {
+			hosts = append(hosts, h)
+		}
+		d.mu.Unlock()
+		changed := false
+		for _, h := range hosts {
+			rctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			c, _ := d.resolve(rctx, h)
+			cancel()
+			changed = changed || c
+		}
+		if changed {
+			t.CloseIdleConnections()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package lcd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody caps the response body excerpt kept in an Error.
+const maxErrorBody = 512
+
+// Error is a failed LCD or RPC request. Callers tell a route or object the node does
+// not have (NotFound) from a node that is down or overloaded (Retryable).
+type Error struct {
+	// Endpoint names the query, e.g. "lcd balance" or "rpc block_results".
+	Endpoint string
+	// URL is the request URL.
+	URL string
+	// Status is the HTTP status, 0 when no response was received.
+	Status int
+	// Retryable is set for transport failures, 429 and 5xx other than 501: the same
+	// request may succeed later. Other statuses repeat until the node or query changes.
+	Retryable bool
+	// Body is the start of the response body.
+	Body string
+	// Err is the transport error, or errRouteMissing for routes this node version
+	// does not serve.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Status == 0 {
+		return fmt.Sprintf("%s: %v", e.Endpoint, e.Err)
+	}
+	if e.Body == "" {
+		return fmt.Sprintf("%s: status %d", e.Endpoint, e.Status)
+	}
+	return fmt.Sprintf("%s: status %d: %s", e.Endpoint, e.Status, e.Body)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// NotFound reports a 404 or 501: a missing route (feature) or object, not an outage.
+func (e *Error) NotFound() bool {
+	return e.Status == http.StatusNotFound || e.Status == http.StatusNotImplemented
+}
+
+// transportError wraps a request that got no response.
+func transportError(endpoint, u string, err error) *Error {
+	return &Error{Endpoint: endpoint, URL: u, Retryable: true, Err: err}
+}
+
+// statusError builds the Error for a non-200 response, reading a body excerpt.
+func statusError(endpoint string, resp *http.Response) *Error {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	e := &Error{Endpoint: endpoint, Status: resp.StatusCode, Body: strings.TrimSpace(string(b)),
+		Retryable: resp.StatusCode == http.StatusTooManyRequests ||
+			resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented}
+	if resp.Request != nil {
+		e.URL = resp.Request.URL.String()
+	}
+	return e
+}
+
+// markRouteMissing flags a 404/501 *Error as a route this node version does not serve
+// (errors.Is(err, errRouteMissing)).
+func markRouteMissing(err error) error {
+	var e *Error
+	if errors.As(err, &e) && e.NotFound() {
+		e.Err = errRouteMissing
+	}
+	return err
+}
+
+// get GETs u and returns the response when it is a 200, its body bounded by the
+// client's response limits. Anything else is an *Error labelled endpoint, with the
+// response body closed.
+func (c *Client) get(u, endpoint string) (*http.Response, error) {
+	req, err := c.newRequest(http.MethodGet, u, endpoint, nil)
+	if err != nil {
+		return nil, transportError(endpoint, u, err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, transportError(endpoint, u, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, statusError(endpoint, resp)
+	}
+	c.guard(resp, endpoint)
+	return resp, nil
+}
+
+// IsNotFound reports whether err comes from a 404 or 501 answer: the node lacks the
+// route (an optional feature) or the object, as opposed to failing.
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.NotFound()
+}
+
+// IsRetryable reports whether err is an LCD/RPC failure that may clear by itself
+// (transport error, 429 or 5xx other than 501).
+func IsRetryable(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Retryable
+}
+
+// StatusOf returns the HTTP status of an LCD/RPC error, 0 when err carries none.
+func StatusOf(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Status
+	}
+	return 0
+}
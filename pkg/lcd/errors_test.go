@@ -0,0 +1,53 @@
+package lcd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTypedErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/cosmos/bank/v1beta1/supply/"):
+			http.Error(w, "not implemented", http.StatusNotImplemented)
+		case strings.HasPrefix(r.URL.Path, "/cosmos/bank/v1beta1/balances/"):
+			http.Error(w, "node overloaded", http.StatusServiceUnavailable)
+		case strings.HasPrefix(r.URL.Path, "/cosmos/auth/v1beta1/accounts/"):
+			http.Error(w, "invalid address", http.StatusBadRequest)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, ts.Client())
+
+	_, err := c.supplyFrom(ts.URL+"/cosmos/bank/v1beta1/supply/by_denom?denom=ulume", "ulume")
+	if !IsNotFound(err) || IsRetryable(err) || !errors.Is(err, errRouteMissing) || StatusOf(err) != 501 {
+		t.Fatalf("supply: expected a missing route, got %v", err)
+	}
+
+	_, err = c.BalanceByDenom("lumera1a", "ulume")
+	var e *Error
+	if !errors.As(err, &e) || e.Status != 503 || !e.Retryable || e.Endpoint != "lcd balance" || e.Body != "node overloaded" {
+		t.Fatalf("balance: got %#v", err)
+	}
+	if !strings.Contains(e.URL, "/cosmos/bank/v1beta1/balances/lumera1a") {
+		t.Fatalf("balance URL = %s", e.URL)
+	}
+	if requestErrors.Value("lcd balance", "5xx") == 0 || requestDuration.Count("lcd balance") == 0 {
+		t.Fatal("balance request not recorded in the LCD request metrics")
+	}
+
+	_, _, err = c.AuthAccount("lumera1a")
+	if IsNotFound(err) || IsRetryable(err) || StatusOf(err) != 400 {
+		t.Fatalf("account: expected a non-retryable 400, got %v", err)
+	}
+
+	down := NewClient("http://127.0.0.1:1", http.DefaultClient)
+	if _, err := down.CommunityPool("ulume"); !IsRetryable(err) || StatusOf(err) != 0 {
+		t.Fatalf("transport failure: expected retryable without status, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package lcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+)
+
+// ibcEscrowFromChannels sums the denom balance of every transfer channel's escrow
+// account. Used when the node has no total_escrow route.
+func (c *Client) ibcEscrowFromChannels(denom string) (string, error) {
+	addrs, err := c.TransferEscrowAddresses()
+	if err != nil {
+		return "", err
+	}
+	if len(addrs) == 0 {
+		return "0", nil
+	}
+	bals, err := c.BalancesByDenom(addrs, denom)
+	if err != nil {
+		return "", err
+	}
+	sum := new(big.Int)
+	for _, a := range addrs {
+		v, ok := new(big.Int).SetString(bals[a], 10)
+		if !ok {
+			return "", fmt.Errorf("lcd ibc escrow: bad balance %q for %s", bals[a], a)
+		}
+		sum.Add(sum, v)
+	}
+	return sum.String(), nil
+}
+
+// TransferEscrowAddresses lists the escrow account of every channel bound to the
+// transfer port.
+func (c *Client) TransferEscrowAddresses() ([]string, error) {
+	var addrs []string
+	nextKey := ""
+	for {
+		u := c.base + "/ibc/core/channel/v1/channels?pagination.limit=200"
+		if nextKey != "" {
+			u += "&pagination.key=" + url.QueryEscape(nextKey)
+		}
+		var page struct {
+			Channels []struct {
+				PortID    string `json:"port_id"`
+				ChannelID string `json:"channel_id"`
+			} `json:"channels"`
+			Pagination struct {
+				NextKey string `json:"next_key"`
+			} `json:"pagination"`
+		}
+		if err := c.getJSON(u, "lcd ibc channels", &page); err != nil {
+			return nil, err
+		}
+		for _, ch := range page.Channels {
+			if ch.PortID != "transfer" {
+				continue
+			}
+			var out struct {
+				EscrowAddress string `json:"escrow_address"`
+			}
+			eu := c.base + "/ibc/apps/transfer/v1/channels/" + url.PathEscape(ch.ChannelID) + "/ports/transfer/escrow_address"
+			if err := c.getJSON(eu, "lcd ibc escrow address", &out); err != nil {
+				return nil, err
+			}
+			addrs = append(addrs, out.EscrowAddress)
+		}
+		if page.Pagination.NextKey == "" {
+			return addrs, nil
+		}
+		nextKey = page.Pagination.NextKey
+	}
+}
+
+// getJSON GETs u and decodes a 200 response into v; other statuses become *Error
+// labelled what.
+func (c *Client) getJSON(u, what string, v any) error {
+	resp, err := c.get(u, what)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
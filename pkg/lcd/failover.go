@@ -0,0 +1,257 @@
+package lcd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+)
+
+var (
+	endpointUp = metrics.Default.NewGauge(
+		"lumera_supply_lcd_endpoint_up",
+		"Whether each configured LCD endpoint is considered healthy (1) or skipped until a probe succeeds (0).",
+		"endpoint",
+	)
+	failovers = metrics.Default.NewCounter(
+		"lumera_supply_lcd_failovers_total",
+		"LCD requests moved to the next endpoint, by the endpoint that failed them.",
+		"endpoint",
+	)
+)
+
+// errNoRetry stops a failover for a request whose body cannot be sent again.
+var errNoRetry = errors.New("request body cannot be replayed")
+
+// probePath is the cheap query recovery probes send to endpoints marked down.
+const probePath = "/cosmos/base/tendermint/v1beta1/blocks/latest"
+
+// Failover is a RoundTripper that sends LCD requests to the first healthy endpoint of
+// a list. Clients are built on the first URL (see NewClient); requests under it are
+// rewritten to the endpoint in use, other requests (RPC) pass through. A connection
+// error, timeout or 502/503/504 answer (a node or its proxy out of service) marks the
+// endpoint down and the request moves on to the next one. Any other answer, such as a
+// 404 or a 500 for one query, is returned as is: another node would answer the same. Down endpoints are skipped until Probe
+// finds them answering again; when all are down, all are tried in order.
+type Failover struct {
+	next      http.RoundTripper
+	endpoints []*endpoint
+}
+
+type endpoint struct {
+	base  string
+	label string
+
+	mu        sync.Mutex
+	down      bool
+	downSince time.Time
+	failures  int64
+	lastErr   string
+}
+
+// NewFailover wraps next (http.DefaultTransport when nil) to fail over between the
+// LCD base URLs bases, preferred in order.
+func NewFailover(bases []string, next http.RoundTripper) *Failover {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	f := &Failover{next: next}
+	for _, b := range bases {
+		e := &endpoint{base: strings.TrimRight(b, "/"), label: redactURL(b)}
+		endpointUp.Set(1, e.label)
+		f.endpoints = append(f.endpoints, e)
+	}
+	return f
+}
+
+// ParseURLs splits a comma-separated list of base URLs, dropping empty entries.
+func ParseURLs(s string) []string {
+	var out []string
+	for _, u := range strings.Split(s, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// redactURL drops credentials from u for logs, metrics and /status.
+func redactURL(u string) string {
+	p, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	p.User = nil
+	p.RawQuery = ""
+	return strings.TrimRight(p.String(), "/")
+}
+
+// order returns the endpoints to try: the healthy ones in preference order, or every
+// one when none is healthy.
+func (f *Failover) order() []*endpoint {
+	var up []*endpoint
+	for _, e := range f.endpoints {
+		e.mu.Lock()
+		if !e.down {
+			up = append(up, e)
+		}
+		e.mu.Unlock()
+	}
+	if len(up) == 0 {
+		return f.endpoints
+	}
+	return up
+}
+
+func (f *Failover) RoundTrip(r *http.Request) (*http.Response, error) {
+	primary := f.endpoints[0].base
+	rest, ok := strings.CutPrefix(r.URL.String(), primary)
+	if !ok {
+		return f.next.RoundTrip(r)
+	}
+	order := f.order()
+	var resp *http.Response
+	var err error
+	for i, e := range order {
+		req, rerr := f.rewrite(r, e.base+rest, i > 0)
+		if rerr != nil {
+			if resp == nil && err == nil {
+				return nil, rerr
+			}
+			// keep the last endpoint's answer
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		resp, err = f.next.RoundTrip(req)
+		if r.Context().Err() != nil {
+			// the caller gave up; not the endpoint's fault
+			return resp, err
+		}
+		if err == nil && !unavailable(resp.StatusCode) {
+			e.succeeded()
+			return resp, nil
+		}
+		var msg string
+		if err != nil {
+			msg = err.Error()
+		} else {
+			msg = "status " + resp.Status
+		}
+		e.failed(msg)
+		if i+1 < len(order) {
+			failovers.Inc(e.label)
+		}
+	}
+	return resp, err
+}
+
+// unavailable reports whether an answer with status code means the endpoint is out of
+// service rather than answering the query.
+func unavailable(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+// rewrite returns r aimed at u. Retries need a fresh body; a request whose body
+// cannot be replayed is not retried (errNoRetry).
+func (f *Failover) rewrite(r *http.Request, u string, retry bool) (*http.Request, error) {
+	target, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+	req := r.Clone(r.Context())
+	req.URL, req.Host = target, ""
+	if retry && r.Body != nil && r.Body != http.NoBody {
+		if r.GetBody == nil {
+			return nil, errNoRetry
+		}
+		if req.Body, err = r.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+func (e *endpoint) succeeded() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.down {
+		e.down, e.downSince = false, time.Time{}
+		endpointUp.Set(1, e.label)
+	}
+}
+
+func (e *endpoint) failed(msg string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures++
+	e.lastErr = msg
+	if !e.down {
+		e.down, e.downSince = true, time.Now()
+		endpointUp.Set(0, e.label)
+	}
+}
+
+// Probe queries every endpoint marked down and marks those that answer healthy again,
+// so traffic returns to preferred endpoints after an outage. It runs periodically.
+func (f *Failover) Probe(ctx context.Context) error {
+	for _, e := range f.endpoints {
+		e.mu.Lock()
+		down := e.down
+		e.mu.Unlock()
+		if !down {
+			continue
+		}
+		req, err := http.NewRequestWithContext(context.WithValue(ctx, endpointKey{}, "lcd failover probe"), http.MethodGet, e.base+probePath, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := f.next.RoundTrip(req)
+		if err != nil {
+			e.failed(err.Error())
+			continue
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			e.succeeded()
+		} else {
+			e.failed("status " + resp.Status)
+		}
+	}
+	return nil
+}
+
+// EndpointStatus is one failover endpoint's health, for /status.
+type EndpointStatus struct {
+	URL string `json:"url"`
+	// Up is false while requests skip the endpoint, until a probe succeeds.
+	Up        bool       `json:"up"`
+	DownSince *time.Time `json:"down_since,omitempty"`
+	// Failures counts failed requests and probes since startup.
+	Failures  int64  `json:"failures"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Endpoints reports the endpoints in preference order.
+func (f *Failover) Endpoints() []EndpointStatus {
+	out := make([]EndpointStatus, 0, len(f.endpoints))
+	for _, e := range f.endpoints {
+		e.mu.Lock()
+		st := EndpointStatus{URL: e.label, Up: !e.down, Failures: e.failures, LastError: e.lastErr}
+		if e.down {
+			t := e.downSince
+			st.DownSince = &t
+		}
+		e.mu.Unlock()
+		out = append(out, st)
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package lcd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFailover(t *testing.T) {
+	var primaryDown atomic.Bool
+	var primaryHits, backupHits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits.Add(1)
+		if primaryDown.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1"}}`))
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backupHits.Add(1)
+		if r.Method == http.MethodPost {
+			b, _ := io.ReadAll(r.Body)
+			_, _ = w.Write(b)
+			return
+		}
+		_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"2"}}`))
+	}))
+	defer backup.Close()
+
+	f := NewFailover([]string{primary.URL, backup.URL + "/"}, nil)
+	client := NewClient(primary.URL, &http.Client{Transport: f})
+
+	if amt, err := client.TotalSupplyByDenom("ulume"); err != nil || amt != "1" {
+		t.Fatalf("primary: %q %v", amt, err)
+	}
+
+	// a 5xx moves the request to the backup and marks the primary down
+	primaryDown.Store(true)
+	if amt, err := client.TotalSupplyByDenom("ulume"); err != nil || amt != "2" {
+		t.Fatalf("failover: %q %v", amt, err)
+	}
+	st := f.Endpoints()
+	if st[0].Up || st[0].DownSince == nil || st[0].Failures != 1 || st[0].LastError == "" || !st[1].Up {
+		t.Fatalf("status after failover: %+v", st)
+	}
+	// down endpoints are skipped until a probe succeeds
+	hits := primaryHits.Load()
+	if amt, err := client.TotalSupplyByDenom("ulume"); err != nil || amt != "2" || primaryHits.Load() != hits {
+		t.Fatalf("primary not skipped: %q %v", amt, err)
+	}
+	if err := f.Probe(context.Background()); err != nil || f.Endpoints()[0].Up {
+		t.Fatalf("probe of a down primary: %v %+v", err, f.Endpoints())
+	}
+	primaryDown.Store(false)
+	if err := f.Probe(context.Background()); err != nil || !f.Endpoints()[0].Up {
+		t.Fatalf("probe of a recovered primary: %v %+v", err, f.Endpoints())
+	}
+	if amt, err := client.TotalSupplyByDenom("ulume"); err != nil || amt != "1" {
+		t.Fatalf("back on primary: %q %v", amt, err)
+	}
+
+	// request bodies are replayed on the next endpoint
+	primaryDown.Store(true)
+	req, _ := http.NewRequest(http.MethodPost, primary.URL+"/rpc", strings.NewReader("payload"))
+	resp, err := f.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(b) != "payload" {
+		t.Fatalf("replayed body: %q", b)
+	}
+}
+
+func TestFailoverPassesQueryErrors(t *testing.T) {
+	var status atomic.Int32
+	var backupHits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "query failed", int(status.Load()))
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backupHits.Add(1)
+	}))
+	defer backup.Close()
+
+	f := NewFailover([]string{primary.URL, backup.URL}, nil)
+	for _, code := range []int{http.StatusNotFound, http.StatusInternalServerError, http.StatusNotImplemented} {
+		status.Store(int32(code))
+		req, _ := http.NewRequest(http.MethodGet, primary.URL+"/cosmos/bank/v1beta1/supply", nil)
+		resp, err := f.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("%d: %v", code, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != code {
+			t.Errorf("%d: got %d", code, resp.StatusCode)
+		}
+	}
+	if n := backupHits.Load(); n != 0 {
+		t.Errorf("backup hit %d times", n)
+	}
+	if st := f.Endpoints(); !st[0].Up || st[0].Failures != 0 {
+		t.Errorf("primary marked down by query errors: %+v", st[0])
+	}
+}
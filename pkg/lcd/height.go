@@ -0,0 +1,56 @@
+package lcd
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// heightTransport pins every request to a block height via x-cosmos-block-height.
+type heightTransport struct {
+	height int64
+	next   http.RoundTripper
+}
+
+func (t heightTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("x-cosmos-block-height", strconv.FormatInt(t.height, 10))
+	return t.next.RoundTrip(r)
+}
+
+// AtHeight returns a client whose queries are evaluated at height: LCD requests carry
+// x-cosmos-block-height and batched abci_query calls the height parameter.
+func (c *Client) AtHeight(height int64) *Client {
+	next := c.client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	hc := *c.client
+	hc.Transport = heightTransport{height: height, next: next}
+	cp := c.clone(&hc)
+	cp.height = height
+	return cp
+}
+
+// Height returns the height the client's queries are pinned to, 0 for the latest.
+func (c *Client) Height() int64 { return c.height }
+
+// Block returns the height and time of the block at height. When the LCD fails and an
+// RPC endpoint is configured, the CometBFT block is used instead.
+func (c *Client) Block(height int64) (int64, time.Time, error) {
+	h, t, err := c.lcdBlock(strconv.FormatInt(height, 10), "lcd block")
+	if err == nil || c.rpc == "" {
+		return h, t, err
+	}
+	rh, rt, rerr := c.RPCBlock(height)
+	if rerr != nil {
+		return 0, time.Time{}, errors.Join(err, rerr)
+	}
+	log.Printf("warn: lcd block %d failed, using rpc block: %v", height, err)
+	return rh, rt, nil
+}
+
+// Base returns the LCD base URL the client queries.
+func (c *Client) Base() string { return c.base }
@@ -0,0 +1,40 @@
+package lcd
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+)
+
+var (
+	requestDuration = metrics.Default.NewHistogram(
+		"lumera_supply_lcd_request_duration_seconds",
+		"LCD/RPC request durations until the response headers, by endpoint (e.g. \"lcd balance\", \"rpc batch\").",
+		[]float64{0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		"endpoint",
+	)
+	requestErrors = metrics.Default.NewCounter(
+		"lumera_supply_lcd_errors_total",
+		"Failed LCD/RPC requests by endpoint and kind: transport (no response), 4xx or 5xx.",
+		"endpoint", "kind",
+	)
+)
+
+// do sends req, built by newRequest, and records its duration and failure under the
+// request's endpoint.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	endpoint, _ := req.Context().Value(endpointKey{}).(string)
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	requestDuration.Observe(time.Since(start).Seconds(), endpoint)
+	switch {
+	case err != nil:
+		requestErrors.Inc(endpoint, "transport")
+	case resp.StatusCode >= 500:
+		requestErrors.Inc(endpoint, "5xx")
+	case resp.StatusCode >= 400:
+		requestErrors.Inc(endpoint, "4xx")
+	}
+	return resp, err
+}
@@ -0,0 +1,123 @@
+package lcd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+)
+
+var (
+	responsesLimited = metrics.Default.NewCounter(
+		"lumera_supply_lcd_responses_limited_total",
+		"LCD/RPC responses cut off for exceeding the body size or JSON nesting limit.",
+		"endpoint", "limit",
+	)
+	responseEncodings = metrics.Default.NewCounter(
+		"lumera_supply_lcd_responses_total",
+		"Successful LCD/RPC responses by transfer encoding: gzip (decompressed transparently) or identity.",
+		"encoding",
+	)
+)
+
+// Default response limits (see SetResponseLimits). Cosmos responses nest a dozen
+// levels at most; the largest bodies are verbose claim-list pages.
+const (
+	DefaultMaxResponseBytes = 64 << 20
+	DefaultMaxResponseDepth = 64
+)
+
+// ErrResponseLimit is wrapped by errors for responses cut off by SetResponseLimits.
+var ErrResponseLimit = errors.New("response limit exceeded")
+
+type responseLimits struct {
+	maxBytes int64
+	maxDepth int
+}
+
+// SetResponseLimits bounds the LCD/RPC response bodies the client decodes: maxBytes in
+// total and maxDepth levels of JSON objects and arrays. A response over either limit
+// fails with ErrResponseLimit instead of being buffered, so a misbehaving upstream
+// cannot exhaust memory. Values <= 0 keep the defaults.
+func (c *Client) SetResponseLimits(maxBytes int64, maxDepth int) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxResponseBytes
+	}
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxResponseDepth
+	}
+	c.limits = responseLimits{maxBytes: maxBytes, maxDepth: maxDepth}
+}
+
+// guard replaces resp.Body with a reader enforcing the client's response limits.
+func (c *Client) guard(resp *http.Response, endpoint string) {
+	if resp.Uncompressed {
+		responseEncodings.Inc(CompressionGzip)
+	} else {
+		responseEncodings.Inc("identity")
+	}
+	l := c.limits
+	if l.maxBytes <= 0 {
+		l.maxBytes = DefaultMaxResponseBytes
+	}
+	if l.maxDepth <= 0 {
+		l.maxDepth = DefaultMaxResponseDepth
+	}
+	resp.Body = &guardedBody{ReadCloser: resp.Body, endpoint: endpoint, limits: l, left: l.maxBytes}
+}
+
+// guardedBody fails reads past the size limit and tracks JSON nesting outside strings,
+// failing once it exceeds the depth limit.
+type guardedBody struct {
+	io.ReadCloser
+	endpoint string
+	limits   responseLimits
+	left     int64
+	depth    int
+	inString bool
+	escaped  bool
+	err      error
+}
+
+func (b *guardedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	// read one byte past the limit to tell a body of exactly maxBytes from a longer one
+	if int64(len(p)) > b.left+1 {
+		p = p[:b.left+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.left {
+		return 0, b.fail("size", fmt.Sprintf("more than %d bytes", b.limits.maxBytes))
+	}
+	b.left -= int64(n)
+	for i, c := range p[:n] {
+		switch {
+		case b.escaped:
+			b.escaped = false
+		case b.inString:
+			b.escaped = c == '\\'
+			b.inString = c != '"'
+		case c == '"':
+			b.inString = true
+		case c == '{' || c == '[':
+			if b.depth++; b.depth > b.limits.maxDepth {
+				return i, b.fail("depth", fmt.Sprintf("JSON nested deeper than %d levels", b.limits.maxDepth))
+			}
+		case c == '}' || c == ']':
+			b.depth--
+		}
+	}
+	return n, err
+}
+
+func (b *guardedBody) fail(limit, what string) error {
+	responsesLimited.Inc(b.endpoint, limit)
+	log.Printf("warn: %s: response cut off: %s", b.endpoint, what)
+	b.err = fmt.Errorf("%s: %w: %s", b.endpoint, ErrResponseLimit, what)
+	return b.err
+}
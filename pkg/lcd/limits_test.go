@@ -0,0 +1,47 @@
+package lcd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseLimits(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, ts.Client())
+	c.SetResponseLimits(256, 8)
+
+	body = `{"pool":[{"denom":"ulume","amount":"42"}]}`
+	if amt, err := c.CommunityPool("ulume"); err != nil || amt != "42" {
+		t.Fatalf("small response: %s, %v", amt, err)
+	}
+
+	before := responsesLimited.Value("lcd community pool", "size")
+	body = `{"pool":[` + strings.Repeat(`{"denom":"ulume","amount":"1"},`, 20) + `{}]}`
+	if _, err := c.CommunityPool("ulume"); !errors.Is(err, ErrResponseLimit) {
+		t.Fatalf("oversized response: expected ErrResponseLimit, got %v", err)
+	}
+	if got := responsesLimited.Value("lcd community pool", "size"); got != before+1 {
+		t.Fatalf("size limit counter = %v, want %v", got, before+1)
+	}
+
+	// brackets inside strings do not count towards depth
+	body = `{"pool":[{"denom":"[[[[[[[[[[{{{{{{{{","amount":"1"}]}`
+	if _, err := c.CommunityPool("ulume"); err != nil {
+		t.Fatalf("brackets in strings: %v", err)
+	}
+	body = strings.Repeat("[", 9) + strings.Repeat("]", 9)
+	if _, err := c.CommunityPool("ulume"); !errors.Is(err, ErrResponseLimit) {
+		t.Fatalf("deep response: expected ErrResponseLimit, got %v", err)
+	}
+	// derived clients keep the limits
+	if _, err := c.AtHeight(5).CommunityPool("ulume"); !errors.Is(err, ErrResponseLimit) {
+		t.Fatalf("AtHeight: expected ErrResponseLimit, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package lcd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// memoTransport answers GET requests it has already forwarded from memory. Answers
+// below 500 and 501s are kept, so missing routes and accounts are not asked for again;
+// other server errors may be transient and are.
+type memoTransport struct {
+	mu      sync.Mutex
+	answers map[string]memoAnswer
+	stats   *CallStats
+	next    http.RoundTripper
+}
+
+type memoAnswer struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func (t *memoTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Method != http.MethodGet {
+		return t.next.RoundTrip(r)
+	}
+	key := r.URL.String() + "@" + r.Header.Get("x-cosmos-block-height")
+	t.mu.Lock()
+	a, ok := t.answers[key]
+	t.mu.Unlock()
+	if ok {
+		t.stats.CacheHit()
+		return &http.Response{Status: http.StatusText(a.status), StatusCode: a.status, Proto: "HTTP/1.1", ProtoMajor: 1, ProtoMinor: 1,
+			Header: a.header.Clone(), Body: io.NopCloser(bytes.NewReader(a.body)), ContentLength: int64(len(a.body)), Request: r}, nil
+	}
+	resp, err := t.next.RoundTrip(r)
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.answers[key] = memoAnswer{status: resp.StatusCode, header: resp.Header.Clone(), body: body}
+	t.mu.Unlock()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
+
+// Memoized returns a client that makes each GET request once and answers repeats from
+// memory, for work that reads the same chain state many times over, such as
+// simulations. Pin it to a height (AtHeight) so the state cannot move underneath.
+// Repeats count as cache hits in the client's stats.
+func (c *Client) Memoized() *Client {
+	next := c.client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	hc := *c.client
+	hc.Transport = &memoTransport{answers: map[string]memoAnswer{}, stats: c.stats, next: next}
+	return c.clone(&hc)
+}
@@ -0,0 +1,45 @@
+package lcd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMemoized(t *testing.T) {
+	var calls atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Query().Get("denom") == "uerr" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1000"}}`))
+	}))
+	defer ts.Close()
+	var stats CallStats
+	c := NewClient(ts.URL, ts.Client()).WithStats(&stats).Memoized()
+
+	for i := 0; i < 3; i++ {
+		if got, err := c.TotalSupplyByDenom("ulume"); err != nil || got != "1000" {
+			t.Fatalf("supply: %q %v", got, err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("%d upstream calls, want 1", n)
+	}
+	if stats.CacheHits() != 2 {
+		t.Fatalf("cache hits %d, want 2", stats.CacheHits())
+	}
+	// another height is another answer
+	if _, err := c.AtHeight(5).TotalSupplyByDenom("ulume"); err != nil || calls.Load() != 2 {
+		t.Fatalf("pinned request: %v, %d calls", err, calls.Load())
+	}
+	// failures are asked again
+	_, _ = c.TotalSupplyByDenom("uerr")
+	_, _ = c.TotalSupplyByDenom("uerr")
+	if n := calls.Load(); n < 4 {
+		t.Fatalf("failed answer was memoized (%d calls)", n)
+	}
+}
@@ -0,0 +1,47 @@
+package lcd
+
+import (
+	"io"
+	"net/http"
+)
+
+// maxRawBody caps the body returned by Raw.
+const maxRawBody = 4 << 20
+
+// RawResponse is an upstream answer as Raw returns it.
+type RawResponse struct {
+	Status      int
+	ContentType string
+	// Body is cut at 4 MiB, and Truncated set, when the upstream sent more.
+	Body      []byte
+	Truncated bool
+}
+
+// Raw GETs path (including any query string) from the LCD through the client's own
+// request path (timeouts, stats, instrumentation, failover) and returns the upstream
+// answer whatever its status. Callers must restrict path.
+func (c *Client) Raw(path string) (RawResponse, error) {
+	const endpoint = "lcd raw"
+	u := c.base + path
+	req, err := c.newRequest(http.MethodGet, u, endpoint, nil)
+	if err != nil {
+		return RawResponse{}, transportError(endpoint, u, err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return RawResponse{}, transportError(endpoint, u, err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxRawBody+1))
+	if err != nil {
+		return RawResponse{}, transportError(endpoint, u, err)
+	}
+	out := RawResponse{Status: resp.StatusCode, ContentType: resp.Header.Get("Content-Type"), Body: b}
+	if len(b) > maxRawBody {
+		out.Body, out.Truncated = b[:maxRawBody], true
+	}
+	if out.ContentType == "" {
+		out.ContentType = http.DetectContentType(out.Body)
+	}
+	return out, nil
+}
@@ -0,0 +1,134 @@
+package lcd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// errNoRPC is returned by RPC methods when no CometBFT endpoint is configured.
+var errNoRPC = errors.New("rpc endpoint not configured")
+
+// rpcCall issues a single CometBFT JSON-RPC request and decodes its result into out.
+func (c *Client) rpcCall(method string, params, out any) error {
+	if c.rpc == "" {
+		return errNoRPC
+	}
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(http.MethodPost, c.rpc, "rpc "+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return transportError("rpc "+method, c.rpc, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return statusError("rpc "+method, resp)
+	}
+	c.guard(resp, "rpc "+method)
+	var env struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("rpc %s: %w", method, err)
+	}
+	if env.Error != nil {
+		return fmt.Errorf("rpc %s: %s %s", method, env.Error.Message, env.Error.Data)
+	}
+	return json.Unmarshal(env.Result, out)
+}
+
+// rpcStatus is the part of the CometBFT status result the client reads.
+type rpcStatus struct {
+	SyncInfo struct {
+		LatestBlockHeight   string    `json:"latest_block_height"`
+		LatestBlockTime     time.Time `json:"latest_block_time"`
+		EarliestBlockHeight string    `json:"earliest_block_height"`
+	} `json:"sync_info"`
+}
+
+// RPCStatus returns the latest height and block time reported by the RPC node.
+func (c *Client) RPCStatus() (int64, time.Time, error) {
+	var out rpcStatus
+	if err := c.rpcCall("status", map[string]any{}, &out); err != nil {
+		return 0, time.Time{}, err
+	}
+	h, err := parseInt(out.SyncInfo.LatestBlockHeight)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return h, out.SyncInfo.LatestBlockTime, nil
+}
+
+// RPCEarliestHeight returns the oldest block height the RPC node keeps.
+func (c *Client) RPCEarliestHeight() (int64, error) {
+	var out rpcStatus
+	if err := c.rpcCall("status", map[string]any{}, &out); err != nil {
+		return 0, err
+	}
+	return parseInt(out.SyncInfo.EarliestBlockHeight)
+}
+
+// RPCBlock returns the header height and time of the block at height (0 = latest).
+func (c *Client) RPCBlock(height int64) (int64, time.Time, error) {
+	params := map[string]any{}
+	if height > 0 {
+		params["height"] = strconv.FormatInt(height, 10)
+	}
+	var out struct {
+		Block struct {
+			Header struct {
+				Height string    `json:"height"`
+				Time   time.Time `json:"time"`
+			} `json:"header"`
+		} `json:"block"`
+	}
+	if err := c.rpcCall("block", params, &out); err != nil {
+		return 0, time.Time{}, err
+	}
+	h, err := parseInt(out.Block.Header.Height)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return h, out.Block.Header.Time, nil
+}
+
+// ABCIQuery runs a gRPC query path (e.g., /cosmos.bank.v1beta1.Query/Balance) with a
+// protobuf-encoded request via abci_query and returns the raw response bytes.
+func (c *Client) ABCIQuery(path string, data []byte, height int64) ([]byte, error) {
+	params := abciQueryParams{Path: path, Data: hex.EncodeToString(data)}
+	if height > 0 {
+		params.Height = strconv.FormatInt(height, 10)
+	}
+	var out struct {
+		Response struct {
+			Code  uint32 `json:"code"`
+			Log   string `json:"log"`
+			Value string `json:"value"`
+		} `json:"response"`
+	}
+	if err := c.rpcCall("abci_query", params, &out); err != nil {
+		return nil, err
+	}
+	if out.Response.Code != 0 {
+		return nil, fmt.Errorf("rpc abci_query %s: code %d: %s", path, out.Response.Code, out.Response.Log)
+	}
+	return base64.StdEncoding.DecodeString(out.Response.Value)
+}
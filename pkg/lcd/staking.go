@@ -0,0 +1,192 @@
+package lcd
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/lumera-labs/lumera-supply/pkg/bech32"
+)
+
+// Delegation is one delegation's current balance (already reduced by any slashing).
+type Delegation struct {
+	Validator string
+	Amount    string
+}
+
+// Delegations lists address's delegations in denom.
+func (c *Client) Delegations(address, denom string) ([]Delegation, error) {
+	var out struct {
+		Responses []struct {
+			Delegation struct {
+				ValidatorAddress string `json:"validator_address"`
+			} `json:"delegation"`
+			Balance struct {
+				Denom  string `json:"denom"`
+				Amount string `json:"amount"`
+			} `json:"balance"`
+		} `json:"delegation_responses"`
+	}
+	u := c.base + "/cosmos/staking/v1beta1/delegations/" + url.PathEscape(address) + "?pagination.limit=1000"
+	if err := c.getJSON(u, "lcd delegations", &out); err != nil {
+		return nil, err
+	}
+	ds := make([]Delegation, 0, len(out.Responses))
+	for _, r := range out.Responses {
+		if r.Balance.Denom == denom {
+			ds = append(ds, Delegation{Validator: r.Delegation.ValidatorAddress, Amount: r.Balance.Amount})
+		}
+	}
+	return ds, nil
+}
+
+// UnbondingTotal sums the balances of address's unbonding delegation entries.
+func (c *Client) UnbondingTotal(address string) (string, error) {
+	var out struct {
+		Responses []struct {
+			Entries []struct {
+				Balance string `json:"balance"`
+			} `json:"entries"`
+		} `json:"unbonding_responses"`
+	}
+	u := c.base + "/cosmos/staking/v1beta1/delegators/" + url.PathEscape(address) + "/unbonding_delegations?pagination.limit=1000"
+	if err := c.getJSON(u, "lcd unbonding delegations", &out); err != nil {
+		return "", err
+	}
+	sum := new(big.Int)
+	for _, r := range out.Responses {
+		for _, e := range r.Entries {
+			if v, ok := new(big.Int).SetString(e.Balance, 10); ok {
+				sum.Add(sum, v)
+			}
+		}
+	}
+	return sum.String(), nil
+}
+
+// StakingPool is the staking module's token pool: tokens bonded to active validators
+// and tokens held by unbonded or unbonding validators and delegations.
+type StakingPool struct {
+	Bonded    string
+	NotBonded string
+}
+
+// StakingPool returns the staking pool, in the bond denom (see BondDenom).
+func (c *Client) StakingPool() (StakingPool, error) {
+	var out struct {
+		Pool struct {
+			BondedTokens    string `json:"bonded_tokens"`
+			NotBondedTokens string `json:"not_bonded_tokens"`
+		} `json:"pool"`
+	}
+	if err := c.getJSON(c.base+"/cosmos/staking/v1beta1/pool", "lcd staking pool", &out); err != nil {
+		return StakingPool{}, err
+	}
+	p := StakingPool{Bonded: out.Pool.BondedTokens, NotBonded: out.Pool.NotBondedTokens}
+	if p.Bonded == "" {
+		p.Bonded = "0"
+	}
+	if p.NotBonded == "" {
+		p.NotBonded = "0"
+	}
+	return p, nil
+}
+
+// BondDenom returns the denom the staking module bonds.
+func (c *Client) BondDenom() (string, error) {
+	var out struct {
+		Params struct {
+			BondDenom string `json:"bond_denom"`
+		} `json:"params"`
+	}
+	if err := c.getJSON(c.base+"/cosmos/staking/v1beta1/params", "lcd staking params", &out); err != nil {
+		return "", err
+	}
+	if out.Params.BondDenom == "" {
+		return "", fmt.Errorf("lcd staking params: no bond_denom")
+	}
+	return out.Params.BondDenom, nil
+}
+
+// ValidatorState is the slashing-relevant state of a validator.
+type ValidatorState struct {
+	Jailed     bool
+	Tombstoned bool
+}
+
+// Validator returns valoper's jailed and tombstoned flags. Tombstoning is read from
+// the slashing module's signing info, keyed by the consensus address derived from the
+// validator's ed25519 consensus key.
+func (c *Client) Validator(valoper string) (ValidatorState, error) {
+	var out struct {
+		Validator struct {
+			Jailed          bool `json:"jailed"`
+			ConsensusPubkey struct {
+				Key string `json:"key"`
+			} `json:"consensus_pubkey"`
+		} `json:"validator"`
+	}
+	if err := c.getJSON(c.base+"/cosmos/staking/v1beta1/validators/"+url.PathEscape(valoper), "lcd validator", &out); err != nil {
+		return ValidatorState{}, err
+	}
+	st := ValidatorState{Jailed: out.Validator.Jailed}
+	if !st.Jailed {
+		// only jailed validators can be tombstoned
+		return st, nil
+	}
+	cons, err := consensusAddress(valoper, out.Validator.ConsensusPubkey.Key)
+	if err != nil {
+		return st, err
+	}
+	var info struct {
+		ValSigningInfo struct {
+			Tombstoned bool `json:"tombstoned"`
+		} `json:"val_signing_info"`
+	}
+	if err := c.getJSON(c.base+"/cosmos/slashing/v1beta1/signing_infos/"+url.PathEscape(cons), "lcd signing info", &info); err != nil {
+		return st, err
+	}
+	st.Tombstoned = info.ValSigningInfo.Tombstoned
+	return st, nil
+}
+
+// consensusAddress derives the valcons bech32 address (sha256(pubkey)[:20]) for an
+// ed25519 consensus key, using the valoper address's prefix.
+func consensusAddress(valoper, pubkeyB64 string) (string, error) {
+	hrp, _, err := bech32.Decode(valoper)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(hrp, "valoper") {
+		return "", fmt.Errorf("%s: not a validator operator address", valoper)
+	}
+	pk, err := base64.StdEncoding.DecodeString(pubkeyB64)
+	if err != nil {
+		return "", fmt.Errorf("consensus pubkey: %w", err)
+	}
+	sum := sha256.Sum256(pk)
+	return bech32.Encode(strings.TrimSuffix(hrp, "valoper")+"valcons", sum[:20])
+}
+
+// DelegatorRewards returns address's unclaimed staking rewards in denom, truncated to
+// an integer. Rewards are spendable once withdrawn, whatever the account's vesting.
+func (c *Client) DelegatorRewards(address, denom string) (string, error) {
+	var out struct {
+		Total []struct {
+			Denom  string `json:"denom"`
+			Amount string `json:"amount"`
+		} `json:"total"`
+	}
+	if err := c.getJSON(c.base+"/cosmos/distribution/v1beta1/delegators/"+url.PathEscape(address)+"/rewards", "lcd delegator rewards", &out); err != nil {
+		return "", err
+	}
+	for _, t := range out.Total {
+		if t.Denom == denom {
+			return decToIntString(t.Amount), nil
+		}
+	}
+	return "0", nil
+}
@@ -0,0 +1,30 @@
+package lcd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStakingPool(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/staking/v1beta1/pool":
+			_, _ = w.Write([]byte(`{"pool":{"not_bonded_tokens":"2500","bonded_tokens":"900000"}}`))
+		case "/cosmos/staking/v1beta1/params":
+			_, _ = w.Write([]byte(`{"params":{"unbonding_time":"1814400s","max_validators":100,"bond_denom":"ulume"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(ts.URL, ts.Client())
+
+	p, err := c.StakingPool()
+	if err != nil || p != (StakingPool{Bonded: "900000", NotBonded: "2500"}) {
+		t.Fatalf("pool: %+v %v", p, err)
+	}
+	if d, err := c.BondDenom(); err != nil || d != "ulume" {
+		t.Fatalf("bond denom: %q %v", d, err)
+	}
+}
@@ -0,0 +1,78 @@
+package lcd
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// CallStats counts upstream work done on behalf of one unit of work, such as a
+// snapshot computation (see WithStats). Methods are safe on a nil receiver.
+type CallStats struct {
+	calls, retries, cacheHits atomic.Int64
+}
+
+// Calls is the number of HTTP requests sent to LCD/RPC.
+func (s *CallStats) Calls() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.calls.Load()
+}
+
+// Retries is the number of queries re-sent after a failed attempt (e.g., per-address
+// LCD requests after an RPC batch could not answer them).
+func (s *CallStats) Retries() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.retries.Load()
+}
+
+// CacheHits is the number of lookups answered from a memo instead of upstream.
+func (s *CallStats) CacheHits() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.cacheHits.Load()
+}
+
+// CacheHit records a lookup answered without an upstream call.
+func (s *CallStats) CacheHit() {
+	if s != nil {
+		s.cacheHits.Add(1)
+	}
+}
+
+func (s *CallStats) retry() {
+	if s != nil {
+		s.retries.Add(1)
+	}
+}
+
+// countingTransport counts every request into stats.
+type countingTransport struct {
+	stats *CallStats
+	next  http.RoundTripper
+}
+
+func (t countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.stats.calls.Add(1)
+	return t.next.RoundTrip(r)
+}
+
+// WithStats returns a client that records its requests (including those of clients
+// derived from it with AtHeight) into stats.
+func (c *Client) WithStats(stats *CallStats) *Client {
+	next := c.client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	hc := *c.client
+	hc.Transport = countingTransport{stats: stats, next: next}
+	cp := c.clone(&hc)
+	cp.stats = stats
+	return cp
+}
+
+// Stats returns the stats the client records into, or nil.
+func (c *Client) Stats() *CallStats { return c.stats }
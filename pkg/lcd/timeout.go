@@ -0,0 +1,194 @@
+package lcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+)
+
+var timeoutsHit = metrics.Default.NewCounter(
+	"lumera_supply_lcd_timeouts_total",
+	"LCD/RPC requests that ran out of time, by endpoint timeout key (see -lcd-timeouts).",
+	"endpoint",
+)
+
+// DefaultEndpointTimeouts are the per-endpoint limits a Timeout starts with: claim
+// list pages and RPC batches legitimately take longer than single balance queries.
+var DefaultEndpointTimeouts = map[string]time.Duration{
+	"claim":     30 * time.Second,
+	"rpc_batch": 15 * time.Second,
+}
+
+// Timeout is a RoundTripper that bounds each request, including reading its body, like
+// http.Client.Timeout does, except that the limit can be changed while clients built on
+// it are in use. Requests issued by Client carry their endpoint, which may have its own
+// limit (see SetEndpoints).
+type Timeout struct {
+	next      http.RoundTripper
+	d         atomic.Int64
+	endpoints atomic.Pointer[map[string]time.Duration]
+}
+
+// NewTimeout wraps next (http.DefaultTransport when nil) with limit d; d <= 0 disables it.
+// The per-endpoint limits start as DefaultEndpointTimeouts.
+func NewTimeout(next http.RoundTripper, d time.Duration) *Timeout {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	t := &Timeout{next: next}
+	t.d.Store(int64(d))
+	t.SetEndpoints(DefaultEndpointTimeouts)
+	return t
+}
+
+// Set changes the limit for requests sent from now on.
+func (t *Timeout) Set(d time.Duration) { t.d.Store(int64(d)) }
+
+// Get returns the current limit.
+func (t *Timeout) Get() time.Duration { return time.Duration(t.d.Load()) }
+
+// SetEndpoints replaces the per-endpoint limits, keyed as described in timeoutKey. They
+// replace the overall limit for matching requests, whatever it is set to later.
+func (t *Timeout) SetEndpoints(m map[string]time.Duration) {
+	cp := maps.Clone(m)
+	t.endpoints.Store(&cp)
+}
+
+// Endpoints returns the per-endpoint limits.
+func (t *Timeout) Endpoints() map[string]time.Duration {
+	return maps.Clone(*t.endpoints.Load())
+}
+
+// For returns the limit applying to requests for endpoint: the longest matching key
+// (whole "_"-separated words, so "claim" covers "claim_list_claimed"), else the overall
+// limit.
+func (t *Timeout) For(endpoint string) time.Duration {
+	m := *t.endpoints.Load()
+	for k := timeoutKey(endpoint); k != ""; {
+		if d, ok := m[k]; ok {
+			return d
+		}
+		i := strings.LastIndexByte(k, '_')
+		if i < 0 {
+			break
+		}
+		k = k[:i]
+	}
+	return t.Get()
+}
+
+// timeoutKey maps an endpoint label ("lcd claim list_claimed", "rpc batch") to its
+// timeout key ("claim_list_claimed", "rpc_batch").
+func timeoutKey(endpoint string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(endpoint, "lcd "), " ", "_")
+}
+
+// ParseEndpointTimeouts parses "key=duration" pairs separated by commas, e.g.
+// "claim=45s,balance=3s", on top of DefaultEndpointTimeouts. A duration of 0 removes
+// the key, so it uses the overall limit.
+func ParseEndpointTimeouts(s string) (map[string]time.Duration, error) {
+	out := maps.Clone(DefaultEndpointTimeouts)
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("lcd timeout %q: want key=duration", kv)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || d < 0 || d > 5*time.Minute {
+			return nil, fmt.Errorf("lcd timeout %q: duration must be in [0, 5m]", kv)
+		}
+		k = strings.TrimSpace(k)
+		if d == 0 {
+			delete(out, k)
+		} else {
+			out[k] = d
+		}
+	}
+	return out, nil
+}
+
+// FormatEndpointTimeouts renders m in the ParseEndpointTimeouts format, sorted by key.
+func FormatEndpointTimeouts(m map[string]time.Duration) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + m[k].String()
+	}
+	return strings.Join(parts, ",")
+}
+
+func (t *Timeout) RoundTrip(r *http.Request) (*http.Response, error) {
+	endpoint, _ := r.Context().Value(endpointKey{}).(string)
+	d := t.For(endpoint)
+	if d <= 0 {
+		return t.next.RoundTrip(r)
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), d)
+	resp, err := t.next.RoundTrip(r.WithContext(ctx))
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			timeoutsHit.Inc(timeoutLabel(endpoint))
+		}
+		cancel()
+		return nil, err
+	}
+	// the deadline keeps covering the body until the caller closes it
+	resp.Body = &cancelBody{ReadCloser: resp.Body, ctx: ctx, cancel: cancel, endpoint: endpoint}
+	return resp, nil
+}
+
+func timeoutLabel(endpoint string) string {
+	if endpoint == "" {
+		return "other"
+	}
+	return timeoutKey(endpoint)
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	ctx      context.Context
+	cancel   context.CancelFunc
+	endpoint string
+	counted  bool
+}
+
+func (b *cancelBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && !b.counted && errors.Is(b.ctx.Err(), context.DeadlineExceeded) {
+		b.counted = true
+		timeoutsHit.Inc(timeoutLabel(b.endpoint))
+	}
+	return n, err
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// endpointKey labels a request's context with its endpoint (see newRequest).
+type endpointKey struct{}
+
+// newRequest builds a request under the client's context labelled with endpoint, so a
+// Timeout below the client can apply that endpoint's limit.
+func (c *Client) newRequest(method, u, endpoint string, body io.Reader) (*http.Request, error) {
+	return http.NewRequestWithContext(context.WithValue(c.Context(), endpointKey{}, endpoint), method, u, body)
+}
@@ -0,0 +1,69 @@
+package lcd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutAdjustable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"block":{"header":{"height":"7","time":"2026-01-01T00:00:00Z"}}}`))
+	}))
+	defer srv.Close()
+	tt := NewTimeout(srv.Client().Transport, 20*time.Millisecond)
+	c := NewClient(srv.URL, &http.Client{Transport: tt})
+
+	if _, _, err := c.LatestHeight(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	tt.Set(time.Second)
+	if h, _, err := c.LatestHeight(); err != nil || h != 7 {
+		t.Fatalf("after raising the timeout: got %d, %v", h, err)
+	}
+}
+
+func TestTimeoutPerEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"claims":[],"balance":{"denom":"ulume","amount":"1"}}`))
+	}))
+	defer srv.Close()
+	tt := NewTimeout(srv.Client().Transport, 20*time.Millisecond)
+	if d := tt.For("lcd claim list_claimed"); d != 30*time.Second {
+		t.Fatalf("default claim timeout = %s", d)
+	}
+	m, err := ParseEndpointTimeouts("claim=1s, rpc_batch=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt.SetEndpoints(m)
+	if d := tt.For("rpc batch"); d != 20*time.Millisecond {
+		t.Fatalf("removed key: rpc batch timeout = %s", d)
+	}
+	c := NewClient(srv.URL, &http.Client{Transport: tt})
+
+	if _, err := c.ClaimListClaimed(1, "ulume"); err != nil {
+		t.Fatalf("claim list under its own limit: %v", err)
+	}
+	before := timeoutsHit.Value("balance")
+	if _, err := c.BalanceByDenom("lumera1a", "ulume"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("balance: expected deadline exceeded, got %v", err)
+	}
+	if got := timeoutsHit.Value("balance"); got != before+1 {
+		t.Fatalf("timeouts counted %v, want %v", got, before+1)
+	}
+
+	for _, bad := range []string{"claim", "claim=abc", "=1s", "claim=10m"} {
+		if _, err := ParseEndpointTimeouts(bad); err == nil {
+			t.Fatalf("ParseEndpointTimeouts(%q): expected an error", bad)
+		}
+	}
+	if s := FormatEndpointTimeouts(DefaultEndpointTimeouts); s != "claim=30s,rpc_batch=15s" {
+		t.Fatalf("FormatEndpointTimeouts = %s", s)
+	}
+}
@@ -0,0 +1,88 @@
+package lcd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// TransportOptions configures the outbound HTTP transport for LCD/RPC calls.
+type TransportOptions struct {
+	// CAFile is a PEM bundle appended to the system roots (optional).
+	CAFile string
+	// InsecureSkipVerify disables TLS verification. For development only.
+	InsecureSkipVerify bool
+	// DNSRefresh re-resolves upstream hostnames at this interval and dials their
+	// addresses healthy-first (see dns.go). Zero keeps the default dialer.
+	DNSRefresh time.Duration
+	// Context stops the background re-resolution when done. Nil runs it for the life
+	// of the process.
+	Context context.Context
+	// Compression is the Accept-Encoding requested from upstreams: CompressionGzip
+	// (the default when empty) or CompressionNone for nodes or proxies that mishandle it.
+	Compression string
+}
+
+// Accept-Encoding settings for TransportOptions.Compression.
+const (
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
+// NewTransport returns a transport that honors HTTPS_PROXY / HTTP_PROXY / NO_PROXY
+// and trusts the extra CAs in opt.CAFile. With gzip, responses are requested
+// compressed and decompressed transparently; response limits (see SetResponseLimits)
+// apply to the decompressed body.
+func NewTransport(opt TransportOptions) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	switch opt.Compression {
+	case "", CompressionGzip:
+		t.DisableCompression = false
+	case CompressionNone:
+		t.DisableCompression = true
+	default:
+		return nil, fmt.Errorf("compression %q: want %s or %s", opt.Compression, CompressionGzip, CompressionNone)
+	}
+	if opt.CAFile != "" || opt.InsecureSkipVerify {
+		tc, err := tlsConfig(opt)
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig = tc
+	}
+	if opt.DNSRefresh > 0 {
+		ctx := opt.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		d := newDNSDialer(opt.DNSRefresh)
+		t.DialContext = d.DialContext
+		go d.run(ctx, t)
+	}
+	return t, nil
+}
+
+// tlsConfig trusts the extra CAs in opt.CAFile on top of the system roots.
+func tlsConfig(opt TransportOptions) (*tls.Config, error) {
+	tc := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: opt.InsecureSkipVerify}
+	if opt.CAFile != "" {
+		pem, err := os.ReadFile(opt.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca file %s: no PEM certificates found", opt.CAFile)
+		}
+		tc.RootCAs = pool
+	}
+	return tc, nil
+}
@@ -0,0 +1,137 @@
+package lcd
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewTransport_CAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"block":{"header":{"height":"7","time":"2025-01-01T00:00:00Z"}}}`))
+	}))
+	defer srv.Close()
+
+	// untrusted by default
+	plain, err := NewTransport(TransportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewClient(srv.URL, &http.Client{Transport: plain, Timeout: 5 * time.Second}).LatestHeight(); err == nil {
+		t.Fatal("expected TLS verification failure without CA")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tr, err := NewTransport(TransportOptions{CAFile: caFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, _, err := NewClient(srv.URL, &http.Client{Transport: tr, Timeout: 5 * time.Second}).LatestHeight(); err != nil || h != 7 {
+		t.Fatalf("LatestHeight with CA: %d, %v", h, err)
+	}
+
+	if _, err := NewTransport(TransportOptions{CAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Fatal("expected error for missing CA file")
+	}
+}
+
+func TestDNSDialer_HealthyFirst(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	d := newDNSDialer(time.Minute)
+	// addresses are sorted, so the unreachable 127.0.0.0 is dialed first
+	d.lookup = func(context.Context, string) ([]string, error) { return []string{"127.0.0.1", "127.0.0.0"}, nil }
+	conn, err := d.DialContext(context.Background(), "tcp", net.JoinHostPort("lcd.example", port))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	conn.Close()
+	got, _ := d.candidates(context.Background(), "lcd.example")
+	if got[0] != "127.0.0.1" {
+		t.Fatalf("failed address should be tried last, got %v", got)
+	}
+}
+
+func TestDNSDialer_RunStops(t *testing.T) {
+	d := newDNSDialer(time.Millisecond)
+	var lookups atomic.Int32
+	d.lookup = func(context.Context, string) ([]string, error) {
+		lookups.Add(1)
+		return []string{"127.0.0.1"}, nil
+	}
+	if _, err := d.resolve(context.Background(), "lcd.example"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.run(ctx, &http.Transport{})
+		close(done)
+	}()
+	for lookups.Load() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not stop after its context was cancelled")
+	}
+}
+
+func TestNewTransport_Compression(t *testing.T) {
+	var gotEncoding atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEncoding.Store(r.Header.Get("Accept-Encoding"))
+		body := []byte(`{"block":{"header":{"height":"7","time":"2025-01-01T00:00:00Z"}}}`)
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_, _ = w.Write(body)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		_, _ = zw.Write(body)
+		_ = zw.Close()
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct{ compression, encoding string }{
+		{"", "gzip"},
+		{CompressionGzip, "gzip"},
+		{CompressionNone, ""},
+	} {
+		tr, err := NewTransport(TransportOptions{Compression: tc.compression})
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := responseEncodings.Value("gzip")
+		h, _, err := NewClient(srv.URL, &http.Client{Transport: tr, Timeout: 5 * time.Second}).LatestHeight()
+		if err != nil || h != 7 {
+			t.Fatalf("%q: LatestHeight = %d, %v", tc.compression, h, err)
+		}
+		if got := gotEncoding.Load().(string); got != tc.encoding {
+			t.Fatalf("%q: Accept-Encoding = %q, want %q", tc.compression, got, tc.encoding)
+		}
+		if counted := responseEncodings.Value("gzip") - before; (counted == 1) != (tc.encoding == "gzip") {
+			t.Fatalf("%q: gzip responses counted %v", tc.compression, counted)
+		}
+	}
+	if _, err := NewTransport(TransportOptions{Compression: "br"}); err == nil {
+		t.Fatal("expected an error for an unsupported compression")
+	}
+}
@@ -0,0 +1,81 @@
+// Package logging filters standard library log output by level. The level of a line is
+// taken from the message prefix used throughout the service: "debug: " and "warn: ";
+// any other message is info. All standard library.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// Level orders log messages by severity.
+type Level int32
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+)
+
+var names = [...]string{Debug: "debug", Info: "info", Warn: "warn"}
+
+func (l Level) String() string {
+	if l < Debug || l > Warn {
+		return fmt.Sprintf("level(%d)", int32(l))
+	}
+	return names[l]
+}
+
+// ParseLevel parses "debug", "info" or "warn".
+func ParseLevel(s string) (Level, error) {
+	for l, n := range names {
+		if s == n {
+			return Level(l), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid log level %q (want debug, info or warn)", s)
+}
+
+var current atomic.Int32
+
+func init() { current.Store(int32(Info)) }
+
+// SetLevel sets the minimum level written by Filter writers.
+func SetLevel(l Level) { current.Store(int32(l)) }
+
+// CurrentLevel returns the minimum level written.
+func CurrentLevel() Level { return Level(current.Load()) }
+
+// Filter returns a writer for log.SetOutput that drops lines below the current level.
+// The log package writes one line per call, so each Write is one message.
+func Filter(w io.Writer) io.Writer { return filter{w} }
+
+type filter struct{ w io.Writer }
+
+func (f filter) Write(p []byte) (int, error) {
+	if levelOf(string(p)) < CurrentLevel() {
+		return len(p), nil
+	}
+	return f.w.Write(p)
+}
+
+// levelOf classifies a log line, skipping the date and time fields the log package
+// prepends.
+func levelOf(line string) Level {
+	for {
+		field, rest, ok := strings.Cut(line, " ")
+		if !ok || field == "" || strings.Trim(field, "0123456789/:.") != "" {
+			break
+		}
+		line = rest
+	}
+	switch {
+	case strings.HasPrefix(line, "debug: "):
+		return Debug
+	case strings.HasPrefix(line, "warn: "):
+		return Warn
+	}
+	return Info
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	defer SetLevel(CurrentLevel())
+	var buf bytes.Buffer
+	l := log.New(Filter(&buf), "", log.LstdFlags|log.Lmicroseconds)
+
+	cases := []struct {
+		level Level
+		msg   string
+		want  bool
+	}{
+		{Info, "debug: refreshed", false},
+		{Info, "listening on :8080", true},
+		{Info, "warn: prune history: disk full", true},
+		{Warn, "listening on :8080", false},
+		{Warn, "refresher error: warn: nested", false},
+		{Warn, "warn: prune history: disk full", true},
+		{Debug, "debug: refreshed", true},
+	}
+	for _, c := range cases {
+		buf.Reset()
+		SetLevel(c.level)
+		l.Print(c.msg)
+		if got := buf.Len() > 0; got != c.want {
+			t.Errorf("level %s, %q: written=%v, want %v", c.level, c.msg, got, c.want)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, n := range []string{"debug", "info", "warn"} {
+		l, err := ParseLevel(n)
+		if err != nil || l.String() != n {
+			t.Errorf("ParseLevel(%q) = %v, %v", n, l, err)
+		}
+	}
+	if _, err := ParseLevel("trace"); err == nil {
+		t.Error("ParseLevel(trace): want error")
+	}
+}
@@ -0,0 +1,351 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Minimal Prometheus text-format (v0.0.4) registry, also served as OpenMetrics 1.0 with
+// exemplars to scrapers asking for it. All standard library.
+
+type collector interface {
+	// write renders the collector; om selects OpenMetrics, which adds exemplars.
+	write(w io.Writer, om bool)
+}
+
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+func NewRegistry() *Registry { return &Registry{} }
+
+// Default is the process-wide registry served at /metrics.
+var Default = NewRegistry()
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	r.collectors = append(r.collectors, c)
+	r.mu.Unlock()
+}
+
+// Write writes all registered metrics in Prometheus text format.
+func (r *Registry) Write(w io.Writer) { r.write(w, false) }
+
+// WriteOpenMetrics writes all registered metrics in OpenMetrics text format, including
+// exemplars.
+func (r *Registry) WriteOpenMetrics(w io.Writer) {
+	r.write(w, true)
+	io.WriteString(w, "# EOF\n")
+}
+
+func (r *Registry) write(w io.Writer, om bool) {
+	r.mu.Lock()
+	cs := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+	for _, c := range cs {
+		c.write(w, om)
+	}
+}
+
+// Handler serves the registry in Prometheus text format, or as OpenMetrics when the
+// scraper accepts it (Prometheus does once exemplar storage is enabled).
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.Header.Get("Accept"), "application/openmetrics-text") {
+			w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+			r.WriteOpenMetrics(w)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// Exemplar links a sample to an external reference, such as the snapshot a refresh
+// produced. OpenMetrics only allows exemplars on counters and histogram buckets.
+type Exemplar struct {
+	Labels map[string]string
+	Value  float64
+	Time   time.Time
+}
+
+// String renders the exemplar as it follows a sample: # {a="x"} value timestamp.
+func (e Exemplar) String() string {
+	names := make([]string, 0, len(e.Labels))
+	for n := range e.Labels {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(" # {")
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, n, escapeLabel(e.Labels[n]))
+	}
+	b.WriteString("} ")
+	b.WriteString(formatFloat(e.Value))
+	if !e.Time.IsZero() {
+		fmt.Fprintf(&b, " %.3f", float64(e.Time.UnixMilli())/1000)
+	}
+	return b.String()
+}
+
+// Counter is a monotonically increasing value, optionally partitioned by labels.
+type Counter struct {
+	name, help string
+	labels     []string
+	mu         sync.Mutex
+	vals       map[string]float64
+	exemplars  map[string]Exemplar
+}
+
+// NewCounter registers a counter on r. Label values are passed positionally to Inc/Add.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, labels: labels, vals: map[string]float64{}, exemplars: map[string]Exemplar{}}
+	r.register(c)
+	return c
+}
+
+// AddExemplar adds v and records ex (its Value defaults to v) as the series' exemplar.
+func (c *Counter) AddExemplar(v float64, ex Exemplar, labelValues ...string) {
+	if ex.Value == 0 {
+		ex.Value = v
+	}
+	if ex.Time.IsZero() {
+		ex.Time = time.Now()
+	}
+	k := labelKey(c.labels, labelValues)
+	c.mu.Lock()
+	c.vals[k] += v
+	c.exemplars[k] = ex
+	c.mu.Unlock()
+}
+
+func (c *Counter) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+func (c *Counter) Add(v float64, labelValues ...string) {
+	k := labelKey(c.labels, labelValues)
+	c.mu.Lock()
+	c.vals[k] += v
+	c.mu.Unlock()
+}
+
+// Value returns the current value for the given label values.
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.vals[labelKey(c.labels, labelValues)]
+}
+
+func (c *Counter) write(w io.Writer, om bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !om {
+		writeSeries(w, c.name, c.help, "counter", c.vals, nil)
+		return
+	}
+	// OpenMetrics names the family without the _total suffix its samples carry
+	family := strings.TrimSuffix(c.name, "_total")
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", family, c.help, family)
+	writeSamples(w, family+"_total", c.vals, c.exemplars)
+}
+
+// Gauge is a value that can go up and down, optionally partitioned by labels.
+type Gauge struct {
+	name, help string
+	labels     []string
+	mu         sync.Mutex
+	vals       map[string]float64
+}
+
+// NewGauge registers a gauge on r. Label values are passed positionally to Set.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{name: name, help: help, labels: labels, vals: map[string]float64{}}
+	r.register(g)
+	return g
+}
+
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	k := labelKey(g.labels, labelValues)
+	g.mu.Lock()
+	g.vals[k] = v
+	g.mu.Unlock()
+}
+
+// Value returns the current value for the given label values.
+func (g *Gauge) Value(labelValues ...string) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.vals[labelKey(g.labels, labelValues)]
+}
+
+func (g *Gauge) write(w io.Writer, _ bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	writeSeries(w, g.name, g.help, "gauge", g.vals, nil)
+}
+
+// Histogram counts observations into cumulative buckets, optionally partitioned by labels.
+type Histogram struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+	mu         sync.Mutex
+	series     map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts    []uint64 // per bucket, non-cumulative; the last is +Inf
+	exemplars []*Exemplar
+	sum       float64
+	count     uint64
+}
+
+// NewHistogram registers a histogram with the given upper bucket bounds (ascending; +Inf
+// is implied) on r. Label values are passed positionally to Observe.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{name: name, help: help, labels: labels, buckets: buckets, series: map[string]*histogramSeries{}}
+	r.register(h)
+	return h
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) { h.observe(v, nil, labelValues) }
+
+// ObserveExemplar observes v and records ex (with Value v) on v's bucket.
+func (h *Histogram) ObserveExemplar(v float64, ex Exemplar, labelValues ...string) {
+	ex.Value = v
+	if ex.Time.IsZero() {
+		ex.Time = time.Now()
+	}
+	h.observe(v, &ex, labelValues)
+}
+
+func (h *Histogram) observe(v float64, ex *Exemplar, labelValues []string) {
+	k := labelKey(h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.series[k]
+	if s == nil {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets)+1), exemplars: make([]*Exemplar, len(h.buckets)+1)}
+		h.series[k] = s
+	}
+	i := sort.SearchFloat64s(h.buckets, v) // first bound >= v
+	s.counts[i]++
+	if ex != nil {
+		s.exemplars[i] = ex
+	}
+	s.sum += v
+	s.count++
+}
+
+// Count returns the number of observations for the given label values.
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s := h.series[labelKey(h.labels, labelValues)]; s != nil {
+		return s.count
+	}
+	return 0
+}
+
+func (h *Histogram) write(w io.Writer, om bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := h.series[k]
+		var cum uint64
+		for i, n := range s.counts {
+			cum += n
+			le := "+Inf"
+			if i < len(h.buckets) {
+				le = formatFloat(h.buckets[i])
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d", h.name, withLabel(k, "le", le), cum)
+			if om && s.exemplars[i] != nil {
+				io.WriteString(w, s.exemplars[i].String())
+			}
+			io.WriteString(w, "\n")
+		}
+		fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", h.name, k, formatFloat(s.sum), h.name, k, s.count)
+	}
+}
+
+func writeSeries(w io.Writer, name, help, typ string, vals map[string]float64, exemplars map[string]Exemplar) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	writeSamples(w, name, vals, exemplars)
+}
+
+func writeSamples(w io.Writer, name string, vals map[string]float64, exemplars map[string]Exemplar) {
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s%s %s", name, k, formatFloat(vals[k]))
+		if ex, ok := exemplars[k]; ok {
+			io.WriteString(w, ex.String())
+		}
+		io.WriteString(w, "\n")
+	}
+}
+
+// withLabel adds name="value" to a rendered label set.
+func withLabel(key, name, value string) string {
+	l := name + `="` + escapeLabel(value) + `"`
+	if key == "" {
+		return "{" + l + "}"
+	}
+	return key[:len(key)-1] + "," + l + "}"
+}
+
+// labelKey renders {a="x",b="y"}; missing values are empty strings.
+func labelKey(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		b.WriteString(n)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(v))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func escapeLabel(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+func formatFloat(v float64) string {
+	if v == float64(int64(v)) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%g", v)
+}
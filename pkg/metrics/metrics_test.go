@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenMetricsExemplars(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("snapshots_total", "Snapshots.", "denom")
+	h := r.NewHistogram("refresh_seconds", "Refreshes.", []float64{1, 5})
+	at := time.Unix(1700000000, 0)
+	c.AddExemplar(1, Exemplar{Labels: map[string]string{"etag": "abc", "height": "7"}, Time: at}, "ulume")
+	h.Observe(0.5)
+	h.ObserveExemplar(2, Exemplar{Labels: map[string]string{"etag": "abc"}, Time: at})
+
+	var om bytes.Buffer
+	r.WriteOpenMetrics(&om)
+	for _, want := range []string{
+		"# TYPE snapshots counter\n",
+		`snapshots_total{denom="ulume"} 1 # {etag="abc",height="7"} 1 1700000000.000` + "\n",
+		`refresh_seconds_bucket{le="1"} 1` + "\n",
+		`refresh_seconds_bucket{le="5"} 2 # {etag="abc"} 2 1700000000.000` + "\n",
+		`refresh_seconds_bucket{le="+Inf"} 2` + "\n",
+		"refresh_seconds_sum 2.5\nrefresh_seconds_count 2\n",
+	} {
+		if !strings.Contains(om.String(), want) {
+			t.Errorf("OpenMetrics output lacks %q:\n%s", want, om.String())
+		}
+	}
+	if !strings.HasSuffix(om.String(), "# EOF\n") {
+		t.Error("OpenMetrics output must end with # EOF")
+	}
+
+	// the Prometheus text format has no exemplars
+	var text bytes.Buffer
+	r.Write(&text)
+	if strings.Contains(text.String(), " # {") || !strings.Contains(text.String(), `snapshots_total{denom="ulume"} 1`+"\n") {
+		t.Errorf("unexpected text output:\n%s", text.String())
+	}
+}
@@ -0,0 +1,90 @@
+package mockchain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Change is an update to the chain state between blocks, e.g. a scenario step.
+type Change struct {
+	// Accounts are added, or replace the account with the same address (module
+	// accounts: the same name).
+	Accounts []Account `json:"accounts,omitempty"`
+	// Balances sets balances by address or module account name.
+	Balances map[string]string `json:"balances,omitempty"`
+	// Claims are added; a zero Time is the latest block time.
+	Claims []Claim `json:"claims,omitempty"`
+	// Supply, when set, fixes the total supply; otherwise a state without one keeps
+	// it the sum of balances and the IBC escrow.
+	Supply        *string `json:"supply,omitempty"`
+	IBCEscrow     *string `json:"ibc_escrow,omitempty"`
+	CommunityPool *string `json:"community_pool,omitempty"`
+}
+
+// Apply changes the state. A change leaving an invalid state is rejected and the state
+// kept as it was.
+func (s *Server) Apply(ch Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := json.Marshal(s.st)
+	if err != nil {
+		return err
+	}
+	var next State
+	if err := json.Unmarshal(b, &next); err != nil {
+		return err
+	}
+	next.autoSupply = s.st.autoSupply
+
+	for _, a := range ch.Accounts {
+		i := next.find(a.Address, a.Name)
+		if i < 0 {
+			next.Accounts = append(next.Accounts, a)
+			continue
+		}
+		if a.Address == "" {
+			a.Address = next.Accounts[i].Address
+		}
+		next.Accounts[i] = a
+	}
+	for key, bal := range ch.Balances {
+		i := next.find(key, key)
+		if i < 0 {
+			return fmt.Errorf("balances: no account %q", key)
+		}
+		next.Accounts[i].Balance = bal
+	}
+	_, now := s.latest()
+	for _, c := range ch.Claims {
+		if c.Time.IsZero() {
+			c.Time = now
+		}
+		next.Claims = append(next.Claims, c)
+	}
+	if ch.Supply != nil {
+		next.Supply, next.autoSupply = *ch.Supply, false
+	}
+	if ch.IBCEscrow != nil {
+		next.IBCEscrow = *ch.IBCEscrow
+	}
+	if ch.CommunityPool != nil {
+		next.CommunityPool = *ch.CommunityPool
+	}
+	if err := next.normalize(); err != nil {
+		return err
+	}
+	s.st = &next
+	s.index()
+	return nil
+}
+
+// find returns the index of the account with address addr, or of the module account
+// named name, or -1.
+func (st *State) find(addr, name string) int {
+	for i, a := range st.Accounts {
+		if addr != "" && a.Address == addr || name != "" && a.Type == TypeModule && a.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,155 @@
+package mockchain
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+)
+
+func newMock(t *testing.T, st *State) (*Server, *httptest.Server) {
+	t.Helper()
+	m, err := New(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(m)
+	t.Cleanup(ts.Close)
+	return m, ts
+}
+
+func TestMockServesLCD(t *testing.T) {
+	now := time.Now()
+	m, ts := newMock(t, Default(now, 10))
+	c := lcd.NewClient(ts.URL, ts.Client())
+
+	if h, _, err := c.LatestHeight(); err != nil || h < 1000 {
+		t.Fatalf("height: %d %v", h, err)
+	}
+	total, err := c.TotalSupplyByDenom("ulume")
+	if err != nil || total != m.State().Supply {
+		t.Fatalf("supply: %q %v, want %s", total, err, m.State().Supply)
+	}
+	if addr, err := c.ModuleAddressByName("claim"); err != nil || addr != Address("lumera", "claim") {
+		t.Fatalf("claim module: %q %v", addr, err)
+	}
+	if md, err := c.DenomMetadata("ulume"); err != nil || md.Display != "lume" || md.Decimals != 6 {
+		t.Fatalf("metadata: %+v %v", md, err)
+	}
+
+	// every vesting type answers with its @type and a spendable balance net of locks
+	for name, typ := range map[string]string{"mock/delayed": "DelayedVestingAccount", "mock/continuous": "ContinuousVestingAccount", "mock/periodic": "PeriodicVestingAccount", "mock/permanent": "PermanentLockedAccount"} {
+		addr := Address("lumera", name)
+		if _, got, err := c.AuthAccount(addr); err != nil || !strings.HasSuffix(got, typ) {
+			t.Fatalf("%s: type %q %v", name, got, err)
+		}
+		bal, _ := c.BalanceByDenom(addr, "ulume")
+		spendable, _ := c.SpendableBalance(addr, "ulume")
+		if spendable == bal {
+			t.Fatalf("%s: spendable %s equals balance", name, spendable)
+		}
+	}
+	if _, _, err := c.AuthAccount(Address("lumera", "nobody")); lcd.StatusOf(err) != http.StatusNotFound {
+		t.Fatalf("unknown account: %v", err)
+	}
+
+	if p, err := c.StakingPool(); err != nil || p.Bonded != "80000000000000" || p.NotBonded != "1200000000000" {
+		t.Fatalf("staking pool: %+v %v", p, err)
+	}
+
+	recs, err := c.ClaimListClaimed(1, "ulume")
+	if err != nil || len(recs) != 3 || recs[0].Time == nil || recs[0].Amount != "1000000000" {
+		t.Fatalf("claims: %+v %v", recs, err)
+	}
+}
+
+func TestMockClaimPagination(t *testing.T) {
+	_, ts := newMock(t, Default(time.Now(), 20))
+	type page struct {
+		Claims     []map[string]any `json:"claims"`
+		Pagination struct {
+			NextKey *string `json:"next_key"`
+			Total   string  `json:"total"`
+		} `json:"pagination"`
+	}
+	get := func(query string) page {
+		t.Helper()
+		resp, err := http.Get(ts.URL + claimPrefix + "/list_claimed/2?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var p page
+		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	var seen int
+	p := get("pagination.limit=2&pagination.count_total=true")
+	if p.Pagination.Total != "5" {
+		t.Fatalf("total: %q", p.Pagination.Total)
+	}
+	for {
+		seen += len(p.Claims)
+		if p.Pagination.NextKey == nil {
+			break
+		}
+		p = get("pagination.limit=2&pagination.key=" + *p.Pagination.NextKey)
+	}
+	if seen != 5 {
+		t.Fatalf("paged %d records, want 5", seen)
+	}
+	if p := get("pagination.offset=4"); len(p.Claims) != 1 || p.Pagination.NextKey != nil {
+		t.Fatalf("offset page: %+v", p)
+	}
+}
+
+func TestMockWithPolicy(t *testing.T) {
+	pol, err := policy.Load("../../policy.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := Default(time.Now(), 8)
+	st.AddPolicy(pol, time.Now())
+	_, ts := newMock(t, st)
+
+	snap, err := supply.NewComputer(lcd.NewClient(ts.URL, ts.Client()), pol).ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cohorts := map[string]string{}
+	for _, co := range snap.NonCirculating.Cohorts {
+		cohorts[co.Name] = co.Amount
+	}
+	for _, name := range []string{"ibc_escrow", "community_pool", "foundation_genesis", "claim_delayed"} {
+		if a := cohorts[name]; a == "" || a == "0" {
+			t.Errorf("cohort %s: %q (have %v)", name, a, cohorts)
+		}
+	}
+	if snap.Circulating == "" || snap.Circulating == "0" || snap.Circulating == snap.Total {
+		t.Fatalf("circulating %s of %s", snap.Circulating, snap.Total)
+	}
+}
+
+func TestStateValidation(t *testing.T) {
+	for name, st := range map[string]*State{
+		"no denom":       {},
+		"unknown type":   {Denom: "ulume", Accounts: []Account{{Address: "a", Type: "vesting"}}},
+		"bad amount":     {Denom: "ulume", Accounts: []Account{{Address: "a", Type: TypeBase, Balance: "1.5"}}},
+		"duplicate":      {Denom: "ulume", Accounts: []Account{{Address: "a", Type: TypeBase}, {Address: "a", Type: TypeBase}}},
+		"bad tier":       {Denom: "ulume", Claims: []Claim{{Address: "a", Tier: 5, Amount: "1"}}},
+		"bad block time": {Denom: "ulume", BlockTime: "fast"},
+	} {
+		if _, err := New(st); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package mockchain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/vesting"
+)
+
+// claimPrefix is the claim module route prefix the mock serves (lcd.DefaultClaimPrefixes).
+const claimPrefix = "/LumeraProtocol/lumera/claim"
+
+// defaultPageLimit is the page size of claim lists paged by pagination.key or
+// pagination.offset without a pagination.limit.
+const defaultPageLimit = 100
+
+// Server is the fake LCD. A block follows every BlockTime of wall time, or only on
+// Advance once frozen; Apply changes the state between blocks.
+type Server struct {
+	mu        sync.RWMutex
+	st        *State
+	genesis   time.Time // block time at st.Height
+	blockTime time.Duration
+	started   time.Time
+	offset    time.Duration
+	frozen    bool
+	accounts  map[string]*Account
+	modules   map[string]*Account
+	claims    map[int][]Claim
+	ve        *vesting.Engine
+}
+
+// New validates st, fills its defaults and serves it from now on.
+func New(st *State) (*Server, error) {
+	if err := st.normalize(); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	s := &Server{st: st, genesis: now, started: now, ve: vesting.NewEngine()}
+	if st.Time != nil {
+		s.genesis = st.Time.UTC()
+	}
+	s.index()
+	return s, nil
+}
+
+// index rebuilds the lookups from s.st.
+func (s *Server) index() {
+	s.blockTime, _ = time.ParseDuration(s.st.BlockTime)
+	s.accounts, s.modules, s.claims = map[string]*Account{}, map[string]*Account{}, map[int][]Claim{}
+	for i := range s.st.Accounts {
+		a := &s.st.Accounts[i]
+		s.accounts[a.Address] = a
+		if a.Type == TypeModule {
+			s.modules[a.Name] = a
+		}
+	}
+	for _, c := range s.st.Claims {
+		s.claims[c.Tier] = append(s.claims[c.Tier], c)
+	}
+}
+
+// State returns the served state, with defaults and derived accounts filled in. It
+// must not be modified; see Apply.
+func (s *Server) State() *State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.st
+}
+
+// Freeze stops the chain at its current block: from now on only Advance adds blocks.
+func (s *Server) Freeze() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.frozen {
+		s.offset += time.Since(s.started)
+		s.frozen = true
+	}
+}
+
+// Advance moves the chain d ahead, adding the blocks produced in d.
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset += d
+}
+
+// Latest returns the latest block's height and time.
+func (s *Server) Latest() (int64, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.latest()
+}
+
+func (s *Server) latest() (int64, time.Time) {
+	elapsed := s.offset
+	if !s.frozen {
+		elapsed += time.Since(s.started)
+	}
+	n := int64(elapsed / s.blockTime)
+	return s.st.Height + n, s.blockAt(s.st.Height + n)
+}
+
+func (s *Server) blockAt(h int64) time.Time {
+	return s.genesis.Add(time.Duration(h-s.st.Height) * s.blockTime)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, 12, "method not allowed")
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	latest, _ := s.latest()
+	path, q := r.URL.Path, r.URL.Query()
+	switch {
+	case path == "/cosmos/base/tendermint/v1beta1/blocks/latest":
+		s.writeBlock(w, latest)
+	case strings.HasPrefix(path, "/cosmos/base/tendermint/v1beta1/blocks/"):
+		h, err := strconv.ParseInt(strings.TrimPrefix(path, "/cosmos/base/tendermint/v1beta1/blocks/"), 10, 64)
+		if err != nil || h < 1 || h > latest {
+			writeError(w, http.StatusBadRequest, 3, "requested block height is bigger than the chain length")
+			return
+		}
+		s.writeBlock(w, h)
+	case path == "/cosmos/bank/v1beta1/supply/by_denom":
+		writeJSON(w, map[string]any{"amount": s.coin(q.Get("denom"), s.supplyOf(q.Get("denom")))})
+	case path == "/cosmos/bank/v1beta1/supply":
+		writeJSON(w, map[string]any{"supply": []any{s.coin(s.st.Denom, s.st.Supply)}, "pagination": map[string]any{"next_key": nil, "total": "1"}})
+	case strings.HasPrefix(path, "/cosmos/bank/v1beta1/supply/"):
+		// the route nodes before SDK 0.46 serve
+		d := strings.TrimPrefix(path, "/cosmos/bank/v1beta1/supply/")
+		writeJSON(w, map[string]any{"amount": s.coin(d, s.supplyOf(d))})
+	case strings.HasPrefix(path, "/cosmos/bank/v1beta1/denoms_metadata/"):
+		s.writeMetadata(w, strings.TrimPrefix(path, "/cosmos/bank/v1beta1/denoms_metadata/"))
+	case path == "/cosmos/bank/v1beta1/denoms_metadata_by_query_string":
+		s.writeMetadata(w, q.Get("denom"))
+	case strings.HasPrefix(path, "/cosmos/bank/v1beta1/balances/") && strings.HasSuffix(path, "/by_denom"):
+		addr := strings.TrimSuffix(strings.TrimPrefix(path, "/cosmos/bank/v1beta1/balances/"), "/by_denom")
+		writeJSON(w, map[string]any{"balance": s.coin(q.Get("denom"), s.balance(addr, q.Get("denom"), false))})
+	case strings.HasPrefix(path, "/cosmos/bank/v1beta1/spendable_balances/") && strings.HasSuffix(path, "/by_denom"):
+		addr := strings.TrimSuffix(strings.TrimPrefix(path, "/cosmos/bank/v1beta1/spendable_balances/"), "/by_denom")
+		writeJSON(w, map[string]any{"balance": s.coin(q.Get("denom"), s.balance(addr, q.Get("denom"), true))})
+	case path == "/cosmos/auth/v1beta1/module_accounts":
+		names := make([]string, 0, len(s.modules))
+		for n := range s.modules {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		list := make([]any, 0, len(names))
+		for _, n := range names {
+			list = append(list, s.accountJSON(s.modules[n]))
+		}
+		writeJSON(w, map[string]any{"accounts": list})
+	case strings.HasPrefix(path, "/cosmos/auth/v1beta1/module_accounts/"):
+		a := s.modules[strings.TrimPrefix(path, "/cosmos/auth/v1beta1/module_accounts/")]
+		if a == nil {
+			writeError(w, http.StatusNotFound, 5, "module account not found")
+			return
+		}
+		writeJSON(w, map[string]any{"account": s.accountJSON(a)})
+	case strings.HasPrefix(path, "/cosmos/auth/v1beta1/accounts/"):
+		a := s.accounts[strings.TrimPrefix(path, "/cosmos/auth/v1beta1/accounts/")]
+		if a == nil {
+			writeError(w, http.StatusNotFound, 5, "account not found")
+			return
+		}
+		writeJSON(w, map[string]any{"account": s.accountJSON(a)})
+	case strings.HasPrefix(path, "/ibc/apps/transfer/v1/denoms/") && strings.HasSuffix(path, "/total_escrow"):
+		d := strings.TrimSuffix(strings.TrimPrefix(path, "/ibc/apps/transfer/v1/denoms/"), "/total_escrow")
+		amt := "0"
+		if d == s.st.Denom && s.st.IBCEscrow != "" {
+			amt = s.st.IBCEscrow
+		}
+		writeJSON(w, map[string]any{"amount": s.coin(d, amt)})
+	case path == "/cosmos/distribution/v1beta1/community_pool":
+		pool := []any{}
+		if s.st.CommunityPool != "" {
+			pool = append(pool, s.coin(s.st.Denom, s.st.CommunityPool))
+		}
+		writeJSON(w, map[string]any{"pool": pool})
+	case path == "/cosmos/staking/v1beta1/params":
+		writeJSON(w, map[string]any{"params": map[string]any{"unbonding_time": "1814400s", "max_validators": 100, "bond_denom": s.st.Denom}})
+	case path == "/cosmos/staking/v1beta1/pool":
+		// the pool is what the staking module accounts hold
+		writeJSON(w, map[string]any{"pool": map[string]any{
+			"bonded_tokens":     s.moduleBalance("bonded_tokens_pool"),
+			"not_bonded_tokens": s.moduleBalance("not_bonded_tokens_pool"),
+		}})
+	case strings.HasPrefix(path, "/cosmos/staking/v1beta1/delegations/"):
+		writeJSON(w, map[string]any{"delegation_responses": []any{}, "pagination": map[string]any{"next_key": nil, "total": "0"}})
+	case strings.HasPrefix(path, "/cosmos/staking/v1beta1/delegators/") && strings.HasSuffix(path, "/unbonding_delegations"):
+		writeJSON(w, map[string]any{"unbonding_responses": []any{}, "pagination": map[string]any{"next_key": nil, "total": "0"}})
+	case strings.HasPrefix(path, claimPrefix+"/list_claimed/"):
+		tier, err := strconv.Atoi(strings.TrimPrefix(path, claimPrefix+"/list_claimed/"))
+		if err != nil || tier < 1 || tier > 4 {
+			writeError(w, http.StatusBadRequest, 3, "invalid vesting tier")
+			return
+		}
+		s.writeClaims(w, r, tier)
+	default:
+		writeError(w, http.StatusNotImplemented, 12, "Not Implemented")
+	}
+}
+
+// writeBlock answers a block query with the header fields clients read.
+func (s *Server) writeBlock(w http.ResponseWriter, h int64) {
+	writeJSON(w, map[string]any{"block": map[string]any{"header": map[string]any{
+		"chain_id": "lumera-mock",
+		"height":   strconv.FormatInt(h, 10),
+		"time":     s.blockAt(h).Format(time.RFC3339Nano),
+	}}})
+}
+
+func (s *Server) writeMetadata(w http.ResponseWriter, denom string) {
+	if denom != s.st.Denom {
+		writeError(w, http.StatusNotFound, 5, fmt.Sprintf("client metadata for denom %s", denom))
+		return
+	}
+	units := []any{map[string]any{"denom": s.st.Denom, "exponent": 0, "aliases": []string{}}}
+	if s.st.Display != s.st.Denom {
+		units = append(units, map[string]any{"denom": s.st.Display, "exponent": s.st.Decimals, "aliases": []string{}})
+	}
+	writeJSON(w, map[string]any{"metadata": map[string]any{
+		"base": s.st.Denom, "display": s.st.Display, "denom_units": units,
+		"name": strings.ToUpper(s.st.Display), "symbol": strings.ToUpper(s.st.Display),
+	}})
+}
+
+// writeClaims lists a tier's claim records a page at a time. Pages follow Cosmos
+// pagination: pagination.key (the previous next_key) or pagination.offset, and
+// pagination.limit; pagination.count_total adds the total.
+func (s *Server) writeClaims(w http.ResponseWriter, r *http.Request, tier int) {
+	q := r.URL.Query()
+	all := s.claims[tier]
+	offset, limit := 0, defaultPageLimit
+	if v := q.Get("pagination.limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, 3, "invalid pagination.limit")
+			return
+		}
+		limit = n
+	} else if q.Get("pagination.key") == "" && q.Get("pagination.offset") == "" {
+		// unpaginated queries get every record, as the claim module lists them
+		limit = len(all)
+	}
+	if v := q.Get("pagination.key"); v != "" {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err == nil {
+			offset, err = strconv.Atoi(string(b))
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 3, "invalid pagination.key")
+			return
+		}
+	} else if v := q.Get("pagination.offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, 3, "invalid pagination.offset")
+			return
+		}
+		offset = n
+	}
+	offset = min(offset, len(all))
+	end := min(offset+limit, len(all))
+	list := make([]any, 0, end-offset)
+	for _, c := range all[offset:end] {
+		list = append(list, map[string]any{
+			"destAddress": c.Address,
+			"claimTime":   strconv.FormatInt(c.Time.Unix(), 10),
+			"vestedTier":  tier,
+			"balance":     []any{s.coin(s.st.Denom, c.Amount)},
+		})
+	}
+	page := map[string]any{"next_key": nil, "total": "0"}
+	if end < len(all) {
+		page["next_key"] = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	if q.Get("pagination.count_total") == "true" {
+		page["total"] = strconv.Itoa(len(all))
+	}
+	writeJSON(w, map[string]any{"claims": list, "pagination": page})
+}
+
+// accountJSON renders a as the auth module does, with the vesting fields of its type.
+func (s *Server) accountJSON(a *Account) map[string]any {
+	base := map[string]any{"address": a.Address, "pub_key": nil, "account_number": "0", "sequence": "0"}
+	ts := func(t *time.Time) string {
+		if t == nil {
+			return "0"
+		}
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	vestingBase := func() map[string]any {
+		return map[string]any{
+			"base_account":      base,
+			"original_vesting":  []any{s.coin(s.st.Denom, a.OriginalVesting)},
+			"delegated_free":    []any{},
+			"delegated_vesting": []any{},
+			"end_time":          ts(s.endTime(a)),
+		}
+	}
+	out := map[string]any{"@type": typeURLs[a.Type]}
+	switch a.Type {
+	case TypeModule:
+		out["base_account"], out["name"], out["permissions"] = base, a.Name, a.Permissions
+		if a.Permissions == nil {
+			out["permissions"] = []string{}
+		}
+	case TypeDelayed, TypePermanent:
+		out["base_vesting_account"] = vestingBase()
+	case TypeContinuous, TypePeriodic:
+		out["base_vesting_account"] = vestingBase()
+		out["start_time"] = ts(a.StartTime)
+		if a.Type == TypePeriodic {
+			periods := make([]any, 0, len(a.Periods))
+			for _, p := range a.Periods {
+				periods = append(periods, map[string]any{"length": strconv.FormatInt(p.LengthSeconds, 10), "amount": []any{s.coin(s.st.Denom, p.Amount)}})
+			}
+			out["vesting_periods"] = periods
+		}
+	default:
+		for k, v := range base {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// endTime is when a's vesting ends; periodic schedules end with their last period.
+func (s *Server) endTime(a *Account) *time.Time {
+	if a.Type != TypePeriodic || a.StartTime == nil {
+		return a.EndTime
+	}
+	end := *a.StartTime
+	for _, p := range a.Periods {
+		end = end.Add(time.Duration(p.LengthSeconds) * time.Second)
+	}
+	return &end
+}
+
+// locked is the part of a's original vesting still locked at now.
+func (s *Server) locked(a *Account, now time.Time) string {
+	var start, end time.Time
+	if a.StartTime != nil {
+		start = *a.StartTime
+	}
+	if e := s.endTime(a); e != nil {
+		end = *e
+	}
+	switch a.Type {
+	case TypeDelayed:
+		return s.ve.DelayedLocked(a.OriginalVesting, now, end)
+	case TypeContinuous:
+		return s.ve.ContinuousLocked(a.OriginalVesting, now, start, end)
+	case TypePeriodic:
+		periods := make([]vesting.Period, 0, len(a.Periods))
+		for _, p := range a.Periods {
+			start = start.Add(time.Duration(p.LengthSeconds) * time.Second)
+			periods = append(periods, vesting.Period{End: start, Amount: p.Amount})
+		}
+		return s.ve.PeriodicLocked(periods, now)
+	case TypePermanent:
+		return s.ve.PermanentLocked(a.OriginalVesting)
+	}
+	return "0"
+}
+
+// balance is addr's balance of denom; spendable leaves out what is still locked.
+func (s *Server) balance(addr, denom string, spendable bool) string {
+	a := s.accounts[addr]
+	if a == nil || denom != s.st.Denom {
+		return "0"
+	}
+	if !spendable {
+		return a.Balance
+	}
+	bal, _ := new(big.Int).SetString(a.Balance, 10)
+	_, now := s.latest()
+	locked, _ := new(big.Int).SetString(s.locked(a, now), 10)
+	if bal.Cmp(locked) <= 0 {
+		return "0"
+	}
+	return bal.Sub(bal, locked).String()
+}
+
+func (s *Server) moduleBalance(name string) string {
+	if a := s.modules[name]; a != nil {
+		return a.Balance
+	}
+	return "0"
+}
+
+func (s *Server) supplyOf(denom string) string {
+	if denom == s.st.Denom {
+		return s.st.Supply
+	}
+	return "0"
+}
+
+func (s *Server) coin(denom, amount string) map[string]string {
+	return map[string]string{"denom": denom, "amount": amount}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers in the gRPC-gateway error shape Cosmos nodes use.
+func writeError(w http.ResponseWriter, status, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"code": code, "message": msg, "details": []any{}})
+}
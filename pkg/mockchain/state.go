@@ -0,0 +1,331 @@
+// Package mockchain serves a fake Cosmos LCD over a configurable chain state: supply,
+// accounts of every vesting type, module accounts and paginated claim records. It lets
+// frontend and integration developers run the full stack locally without a devnet
+// (see cmd/lumera-mockchain).
+package mockchain
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/bech32"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+)
+
+// Account types (Account.Type).
+const (
+	TypeBase       = "base"
+	TypeModule     = "module"
+	TypeDelayed    = "delayed"
+	TypeContinuous = "continuous"
+	TypePeriodic   = "periodic"
+	TypePermanent  = "permanent"
+)
+
+// typeURLs are the "@type" values the LCD reports for each account type.
+var typeURLs = map[string]string{
+	TypeBase:       "/cosmos.auth.v1beta1.BaseAccount",
+	TypeModule:     "/cosmos.auth.v1beta1.ModuleAccount",
+	TypeDelayed:    "/cosmos.vesting.v1beta1.DelayedVestingAccount",
+	TypeContinuous: "/cosmos.vesting.v1beta1.ContinuousVestingAccount",
+	TypePeriodic:   "/cosmos.vesting.v1beta1.PeriodicVestingAccount",
+	TypePermanent:  "/cosmos.vesting.v1beta1.PermanentLockedAccount",
+}
+
+// State is the chain the mock serves. Amounts are base units of Denom.
+type State struct {
+	Denom    string `json:"denom"`
+	Display  string `json:"display"`
+	Decimals int    `json:"decimals"`
+	// Prefix is the bech32 prefix of generated addresses (module accounts, claims).
+	Prefix string `json:"prefix"`
+	// Supply defaults to the sum of balances and the IBC escrow (the community pool is
+	// part of the distribution module account's balance).
+	Supply string `json:"supply,omitempty"`
+	// Height is the block height at startup and Time its block time (default: the
+	// startup time); a block follows every BlockTime (a Go duration, default 6s).
+	Height    int64      `json:"height"`
+	Time      *time.Time `json:"time,omitempty"`
+	BlockTime string     `json:"block_time,omitempty"`
+	IBCEscrow string     `json:"ibc_escrow,omitempty"`
+	// CommunityPool is a decimal amount, as the distribution module reports it.
+	CommunityPool string    `json:"community_pool,omitempty"`
+	Accounts      []Account `json:"accounts"`
+	Claims        []Claim   `json:"claims,omitempty"`
+
+	// autoSupply keeps Supply the sum of balances across Apply.
+	autoSupply bool
+}
+
+// Account is an account and its balance. Module accounts may leave Address empty; it
+// is derived from Name as the chain does.
+type Account struct {
+	Address     string   `json:"address,omitempty"`
+	Type        string   `json:"type"`
+	Name        string   `json:"name,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	Balance     string   `json:"balance"`
+	// OriginalVesting defaults to Balance for vesting accounts.
+	OriginalVesting string     `json:"original_vesting,omitempty"`
+	StartTime       *time.Time `json:"start_time,omitempty"`
+	EndTime         *time.Time `json:"end_time,omitempty"`
+	// Periods of a periodic account follow each other from StartTime.
+	Periods []Period `json:"periods,omitempty"`
+}
+
+// Period is one step of a periodic vesting schedule.
+type Period struct {
+	LengthSeconds int64  `json:"length_seconds"`
+	Amount        string `json:"amount"`
+}
+
+// Claim is a claim module record. Claimed addresses without an account of their own
+// get a delayed vesting account locking Amount for Tier*6 months from Time.
+type Claim struct {
+	Address string    `json:"address"`
+	Tier    int       `json:"tier"`
+	Time    time.Time `json:"time"`
+	Amount  string    `json:"amount"`
+}
+
+// Load reads a State from a JSON file.
+func Load(path string) (*State, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var st State
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &st, nil
+}
+
+// Address derives a bech32 address from seed the way the chain derives module
+// addresses: the first 20 bytes of its SHA-256.
+func Address(prefix, seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	a, _ := bech32.Encode(prefix, sum[:20])
+	return a
+}
+
+// Default returns a small chain as of now: the usual module accounts, an account of
+// every vesting type and claims claim records spread over the four tiers.
+func Default(now time.Time, claims int) *State {
+	now = now.UTC().Truncate(time.Second)
+	at := func(d time.Duration) *time.Time { t := now.Add(d); return &t }
+	const month = 30 * 24 * time.Hour
+	st := &State{
+		Denom: "ulume", Display: "lume", Decimals: 6, Prefix: "lumera",
+		Height: 1000, BlockTime: "6s",
+		IBCEscrow: "1500000000000", CommunityPool: "2500000000000.125000000000000000",
+		Accounts: []Account{
+			{Type: TypeModule, Name: "distribution", Balance: "2600000000000"},
+			{Type: TypeModule, Name: "bonded_tokens_pool", Permissions: []string{"burner", "staking"}, Balance: "80000000000000"},
+			{Type: TypeModule, Name: "not_bonded_tokens_pool", Permissions: []string{"burner", "staking"}, Balance: "1200000000000"},
+			{Type: TypeModule, Name: "gov", Permissions: []string{"burner"}, Balance: "50000000000"},
+			{Type: TypeModule, Name: "mint", Permissions: []string{"minter"}},
+			{Type: TypeModule, Name: "fee_collector", Balance: "12000000"},
+			{Type: TypeModule, Name: "claim", Balance: "15000000000000"},
+			{Type: TypeBase, Address: Address("lumera", "mock/holder"), Balance: "90000000000000"},
+			{Type: TypeDelayed, Address: Address("lumera", "mock/delayed"), Balance: "5000000000000", EndTime: at(6 * month)},
+			{Type: TypeContinuous, Address: Address("lumera", "mock/continuous"), Balance: "6000000000000", StartTime: at(-3 * month), EndTime: at(9 * month)},
+			{Type: TypePeriodic, Address: Address("lumera", "mock/periodic"), Balance: "4000000000000", StartTime: at(-month), Periods: []Period{
+				{LengthSeconds: int64(3 * month / time.Second), Amount: "1000000000000"},
+				{LengthSeconds: int64(3 * month / time.Second), Amount: "1000000000000"},
+				{LengthSeconds: int64(3 * month / time.Second), Amount: "1000000000000"},
+				{LengthSeconds: int64(3 * month / time.Second), Amount: "1000000000000"},
+			}},
+			{Type: TypePermanent, Address: Address("lumera", "mock/permanent"), Balance: "3000000000000"},
+		},
+	}
+	for i := 0; i < claims; i++ {
+		st.Claims = append(st.Claims, Claim{
+			Address: Address("lumera", fmt.Sprintf("mock/claim/%d", i)),
+			Tier:    i%4 + 1,
+			Time:    now.Add(-time.Duration(i) * time.Hour),
+			Amount:  fmt.Sprint(1000000000 + i),
+		})
+	}
+	return st
+}
+
+// AddPolicy adds accounts for the addresses and module accounts a supply policy names,
+// so the service computes every disclosed cohort against the mock. Foundation entries
+// cycle through the vesting types; supernode bootstraps are delayed (or permanent)
+// accounts following their schedule. Addresses already in the state are kept.
+func (st *State) AddPolicy(p *policy.Policy, now time.Time) {
+	now = now.UTC().Truncate(time.Second)
+	have := map[string]bool{}
+	for _, a := range st.Accounts {
+		have[a.Address] = true
+		if a.Name != "" {
+			have[a.Name] = true
+		}
+	}
+	add := func(a Account) {
+		key := a.Address
+		if key == "" {
+			key = a.Name
+		}
+		if !have[key] {
+			have[key] = true
+			st.Accounts = append(st.Accounts, a)
+		}
+	}
+	amount := func(s string) string {
+		if n, ok := new(big.Int).SetString(strings.TrimSuffix(s, st.Denom), 10); ok {
+			return n.String()
+		}
+		return "1000000000000"
+	}
+	cycle := []string{TypeDelayed, TypeContinuous, TypePeriodic, TypePermanent}
+	for i, e := range p.Disclosed.FoundationGenesis {
+		a := Account{Address: e.Address, Type: cycle[i%len(cycle)], Balance: amount(e.Amount)}
+		start, end := now.AddDate(0, -1, 0), now.AddDate(0, 6*(i%4+1), 0)
+		a.StartTime, a.EndTime = &start, &end
+		if a.Type == TypePeriodic {
+			a.Periods = splitPeriods(a.Balance, start, end, 4)
+		}
+		add(a)
+	}
+	for _, e := range p.Disclosed.SupernodeBootstraps {
+		a := Account{Address: e.Address, Type: TypeDelayed, Balance: amount(e.Amount)}
+		switch {
+		case e.Permanent:
+			a.Type = TypePermanent
+		case e.EndTime != nil:
+			a.EndTime = e.EndTime
+		default:
+			months := 12
+			if e.DurationMonths != nil {
+				months = *e.DurationMonths
+			}
+			end := now.AddDate(0, months, 0)
+			if e.StartTime != nil {
+				end = e.StartTime.AddDate(0, months, 0)
+			}
+			a.EndTime = &end
+		}
+		add(a)
+	}
+	for _, m := range p.ModuleAccounts {
+		if strings.HasPrefix(m, st.Prefix+"1") {
+			add(Account{Address: m, Type: TypeBase, Balance: "1000000000000"})
+		} else {
+			add(Account{Type: TypeModule, Name: m, Balance: "1000000000000"})
+		}
+	}
+	for _, b := range p.BurnAddresses {
+		add(Account{Address: b, Type: TypeBase, Balance: "1000000"})
+	}
+}
+
+// splitPeriods divides total into n equal periods from start to end; the last takes
+// the remainder.
+func splitPeriods(total string, start, end time.Time, n int) []Period {
+	t, _ := new(big.Int).SetString(total, 10)
+	part := new(big.Int).Div(t, big.NewInt(int64(n)))
+	length := int64(end.Sub(start)/time.Second) / int64(n)
+	out := make([]Period, n)
+	for i := range out {
+		out[i] = Period{LengthSeconds: length, Amount: part.String()}
+	}
+	out[n-1].Amount = new(big.Int).Sub(t, new(big.Int).Mul(part, big.NewInt(int64(n-1)))).String()
+	return out
+}
+
+// normalize fills defaults and derived fields and validates the state.
+func (st *State) normalize() error {
+	if st.Denom == "" {
+		return fmt.Errorf("denom is required")
+	}
+	if st.Prefix == "" {
+		st.Prefix = "lumera"
+	}
+	if st.Display == "" {
+		st.Display = strings.TrimPrefix(st.Denom, "u")
+		st.Decimals = 6
+	}
+	if st.BlockTime == "" {
+		st.BlockTime = "6s"
+	}
+	if d, err := time.ParseDuration(st.BlockTime); err != nil || d <= 0 {
+		return fmt.Errorf("invalid block_time %q", st.BlockTime)
+	}
+	if st.Height <= 0 {
+		st.Height = 1
+	}
+	for _, f := range []struct{ name, v string }{{"ibc_escrow", st.IBCEscrow}, {"supply", st.Supply}} {
+		if _, ok := new(big.Int).SetString(f.v, 10); f.v != "" && !ok {
+			return fmt.Errorf("%s: invalid amount %q", f.name, f.v)
+		}
+	}
+	if st.CommunityPool != "" {
+		if _, ok := new(big.Rat).SetString(st.CommunityPool); !ok {
+			return fmt.Errorf("community_pool: invalid amount %q", st.CommunityPool)
+		}
+	}
+
+	have := map[string]bool{}
+	for i := range st.Accounts {
+		a := &st.Accounts[i]
+		if _, ok := typeURLs[a.Type]; !ok {
+			return fmt.Errorf("accounts[%d]: unknown type %q", i, a.Type)
+		}
+		if a.Address == "" {
+			if a.Type != TypeModule || a.Name == "" {
+				return fmt.Errorf("accounts[%d]: address is required", i)
+			}
+			a.Address = Address(st.Prefix, a.Name)
+		}
+		if a.Balance == "" {
+			a.Balance = "0"
+		}
+		if a.OriginalVesting == "" {
+			a.OriginalVesting = a.Balance
+		}
+		for _, v := range []string{a.Balance, a.OriginalVesting} {
+			if _, ok := new(big.Int).SetString(v, 10); !ok {
+				return fmt.Errorf("accounts[%d]: invalid amount %q", i, v)
+			}
+		}
+		if have[a.Address] {
+			return fmt.Errorf("accounts[%d]: duplicate address %s", i, a.Address)
+		}
+		have[a.Address] = true
+	}
+	for i, c := range st.Claims {
+		if c.Tier < 1 || c.Tier > 4 {
+			return fmt.Errorf("claims[%d]: tier must be 1 to 4", i)
+		}
+		if _, ok := new(big.Int).SetString(c.Amount, 10); !ok {
+			return fmt.Errorf("claims[%d]: invalid amount %q", i, c.Amount)
+		}
+		if !have[c.Address] {
+			have[c.Address] = true
+			end := c.Time.AddDate(0, c.Tier*6, 0)
+			st.Accounts = append(st.Accounts, Account{Address: c.Address, Type: TypeDelayed, Balance: c.Amount, OriginalVesting: c.Amount, EndTime: &end})
+		}
+	}
+	if st.Supply == "" {
+		st.autoSupply = true
+	}
+	if st.autoSupply {
+		sum := new(big.Int)
+		for _, a := range st.Accounts {
+			v, _ := new(big.Int).SetString(a.Balance, 10)
+			sum.Add(sum, v)
+		}
+		if v, ok := new(big.Int).SetString(st.IBCEscrow, 10); ok {
+			sum.Add(sum, v)
+		}
+		st.Supply = sum.String()
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Alert actions derived from service events.
+const (
+	actionTrigger = "trigger"
+	actionResolve = "resolve"
+)
+
+// alertAction maps an event to an on-call action. Only service events with a
+// Subsystem page; everything else is ignored by the alert integrations.
+func alertAction(ev Event) (string, bool) {
+	if ev.Subsystem == "" {
+		return "", false
+	}
+	switch ev.Kind {
+	case KindAnomaly, KindRefreshFailing:
+		return actionTrigger, true
+	case KindRefreshRecovered:
+		return actionResolve, true
+	}
+	return "", false
+}
+
+// DedupKey identifies the incident for a failing subsystem, so repeated triggers
+// update one incident and the recovery resolves it.
+func DedupKey(subsystem string) string { return "lumera-supply/" + subsystem }
+
+func summary(ev Event) string {
+	if ev.Message != "" {
+		return fmt.Sprintf("lumera-supply %s: %s", ev.Subsystem, ev.Message)
+	}
+	return fmt.Sprintf("lumera-supply %s: %s", ev.Subsystem, ev.Kind)
+}
+
+// PagerDuty sends trigger/resolve events to the PagerDuty Events API v2.
+type PagerDuty struct {
+	RoutingKey string
+	// Source names this instance in incidents (e.g., the hostname).
+	Source string
+	// URL defaults to https://events.pagerduty.com/v2/enqueue.
+	URL    string
+	Client *http.Client
+}
+
+func (p *PagerDuty) Notify(ctx context.Context, ev Event) error {
+	action, ok := alertAction(ev)
+	if !ok {
+		return nil
+	}
+	body := map[string]any{
+		"routing_key":  p.RoutingKey,
+		"event_action": action,
+		"dedup_key":    DedupKey(ev.Subsystem),
+	}
+	if action == actionTrigger {
+		body["payload"] = map[string]any{
+			"summary":        summary(ev),
+			"source":         p.Source,
+			"severity":       "critical",
+			"component":      ev.Subsystem,
+			"timestamp":      ev.Time.Format(time.RFC3339),
+			"custom_details": ev,
+		}
+	}
+	u := p.URL
+	if u == "" {
+		u = "https://events.pagerduty.com/v2/enqueue"
+	}
+	return postJSON(ctx, p.Client, u, nil, body)
+}
+
+// Opsgenie creates and closes alerts through the Opsgenie Alert API, using the dedup
+// key as the alert alias.
+type Opsgenie struct {
+	APIKey string
+	Source string
+	// URL defaults to https://api.opsgenie.com (use https://api.eu.opsgenie.com for EU).
+	URL    string
+	Client *http.Client
+}
+
+func (o *Opsgenie) Notify(ctx context.Context, ev Event) error {
+	action, ok := alertAction(ev)
+	if !ok {
+		return nil
+	}
+	base := o.URL
+	if base == "" {
+		base = "https://api.opsgenie.com"
+	}
+	alias := DedupKey(ev.Subsystem)
+	hdr := http.Header{"Authorization": {"GenieKey " + o.APIKey}}
+	if action == actionResolve {
+		u := base + "/v2/alerts/" + url.PathEscape(alias) + "/close?identifierType=alias"
+		return postJSON(ctx, o.Client, u, hdr, map[string]any{"source": o.Source, "note": summary(ev)})
+	}
+	return postJSON(ctx, o.Client, base+"/v2/alerts", hdr, map[string]any{
+		"message":     truncate(summary(ev), 130),
+		"alias":       alias,
+		"description": ev.Message,
+		"priority":    "P1",
+		"source":      o.Source,
+		"tags":        []string{"lumera-supply", ev.Subsystem},
+	})
+}
+
+// postJSON POSTs v and expects a 2xx answer.
+func postJSON(ctx context.Context, client *http.Client, u string, hdr http.Header, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	for k, vs := range hdr {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		rb, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("POST %s: status %d: %s", req.URL.Redacted(), resp.StatusCode, string(rb))
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
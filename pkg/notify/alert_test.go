@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAlertIntegrations(t *testing.T) {
+	type hit struct {
+		path, auth string
+		body       map[string]any
+	}
+	var hits []hit
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		hits = append(hits, hit{r.URL.RequestURI(), r.Header.Get("Authorization"), body})
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	pd := &PagerDuty{RoutingKey: "rk", Source: "host1", URL: srv.URL + "/v2/enqueue"}
+	og := &Opsgenie{APIKey: "key", Source: "host1", URL: srv.URL}
+	ctx := context.Background()
+	now := time.Now()
+	for _, ev := range []Event{
+		{Kind: KindUnlocked, Address: "lumera1a", Time: now}, // not an alert
+		{Kind: KindAnomaly, Subsystem: SubsystemQuorum, Message: "mismatch", Time: now},
+		{Kind: KindRefreshRecovered, Subsystem: SubsystemQuorum, Time: now},
+	} {
+		if err := (Multi{pd, og}).Notify(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(hits) != 4 {
+		t.Fatalf("expected 4 calls, got %+v", hits)
+	}
+	if hits[0].body["event_action"] != "trigger" || hits[0].body["dedup_key"] != "lumera-supply/quorum" {
+		t.Fatalf("unexpected pagerduty trigger: %+v", hits[0])
+	}
+	if hits[1].path != "/v2/alerts" || hits[1].auth != "GenieKey key" || hits[1].body["alias"] != "lumera-supply/quorum" {
+		t.Fatalf("unexpected opsgenie create: %+v", hits[1])
+	}
+	if hits[2].body["event_action"] != "resolve" || hits[2].body["dedup_key"] != "lumera-supply/quorum" {
+		t.Fatalf("unexpected pagerduty resolve: %+v", hits[2])
+	}
+	if hits[3].path != "/v2/alerts/lumera-supply%2Fquorum/close?identifierType=alias" {
+		t.Fatalf("unexpected opsgenie close: %+v", hits[3])
+	}
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultEmailSubject and DefaultEmailBody are the templates used when a route does not
+// set its own. Both execute against an Event.
+const (
+	DefaultEmailSubject = `[lumera-supply] {{.Kind}}{{with .Address}} {{.}}{{end}}`
+	DefaultEmailBody    = `Event: {{.Kind}}
+{{with .Message}}{{.}}
+{{end}}{{with .Address}}Address: {{.}}
+Locked: {{$.Previous}} -> {{$.Current}} {{$.Denom}}
+{{end}}{{with .Height}}Height: {{.}}
+{{end}}Time: {{.Time.UTC.Format "2006-01-02 15:04:05 UTC"}}
+`
+)
+
+// Email sends events as plain-text mail through an SMTP relay.
+type Email struct {
+	Addr    string // host:port
+	Auth    smtp.Auth
+	From    string
+	To      []string
+	subject *template.Template
+	body    *template.Template
+	// send is smtp.SendMail; replaced in tests
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmail parses the subject and body templates (defaults when empty).
+func NewEmail(addr, from string, to []string, auth smtp.Auth, subject, body string) (*Email, error) {
+	if subject == "" {
+		subject = DefaultEmailSubject
+	}
+	if body == "" {
+		body = DefaultEmailBody
+	}
+	st, err := template.New("subject").Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("email subject template: %w", err)
+	}
+	bt, err := template.New("body").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("email body template: %w", err)
+	}
+	return &Email{Addr: addr, Auth: auth, From: from, To: to, subject: st, body: bt, send: smtp.SendMail}, nil
+}
+
+func (e *Email) Notify(_ context.Context, ev Event) error {
+	msg, err := e.message(ev)
+	if err != nil {
+		return err
+	}
+	if err := e.send(e.Addr, e.Auth, e.From, e.To, msg); err != nil {
+		return fmt.Errorf("email %s: %w", e.Addr, err)
+	}
+	return nil
+}
+
+// message renders the RFC 5322 message for ev.
+func (e *Email) message(ev Event) ([]byte, error) {
+	var subj, body bytes.Buffer
+	if err := e.subject.Execute(&subj, ev); err != nil {
+		return nil, err
+	}
+	if err := e.body.Execute(&body, ev); err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", e.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subj.String())))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(body.String(), "\n", "\r\n"))
+	return b.Bytes(), nil
+}
@@ -0,0 +1,99 @@
+// Package notify delivers service events (e.g., unlocks of watched addresses) to
+// external sinks. All standard library.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Event kinds.
+const (
+	// KindUnlocked fires when a watched address's locked amount reaches zero.
+	KindUnlocked = "unlocked"
+	// KindLockedChanged fires when a watched address's locked amount moves by at least
+	// the subscription threshold.
+	KindLockedChanged = "locked_changed"
+	// KindAnomaly fires when a computed snapshot is rejected as inconsistent (e.g., quorum).
+	KindAnomaly = "anomaly"
+	// KindRefreshFailing fires once refreshes have failed continuously for the configured
+	// period; KindRefreshRecovered follows on the next success.
+	KindRefreshFailing   = "refresh_failing"
+	KindRefreshRecovered = "refresh_recovered"
+	// KindPolicyReloaded fires after the policy file is reloaded.
+	KindPolicyReloaded = "policy_reloaded"
+)
+
+// Kinds lists every event kind, for validating routing config.
+var Kinds = []string{KindUnlocked, KindLockedChanged, KindAnomaly, KindRefreshFailing, KindRefreshRecovered, KindPolicyReloaded}
+
+// Event is the JSON body delivered to sinks. Address-related fields are empty for
+// service events, which carry Message and Subsystem instead.
+type Event struct {
+	Kind     string    `json:"kind"`
+	Address  string    `json:"address"`
+	Denom    string    `json:"denom"`
+	Previous string    `json:"previous"`
+	Current  string    `json:"current"`
+	Height   int64     `json:"height"`
+	Time     time.Time `json:"time"`
+	Message  string    `json:"message,omitempty"`
+	// Subsystem names the failing component of service events ("quorum", "refresh");
+	// alert integrations derive their deduplication keys from it.
+	Subsystem string `json:"subsystem,omitempty"`
+}
+
+// Notifier delivers an event to one sink.
+type Notifier interface {
+	Notify(ctx context.Context, ev Event) error
+}
+
+// Multi fans an event out to several notifiers, joining their errors.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, ev Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, ev); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Only forwards events whose kind is in kinds to n; an empty kinds forwards all.
+func Only(kinds []string, n Notifier) Notifier {
+	if len(kinds) == 0 {
+		return n
+	}
+	return &only{kinds: kinds, next: n}
+}
+
+type only struct {
+	kinds []string
+	next  Notifier
+}
+
+func (o *only) Notify(ctx context.Context, ev Event) error {
+	if !slices.Contains(o.kinds, ev.Kind) {
+		return nil
+	}
+	return o.next.Notify(ctx, ev)
+}
+
+// Webhook POSTs events as JSON to URL and expects a 2xx answer.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w *Webhook) Notify(ctx context.Context, ev Event) error {
+	if err := postJSON(ctx, w.Client, w.URL, nil, ev); err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
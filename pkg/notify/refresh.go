@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Subsystems named in service events.
+const (
+	SubsystemQuorum  = "quorum"
+	SubsystemRefresh = "refresh"
+)
+
+// RefreshMonitor turns refresh results (see cache.SnapshotCache.OnRefresh) into service
+// events: KindAnomaly once per failure run when IsAnomaly matches the error,
+// KindRefreshFailing once the run has lasted After, and KindRefreshRecovered on the
+// next success after either was sent.
+type RefreshMonitor struct {
+	After     time.Duration
+	IsAnomaly func(error) bool
+	N         Notifier
+
+	mu        sync.Mutex
+	anomalous bool
+	failing   bool
+}
+
+// Observe records one refresh result.
+func (m *RefreshMonitor) Observe(err error, failingSince time.Time) {
+	m.mu.Lock()
+	var evs []Event
+	now := time.Now().UTC()
+	switch {
+	case err == nil:
+		// one recovery per subsystem so each alert incident resolves
+		if m.anomalous {
+			evs = append(evs, Event{Kind: KindRefreshRecovered, Subsystem: SubsystemQuorum, Time: now, Message: "snapshots pass quorum again"})
+		}
+		if m.failing {
+			evs = append(evs, Event{Kind: KindRefreshRecovered, Subsystem: SubsystemRefresh, Time: now, Message: "snapshot refresh recovered"})
+		}
+		m.anomalous, m.failing = false, false
+	default:
+		if !m.anomalous && m.IsAnomaly != nil && m.IsAnomaly(err) {
+			m.anomalous = true
+			evs = append(evs, Event{Kind: KindAnomaly, Subsystem: SubsystemQuorum, Time: now,
+				Message: "snapshot publication blocked: " + err.Error()})
+		}
+		if !m.failing && m.After > 0 && now.Sub(failingSince) >= m.After {
+			m.failing = true
+			evs = append(evs, Event{Kind: KindRefreshFailing, Subsystem: SubsystemRefresh, Time: now,
+				Message: fmt.Sprintf("snapshot refresh failing since %s: %v", failingSince.UTC().Format(time.RFC3339), err)})
+		}
+	}
+	m.mu.Unlock()
+	for _, ev := range evs {
+		if err := m.N.Notify(context.Background(), ev); err != nil {
+			log.Printf("warn: notify %s: %v", ev.Kind, err)
+		}
+	}
+}
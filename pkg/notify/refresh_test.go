@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recorder struct{ kinds []string }
+
+func (r *recorder) Notify(_ context.Context, ev Event) error {
+	r.kinds = append(r.kinds, ev.Kind)
+	return nil
+}
+
+func TestRefreshMonitor(t *testing.T) {
+	errQuorum := errors.New("quorum")
+	rec := &recorder{}
+	m := &RefreshMonitor{After: time.Minute, IsAnomaly: func(err error) bool { return err == errQuorum }, N: Only([]string{KindAnomaly, KindRefreshFailing, KindRefreshRecovered}, rec)}
+
+	m.Observe(nil, time.Time{})
+	m.Observe(errors.New("lcd down"), time.Now())
+	if len(rec.kinds) != 0 {
+		t.Fatalf("short outage should not notify: %v", rec.kinds)
+	}
+	since := time.Now().Add(-2 * time.Minute)
+	m.Observe(errQuorum, since)
+	m.Observe(errQuorum, since)
+	m.Observe(nil, time.Time{})
+	want := []string{KindAnomaly, KindRefreshFailing, KindRefreshRecovered, KindRefreshRecovered}
+	if strings.Join(rec.kinds, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", rec.kinds, want)
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	e, err := NewEmail("smtp.example.com:25", "supply@example.com", []string{"ops@example.com"}, nil, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent []byte
+	e.send = func(_ string, _ smtp.Auth, _ string, _ []string, msg []byte) error {
+		sent = msg
+		return nil
+	}
+	ev := Event{Kind: KindUnlocked, Address: "lumera1a", Denom: "ulume", Previous: "10", Current: "0", Height: 7, Time: time.Unix(0, 0)}
+	if err := e.Notify(context.Background(), ev); err != nil {
+		t.Fatal(err)
+	}
+	msg := string(sent)
+	for _, want := range []string{"Subject: [lumera-supply] unlocked lumera1a\r\n", "Locked: 10 -> 0 ulume\r\n", "Height: 7\r\n"} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("message missing %q:\n%s", want, msg)
+		}
+	}
+	if _, err := NewEmail("a:25", "f", []string{"t"}, nil, "{{", ""); err == nil {
+		t.Fatal("expected template error")
+	}
+}
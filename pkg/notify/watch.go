@@ -0,0 +1,218 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/store"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// watchesDoc is the store document holding subscriptions.
+const watchesDoc = "watches"
+
+// Subscription watches the locked amount of one address across all cohorts.
+type Subscription struct {
+	Address string `json:"address"`
+	// Threshold is the minimum absolute change (base units) that fires locked_changed;
+	// empty or "0" fires on any change.
+	Threshold string `json:"threshold,omitempty"`
+	// Webhook overrides the default notifier for this subscription.
+	Webhook   string    `json:"webhook,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// LastLocked is the locked amount at the last evaluated snapshot (empty before the first).
+	LastLocked string `json:"last_locked,omitempty"`
+}
+
+// Watchlist holds subscriptions and turns snapshot changes into events. Subscriptions
+// (and their last seen amounts) are persisted to the store when one is configured.
+type Watchlist struct {
+	mu       sync.Mutex
+	subs     map[string]*Subscription
+	store    *store.FileStore
+	notifier Notifier
+}
+
+// NewWatchlist loads persisted subscriptions from st (optional). n receives events for
+// subscriptions without their own webhook; nil only logs them.
+func NewWatchlist(st *store.FileStore, n Notifier) (*Watchlist, error) {
+	w := &Watchlist{subs: map[string]*Subscription{}, store: st, notifier: n}
+	if st == nil {
+		return w, nil
+	}
+	var saved []Subscription
+	if _, err := st.Doc(watchesDoc, &saved); err != nil {
+		return nil, fmt.Errorf("load watches: %w", err)
+	}
+	for i := range saved {
+		w.subs[saved[i].Address] = &saved[i]
+	}
+	return w, nil
+}
+
+// Add registers or replaces the subscription for sub.Address, stored lowercase as
+// snapshots report it.
+func (w *Watchlist) Add(sub Subscription) (Subscription, error) {
+	sub.Address = strings.ToLower(sub.Address)
+	if sub.Address == "" || len(sub.Address) > 128 {
+		return sub, errors.New("invalid address")
+	}
+	if sub.Threshold != "" {
+		if v, ok := new(big.Int).SetString(sub.Threshold, 10); !ok || v.Sign() < 0 {
+			return sub, errors.New("invalid threshold (non-negative integer in base units)")
+		}
+	}
+	if sub.Webhook != "" {
+		if u, err := url.Parse(sub.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return sub, errors.New("invalid webhook (http or https URL expected)")
+		}
+	}
+	sub.CreatedAt = time.Now().UTC()
+	sub.LastLocked = ""
+	w.mu.Lock()
+	w.subs[sub.Address] = &sub
+	err := w.saveLocked()
+	w.mu.Unlock()
+	return sub, err
+}
+
+// Remove deletes the subscription for address and reports whether it existed.
+func (w *Watchlist) Remove(address string) (bool, error) {
+	address = strings.ToLower(address)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.subs[address]; !ok {
+		return false, nil
+	}
+	delete(w.subs, address)
+	return true, w.saveLocked()
+}
+
+// List returns the subscriptions ordered by address.
+func (w *Watchlist) List() []Subscription {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make([]Subscription, 0, len(w.subs))
+	for _, s := range w.subs {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return out
+}
+
+// Observe evaluates snap against the subscriptions and delivers resulting events.
+// It is meant to be registered with cache.SnapshotCache.Subscribe.
+func (w *Watchlist) Observe(snap *types.SupplySnapshot) {
+	for _, d := range w.evaluate(snap) {
+		n := w.notifier
+		if d.webhook != "" {
+			n = &Webhook{URL: d.webhook}
+		}
+		if n == nil {
+			log.Printf("notify: %s %s %s -> %s (no notifier configured)", d.ev.Kind, d.ev.Address, d.ev.Previous, d.ev.Current)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := n.Notify(ctx, d.ev); err != nil {
+			log.Printf("warn: notify %s %s: %v", d.ev.Kind, d.ev.Address, err)
+		}
+		cancel()
+	}
+}
+
+type delivery struct {
+	ev      Event
+	webhook string
+}
+
+// evaluate updates each subscription's last seen amount and returns the events due.
+// The first snapshot after subscribing only records a baseline.
+func (w *Watchlist) evaluate(snap *types.SupplySnapshot) []delivery {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.subs) == 0 {
+		return nil
+	}
+	locked := LockedByAddress(snap)
+	var out []delivery
+	for addr, sub := range w.subs {
+		cur := locked[addr]
+		if cur == nil {
+			cur = new(big.Int)
+		}
+		prev, hadPrev := new(big.Int).SetString(sub.LastLocked, 10)
+		sub.LastLocked = cur.String()
+		if !hadPrev || prev.Cmp(cur) == 0 {
+			continue
+		}
+		ev := Event{Address: addr, Denom: snap.Denom, Previous: prev.String(), Current: cur.String(), Height: snap.Height, Time: snap.UpdatedAt}
+		switch {
+		case cur.Sign() == 0:
+			ev.Kind = KindUnlocked
+		case sub.Threshold == "" || new(big.Int).Abs(new(big.Int).Sub(cur, prev)).Cmp(thresholdOf(sub)) >= 0:
+			ev.Kind = KindLockedChanged
+		default:
+			// below threshold: keep the old baseline so small moves accumulate
+			sub.LastLocked = prev.String()
+			continue
+		}
+		out = append(out, delivery{ev: ev, webhook: sub.Webhook})
+	}
+	if err := w.saveLocked(); err != nil {
+		log.Printf("warn: save watches: %v", err)
+	}
+	return out
+}
+
+func thresholdOf(sub *Subscription) *big.Int {
+	v, ok := new(big.Int).SetString(sub.Threshold, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return v
+}
+
+// LockedByAddress sums locked amounts per address over cohort items and single-address
+// cohorts of snap.
+func LockedByAddress(snap *types.SupplySnapshot) map[string]*big.Int {
+	out := map[string]*big.Int{}
+	add := func(addr, amount string) {
+		v, ok := new(big.Int).SetString(amount, 10)
+		if !ok {
+			return
+		}
+		if out[addr] == nil {
+			out[addr] = new(big.Int)
+		}
+		out[addr].Add(out[addr], v)
+	}
+	for _, c := range snap.NonCirculating.Cohorts {
+		if c.Address != "" {
+			add(c.Address, c.Amount)
+		}
+		for _, it := range c.Items {
+			add(it.Address, it.Amount)
+		}
+	}
+	return out
+}
+
+func (w *Watchlist) saveLocked() error {
+	if w.store == nil {
+		return nil
+	}
+	out := make([]Subscription, 0, len(w.subs))
+	for _, s := range w.subs {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return w.store.PutDoc(watchesDoc, out)
+}
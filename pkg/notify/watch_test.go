@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+func snapWith(amounts map[string]string) *types.SupplySnapshot {
+	var items []types.AddressItem
+	for a, v := range amounts {
+		items = append(items, types.AddressItem{Address: a, Amount: v})
+	}
+	return &types.SupplySnapshot{Denom: "ulume", NonCirculating: types.NonCircBreakdown{
+		Cohorts: []types.CohortEntry{{Name: "claim_delayed", Items: items}},
+	}}
+}
+
+func TestWatchlistEvaluate(t *testing.T) {
+	w, _ := NewWatchlist(nil, nil)
+	if _, err := w.Add(Subscription{Address: "lumera1a", Threshold: "100"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add(Subscription{Address: "lumera1b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := w.evaluate(snapWith(map[string]string{"lumera1a": "1000", "lumera1b": "50"})); len(got) != 0 {
+		t.Fatalf("baseline should not notify: %+v", got)
+	}
+	// 1a moves by 60 (< threshold), 1b fully unlocks
+	got := w.evaluate(snapWith(map[string]string{"lumera1a": "940"}))
+	if len(got) != 1 || got[0].ev.Kind != KindUnlocked || got[0].ev.Address != "lumera1b" {
+		t.Fatalf("expected unlock for lumera1b only: %+v", got)
+	}
+	// cumulative move of 1a reaches the threshold against the old baseline
+	got = w.evaluate(snapWith(map[string]string{"lumera1a": "900"}))
+	if len(got) != 1 || got[0].ev.Kind != KindLockedChanged || got[0].ev.Previous != "1000" || got[0].ev.Current != "900" {
+		t.Fatalf("expected locked_changed 1000 -> 900: %+v", got)
+	}
+
+	if _, err := w.Add(Subscription{Address: "lumera1c", Threshold: "-1"}); err == nil {
+		t.Fatal("expected error for negative threshold")
+	}
+}
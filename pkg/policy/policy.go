@@ -1,14 +1,20 @@
 package policy
 
 import (
-	"crypto/sha1"
+	"bytes"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/bech32"
 )
 
 // Policy defines cohorts, module accounts, and IBC channels to consider non-circulating.
@@ -25,16 +31,67 @@ type Policy struct {
 	// for backward compatibility with older policies and tests.
 	ModuleAccounts []string `json:"module_accounts"`
 
+	// GovDeposits treats proposal deposits escrowed in the gov module account as
+	// non-circulating (cohort "gov_deposits"): they are not spendable until refunded or burned.
+	GovDeposits bool `json:"gov_deposits,omitempty"`
+
+	// StakingBonded treats tokens bonded to validators as non-circulating (cohort
+	// "staking_bonded"), for providers that publish staked supply separately.
+	StakingBonded bool `json:"staking_bonded,omitempty"`
+
+	// BurnAddresses are addresses nobody controls; transfers to them are tracked as
+	// burn_transferred when burn tracking is enabled.
+	BurnAddresses []string `json:"burn_addresses,omitempty"`
+
 	// New nested disclosed lockups structure.
 	Disclosed DisclosedLockups `json:"disclosed_lockups"`
 
+	// Cohorts carries optional per-cohort metadata keyed by computed cohort name
+	// (e.g., "foundation_genesis", "ibc_escrow", "module:claim").
+	Cohorts map[string]CohortMeta `json:"cohorts,omitempty"`
+
+	// AddressGroups are named sets of metadata (custody, notes, disclosure links) that
+	// disclosed lockup entries reference by name in their group field, so metadata shared
+	// by many addresses is stated once.
+	AddressGroups map[string]AddressGroup `json:"address_groups,omitempty"`
+
+	// Overlaps says how addresses counted by more than one cohort are handled.
+	Overlaps *OverlapPolicy `json:"overlaps,omitempty"`
+
 	// Backward-compatibility: older flat cohorts used in tests (not populated from JSON).
 	DisclosedLockups []Cohort `json:"-"`
 
-	// ETag is a short hash representing the policy content/version.
+	// ETag identifies the policy content: "policy-[<version>-]<hash>", where hash is
+	// the leading 16 hex digits of Hash. Set by Load and Parse; see ComputeETag.
 	ETag string `json:"-"`
 }
 
+// Overlap modes (OverlapPolicy.Mode).
+const (
+	OverlapReport = "report"
+	OverlapDedupe = "dedupe"
+	OverlapError  = "error"
+)
+
+// OverlapPolicy handles addresses whose balance several cohorts count, which overstates
+// non-circulating supply.
+type OverlapPolicy struct {
+	// Mode is "report" (default: log them and list them in /status), "dedupe" (count
+	// each address only in its highest-precedence cohort) or "error" (reject snapshots).
+	Mode string `json:"mode,omitempty"`
+	// Precedence lists cohort names from highest to lowest for dedupe; unlisted cohorts
+	// rank below, in the order they are computed.
+	Precedence []string `json:"precedence,omitempty"`
+}
+
+// OverlapMode returns the configured overlap mode, OverlapReport by default.
+func (p *Policy) OverlapMode() string {
+	if p == nil || p.Overlaps == nil || p.Overlaps.Mode == "" {
+		return OverlapReport
+	}
+	return p.Overlaps.Mode
+}
+
 type DisclosedLockups struct {
 	FoundationGenesis   []FoundationEntry `json:"foundation_genesis"`
 	SupernodeBootstraps []SupernodeEntry  `json:"supernode_bootstraps"`
@@ -48,15 +105,133 @@ type FoundationEntry struct {
 	Reason  string `json:"reason,omitempty"`
 	Address string `json:"address"`
 	Custody string `json:"custody,omitempty"`
+	// Group names the entry's address group (see Policy.AddressGroups).
+	Group string `json:"group,omitempty"`
 }
 
 type SupernodeEntry struct {
-	Name           string     `json:"name"`
-	Address        string     `json:"address"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	// Amount is the bootstrap principal (e.g., "25000000000ulume"). Balance-based
+	// fallbacks lock at most this much, so withdrawn staking rewards stay circulating.
+	Amount         string     `json:"amount,omitempty"`
 	Permanent      bool       `json:"permanent,omitempty"`
 	DurationMonths *int       `json:"duration_months,omitempty"`
 	StartTime      *time.Time `json:"start_time,omitempty"`
 	EndTime        *time.Time `json:"end_time,omitempty"`
+	// Group names the entry's address group (see Policy.AddressGroups).
+	Group string `json:"group,omitempty"`
+}
+
+// AddressGroup is metadata shared by the disclosed addresses referencing it, reported
+// with their cohort items.
+type AddressGroup struct {
+	// Custody says who controls the addresses (e.g., "multisig", "exchange custody").
+	Custody string `json:"custody,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+	// Links are http(s) URLs of the public disclosures covering the addresses.
+	Links []string `json:"links,omitempty"`
+}
+
+// AddressGroup returns the address group named name.
+func (p *Policy) AddressGroup(name string) (AddressGroup, bool) {
+	if p == nil {
+		return AddressGroup{}, false
+	}
+	g, ok := p.AddressGroups[name]
+	return g, ok
+}
+
+// CohortMeta is reporting metadata attached to a computed cohort.
+type CohortMeta struct {
+	// Tags group cohorts into reporting categories (e.g., "protocol", "investors", "community").
+	Tags []string `json:"tags,omitempty"`
+	// RefreshInterval (a Go duration, e.g. "1h") lets slow-moving cohorts be recomputed
+	// less often than the snapshot; in between, snapshots reuse the last result.
+	RefreshInterval string `json:"refresh_interval,omitempty"`
+	// ID is a positive number identifying the cohort in every output. Unlike the name it
+	// is never derived, so downstream SQL models can key on it across renames.
+	ID int `json:"id,omitempty"`
+	// Slug is a machine-safe identifier ([a-z][a-z0-9_]*); it defaults to Slug(name).
+	Slug string `json:"slug,omitempty"`
+	// DisclosureURL links the public document justifying the cohort's classification
+	// as non-circulating (an http(s) URL).
+	DisclosureURL string `json:"disclosure_url,omitempty"`
+	// Notes explain the classification in a sentence or two.
+	Notes string `json:"notes,omitempty"`
+}
+
+// CohortTags returns the configured tags for a cohort name, or nil.
+func (p *Policy) CohortTags(name string) []string {
+	if p == nil {
+		return nil
+	}
+	return p.Cohorts[name].Tags
+}
+
+// CohortDisclosure returns the configured disclosure URL and notes for a cohort name.
+func (p *Policy) CohortDisclosure(name string) (disclosureURL, notes string) {
+	if p == nil {
+		return "", ""
+	}
+	m := p.Cohorts[name]
+	return m.DisclosureURL, m.Notes
+}
+
+// CohortID returns the configured ID (0 when unassigned) and slug for a cohort name.
+func (p *Policy) CohortID(name string) (int, string) {
+	if p == nil {
+		return 0, Slug(name)
+	}
+	m := p.Cohorts[name]
+	if m.Slug == "" {
+		return m.ID, Slug(name)
+	}
+	return m.ID, m.Slug
+}
+
+// Slug derives a machine-safe identifier from a cohort name: lower case, with runs of
+// other characters replaced by "_" (e.g. "module:gov" becomes "module_gov").
+func Slug(name string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if sep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			sep = false
+			continue
+		}
+		sep = true
+	}
+	return b.String()
+}
+
+// httpURL reports whether s is an absolute http or https URL.
+func httpURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+func validSlug(s string) bool {
+	for i, r := range s {
+		if !(r >= 'a' && r <= 'z' || i > 0 && (r >= '0' && r <= '9' || r == '_')) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// RefreshInterval returns a cohort's refresh interval, or 0 to recompute it on every
+// snapshot.
+func (p *Policy) RefreshInterval(name string) time.Duration {
+	if p == nil {
+		return 0
+	}
+	d, _ := time.ParseDuration(p.Cohorts[name].RefreshInterval)
+	return d
 }
 
 type Cohort struct {
@@ -75,22 +250,143 @@ func Load(path string) (*Policy, error) {
 	if err != nil {
 		return nil, err
 	}
+	return Parse(b)
+}
+
+// LoadOrEmbedded loads the policy file at path, or parses embedded (a policy compiled
+// into the binary) when the file cannot be loaded, so a deployment missing its policy
+// file still counts the official cohorts. fallback reports that embedded was used, and
+// err is then the file's error, for logging. An empty embedded changes nothing.
+func LoadOrEmbedded(path string, embedded []byte) (p *Policy, fallback bool, err error) {
+	p, err = Load(path)
+	if err == nil || len(embedded) == 0 {
+		return p, false, err
+	}
+	ep, eerr := Parse(embedded)
+	if eerr != nil {
+		return nil, false, fmt.Errorf("%w (embedded policy: %v)", err, eerr)
+	}
+	return ep, true, err
+}
+
+// Parse decodes, normalizes (see NormalizeAddresses) and validates a policy document
+// and sets its ETag.
+func Parse(b []byte) (*Policy, error) {
 	var p Policy
 	if err := json.Unmarshal(b, &p); err != nil {
 		return nil, err
 	}
+	if err := p.NormalizeAddresses(); err != nil {
+		return nil, err
+	}
 	if err := p.Validate(); err != nil {
 		return nil, err
 	}
-	// compute a short ETag from file content and version (first 8 hex of sha1)
-	h := sha1.Sum(b)
-	short := hex.EncodeToString(h[:4])
+	p.ETag = p.ComputeETag()
+	return &p, nil
+}
+
+// NormalizeAddresses rewrites the policy's addresses to their canonical lowercase bech32
+// form, so they match the addresses the chain reports. An address with a bad checksum or
+// mixed case, a prefix differing from the other addresses, or listed twice in the same
+// list is an error. Module account entries are only treated as addresses when they look
+// like one (see ModuleAccounts).
+func (p *Policy) NormalizeAddresses() error {
+	var prefix string
+	norm := func(field string, addr *string, seen map[string]bool) error {
+		if *addr == "" {
+			return nil // reported by Validate
+		}
+		a, err := bech32.Normalize(*addr)
+		if err != nil {
+			return fmt.Errorf("%s: %q: %w", field, *addr, err)
+		}
+		hrp := a[:strings.LastIndexByte(a, '1')]
+		if prefix == "" {
+			prefix = hrp
+		} else if hrp != prefix {
+			return fmt.Errorf("%s: %q has prefix %q, other addresses use %q", field, *addr, hrp, prefix)
+		}
+		if seen[a] {
+			return fmt.Errorf("%s: duplicate address %s", field, a)
+		}
+		seen[a] = true
+		*addr = a
+		return nil
+	}
+	seen := map[string]bool{}
+	for i := range p.Disclosed.FoundationGenesis {
+		if err := norm(fmt.Sprintf("disclosed_lockups.foundation_genesis[%d]", i), &p.Disclosed.FoundationGenesis[i].Address, seen); err != nil {
+			return err
+		}
+	}
+	seen = map[string]bool{}
+	for i := range p.Disclosed.SupernodeBootstraps {
+		if err := norm(fmt.Sprintf("disclosed_lockups.supernode_bootstraps[%d]", i), &p.Disclosed.SupernodeBootstraps[i].Address, seen); err != nil {
+			return err
+		}
+	}
+	seen = map[string]bool{}
+	for i := range p.BurnAddresses {
+		if err := norm(fmt.Sprintf("burn_addresses[%d]", i), &p.BurnAddresses[i], seen); err != nil {
+			return err
+		}
+	}
+	seen = map[string]bool{}
+	for i, m := range p.ModuleAccounts {
+		if looksLikeAddress(m) {
+			if err := norm(fmt.Sprintf("module_accounts[%d]", i), &p.ModuleAccounts[i], seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// looksLikeAddress reports whether a module account entry is an address rather than a
+// module name: it has a "1" separator followed by at least a checksum, like lumera1...;
+// module names (claim, gov, distribution) do not.
+func looksLikeAddress(s string) bool {
+	i := strings.LastIndexByte(s, '1')
+	return i > 0 && len(s)-i > 6
+}
+
+// Canonical returns the policy as canonical JSON: the decoded fields only, object
+// keys sorted, no insignificant whitespace. Formatting, key order and unknown fields
+// in the source document do not affect it.
+func (p *Policy) Canonical() ([]byte, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	// round-trip through a generic value so raw sub-documents get sorted keys too
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// Hash is the hex SHA-256 of the canonical JSON.
+func (p *Policy) Hash() string {
+	b, err := p.Canonical()
+	if err != nil {
+		// only reachable with invalid raw sub-documents, which Parse rejects
+		b = nil
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// ComputeETag derives the policy ETag from the version and the canonical content hash.
+func (p *Policy) ComputeETag() string {
+	short := p.Hash()[:16]
 	if p.Version != "" {
-		p.ETag = "policy-" + p.Version + "-" + short
-	} else {
-		p.ETag = "policy-" + short
+		return "policy-" + p.Version + "-" + short
 	}
-	return &p, nil
+	return "policy-" + short
 }
 
 func (p *Policy) Validate() error {
@@ -105,11 +401,82 @@ func (p *Policy) Validate() error {
 		if e.Address == "" {
 			return fmt.Errorf("disclosed_lockups.foundation_genesis[%d] missing address", i)
 		}
+		if _, ok := p.AddressGroups[e.Group]; e.Group != "" && !ok {
+			return fmt.Errorf("disclosed_lockups.foundation_genesis[%d].group %q is not in address_groups", i, e.Group)
+		}
 	}
 	for i, e := range p.Disclosed.SupernodeBootstraps {
 		if e.Address == "" {
 			return fmt.Errorf("disclosed_lockups.supernode_bootstraps[%d] missing address", i)
 		}
+		if _, ok := p.AddressGroups[e.Group]; e.Group != "" && !ok {
+			return fmt.Errorf("disclosed_lockups.supernode_bootstraps[%d].group %q is not in address_groups", i, e.Group)
+		}
+	}
+	groups := make([]string, 0, len(p.AddressGroups))
+	for name := range p.AddressGroups {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+	for _, name := range groups {
+		if name == "" {
+			return errors.New("address_groups has an empty name")
+		}
+		for i, l := range p.AddressGroups[name].Links {
+			if !httpURL(l) {
+				return fmt.Errorf("address_groups[%q].links[%d] %q is not an http(s) URL", name, i, l)
+			}
+		}
+	}
+	ids := map[int]string{}
+	slugs := map[string]string{}
+	names := make([]string, 0, len(p.Cohorts))
+	for name := range p.Cohorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m := p.Cohorts[name]
+		if m.ID < 0 {
+			return fmt.Errorf("cohorts[%q].id %d must be positive", name, m.ID)
+		}
+		if other, dup := ids[m.ID]; dup && m.ID > 0 {
+			return fmt.Errorf("cohorts[%q].id %d is also used by %q", name, m.ID, other)
+		}
+		ids[m.ID] = name
+		if m.Slug != "" && !validSlug(m.Slug) {
+			return fmt.Errorf("cohorts[%q].slug %q must match [a-z][a-z0-9_]*", name, m.Slug)
+		}
+		_, slug := p.CohortID(name)
+		if other, dup := slugs[slug]; dup {
+			return fmt.Errorf("cohorts[%q].slug %q is also used by %q", name, slug, other)
+		}
+		slugs[slug] = name
+		for i, t := range m.Tags {
+			if t == "" {
+				return fmt.Errorf("cohorts[%q].tags[%d] is empty", name, i)
+			}
+		}
+		if m.DisclosureURL != "" && !httpURL(m.DisclosureURL) {
+			return fmt.Errorf("cohorts[%q].disclosure_url %q is not an http(s) URL", name, m.DisclosureURL)
+		}
+		if m.RefreshInterval != "" {
+			if d, err := time.ParseDuration(m.RefreshInterval); err != nil || d < 0 {
+				return fmt.Errorf("cohorts[%q].refresh_interval %q is not a valid duration", name, m.RefreshInterval)
+			}
+		}
+	}
+	if o := p.Overlaps; o != nil {
+		switch o.Mode {
+		case "", OverlapReport, OverlapDedupe, OverlapError:
+		default:
+			return fmt.Errorf("overlaps.mode %q must be report, dedupe or error", o.Mode)
+		}
+		for i, name := range o.Precedence {
+			if name == "" {
+				return fmt.Errorf("overlaps.precedence[%d] is empty", i)
+			}
+		}
 	}
 	// Back-compat: ensure names present in flat disclosed lockups if used programmatically
 	for i, c := range p.DisclosedLockups {
@@ -0,0 +1,150 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestETagIsCanonical(t *testing.T) {
+	a, err := Parse([]byte(`{"version":"1.0.0","module_accounts":["claim"],"disclosed_lockups":{"timelocks":[{"a":1,"b":2}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// same content: reordered keys, whitespace, an unknown field
+	b, err := Parse([]byte(`{
+  "disclosed_lockups": {"timelocks": [ {"b": 2, "a": 1} ]},
+  "module_accounts": ["claim"],
+  "comment": "ignored",
+  "version": "1.0.0"
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ETag != b.ETag {
+		t.Fatalf("etags differ for equal content: %s vs %s", a.ETag, b.ETag)
+	}
+	if !strings.HasPrefix(a.ETag, "policy-1.0.0-") || len(a.Hash()) != 64 {
+		t.Fatalf("unexpected etag %s / hash %s", a.ETag, a.Hash())
+	}
+	c, err := Parse([]byte(`{"version":"1.0.0","module_accounts":["claim","gov"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ETag == a.ETag {
+		t.Fatal("different content must change the etag")
+	}
+	if (&Policy{ModuleAccounts: []string{"claim"}}).ComputeETag() == "" {
+		t.Fatal("programmatic policies get an etag too")
+	}
+}
+
+func TestNormalizeAddresses(t *testing.T) {
+	const a, b = "lumera1qyqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqjd8x3v", "lumera1qgqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqshwqsf"
+	p, err := Parse([]byte(`{"module_accounts":["claim","` + strings.ToUpper(b) + `"],"burn_addresses":["` + strings.ToUpper(a) + `"],
+"disclosed_lockups":{"foundation_genesis":[{"name":"f","address":"` + strings.ToUpper(a) + `"}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Disclosed.FoundationGenesis[0].Address != a || p.BurnAddresses[0] != a || p.ModuleAccounts[0] != "claim" || p.ModuleAccounts[1] != b {
+		t.Fatalf("addresses not normalized: %+v", p)
+	}
+	for name, doc := range map[string]string{
+		"mixed case":   `{"burn_addresses":["Lumera1qyqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqjd8x3v"]}`,
+		"checksum":     `{"burn_addresses":["lumera1qyqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqjd8x3w"]}`,
+		"duplicate":    `{"burn_addresses":["` + a + `","` + strings.ToUpper(a) + `"]}`,
+		"prefix":       `{"burn_addresses":["` + a + `","cosmos1qgqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqggwm7m"]}`,
+		"module entry": `{"module_accounts":["lumera1qyqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqjd8x3w"]}`,
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestCohortIDs(t *testing.T) {
+	p, err := Parse([]byte(`{"cohorts":{"module:claim":{"id":3},"claim_delayed":{"id":7,"slug":"claims_vesting"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, slug := p.CohortID("module:claim"); id != 3 || slug != "module_claim" {
+		t.Fatalf("module:claim: %d %q", id, slug)
+	}
+	if id, slug := p.CohortID("claim_delayed"); id != 7 || slug != "claims_vesting" {
+		t.Fatalf("claim_delayed: %d %q", id, slug)
+	}
+	if id, slug := p.CohortID("Foo - Bar"); id != 0 || slug != "foo_bar" {
+		t.Fatalf("unlisted: %d %q", id, slug)
+	}
+	d, err := Parse([]byte(`{"cohorts":{"module:claim":{"disclosure_url":"https://lumera.io/disclosures/claim","notes":"Unclaimed airdrop"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, notes := d.CohortDisclosure("module:claim"); u != "https://lumera.io/disclosures/claim" || notes != "Unclaimed airdrop" {
+		t.Fatalf("module:claim disclosure: %q %q", u, notes)
+	}
+	for name, doc := range map[string]string{
+		"duplicate id":   `{"cohorts":{"a":{"id":1},"b":{"id":1}}}`,
+		"negative id":    `{"cohorts":{"a":{"id":-1}}}`,
+		"unsafe slug":    `{"cohorts":{"a":{"slug":"A-b"}}}`,
+		"duplicate slug": `{"cohorts":{"module:gov":{},"b":{"slug":"module_gov"}}}`,
+		"bad disclosure": `{"cohorts":{"a":{"disclosure_url":"lumera.io/disclosures"}}}`,
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestAddressGroups(t *testing.T) {
+	const addr = "lumera134tmfqteaytw30tpetkq65dnyx595wqqd0uf45"
+	p, err := Parse([]byte(`{"address_groups":{"treasury":{"custody":"multisig","links":["https://lumera.io/disclosures/treasury"]}},
+		"disclosed_lockups":{"foundation_genesis":[{"name":"seed","address":"` + addr + `","group":"treasury"}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, ok := p.AddressGroup("treasury"); !ok || g.Custody != "multisig" || len(g.Links) != 1 {
+		t.Fatalf("treasury: %+v %v", g, ok)
+	}
+	if _, ok := p.AddressGroup("other"); ok {
+		t.Fatal("unknown group found")
+	}
+	for name, doc := range map[string]string{
+		"unknown group": `{"disclosed_lockups":{"supernode_bootstraps":[{"address":"` + addr + `","group":"nope"}]}}`,
+		"empty name":    `{"address_groups":{"":{}}}`,
+		"relative link": `{"address_groups":{"a":{"links":["/disclosures"]}}}`,
+		"non-http link": `{"address_groups":{"a":{"links":["ftp://lumera.io/x"]}}}`,
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLoadOrEmbedded(t *testing.T) {
+	embedded := []byte(`{"version":"embedded","module_accounts":["claim"]}`)
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.json")
+
+	p, fallback, err := LoadOrEmbedded(missing, embedded)
+	if !fallback || err == nil || p == nil || p.Version != "embedded" {
+		t.Fatalf("missing file: policy %+v fallback %v err %v", p, fallback, err)
+	}
+	if _, fallback, err := LoadOrEmbedded(missing, nil); fallback || err == nil {
+		t.Fatalf("without an embedded policy: fallback %v err %v", fallback, err)
+	}
+
+	file := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(file, []byte(`{"version":"file","module_accounts":["claim"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if p, fallback, err := LoadOrEmbedded(file, embedded); fallback || err != nil || p.Version != "file" {
+		t.Fatalf("file present: policy %+v fallback %v err %v", p, fallback, err)
+	}
+
+	// a broken embedded policy is reported along with the file's error
+	if _, _, err := LoadOrEmbedded(missing, []byte("{")); err == nil || !strings.Contains(err.Error(), "embedded policy") {
+		t.Fatalf("broken embedded policy: %v", err)
+	}
+}
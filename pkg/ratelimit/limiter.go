@@ -12,6 +12,7 @@ import (
 
 type bucket struct {
 	tokens chan struct{}
+	stop   chan struct{}
 }
 
 type Limiter struct {
@@ -36,7 +37,7 @@ func (l *Limiter) get(ip string) *bucket {
 	defer l.mu.Unlock()
 	b := l.buckets[ip]
 	if b == nil {
-		b = &bucket{tokens: make(chan struct{}, l.burst)}
+		b = &bucket{tokens: make(chan struct{}, l.burst), stop: make(chan struct{})}
 		// fill burst
 		for i := 0; i < l.burst; i++ {
 			b.tokens <- struct{}{}
@@ -46,7 +47,12 @@ func (l *Limiter) get(ip string) *bucket {
 		go func() {
 			t := time.NewTicker(interval)
 			defer t.Stop()
-			for range t.C {
+			for {
+				select {
+				case <-b.stop:
+					return
+				case <-t.C:
+				}
 				select {
 				case b.tokens <- struct{}{}:
 				default:
@@ -58,6 +64,23 @@ func (l *Limiter) get(ip string) *bucket {
 	return b
 }
 
+// SetRate changes the refill rate and burst. Existing buckets are dropped, so every
+// client starts again from a full burst.
+func (l *Limiter) SetRate(perMin, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if perMin > 0 {
+		l.perMin = perMin
+	}
+	if burst > 0 {
+		l.burst = burst
+	}
+	for ip, b := range l.buckets {
+		close(b.stop)
+		delete(l.buckets, ip)
+	}
+}
+
 func (l *Limiter) Allow(r *http.Request) bool {
 	ip := clientIP(r)
 	b := l.get(ip)
@@ -0,0 +1,42 @@
+// Package recovery turns panics in request handlers, scheduled jobs, subscribers and
+// cohort sources into errors, so one malformed upstream payload cannot take the whole
+// service down. Every recovered panic is counted and logged with its stack.
+package recovery
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+)
+
+var panics = metrics.Default.NewCounter(
+	"lumera_supply_panics_total",
+	"Recovered panics by component.",
+	"component",
+)
+
+// ErrPanic wraps the errors returned for recovered panics.
+var ErrPanic = errors.New("panic")
+
+// Report counts and logs v, a value returned by recover in component, and returns it as
+// an error. Call it from the deferred function, so the logged stack still holds the
+// panicking frames. The log line is key=value with the value and stack quoted, so it
+// stays one line.
+func Report(component string, v any) error {
+	panics.Inc(component)
+	log.Printf("warn: panic recovered component=%s value=%q stack=%q", component, fmt.Sprint(v), debug.Stack())
+	return fmt.Errorf("%w in %s: %v", ErrPanic, component, v)
+}
+
+// Guard runs fn and returns a panic in it as an error.
+func Guard(component string, fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = Report(component, v)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,37 @@
+package recovery
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGuard(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	fail := errors.New("fail")
+	if err := Guard("test", func() error { return fail }); err != fail {
+		t.Fatalf("error passed through: %v", err)
+	}
+	before := panics.Value("test")
+	err := Guard("test", func() error {
+		var m map[string]int
+		m["x"] = 1
+		return nil
+	})
+	if !errors.Is(err, ErrPanic) || !strings.Contains(err.Error(), "assignment to entry in nil map") {
+		t.Fatalf("panic error: %v", err)
+	}
+	if got := panics.Value("test"); got != before+1 {
+		t.Fatalf("panics_total = %v, want %v", got, before+1)
+	}
+	line := buf.String()
+	if strings.Count(line, "\n") != 1 || !strings.Contains(line, "component=test") || !strings.Contains(line, "recovery.TestGuard") {
+		t.Fatalf("log line: %s", line)
+	}
+}
@@ -0,0 +1,171 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A minimal PDF 1.4 writer: A4 pages, the built-in Helvetica font and line drawing
+// are all a text report with one chart needs.
+
+const (
+	pdfWidth   = 595.0
+	pdfHeight  = 842.0
+	pdfMargin  = 50.0
+	pdfLeading = 14.0
+	chartH     = 120.0
+)
+
+type pdfPage struct {
+	buf bytes.Buffer
+	y   float64
+}
+
+type pdfWriter struct {
+	pages []*pdfPage
+}
+
+func (p *pdfWriter) page() *pdfPage {
+	if len(p.pages) == 0 || p.pages[len(p.pages)-1].y < pdfMargin+pdfLeading {
+		p.pages = append(p.pages, &pdfPage{y: pdfHeight - pdfMargin})
+	}
+	return p.pages[len(p.pages)-1]
+}
+
+func (p *pdfWriter) text(s string, size float64, x float64) {
+	pg := p.page()
+	pg.y -= size + (pdfLeading - 10)
+	fmt.Fprintf(&pg.buf, "BT /F1 %.0f Tf %.1f %.1f Td (%s) Tj ET\n", size, x, pg.y, pdfEscape(s))
+}
+
+// row writes cells left to right at fixed column offsets.
+func (p *pdfWriter) row(cells []string, cols []float64) {
+	pg := p.page()
+	pg.y -= pdfLeading
+	for i, c := range cells {
+		fmt.Fprintf(&pg.buf, "BT /F1 9 Tf %.1f %.1f Td (%s) Tj ET\n", pdfMargin+cols[i], pg.y, pdfEscape(truncate(c, 38)))
+	}
+}
+
+func (p *pdfWriter) chart(vals []float64) {
+	pg := p.page()
+	if pg.y-chartH < pdfMargin {
+		p.pages = append(p.pages, &pdfPage{y: pdfHeight - pdfMargin})
+		pg = p.pages[len(p.pages)-1]
+	}
+	pg.y -= chartH + 6
+	w := pdfWidth - 2*pdfMargin
+	fmt.Fprintf(&pg.buf, "0.8 G 0.5 w %.1f %.1f %.1f %.1f re S\n", pdfMargin, pg.y, w, chartH)
+	pts := chartPoints(vals, w, chartH)
+	if len(pts) > 1 {
+		fmt.Fprintf(&pg.buf, "0.2 0.4 0.8 RG 1.5 w\n")
+		for i, pt := range pts {
+			op := "l"
+			if i == 0 {
+				op = "m"
+			}
+			fmt.Fprintf(&pg.buf, "%.1f %.1f %s\n", pdfMargin+pt[0], pg.y+pt[1], op)
+		}
+		pg.buf.WriteString("S 0 G\n")
+	}
+}
+
+func (p *pdfWriter) space(h float64) { p.page().y -= h }
+
+// WritePDF renders the report as a PDF document.
+func WritePDF(w io.Writer, r *Report) error {
+	var p pdfWriter
+	p.text(r.Title(), 18, pdfMargin)
+	p.space(4)
+	p.text(r.Subtitle(), 9, pdfMargin)
+	p.space(10)
+	p.text("Circulating trend", 13, pdfMargin)
+	p.chart(r.values())
+	for _, t := range r.Tables() {
+		p.space(10)
+		p.text(t.Title, 13, pdfMargin)
+		if len(t.Rows) > 0 {
+			cols := columns(len(t.Head))
+			p.row(t.Head, cols)
+			for _, row := range t.Rows {
+				p.row(row, cols)
+			}
+		}
+		if t.Note != "" {
+			p.text(t.Note, 9, pdfMargin)
+		}
+	}
+	return p.write(w)
+}
+
+// columns spreads n columns over the printable width, giving the first one extra room.
+func columns(n int) []float64 {
+	w := pdfWidth - 2*pdfMargin
+	cols := make([]float64, n)
+	if n < 2 {
+		return cols
+	}
+	first := w * 0.3
+	for i := 1; i < n; i++ {
+		cols[i] = first + float64(i-1)*(w-first)/float64(n-1)
+	}
+	return cols
+}
+
+func (p *pdfWriter) write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	out.WriteString("%PDF-1.4\n")
+	// objects: 1 catalog, 2 page tree, 3 font, then a page and its content per page
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	for i, pg := range p.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfWidth, pdfHeight, 5+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", pg.buf.Len(), pg.buf.String()))
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// pdfEscape escapes string delimiters and replaces characters outside printable ASCII,
+// which the standard font encoding cannot show reliably.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,247 @@
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/units"
+)
+
+// Table is one formatted report section; every output format renders the same tables.
+type Table struct {
+	Title string
+	Note  string
+	Head  []string
+	Rows  [][]string
+}
+
+// numeric reports, per column, whether every cell is a number (right-aligned).
+func (t Table) numeric() []bool {
+	out := make([]bool, len(t.Head))
+	for i := range out {
+		out[i] = len(t.Rows) > 0
+		for _, row := range t.Rows {
+			if c := strings.TrimLeft(row[i], "+-"); c == "" || c[0] < '0' || c[0] > '9' {
+				out[i] = false
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Align returns the Markdown delimiter row cells.
+func (t Table) Align() []string {
+	out := make([]string, len(t.Head))
+	for i, num := range t.numeric() {
+		out[i] = "---"
+		if num {
+			out[i] = "---:"
+		}
+	}
+	return out
+}
+
+// Title is the report heading.
+func (r *Report) Title() string {
+	return fmt.Sprintf("%s supply report (%s)", r.Symbol, r.Period)
+}
+
+// Subtitle names the snapshots the report compares.
+func (r *Report) Subtitle() string {
+	return fmt.Sprintf("Block %s at %s, compared with block %s at %s. Generated %s.",
+		units.Format(strconv.FormatInt(r.Latest.Height, 10), 0), date(r.Latest.UpdatedAt),
+		units.Format(strconv.FormatInt(r.Start.Height, 10), 0), date(r.Start.UpdatedAt), date(r.GeneratedAt))
+}
+
+// Tables returns the report sections in display order.
+func (r *Report) Tables() []Table {
+	amt := func(base string) string { return units.Format(base, Decimals) }
+	figures := Table{
+		Title: "Current figures",
+		Head:  []string{"Metric", "Amount (" + r.Symbol + ")", "Change"},
+		Rows: [][]string{
+			{"Total supply", amt(r.Latest.Total), signed(r.Diff.Total.Delta)},
+			{"Circulating", amt(r.Latest.Circulating), signed(r.Diff.Circulating.Delta)},
+			{"Non-circulating", amt(r.Latest.NonCirculating.Sum), signed(r.Diff.NonCirculating.Delta)},
+		},
+	}
+	if r.Latest.Max != nil {
+		figures.Rows = append(figures.Rows, []string{"Max supply", amt(*r.Latest.Max), ""})
+	}
+
+	causes := Table{Title: "Circulating change by cause", Head: []string{"Cause", "Amount (" + r.Symbol + ")"}}
+	for _, a := range r.Diff.Attribution {
+		causes.Rows = append(causes.Rows, []string{strings.ReplaceAll(a.Cause, "_", " "), signed(a.Amount)})
+	}
+	if len(causes.Rows) == 0 {
+		causes.Note = "No circulating change in this period."
+	}
+
+	cohorts := Table{Title: "Cohort changes", Head: []string{"Cohort", "Status", "Change (" + r.Symbol + ")"}}
+	for _, c := range r.Diff.Cohorts {
+		cohorts.Rows = append(cohorts.Rows, []string{c.Name, c.Status, signed(c.Amount.Delta)})
+	}
+	if len(cohorts.Rows) == 0 {
+		cohorts.Note = "No cohort changed in this period."
+	}
+
+	upcoming := Table{
+		Title: fmt.Sprintf("Upcoming unlocks (next %d days)", int(r.Horizon.Hours()/24)),
+		Note:  fmt.Sprintf("Total: %s %s.", amt(r.UpcomingTotal), r.Symbol),
+		Head:  []string{"Date", "Amount (" + r.Symbol + ")", "Addresses", "Cohorts"},
+	}
+	for _, ev := range r.Upcoming {
+		names := make([]string, 0, len(ev.Cohorts))
+		for _, c := range ev.Cohorts {
+			names = append(names, c.Cohort)
+		}
+		upcoming.Rows = append(upcoming.Rows, []string{date(ev.Time), amt(ev.Amount), strconv.Itoa(ev.Addresses), strings.Join(names, ", ")})
+	}
+	if len(upcoming.Rows) == 0 {
+		upcoming.Note = "No unlocks scheduled in this window."
+	}
+	return []Table{figures, causes, cohorts, upcoming}
+}
+
+// values returns the circulating series in display units.
+func (r *Report) values() []float64 {
+	out := make([]float64, 0, len(r.Series))
+	scale := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(Decimals), nil))
+	for _, p := range r.Series {
+		f, ok := new(big.Float).SetString(p.Circulating)
+		if !ok {
+			continue
+		}
+		v, _ := new(big.Float).Quo(f, scale).Float64()
+		out = append(out, v)
+	}
+	return out
+}
+
+func date(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") }
+
+// signed formats a base-unit delta with an explicit sign (+1,234.5).
+func signed(base string) string {
+	s := units.Format(base, Decimals)
+	if s != "0" && !strings.HasPrefix(s, "-") {
+		s = "+" + s
+	}
+	return s
+}
+
+// sparkline renders values as a row of block characters.
+func sparkline(vals []float64) string {
+	bars := []rune("▁▂▃▄▅▆▇█")
+	lo, hi := bounds(vals)
+	var b strings.Builder
+	for _, v := range vals {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(bars)-1))
+		}
+		b.WriteRune(bars[i])
+	}
+	return b.String()
+}
+
+func bounds(vals []float64) (lo, hi float64) {
+	for i, v := range vals {
+		if i == 0 || v < lo {
+			lo = v
+		}
+		if i == 0 || v > hi {
+			hi = v
+		}
+	}
+	return lo, hi
+}
+
+const markdownTemplate = `# {{.R.Title}}
+
+{{.R.Subtitle}}
+
+**Circulating trend:** ` + "`{{.Spark}}`" + `
+{{range .R.Tables}}
+## {{.Title}}
+{{if .Rows}}
+|{{range .Head}} {{.}} |{{end}}
+|{{range .Align}} {{.}} |{{end}}
+{{range .Rows}}|{{range .}} {{.}} |{{end}}
+{{end}}{{end}}{{with .Note}}
+{{.}}
+{{end}}{{end}}`
+
+var mdTmpl = template.Must(template.New("md").Parse(markdownTemplate))
+
+// WriteMarkdown renders the report as GitHub-flavoured Markdown.
+func WriteMarkdown(w io.Writer, r *Report) error {
+	return mdTmpl.Execute(w, map[string]any{"R": r, "Spark": sparkline(r.values())})
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8"><title>{{.R.Title}}</title>
+<style>
+body{font-family:-apple-system,Helvetica,Arial,sans-serif;max-width:860px;margin:2em auto;color:#222}
+table{border-collapse:collapse;width:100%;margin-bottom:1em}
+th,td{border-bottom:1px solid #ddd;padding:4px 8px;text-align:right}
+th:first-child,td:first-child{text-align:left}
+.note{color:#666}
+</style></head><body>
+<h1>{{.R.Title}}</h1>
+<p>{{.R.Subtitle}}</p>
+<h2>Circulating trend</h2>
+{{.Chart}}
+{{range .R.Tables}}<h2>{{.Title}}</h2>
+{{if .Rows}}<table><tr>{{range .Head}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>{{end}}{{with .Note}}<p class="note">{{.}}</p>{{end}}
+{{end}}</body></html>
+`
+
+var htmlTmpl = htmltemplate.Must(htmltemplate.New("html").Parse(htmlTemplate))
+
+// WriteHTML renders the report as a standalone HTML page with an inline SVG chart.
+func WriteHTML(w io.Writer, r *Report) error {
+	return htmlTmpl.Execute(w, map[string]any{"R": r, "Chart": htmltemplate.HTML(svgChart(r.values(), 800, 160))})
+}
+
+// svgChart draws vals as a polyline scaled to the box; values are computed, not user input.
+func svgChart(vals []float64, width, height float64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f">`, width, height, width, height)
+	fmt.Fprintf(&b, `<polyline fill="none" stroke="#3366cc" stroke-width="2" points="`)
+	for i, p := range chartPoints(vals, width, height) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%.1f,%.1f", p[0], height-p[1])
+	}
+	b.WriteString(`"/></svg>`)
+	return b.String()
+}
+
+// chartPoints maps vals onto a width x height box with the origin at the bottom left.
+func chartPoints(vals []float64, width, height float64) [][2]float64 {
+	lo, hi := bounds(vals)
+	const pad = 4
+	out := make([][2]float64, 0, len(vals))
+	for i, v := range vals {
+		x := width / 2
+		if len(vals) > 1 {
+			x = pad + float64(i)*(width-2*pad)/float64(len(vals)-1)
+		}
+		y := height / 2
+		if hi > lo {
+			y = pad + (v-lo)/(hi-lo)*(height-2*pad)
+		}
+		out = append(out, [2]float64{x, y})
+	}
+	return out
+}
@@ -0,0 +1,156 @@
+// Package report builds periodic supply and unlock reports from the snapshot history
+// and renders them as Markdown, HTML or PDF for investor communications.
+package report
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/store"
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+	"github.com/lumera-labs/lumera-supply/pkg/units"
+)
+
+// Decimals is the display exponent used for amounts (ulume -> LUME).
+const Decimals = 6
+
+// maxPoints caps the circulating series so a week of per-minute snapshots is not
+// loaded in full.
+const maxPoints = 48
+
+// maxUpcoming caps the upcoming unlock table.
+const maxUpcoming = 20
+
+// Options selects the report window.
+type Options struct {
+	// Name is the period label ("daily", "weekly").
+	Name string
+	// Period is the look-back window ending at the latest stored snapshot.
+	Period time.Duration
+	// Horizon is how far past the latest snapshot upcoming unlocks are listed.
+	Horizon time.Duration
+	// Now is the generation time (defaults to time.Now).
+	Now time.Time
+}
+
+// ParsePeriod maps a period name to its window.
+func ParsePeriod(name string) (time.Duration, error) {
+	switch name {
+	case "daily":
+		return 24 * time.Hour, nil
+	case "weekly":
+		return 7 * 24 * time.Hour, nil
+	case "monthly":
+		return 30 * 24 * time.Hour, nil
+	}
+	return 0, fmt.Errorf("unknown period %q (want daily, weekly or monthly)", name)
+}
+
+// Report is the data every renderer works from. Amounts are base-unit strings.
+type Report struct {
+	Period      string
+	GeneratedAt time.Time
+	Denom       string
+	Symbol      string
+	Latest      *types.SupplySnapshot
+	Start       *types.SupplySnapshot
+	Diff        types.SnapshotDiff
+	Upcoming    []types.UnlockEvent
+	// UpcomingTotal sums all unlocks in the horizon, including those beyond the table cap.
+	UpcomingTotal string
+	Horizon       time.Duration
+	Series        []Point
+}
+
+// Point is one sample of the circulating series.
+type Point struct {
+	Time        time.Time
+	Height      int64
+	Circulating string
+}
+
+// Build loads the latest stored snapshot and the earliest one inside the period and
+// assembles the report.
+func Build(h *store.History, opt Options) (*Report, error) {
+	if opt.Now.IsZero() {
+		opt.Now = time.Now().UTC()
+	}
+	refs := h.Refs()
+	if len(refs) == 0 {
+		return nil, errors.New("history is empty")
+	}
+	latestRef := refs[len(refs)-1]
+	latest, err := h.ByETag(latestRef.ETag)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := latest.UpdatedAt.Add(-opt.Period)
+	first := len(refs) - 1
+	for i, r := range refs {
+		if !r.UpdatedAt.Before(cutoff) {
+			first = i
+			break
+		}
+	}
+	start, err := h.ByETag(refs[first].ETag)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{
+		Period:      opt.Name,
+		GeneratedAt: opt.Now,
+		Denom:       latest.Denom,
+		Symbol:      units.Symbol(latest.Denom),
+		Latest:      latest,
+		Start:       start,
+		Diff:        supply.Diff(start, latest),
+		Horizon:     opt.Horizon,
+	}
+	from := latest.EvaluationTime()
+	all := supply.UnlockSchedule(latest, from, from.Add(opt.Horizon))
+	sum := new(big.Int)
+	for _, ev := range all {
+		if v, ok := new(big.Int).SetString(ev.Amount, 10); ok {
+			sum.Add(sum, v)
+		}
+	}
+	r.UpcomingTotal = sum.String()
+	if len(all) > maxUpcoming {
+		all = all[:maxUpcoming]
+	}
+	r.Upcoming = all
+
+	window := refs[first:]
+	step := 1
+	if len(window) > maxPoints {
+		step = (len(window) + maxPoints - 1) / maxPoints
+	}
+	for i := 0; i < len(window); i += step {
+		p, err := pointOf(h, window[i], start, latest)
+		if err != nil {
+			return nil, err
+		}
+		r.Series = append(r.Series, p)
+	}
+	if last := r.Series[len(r.Series)-1]; last.Height != latest.Height {
+		r.Series = append(r.Series, Point{Time: latest.UpdatedAt, Height: latest.Height, Circulating: latest.Circulating})
+	}
+	return r, nil
+}
+
+func pointOf(h *store.History, ref types.SnapshotRef, known ...*types.SupplySnapshot) (Point, error) {
+	for _, s := range known {
+		if s.ETag == ref.ETag {
+			return Point{Time: s.UpdatedAt, Height: s.Height, Circulating: s.Circulating}, nil
+		}
+	}
+	s, err := h.ByETag(ref.ETag)
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{Time: s.UpdatedAt, Height: s.Height, Circulating: s.Circulating}, nil
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/store"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+func TestBuildAndRender(t *testing.T) {
+	s, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	h, err := s.OpenHistory()
+	if err != nil {
+		t.Fatalf("open history: %v", err)
+	}
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	unlock := base.Add(10 * 24 * time.Hour)
+	snap := func(height int64, circ, locked string, at time.Time) *types.SupplySnapshot {
+		return &types.SupplySnapshot{Denom: "ulume", Height: height, UpdatedAt: at, ETag: "e" + circ,
+			Total: "1000000000", Circulating: circ, NonCirculating: types.NonCircBreakdown{Sum: locked, Cohorts: []types.CohortEntry{
+				{Name: "claim_delayed", Amount: locked, Items: []types.AddressItem{
+					{Address: "lumera1a", Amount: locked, EndDate: unlock.Format(time.RFC3339), EndUnix: unlock.Unix()},
+				}},
+			}}}
+	}
+	for _, sn := range []*types.SupplySnapshot{
+		snap(10, "600000000", "400000000", base.Add(-10*24*time.Hour)), // outside the week
+		snap(20, "700000000", "300000000", base.Add(-3*24*time.Hour)),
+		snap(30, "750000000", "250000000", base),
+	} {
+		if err := h.Put(sn); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+	}
+	r, err := Build(h, Options{Name: "weekly", Period: 7 * 24 * time.Hour, Horizon: 30 * 24 * time.Hour, Now: base})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if r.Start.Height != 20 || r.Latest.Height != 30 || r.Diff.Circulating.Delta != "50000000" {
+		t.Fatalf("unexpected window: start=%d latest=%d delta=%s", r.Start.Height, r.Latest.Height, r.Diff.Circulating.Delta)
+	}
+	if len(r.Upcoming) != 1 || r.UpcomingTotal != "250000000" || len(r.Series) != 2 {
+		t.Fatalf("unexpected upcoming/series: %+v %s %+v", r.Upcoming, r.UpcomingTotal, r.Series)
+	}
+
+	var md, html, pdf bytes.Buffer
+	if err := WriteMarkdown(&md, r); err != nil {
+		t.Fatalf("markdown: %v", err)
+	}
+	if !strings.Contains(md.String(), "| Circulating | 750 | +50 |") || !strings.Contains(md.String(), "| vesting unlock | +50 |") {
+		t.Fatalf("unexpected markdown:\n%s", md.String())
+	}
+	if err := WriteHTML(&html, r); err != nil {
+		t.Fatalf("html: %v", err)
+	}
+	if !strings.Contains(html.String(), "<polyline") || !strings.Contains(html.String(), "<td>250</td>") {
+		t.Fatalf("unexpected html:\n%s", html.String())
+	}
+	if err := WritePDF(&pdf, r); err != nil {
+		t.Fatalf("pdf: %v", err)
+	}
+	if !bytes.HasPrefix(pdf.Bytes(), []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf.Bytes(), []byte("%%EOF\n")) ||
+		!bytes.Contains(pdf.Bytes(), []byte("(Circulating)")) {
+		t.Fatalf("unexpected pdf output")
+	}
+}
@@ -0,0 +1,159 @@
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/cache"
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+	"github.com/lumera-labs/lumera-supply/pkg/httpserver"
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/mockchain"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+)
+
+// Failure is an expectation the service did not meet.
+type Failure struct {
+	Step    string
+	Path    string
+	Message string
+}
+
+func (f Failure) Error() string {
+	return fmt.Sprintf("step %q: GET %s: %s", f.Step, f.Path, f.Message)
+}
+
+// Run plays sc: it serves sc.Chain from a frozen mock chain, runs the service against
+// it in process, and after each step recomputes the snapshot and checks the step's
+// expectations. It returns the failed expectations; err is set when the scenario
+// cannot be played (an invalid policy or chain state, a failed snapshot).
+func Run(ctx context.Context, sc *Scenario) ([]Failure, error) {
+	var pol *policy.Policy
+	if len(sc.Policy) > 0 {
+		var err error
+		if pol, err = policy.Parse(sc.Policy); err != nil {
+			return nil, fmt.Errorf("policy: %w", err)
+		}
+	}
+	chain := sc.Chain
+	mock, err := mockchain.New(&chain)
+	if err != nil {
+		return nil, fmt.Errorf("chain: %w", err)
+	}
+	mock.Freeze()
+	up := httptest.NewServer(mock)
+	defer up.Close()
+
+	client := lcd.NewClient(up.URL, up.Client())
+	comp := supply.NewComputer(client, pol)
+	// snapshots are only recomputed by the runner, after each step
+	c := cache.NewSnapshotCache(comp, cache.Options{TTL: 24 * time.Hour})
+	defer c.Close()
+	srv := httpserver.New(httpserver.Config{Cache: c, Computer: comp, LCD: client, DefaultDenom: chain.Denom, Limits: config.Limits{RatePerMin: 600000, Burst: 100000}})
+
+	var failures []Failure
+	for i, st := range sc.Steps {
+		name := st.Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		d, err := parseAdvance(st.Advance)
+		if err != nil {
+			return failures, fmt.Errorf("step %q: %w", name, err)
+		}
+		mock.Advance(d)
+		if err := mock.Apply(st.Chain); err != nil {
+			return failures, fmt.Errorf("step %q: chain: %w", name, err)
+		}
+		if _, err := c.Update(ctx, chain.Denom); err != nil {
+			return failures, fmt.Errorf("step %q: snapshot: %w", name, err)
+		}
+		for _, e := range st.Expect {
+			for _, msg := range check(srv, e) {
+				failures = append(failures, Failure{Step: name, Path: e.Path, Message: msg})
+			}
+		}
+	}
+	return failures, nil
+}
+
+// check issues e's request and describes every way the answer differs from e.
+func check(h http.Handler, e Expect) []string {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, e.Path, nil))
+	want := e.Status
+	if want == 0 {
+		want = http.StatusOK
+	}
+	if rec.Code != want {
+		return []string{fmt.Sprintf("status %d, want %d: %s", rec.Code, want, strings.TrimSpace(rec.Body.String()))}
+	}
+	var msgs []string
+	if e.Text != nil && rec.Body.String() != *e.Text {
+		msgs = append(msgs, fmt.Sprintf("body %q, want %q", rec.Body.String(), *e.Text))
+	}
+	if len(e.JSON) == 0 {
+		return msgs
+	}
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(rec.Body.Bytes()))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return append(msgs, fmt.Sprintf("body is not JSON: %v", err))
+	}
+	for field, raw := range e.JSON {
+		got, ok := lookup(doc, field)
+		if !ok {
+			msgs = append(msgs, fmt.Sprintf("%s: missing", field))
+			continue
+		}
+		if g, w := canonical(got), canonical(raw); g != w {
+			msgs = append(msgs, fmt.Sprintf("%s: %s, want %s", field, g, w))
+		}
+	}
+	return msgs
+}
+
+// lookup follows a dotted path through objects and (by index) arrays.
+func lookup(v any, path string) (any, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch t := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = t[key]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, false
+			}
+			v = t[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// canonical renders a JSON value (decoded, or raw) in compact form with sorted keys,
+// so equal values compare equal.
+func canonical(v any) string {
+	if raw, ok := v.(json.RawMessage); ok {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return string(raw)
+		}
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,51 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestScenarios(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no scenarios: %v", err)
+	}
+	for _, f := range files {
+		t.Run(filepath.Base(f), func(t *testing.T) {
+			sc, err := Load(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			failures, err := Run(context.Background(), sc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range failures {
+				t.Error(f)
+			}
+		})
+	}
+}
+
+func TestRunReportsMismatches(t *testing.T) {
+	sc, err := Load("testdata/staking_pool.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.Steps[1].Expect = []Expect{
+		{Path: "/staked", JSON: map[string]json.RawMessage{"bonded": json.RawMessage(`"1"`), "nope.field": json.RawMessage(`1`)}},
+		{Path: "/staked", Status: 404},
+	}
+	failures, err := Run(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 3 {
+		t.Fatalf("failures: %v", failures)
+	}
+	if _, err := parseAdvance("-1d"); err == nil {
+		t.Fatal("negative advance accepted")
+	}
+}
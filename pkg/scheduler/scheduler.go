@@ -0,0 +1,217 @@
+// Package scheduler runs the service's periodic jobs (snapshot refresh, capability
+// probes, exports, history pruning) and records each job's runs, so they can be listed
+// and triggered through the admin API instead of hiding in ad-hoc goroutines.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/recovery"
+)
+
+var (
+	jobRuns = metrics.Default.NewCounter(
+		"lumera_supply_job_runs_total",
+		"Scheduled job runs by result (ok or error).",
+		"job", "result",
+	)
+	jobDuration = metrics.Default.NewHistogram(
+		"lumera_supply_job_duration_seconds",
+		"Scheduled job run duration.",
+		[]float64{0.1, 0.5, 1, 5, 15, 60, 300},
+		"job",
+	)
+)
+
+// ErrUnknownJob is returned by Trigger for a name that was never added.
+var ErrUnknownJob = errors.New("unknown job")
+
+// Job is a periodic task. It runs once when the scheduler starts, then again Every
+// after each run ends, and whenever it is triggered.
+type Job struct {
+	Name string
+	// Every returns the wait after a run; it is called after every run, so jobs can
+	// follow runtime changes (e.g. the refresh TTL) or retry sooner after a failure.
+	Every func() time.Duration
+	// Run's context is cancelled by Stop.
+	Run func(ctx context.Context) error
+	// Quiet skips the log line for failed runs, for jobs that log their own errors.
+	Quiet bool
+}
+
+// Fixed is an Every for a constant interval.
+func Fixed(d time.Duration) func() time.Duration {
+	return func() time.Duration { return d }
+}
+
+// Status is a job's run history as reported by the admin API.
+type Status struct {
+	Name     string     `json:"name"`
+	Running  bool       `json:"running"`
+	Runs     int64      `json:"runs"`
+	Failures int64      `json:"failures"`
+	LastRun  *time.Time `json:"last_run,omitempty"`
+	// LastDurationMS is how long the last run took.
+	LastDurationMS int64      `json:"last_duration_ms"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastSuccess    *time.Time `json:"last_success,omitempty"`
+	// NextRun is unset while the job runs.
+	NextRun *time.Time `json:"next_run,omitempty"`
+}
+
+type entry struct {
+	job     Job
+	trigger chan struct{}
+	st      Status
+}
+
+// Scheduler runs jobs, each in its own goroutine, so a slow job never delays another.
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    map[string]*entry
+	started bool
+
+	ctx     context.Context
+	stop    context.CancelFunc
+	running sync.WaitGroup
+}
+
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{jobs: map[string]*entry{}, ctx: ctx, stop: cancel}
+}
+
+// Add registers j; it starts with the scheduler, or at once if Start was called.
+func (s *Scheduler) Add(j Job) error {
+	if j.Name == "" || j.Run == nil || j.Every == nil {
+		return fmt.Errorf("job %q: name, run and every are required", j.Name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, dup := s.jobs[j.Name]; dup {
+		return fmt.Errorf("job %q already added", j.Name)
+	}
+	e := &entry{job: j, trigger: make(chan struct{}, 1), st: Status{Name: j.Name}}
+	s.jobs[j.Name] = e
+	if s.started {
+		s.running.Add(1)
+		go s.loop(e)
+	}
+	return nil
+}
+
+// Start runs every job added so far; later ones start as they are added.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	for _, e := range s.jobs {
+		s.running.Add(1)
+		go s.loop(e)
+	}
+}
+
+// Stop cancels the context of running jobs, schedules no further runs and waits for
+// the running ones to return, or for ctx to be done.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.stop()
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Trigger runs the named job now, or right after its current run. Triggers arriving
+// while one is pending are merged.
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	e := s.jobs[name]
+	s.mu.Unlock()
+	if e == nil {
+		return fmt.Errorf("%w %q", ErrUnknownJob, name)
+	}
+	select {
+	case e.trigger <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Jobs returns the status of every job, by name.
+func (s *Scheduler) Jobs() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		out = append(out, e.st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (s *Scheduler) loop(e *entry) {
+	defer s.running.Done()
+	for s.ctx.Err() == nil {
+		s.run(e)
+		wait := e.job.Every()
+		next := time.Now().Add(wait)
+		s.mu.Lock()
+		e.st.NextRun = &next
+		s.mu.Unlock()
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-e.trigger:
+			t.Stop()
+		case <-s.ctx.Done():
+			t.Stop()
+		}
+	}
+}
+
+func (s *Scheduler) run(e *entry) {
+	start := time.Now()
+	s.mu.Lock()
+	e.st.Running, e.st.LastRun, e.st.NextRun = true, &start, nil
+	s.mu.Unlock()
+
+	// a panicking job is a failed run, not a dead scheduler
+	err := recovery.Guard("job:"+e.job.Name, func() error { return e.job.Run(s.ctx) })
+
+	took := time.Since(start)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.st.Running = false
+	e.st.Runs++
+	e.st.LastDurationMS = took.Milliseconds()
+	jobDuration.Observe(took.Seconds(), e.job.Name)
+	if err != nil {
+		e.st.Failures++
+		e.st.LastError = err.Error()
+		jobRuns.Inc(e.job.Name, "error")
+		if !e.job.Quiet {
+			log.Printf("warn: job %s: %v", e.job.Name, err)
+		}
+		return
+	}
+	e.st.LastError = ""
+	e.st.LastSuccess = &start
+	jobRuns.Inc(e.job.Name, "ok")
+}
@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSchedulerRunsAndTriggers(t *testing.T) {
+	s := New()
+	var runs atomic.Int32
+	fail := errors.New("boom")
+	err := s.Add(Job{Name: "probe", Every: Fixed(time.Hour), Quiet: true, Run: func(context.Context) error {
+		if runs.Add(1) == 2 {
+			return fail
+		}
+		return nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(Job{Name: "probe", Every: Fixed(time.Hour), Run: func(context.Context) error { return nil }}); err == nil {
+		t.Fatal("expected an error for a duplicate job")
+	}
+	if err := s.Trigger("missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Fatalf("trigger unknown: %v", err)
+	}
+
+	s.Start()
+	waitFor(t, "first run", func() bool { st := s.Jobs()[0]; return st.Runs == 1 && st.NextRun != nil })
+	st := s.Jobs()[0]
+	if st.Name != "probe" || st.Failures != 0 || st.LastSuccess == nil || st.Running || st.NextRun.Before(time.Now().Add(59*time.Minute)) {
+		t.Fatalf("after first run: %+v", st)
+	}
+
+	// a trigger runs the job without waiting out the hour
+	if err := s.Trigger("probe"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "triggered run", func() bool { return s.Jobs()[0].Runs == 2 && s.Jobs()[0].NextRun != nil })
+	st = s.Jobs()[0]
+	if st.Failures != 1 || st.LastError != "boom" || !st.LastSuccess.Before(*st.LastRun) {
+		t.Fatalf("after failed run: %+v", st)
+	}
+
+	// jobs added after Start run at once
+	var late atomic.Bool
+	if err := s.Add(Job{Name: "late", Every: Fixed(time.Hour), Run: func(context.Context) error { late.Store(true); return nil }}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "late job", late.Load)
+	if jobs := s.Jobs(); len(jobs) != 2 || jobs[0].Name != "late" {
+		t.Fatalf("jobs not sorted by name: %+v", jobs)
+	}
+}
+
+func TestSchedulerStopCancelsJobs(t *testing.T) {
+	s := New()
+	var started, stopped atomic.Bool
+	if err := s.Add(Job{Name: "blocking", Every: Fixed(time.Millisecond), Run: func(ctx context.Context) error {
+		started.Store(true)
+		<-ctx.Done()
+		stopped.Store(true)
+		return ctx.Err()
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	waitFor(t, "job start", started.Load)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if !stopped.Load() {
+		t.Fatal("stop returned before the running job")
+	}
+	runs := s.Jobs()[0].Runs
+	time.Sleep(10 * time.Millisecond)
+	if st := s.Jobs()[0]; st.Runs != runs || st.Running {
+		t.Fatalf("job ran after stop: %+v", st)
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+	"github.com/lumera-labs/lumera-supply/pkg/logging"
+)
+
+// resolveLimits merges the tuning flags with the config file's tuning section. A file
+// value applies only when neither the flag nor its environment variable was given;
+// the file was validated on load, so durations parse.
+func resolveLimits(fs *flag.FlagSet, l config.Limits, t config.Tuning) (config.Limits, error) {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	unset := func(name, env string) bool { return !set[name] && os.Getenv(env) == "" }
+
+	if t.RefreshTTL != "" && unset("refresh-ttl", "LUMERA_REFRESH_TTL") {
+		l.RefreshTTL, _ = time.ParseDuration(t.RefreshTTL)
+	}
+	if t.RatePerMin != 0 && unset("rate-per-min", "LUMERA_RATE_PER_MIN") {
+		l.RatePerMin = t.RatePerMin
+	}
+	if t.Burst != 0 && unset("rate-burst", "LUMERA_RATE_BURST") {
+		l.Burst = t.Burst
+	}
+	if t.LCDTimeout != "" && unset("lcd-timeout", "LUMERA_LCD_TIMEOUT") {
+		l.LCDTimeout, _ = time.ParseDuration(t.LCDTimeout)
+	}
+	if t.Concurrency != 0 && unset("lcd-concurrency", "LUMERA_LCD_CONCURRENCY") {
+		l.Concurrency = t.Concurrency
+	}
+	return l, l.Validate()
+}
+
+// resolveLogLevel picks the -log-level flag, or the config file's level when neither
+// the flag nor its environment variable was given.
+func resolveLogLevel(fs *flag.FlagSet, flagLevel, fileLevel string) (logging.Level, error) {
+	set := false
+	fs.Visit(func(f *flag.Flag) { set = set || f.Name == "log-level" })
+	if fileLevel != "" && !set && os.Getenv("LUMERA_LOG_LEVEL") == "" {
+		flagLevel = fileLevel
+	}
+	return logging.ParseLevel(flagLevel)
+}
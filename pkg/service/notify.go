@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"slices"
+
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+	"github.com/lumera-labs/lumera-supply/pkg/notify"
+)
+
+// buildNotifier assembles the configured sinks: the default webhook (when set), one
+// routed email notifier per config route, and the on-call integrations. It returns nil
+// when nothing is configured.
+func buildNotifier(conf *config.File, webhookURL string) (notify.Notifier, error) {
+	var sinks notify.Multi
+	if webhookURL != "" {
+		sinks = append(sinks, &notify.Webhook{URL: webhookURL})
+	}
+	n := conf.Notifications
+	for i, r := range n.Email {
+		for _, k := range r.Events {
+			if !slices.Contains(notify.Kinds, k) {
+				return nil, fmt.Errorf("notifications.email[%d]: unknown event %q (want one of %v)", i, k, notify.Kinds)
+			}
+		}
+		var auth smtp.Auth
+		if n.SMTP.Username != "" {
+			host, _, _ := net.SplitHostPort(n.SMTP.Addr)
+			auth = smtp.PlainAuth("", n.SMTP.Username, os.Getenv(n.SMTP.PasswordEnv), host)
+		}
+		e, err := notify.NewEmail(n.SMTP.Addr, n.SMTP.From, r.To, auth, r.Subject, r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("notifications.email[%d]: %w", i, err)
+		}
+		sinks = append(sinks, notify.Only(r.Events, e))
+	}
+	source, _ := os.Hostname()
+	if pd := n.PagerDuty; pd != nil {
+		key := os.Getenv(pd.RoutingKeyEnv)
+		if key == "" {
+			return nil, fmt.Errorf("notifications.pagerduty: %s is not set", pd.RoutingKeyEnv)
+		}
+		sinks = append(sinks, &notify.PagerDuty{RoutingKey: key, Source: source, URL: pd.URL})
+	}
+	if og := n.Opsgenie; og != nil {
+		key := os.Getenv(og.APIKeyEnv)
+		if key == "" {
+			return nil, fmt.Errorf("notifications.opsgenie: %s is not set", og.APIKeyEnv)
+		}
+		sinks = append(sinks, &notify.Opsgenie{APIKey: key, Source: source, URL: og.URL})
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return sinks, nil
+}
@@ -0,0 +1,440 @@
+// Package service runs the supply HTTP service: flags, upstream clients, the snapshot
+// cache, periodic jobs and graceful shutdown. Both binaries start it, lumera-supply
+// directly and lumera-supply-cli as its serve subcommand, so one binary can be shipped.
+package service
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata" // ?tz= works without zoneinfo in the image
+
+	lumerasupply "github.com/lumera-labs/lumera-supply"
+	"github.com/lumera-labs/lumera-supply/pkg/cache"
+	"github.com/lumera-labs/lumera-supply/pkg/config"
+	"github.com/lumera-labs/lumera-supply/pkg/export"
+	"github.com/lumera-labs/lumera-supply/pkg/httpserver"
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/logging"
+	"github.com/lumera-labs/lumera-supply/pkg/notify"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/scheduler"
+	"github.com/lumera-labs/lumera-supply/pkg/slo"
+	"github.com/lumera-labs/lumera-supply/pkg/store"
+	"github.com/lumera-labs/lumera-supply/pkg/supply"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// Version identifies the build, set by the binaries through -ldflags.
+type Version struct {
+	GitTag    string
+	GitCommit string
+}
+
+// Main runs the service with the command-line arguments args (without the program
+// or subcommand name) until SIGTERM or SIGINT. Invalid flags and setup failures exit
+// the process.
+func Main(args []string, v Version) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	var (
+		addr        = fs.String("addr", getEnv("LUMERA_HTTP_ADDR", ":8080"), "HTTP listen address")
+		lcdURL      = fs.String("lcd", getEnv("LUMERA_LCD_URL", "http://localhost:1317"), "Cosmos LCD base URL; a comma-separated list adds fallbacks, used in order when the first fails")
+		configPath  = fs.String("config", getEnv("LUMERA_CONFIG", ""), "Path to optional JSON config file (custom endpoints)")
+		policyPath  = fs.String("policy", getEnv("LUMERA_POLICY_PATH", "policy.json"), "Path to policy JSON file")
+		candPath    = fs.String("candidate-policy", getEnv("LUMERA_CANDIDATE_POLICY", ""), "Candidate policy evaluated alongside the active one for /policy/candidate (optional)")
+		defaultDen  = fs.String("denom", getEnv("LUMERA_DEFAULT_DENOM", "ulume"), "Default base denom")
+		rpcURL      = fs.String("rpc", getEnv("LUMERA_RPC_URL", ""), "CometBFT RPC URL for batched queries and height fallback (optional)")
+		claimRoutes = fs.String("claim-prefixes", getEnv("LUMERA_CLAIM_PREFIXES", strings.Join(lcd.DefaultClaimPrefixes, ",")), "Comma-separated claim module LCD route prefixes, tried in order until one serves")
+		storeDir    = fs.String("store", getEnv("LUMERA_STORE_DIR", ""), "Directory for persisted snapshot data (empty = in-memory only)")
+		quorumURLs  = fs.String("lcd-quorum", getEnv("LUMERA_LCD_QUORUM", ""), "Comma-separated independent LCD URLs; snapshots publish only when a majority agree (optional)")
+		caFile      = fs.String("lcd-ca", getEnv("LUMERA_LCD_CA_FILE", ""), "PEM file with extra CA certificates for LCD/RPC TLS (optional)")
+		insecure    = fs.Bool("lcd-insecure", getEnv("LUMERA_LCD_INSECURE", "") == "true", "Skip TLS verification for LCD/RPC (development only)")
+		compression = fs.String("lcd-compression", getEnv("LUMERA_LCD_COMPRESSION", lcd.CompressionGzip), "Accept-Encoding for LCD/RPC responses: gzip or none")
+		dnsRefresh  = fs.Duration("dns-refresh", getEnvDuration("LUMERA_DNS_REFRESH", time.Minute), "Re-resolve LCD/RPC hostnames at this interval (0 = rely on the OS resolver per dial)")
+		adminToken  = fs.String("admin-token", getEnv("LUMERA_ADMIN_TOKEN", ""), "Bearer token for /debug/* endpoints (empty = disabled)")
+		webhookURL  = fs.String("webhook-url", getEnv("LUMERA_WEBHOOK_URL", ""), "Default webhook for unlock and service event notifications (optional)")
+		slackSecret = fs.String("slack-signing-secret", getEnv("LUMERA_SLACK_SIGNING_SECRET", ""), "Slack app signing secret for POST /integrations/slack (empty = disabled)")
+		summaryTpl  = fs.String("summary-template", getEnv("LUMERA_SUMMARY_TEMPLATE", ""), "Go text/template for /summary (empty = built-in)")
+		historyCap  = fs.Int("history-keep", getEnvInt("LUMERA_HISTORY_KEEP", 1440), "Stored snapshots to keep for /diff (needs -store)")
+		haltAfter   = fs.Duration("halt-after", getEnvDuration("LUMERA_HALT_AFTER", 5*time.Minute), "Block-time lag after which responses are flagged possibly stale")
+		refreshTTL  = fs.Duration("refresh-ttl", getEnvDuration("LUMERA_REFRESH_TTL", config.DefaultLimits.RefreshTTL), "Snapshot refresh interval")
+		ratePerMin  = fs.Int("rate-per-min", getEnvInt("LUMERA_RATE_PER_MIN", config.DefaultLimits.RatePerMin), "Requests per minute allowed per client IP")
+		rateBurst   = fs.Int("rate-burst", getEnvInt("LUMERA_RATE_BURST", config.DefaultLimits.Burst), "Request burst allowed per client IP")
+		bulkRate    = fs.Int("bulk-rate-per-min", getEnvInt("LUMERA_BULK_RATE_PER_MIN", httpserver.DefaultBulkRatePerMin), "Bulk downloads (/snapshot.json, items.ndjson) per minute allowed per client IP")
+		bulkBurst   = fs.Int("bulk-burst", getEnvInt("LUMERA_BULK_BURST", httpserver.DefaultBulkBurst), "Bulk download burst allowed per client IP")
+		maxItems    = fs.Int("max-cohort-items", getEnvInt("LUMERA_MAX_COHORT_ITEMS", httpserver.DefaultMaxCohortItems), "Cohort items above which /non_circulating summarizes a cohort and /non_circulating/{cohort} pages")
+		lcdTimeout  = fs.Duration("lcd-timeout", getEnvDuration("LUMERA_LCD_TIMEOUT", config.DefaultLimits.LCDTimeout), "Timeout for each LCD/RPC request")
+		lcdConc     = fs.Int("lcd-concurrency", getEnvInt("LUMERA_LCD_CONCURRENCY", config.DefaultLimits.Concurrency), "LCD lookups one request or snapshot may run in parallel")
+		lcdTimeouts = fs.String("lcd-timeouts", getEnv("LUMERA_LCD_TIMEOUTS", ""), "Per-endpoint LCD/RPC timeouts as key=duration pairs, on top of "+lcd.FormatEndpointTimeouts(lcd.DefaultEndpointTimeouts)+" (0 removes a key)")
+		lcdMaxBody  = fs.Int("lcd-max-body", getEnvInt("LUMERA_LCD_MAX_BODY", lcd.DefaultMaxResponseBytes), "Largest LCD/RPC response body to decode, in bytes")
+		trackBurns  = fs.Bool("track-burns", getEnv("LUMERA_TRACK_BURNS", "") == "true", "Follow block results (needs -rpc) to track mints, burns and transfers to burn addresses")
+		reqPolicy   = fs.Bool("require-policy", getEnv("LUMERA_REQUIRE_POLICY", "") == "true", "Answer /circulating with 503 while snapshots are computed without a policy")
+		serveStale  = fs.Duration("serve-stale", getEnvDuration("LUMERA_SERVE_STALE", 0), "Serve the last good snapshot up to this old when a refresh fails (0 = answer 502)")
+		maxStale    = fs.Duration("max-stale", getEnvDuration("LUMERA_MAX_STALE", 10*time.Minute), "Serve a snapshot past its TTL while a background refresh runs, up to this old; older ones get 503 (0 = refresh within the request)")
+		negCirc     = fs.String("negative-circulating", getEnv("LUMERA_NEGATIVE_CIRCULATING", "reject"), "Snapshots whose non-circulating sum exceeds total supply: reject (keep the last good one) or publish")
+		drain       = fs.Duration("shutdown-timeout", getEnvDuration("LUMERA_SHUTDOWN_TIMEOUT", 20*time.Second), "On SIGTERM/SIGINT, wait up to this long for in-flight requests and jobs (keep below the orchestrator's grace period)")
+		warmup      = fs.Duration("warmup", getEnvDuration("LUMERA_WARMUP", 0), "Wait up to this long for the first snapshot before listening (0 = listen immediately)")
+		logLevel    = fs.String("log-level", getEnv("LUMERA_LOG_LEVEL", "info"), "Minimum log level: debug, info or warn")
+	)
+	_ = fs.Parse(args)
+	log.SetOutput(logging.Filter(os.Stderr))
+
+	conf, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	limits, err := resolveLimits(fs, config.Limits{RefreshTTL: *refreshTTL, RatePerMin: *ratePerMin, Burst: *rateBurst, LCDTimeout: *lcdTimeout, Concurrency: *lcdConc}, conf.Tuning)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	level, err := resolveLogLevel(fs, *logLevel, conf.Tuning.LogLevel)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	for _, e := range conf.Endpoints {
+		if _, err := httpserver.CompileEndpoint(e); err != nil {
+			log.Fatalf("config: %v", err)
+		}
+	}
+	notifier, err := buildNotifier(conf, *webhookURL)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	pol, fallback, err := policy.LoadOrEmbedded(*policyPath, lumerasupply.EmbeddedPolicy)
+	switch {
+	case fallback:
+		log.Printf("warn: policy load: %v; using the policy embedded at build time (%s)", err, pol.ETag)
+	case err != nil:
+		log.Printf("policy load warning: %v (service will start but /circulating may be incomplete)", err)
+	}
+
+	// outbound transport honors HTTPS_PROXY / NO_PROXY and the optional extra CAs; its
+	// DNS refresh stops when the service returns
+	bg, stopBG := context.WithCancel(context.Background())
+	defer stopBG()
+	transport, err := lcd.NewTransport(lcd.TransportOptions{CAFile: *caFile, InsecureSkipVerify: *insecure, DNSRefresh: *dnsRefresh, Compression: *compression, Context: bg})
+	if err != nil {
+		log.Fatalf("lcd transport: %v", err)
+	}
+	if *insecure {
+		log.Printf("warn: TLS verification disabled for LCD/RPC calls")
+	}
+	// chaos mode (staging only): faults injected into the primary LCD/RPC client via /admin/chaos
+	var chaos *lcd.Chaos
+	var primary http.RoundTripper = transport
+	if os.Getenv("LUMERA_CHAOS") == "1" {
+		chaos = lcd.NewChaos(transport)
+		primary = chaos
+		log.Printf("warn: CHAOS MODE enabled; upstream faults can be injected via /admin/chaos")
+	}
+	// request timeouts can be changed at runtime through /admin/tuning
+	endpointTimeouts, err := lcd.ParseEndpointTimeouts(*lcdTimeouts)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	primaryTimeout := lcd.NewTimeout(primary, limits.LCDTimeout)
+	peerTimeout := lcd.NewTimeout(transport, limits.LCDTimeout)
+	primaryTimeout.SetEndpoints(endpointTimeouts)
+	peerTimeout.SetEndpoints(endpointTimeouts)
+	lcdURLs := lcd.ParseURLs(*lcdURL)
+	if len(lcdURLs) == 0 {
+		log.Fatalf("config: -lcd is empty")
+	}
+	// fallback endpoints: each attempt gets its own timeout, so a hung endpoint fails
+	// over instead of using up the request
+	var upstream http.RoundTripper = primaryTimeout
+	var failover *lcd.Failover
+	if len(lcdURLs) > 1 {
+		failover = lcd.NewFailover(lcdURLs, primaryTimeout)
+		upstream = failover
+		log.Printf("lcd failover: %d endpoints", len(lcdURLs))
+	}
+	client := lcd.NewClient(lcdURLs[0], &http.Client{Transport: upstream})
+	client.SetRPC(*rpcURL)
+	client.SetClaimPrefixes(strings.Split(*claimRoutes, ","))
+	client.SetResponseLimits(int64(*lcdMaxBody), 0)
+	// periodic jobs, listed and triggered through /admin/jobs; started once all are added
+	jobs := scheduler.New()
+	// probe node capabilities (archive state, optional routes) at startup and hourly
+	// thereafter so chain upgrades switch query strategies; reported in /status. The
+	// probes run on client itself, which records the result.
+	mustAddJob(jobs, scheduler.Job{Name: "capabilities", Every: scheduler.Fixed(time.Hour), Run: func(context.Context) error {
+		client.DetectCapabilities(*defaultDen)
+		return nil
+	}})
+	if failover != nil {
+		// bring endpoints marked down back into rotation once they answer again
+		mustAddJob(jobs, scheduler.Job{Name: "lcd_probe", Every: scheduler.Fixed(30 * time.Second), Run: failover.Probe})
+	}
+
+	// Supply computer
+	computer := supply.NewComputer(client, pol)
+	if *quorumURLs != "" {
+		var peers []*lcd.Client
+		for _, u := range strings.Split(*quorumURLs, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				peer := lcd.NewClient(u, &http.Client{Transport: peerTimeout})
+				peer.SetClaimPrefixes(strings.Split(*claimRoutes, ","))
+				peer.SetResponseLimits(int64(*lcdMaxBody), 0)
+				peers = append(peers, peer)
+			}
+		}
+		computer.SetQuorum(peers)
+		log.Printf("quorum mode: %d peer LCD(s)", len(peers))
+	}
+	computer.SetConcurrency(limits.Concurrency)
+	switch *negCirc {
+	case "reject":
+	case "publish":
+		computer.SetPublishNegative(true)
+	default:
+		log.Fatalf("-negative-circulating must be reject or publish")
+	}
+	if *candPath != "" {
+		cand, err := policy.Load(*candPath)
+		if err != nil {
+			log.Fatalf("candidate policy: %v", err)
+		}
+		computer.SetCandidate(cand)
+		log.Printf("candidate policy loaded (%s)", cand.ETag)
+	}
+
+	var st *store.FileStore
+	if *storeDir != "" {
+		if st, err = store.Open(*storeDir); err != nil {
+			log.Fatalf("store open: %v", err)
+		}
+	}
+
+	if *trackBurns {
+		if *rpcURL == "" {
+			log.Fatalf("-track-burns needs -rpc")
+		}
+		burns, err := supply.NewBurnTracker(*defaultDen, st)
+		if err != nil {
+			log.Fatalf("burn tracker: %v", err)
+		}
+		computer.SetBurnTracker(burns)
+	}
+
+	// Snapshot cache with refresher
+	c := cache.NewSnapshotCache(computer, cache.Options{TTL: limits.RefreshTTL, Store: st, MaxStale: *maxStale})
+	mustAddJob(jobs, scheduler.Job{Name: "refresh", Every: c.TTL, Quiet: true, Run: func(ctx context.Context) error { return c.Refresh(ctx, *defaultDen) }})
+
+	// unlock subscriptions, evaluated on every new snapshot
+	watches, err := notify.NewWatchlist(st, notifier)
+	if err != nil {
+		log.Fatalf("watchlist: %v", err)
+	}
+	c.Subscribe(watches.Observe)
+
+	if notifier != nil {
+		// service events: rejected snapshots, prolonged refresh failures, policy reloads
+		monitor := &notify.RefreshMonitor{
+			After:     conf.Notifications.RefreshFailureWindow(),
+			IsAnomaly: func(err error) bool { return errors.Is(err, supply.ErrQuorum) },
+			N:         notifier,
+		}
+		c.OnRefresh(monitor.Observe)
+	}
+
+	// SIGHUP reloads the policy (and candidate) files; a file that fails to load leaves
+	// the current one
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			p, err := policy.Load(*policyPath)
+			if err != nil {
+				log.Printf("warn: policy reload: %v", err)
+				continue
+			}
+			computer.SetPolicy(p)
+			log.Printf("policy reloaded (%s)", p.ETag)
+			if *candPath != "" {
+				if cand, err := policy.Load(*candPath); err != nil {
+					log.Printf("warn: candidate policy reload: %v", err)
+				} else {
+					computer.SetCandidate(cand)
+				}
+			}
+			if _, err := c.Update(context.Background(), *defaultDen); err != nil {
+				log.Printf("refresh after policy reload: %v", err)
+			}
+			if notifier != nil {
+				ev := notify.Event{Kind: notify.KindPolicyReloaded, Denom: *defaultDen, Time: time.Now().UTC(), Message: "policy reloaded: " + p.ETag}
+				if err := notifier.Notify(context.Background(), ev); err != nil {
+					log.Printf("warn: notify %s: %v", ev.Kind, err)
+				}
+			}
+		}
+	}()
+
+	// snapshot history for /diff and /history
+	var history *store.History
+	if st != nil {
+		if history, err = st.OpenHistory(); err != nil {
+			log.Fatalf("history: %v", err)
+		}
+		c.Subscribe(func(s *types.SupplySnapshot) {
+			if err := history.Put(s); err != nil {
+				log.Printf("warn: store snapshot %s: %v", s.ETag, err)
+			}
+		})
+		mustAddJob(jobs, scheduler.Job{Name: "history_prune", Every: scheduler.Fixed(10 * time.Minute), Run: func(context.Context) error {
+			return history.Prune(*historyCap)
+		}})
+	}
+
+	// warehouse exports of the latest snapshot (config file "exports")
+	for _, e := range conf.Exports {
+		x, err := export.New(e, c.Hydrate)
+		if err != nil {
+			log.Fatalf("config: %v", err)
+		}
+		mustAddJob(jobs, scheduler.Job{Name: "export:" + e.Name, Every: x.Every, Run: func(context.Context) error {
+			s, _ := c.Get()
+			return x.Export(s)
+		}})
+	}
+
+	// set late so setup messages are always logged; New then restores any persisted
+	// /admin/tuning overrides on top of it
+	logging.SetLevel(level)
+	srv := httpserver.New(httpserver.Config{
+		Cache:              c,
+		Computer:           computer,
+		LCD:                client,
+		DefaultDenom:       *defaultDen,
+		Limits:             limits,
+		LCDTimeouts:        []*lcd.Timeout{primaryTimeout, peerTimeout},
+		Failover:           failover,
+		Store:              st,
+		GitTag:             v.GitTag,
+		GitCommit:          v.GitCommit,
+		HaltAfter:          *haltAfter,
+		ServeStale:         *serveStale,
+		RequirePolicy:      *reqPolicy,
+		AdminToken:         *adminToken,
+		Watchlist:          watches,
+		SummaryTemplate:    *summaryTpl,
+		Endpoints:          conf.Endpoints,
+		History:            history,
+		SLO:                newSLOTracker(conf.SLO),
+		Chaos:              chaos,
+		SlackSigningSecret: *slackSecret,
+		BulkRatePerMin:     *bulkRate,
+		BulkBurst:          *bulkBurst,
+		Jobs:               jobs,
+		MaxCohortItems:     *maxItems,
+	})
+	jobs.Start()
+
+	// SIGTERM (orchestrators) and SIGINT start a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	// warm-up: hold off listening until the first snapshot exists, so load balancers
+	// and aggregators right after a deploy get figures instead of cacheable errors
+	if *warmup > 0 {
+		start := time.Now()
+		select {
+		case <-c.Ready():
+			log.Printf("warm-up: first snapshot ready after %s", time.Since(start).Round(time.Millisecond))
+		case <-time.After(*warmup):
+			log.Printf("warn: warm-up: no snapshot after %s, listening anyway (/readyz stays not_ready)", *warmup)
+		case <-ctx.Done():
+		}
+	}
+
+	server := &http.Server{Addr: *addr, Handler: srv}
+	served := make(chan error, 1)
+	if ctx.Err() == nil {
+		log.Printf("Lumera Supply API listening on %s (lcd=%s denom=%s)", *addr, *lcdURL, *defaultDen)
+		log.Printf("Git tag: %s, Git commit: %s", v.GitTag, v.GitCommit)
+		go func() { served <- server.ListenAndServe() }()
+	}
+	select {
+	case err := <-served:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+	stop() // a second signal kills the process
+	shutdown(server, c, jobs, *drain)
+}
+
+// shutdown stops accepting connections and waits up to timeout for in-flight requests,
+// then cancels the snapshot computation in flight and stops the jobs. Requests still
+// running when timeout expires have their connections closed (and contexts cancelled).
+func shutdown(server *http.Server, c *cache.SnapshotCache, jobs *scheduler.Scheduler, timeout time.Duration) {
+	log.Printf("shutting down: draining requests for up to %s", timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("warn: shutdown: requests still running after %s, closing their connections", timeout)
+		_ = server.Close()
+	}
+	c.Close()
+	if err := jobs.Stop(ctx); err != nil {
+		log.Printf("warn: shutdown: jobs still running after %s", timeout)
+	}
+	log.Printf("shutdown complete")
+}
+
+// newSLOTracker converts the config file objectives; unset ones use slo.DefaultTarget.
+func newSLOTracker(c config.SLO) *slo.Tracker {
+	conv := func(t config.SLOTarget) slo.Target {
+		return slo.Target{Availability: t.Availability, Latency: time.Duration(t.LatencyMS) * time.Millisecond, LatencyObjective: t.LatencyObjective}
+	}
+	def := slo.DefaultTarget
+	if c.Default != nil {
+		def = conv(*c.Default)
+	}
+	targets := make(map[string]slo.Target, len(c.Endpoints))
+	for path, t := range c.Endpoints {
+		targets[path] = conv(t)
+	}
+	return slo.New(def, targets)
+}
+
+func mustAddJob(s *scheduler.Scheduler, j scheduler.Job) {
+	if err := s.Add(j); err != nil {
+		log.Fatalf("scheduler: %v", err)
+	}
+}
+
+func getEnv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
+
+func getEnvInt(k string, def int) int {
+	if v := os.Getenv(k); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+		log.Printf("warn: invalid %s=%q, using %d", k, v, def)
+	}
+	return def
+}
+
+func getEnvDuration(k string, def time.Duration) time.Duration {
+	if v := os.Getenv(k); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+		log.Printf("warn: invalid %s=%q, using %s", k, v, def)
+	}
+	return def
+}
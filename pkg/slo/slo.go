@@ -0,0 +1,187 @@
+// Package slo tracks per-endpoint availability and latency against targets over
+// rolling windows and derives error-budget burn rates. All standard library.
+package slo
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+)
+
+var (
+	requestsTotal = metrics.Default.NewCounter(
+		"lumera_supply_slo_requests_total",
+		"Requests counted against SLOs by endpoint and outcome (good, error, slow).",
+		"endpoint", "outcome",
+	)
+	burnRate = metrics.Default.NewGauge(
+		"lumera_supply_slo_burn_rate",
+		"Error budget burn rate per endpoint, SLO (availability, latency) and window; 1 spends the budget exactly over the SLO period.",
+		"endpoint", "slo", "window",
+	)
+)
+
+// Target is an endpoint's objectives.
+type Target struct {
+	// Availability is the fraction of requests that must not fail with a 5xx (e.g., 0.999).
+	Availability float64 `json:"availability"`
+	// Latency is the response time threshold and LatencyObjective the fraction of
+	// requests that must meet it (e.g., 500ms for 0.99).
+	Latency          time.Duration `json:"-"`
+	LatencyMS        int64         `json:"latency_ms"`
+	LatencyObjective float64       `json:"latency_objective"`
+}
+
+// DefaultTarget applies to endpoints without a configured target.
+var DefaultTarget = Target{Availability: 0.999, Latency: 500 * time.Millisecond, LatencyObjective: 0.99}
+
+// Windows are the rolling windows burn rates are reported for, short enough for fast
+// burn alerts and long enough for slow ones.
+var Windows = []time.Duration{5 * time.Minute, time.Hour, 6 * time.Hour, 24 * time.Hour}
+
+// buckets holds one counter set per minute for the longest window.
+const buckets = 24 * 60
+
+type bucket struct {
+	minute              int64
+	total, errors, slow uint64
+}
+
+// Tracker records request outcomes per endpoint.
+type Tracker struct {
+	mu      sync.Mutex
+	def     Target
+	targets map[string]Target
+	series  map[string]*[buckets]bucket
+	now     func() time.Time
+}
+
+// New returns a tracker using def for endpoints missing from targets.
+func New(def Target, targets map[string]Target) *Tracker {
+	return &Tracker{def: def, targets: targets, series: map[string]*[buckets]bucket{}, now: time.Now}
+}
+
+func (t *Tracker) target(endpoint string) Target {
+	tg, ok := t.targets[endpoint]
+	if !ok {
+		tg = t.def
+	}
+	tg.LatencyMS = tg.Latency.Milliseconds()
+	return tg
+}
+
+// Observe records one response. 5xx answers count against availability; responses
+// slower than the target latency count against the latency objective.
+func (t *Tracker) Observe(endpoint string, status int, d time.Duration) {
+	tg := t.target(endpoint)
+	failed := status >= 500
+	slow := d > tg.Latency
+	outcome := "good"
+	switch {
+	case failed:
+		outcome = "error"
+	case slow:
+		outcome = "slow"
+	}
+	requestsTotal.Inc(endpoint, outcome)
+
+	minute := t.now().Unix() / 60
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.series[endpoint]
+	if s == nil {
+		s = new([buckets]bucket)
+		t.series[endpoint] = s
+	}
+	b := &s[minute%buckets]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	b.total++
+	if failed {
+		b.errors++
+	}
+	if slow {
+		b.slow++
+	}
+}
+
+// Status is one endpoint's SLO state.
+type Status struct {
+	Endpoint string         `json:"endpoint"`
+	Target   Target         `json:"target"`
+	Windows  []WindowStatus `json:"windows"`
+}
+
+// WindowStatus reports attainment and burn rates over one rolling window. Ratios are
+// 1 and burn rates 0 when the window saw no requests.
+type WindowStatus struct {
+	Window           string  `json:"window"`
+	Requests         uint64  `json:"requests"`
+	Errors           uint64  `json:"errors"`
+	Slow             uint64  `json:"slow"`
+	Availability     float64 `json:"availability"`
+	LatencyGood      float64 `json:"latency_good"`
+	AvailabilityBurn float64 `json:"availability_burn_rate"`
+	LatencyBurn      float64 `json:"latency_burn_rate"`
+}
+
+// Summary returns every observed endpoint's status, sorted by endpoint, and updates
+// the burn rate gauges.
+func (t *Tracker) Summary() []Status {
+	now := t.now().Unix() / 60
+	t.mu.Lock()
+	names := make([]string, 0, len(t.series))
+	for n := range t.series {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	out := make([]Status, 0, len(names))
+	for _, name := range names {
+		s := t.series[name]
+		st := Status{Endpoint: name, Target: t.target(name)}
+		for _, w := range Windows {
+			ws := WindowStatus{Window: formatWindow(w)}
+			span := int64(w / time.Minute)
+			for _, b := range s {
+				if b.minute > now-span && b.minute <= now {
+					ws.Requests += b.total
+					ws.Errors += b.errors
+					ws.Slow += b.slow
+				}
+			}
+			ws.Availability, ws.AvailabilityBurn = attainment(ws.Requests, ws.Errors, st.Target.Availability)
+			ws.LatencyGood, ws.LatencyBurn = attainment(ws.Requests, ws.Slow, st.Target.LatencyObjective)
+			burnRate.Set(ws.AvailabilityBurn, name, "availability", ws.Window)
+			burnRate.Set(ws.LatencyBurn, name, "latency", ws.Window)
+			st.Windows = append(st.Windows, ws)
+		}
+		out = append(out, st)
+	}
+	t.mu.Unlock()
+	return out
+}
+
+// attainment returns the good ratio and the burn rate: the bad ratio divided by the
+// error budget (1 - objective).
+func attainment(total, bad uint64, objective float64) (float64, float64) {
+	if total == 0 {
+		return 1, 0
+	}
+	badRatio := float64(bad) / float64(total)
+	budget := 1 - objective
+	if budget <= 0 {
+		budget = 1e-9
+	}
+	return 1 - badRatio, badRatio / budget
+}
+
+func formatWindow(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	}
+	return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+}
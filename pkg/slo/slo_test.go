@@ -0,0 +1,41 @@
+package slo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrackerBurnRates(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tr := New(DefaultTarget, map[string]Target{"/total": {Availability: 0.99, Latency: 100 * time.Millisecond, LatencyObjective: 0.9}})
+	tr.now = func() time.Time { return now }
+
+	// two hours ago: one error, outside the 5m and 1h windows
+	now = now.Add(-2 * time.Hour)
+	tr.Observe("/total", 502, time.Millisecond)
+	now = now.Add(2 * time.Hour)
+	for i := 0; i < 8; i++ {
+		tr.Observe("/total", 200, 10*time.Millisecond)
+	}
+	tr.Observe("/total", 500, 10*time.Millisecond)
+	tr.Observe("/total", 200, time.Second) // slow
+
+	sum := tr.Summary()
+	if len(sum) != 1 || sum[0].Endpoint != "/total" || sum[0].Target.LatencyMS != 100 {
+		t.Fatalf("unexpected summary: %+v", sum)
+	}
+	w5 := sum[0].Windows[0]
+	if w5.Window != "5m" || w5.Requests != 10 || w5.Errors != 1 || w5.Slow != 1 {
+		t.Fatalf("unexpected 5m window: %+v", w5)
+	}
+	// 10% errors against a 1% budget burns 10x; 10% slow against a 10% budget burns 1x
+	if w5.AvailabilityBurn < 9.99 || w5.AvailabilityBurn > 10.01 || w5.LatencyBurn < 0.99 || w5.LatencyBurn > 1.01 {
+		t.Fatalf("unexpected burn rates: %+v", w5)
+	}
+	if w6 := sum[0].Windows[2]; w6.Window != "6h" || w6.Requests != 11 || w6.Errors != 2 {
+		t.Fatalf("unexpected 6h window: %+v", w6)
+	}
+	if burnRate.Value("/total", "availability", "5m") != w5.AvailabilityBurn {
+		t.Fatalf("burn rate gauge not updated")
+	}
+}
@@ -0,0 +1,376 @@
+package store
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// ErrNotFound is returned when no stored snapshot matches a lookup.
+var ErrNotFound = errors.New("snapshot not found")
+
+// History persists full (hydrated) snapshots as <dir>/snapshots/<height>_<etag>.json
+// and keeps an in-memory index ordered by height. Cohort items are content-addressed:
+// each distinct item list is written once as <dir>/item_sets/<sha256>.json and
+// snapshots refer to it (see storedSnapshot), so cohorts whose items do not change
+// between snapshots cost no extra space. Each snapshot's figures are also appended to
+// <dir>/history.ndjson; Prune leaves those points, so series outlive the full snapshots.
+type History struct {
+	fs     *FileStore
+	dir    string
+	sets   string
+	idx    []types.SnapshotRef // ascending height
+	points []types.SupplyPoint // ascending height
+	// item set hashes by snapshot ETag, as far as known (filled by Put and Prune)
+	refs map[string][]string
+}
+
+// storedSnapshot is a snapshot as written under snapshots/: cohort items are left out
+// and ItemSets names the item set of each cohort that has items. Snapshots stored
+// before item sets existed carry their items inline and no ItemSets.
+type storedSnapshot struct {
+	*types.SupplySnapshot
+	ItemSets map[string]string `json:"item_sets,omitempty"`
+}
+
+// OpenHistory indexes the snapshots already stored under fs.
+func (s *FileStore) OpenHistory() (*History, error) {
+	h := &History{fs: s, dir: filepath.Join(s.dir, "snapshots"), sets: filepath.Join(s.dir, "item_sets"), refs: map[string][]string{}}
+	if err := os.MkdirAll(h.dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(h.sets, 0o755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		ref, ok := parseSnapshotName(e.Name())
+		if !ok {
+			continue
+		}
+		// the file is written when the snapshot is published, so its mtime stands in
+		// for UpdatedAt without reading every snapshot at startup
+		if info, err := e.Info(); err == nil {
+			ref.UpdatedAt = info.ModTime().UTC()
+		}
+		h.idx = append(h.idx, ref)
+	}
+	sort.Slice(h.idx, func(i, j int) bool { return h.idx[i].Height < h.idx[j].Height })
+	if err := h.loadPoints(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *History) pointsPath() string { return filepath.Join(h.fs.dir, "history.ndjson") }
+
+// loadPoints reads the points file. A store written before it existed is backfilled
+// from its snapshots once.
+func (h *History) loadPoints() error {
+	f, err := os.Open(h.pointsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		for _, ref := range h.idx {
+			snap, err := h.load(ref)
+			if err != nil {
+				continue
+			}
+			if err := h.appendPoint(types.PointOf(snap)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// a line cut short by a crash is skipped
+		if p, err := types.DecodePoint(sc.Bytes()); err == nil {
+			h.points = append(h.points, p)
+		}
+	}
+	sort.SliceStable(h.points, func(i, j int) bool { return h.points[i].Height < h.points[j].Height })
+	return sc.Err()
+}
+
+// appendPoint writes p to the points file and the in-memory series.
+func (h *History) appendPoint(p types.SupplyPoint) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.pointsPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	i := sort.Search(len(h.points), func(i int) bool { return h.points[i].Height > p.Height })
+	h.points = slices.Insert(h.points, i, p)
+	return nil
+}
+
+// Points returns denom's stored points with Time in [from, to], in ascending height
+// order. A zero from or to leaves that end open.
+func (h *History) Points(denom string, from, to time.Time) []types.SupplyPoint {
+	h.fs.mu.Lock()
+	defer h.fs.mu.Unlock()
+	var out []types.SupplyPoint
+	for _, p := range h.points {
+		if p.Denom != denom || !from.IsZero() && p.Time.Before(from) || !to.IsZero() && p.Time.After(to) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func parseSnapshotName(name string) (types.SnapshotRef, bool) {
+	base, ok := strings.CutSuffix(name, ".json")
+	if !ok {
+		return types.SnapshotRef{}, false
+	}
+	hs, etag, ok := strings.Cut(base, "_")
+	if !ok {
+		return types.SnapshotRef{}, false
+	}
+	height, err := strconv.ParseInt(hs, 10, 64)
+	if err != nil {
+		return types.SnapshotRef{}, false
+	}
+	return types.SnapshotRef{ETag: etag, Height: height}, true
+}
+
+func (h *History) path(ref types.SnapshotRef) string {
+	return filepath.Join(h.dir, fmt.Sprintf("%d_%s.json", ref.Height, ref.ETag))
+}
+
+// Put stores snap (which must carry all items) unless its ETag is already stored.
+func (h *History) Put(snap *types.SupplySnapshot) error {
+	h.fs.mu.Lock()
+	defer h.fs.mu.Unlock()
+	for _, r := range h.idx {
+		if r.ETag == snap.ETag {
+			return nil
+		}
+	}
+	ref := types.SnapshotRef{ETag: snap.ETag, Height: snap.Height, UpdatedAt: snap.UpdatedAt}
+	stored, err := h.putItemSets(snap)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(h.path(ref), stored); err != nil {
+		return err
+	}
+	h.refs[ref.ETag] = setSums(stored.ItemSets)
+	i := sort.Search(len(h.idx), func(i int) bool { return h.idx[i].Height > ref.Height })
+	h.idx = append(h.idx, types.SnapshotRef{})
+	copy(h.idx[i+1:], h.idx[i:])
+	h.idx[i] = ref
+	return h.appendPoint(types.PointOf(snap))
+}
+
+func (h *History) itemSetPath(sum string) string {
+	return filepath.Join(h.sets, sum+".json")
+}
+
+// putItemSets writes the item sets of snap's cohorts that are not stored yet and returns
+// snap as it is to be stored, without items. snap itself is not modified.
+func (h *History) putItemSets(snap *types.SupplySnapshot) (storedSnapshot, error) {
+	cp := *snap
+	cp.NonCirculating.Cohorts = slices.Clone(snap.NonCirculating.Cohorts)
+	out := storedSnapshot{SupplySnapshot: &cp}
+	for i := range cp.NonCirculating.Cohorts {
+		co := &cp.NonCirculating.Cohorts[i]
+		if len(co.Items) == 0 {
+			continue
+		}
+		b, err := json.Marshal(co.Items)
+		if err != nil {
+			return storedSnapshot{}, err
+		}
+		sum := sha256.Sum256(b)
+		name := hex.EncodeToString(sum[:])
+		if _, err := os.Stat(h.itemSetPath(name)); errors.Is(err, os.ErrNotExist) {
+			if err := writeFile(h.itemSetPath(name), b); err != nil {
+				return storedSnapshot{}, err
+			}
+		}
+		if out.ItemSets == nil {
+			out.ItemSets = make(map[string]string)
+		}
+		out.ItemSets[co.Name] = name
+		co.Items = nil
+	}
+	return out, nil
+}
+
+// setSums returns the item set hashes of an ItemSets map.
+func setSums(sets map[string]string) []string {
+	sums := make([]string, 0, len(sets))
+	for _, sum := range sets {
+		sums = append(sums, sum)
+	}
+	return sums
+}
+
+// itemSetsOf returns the item set hashes the stored snapshot ref refers to. Callers
+// hold fs.mu.
+func (h *History) itemSetsOf(ref types.SnapshotRef) ([]string, error) {
+	if sums, ok := h.refs[ref.ETag]; ok {
+		return sums, nil
+	}
+	b, err := os.ReadFile(h.path(ref))
+	if err != nil {
+		return nil, err
+	}
+	var stored struct {
+		ItemSets map[string]string `json:"item_sets"`
+	}
+	if err := json.Unmarshal(b, &stored); err != nil {
+		return nil, err
+	}
+	sums := setSums(stored.ItemSets)
+	h.refs[ref.ETag] = sums
+	return sums, nil
+}
+
+// Refs lists the stored snapshots in ascending height order.
+func (h *History) Refs() []types.SnapshotRef {
+	h.fs.mu.Lock()
+	defer h.fs.mu.Unlock()
+	return append([]types.SnapshotRef(nil), h.idx...)
+}
+
+// ByETag loads the stored snapshot with the given ETag.
+func (h *History) ByETag(etag string) (*types.SupplySnapshot, error) {
+	h.fs.mu.Lock()
+	var ref *types.SnapshotRef
+	for i := range h.idx {
+		if h.idx[i].ETag == etag {
+			r := h.idx[i]
+			ref = &r
+			break
+		}
+	}
+	h.fs.mu.Unlock()
+	if ref == nil {
+		return nil, ErrNotFound
+	}
+	return h.load(*ref)
+}
+
+// AtHeight loads the latest stored snapshot at or below height.
+func (h *History) AtHeight(height int64) (*types.SupplySnapshot, error) {
+	h.fs.mu.Lock()
+	i := sort.Search(len(h.idx), func(i int) bool { return h.idx[i].Height > height })
+	var ref types.SnapshotRef
+	found := i > 0
+	if found {
+		ref = h.idx[i-1]
+	}
+	h.fs.mu.Unlock()
+	if !found {
+		return nil, ErrNotFound
+	}
+	return h.load(ref)
+}
+
+func (h *History) load(ref types.SnapshotRef) (*types.SupplySnapshot, error) {
+	b, err := os.ReadFile(h.path(ref))
+	if err != nil {
+		return nil, err
+	}
+	snap, err := types.DecodeSnapshot(b)
+	if err != nil {
+		return nil, err
+	}
+	var stored struct {
+		ItemSets map[string]string `json:"item_sets"`
+	}
+	if err := json.Unmarshal(b, &stored); err != nil {
+		return nil, err
+	}
+	for i := range snap.NonCirculating.Cohorts {
+		co := &snap.NonCirculating.Cohorts[i]
+		sum, ok := stored.ItemSets[co.Name]
+		if !ok {
+			continue
+		}
+		ib, err := os.ReadFile(h.itemSetPath(sum))
+		if err != nil {
+			return nil, fmt.Errorf("items of %s: %w", co.Name, err)
+		}
+		if err := json.Unmarshal(ib, &co.Items); err != nil {
+			return nil, fmt.Errorf("items of %s: %w", co.Name, err)
+		}
+	}
+	return snap, nil
+}
+
+// Prune keeps the newest keep snapshots and deletes the rest, and the item sets only
+// they referred to. Their points stay.
+func (h *History) Prune(keep int) error {
+	h.fs.mu.Lock()
+	defer h.fs.mu.Unlock()
+	if keep <= 0 || len(h.idx) <= keep {
+		return nil
+	}
+	drop := h.idx[:len(h.idx)-keep]
+	var errs []error
+	for _, r := range drop {
+		if err := os.Remove(h.path(r)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		delete(h.refs, r.ETag)
+	}
+	h.idx = append([]types.SnapshotRef(nil), h.idx[len(h.idx)-keep:]...)
+	// sweep the item sets no kept snapshot refers to; a snapshot that cannot be read
+	// leaves every set in place
+	used := make(map[string]bool)
+	for _, r := range h.idx {
+		sums, err := h.itemSetsOf(r)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		for _, sum := range sums {
+			used[sum] = true
+		}
+	}
+	entries, err := os.ReadDir(h.sets)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	for _, e := range entries {
+		sum, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || used[sum] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(h.sets, e.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
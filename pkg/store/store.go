@@ -0,0 +1,201 @@
+// Package store persists snapshot data on local disk. All standard library.
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// FileStore keeps per-snapshot cohort items as JSON files under
+// <dir>/items/<etag>/<cohort>.json so large item lists need not stay in memory, bulk
+// exports of a snapshot next to them (see Export), and small named documents (service
+// state) as <dir>/<name>.json.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Open creates (if needed) and opens a store rooted at dir.
+func Open(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, errors.New("empty store dir")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "items"), 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) itemsPath(etag, cohort string) string {
+	return filepath.Join(s.dir, "items", url.PathEscape(etag), url.QueryEscape(cohort)+".json")
+}
+
+// PutItems writes the items of one cohort for the snapshot identified by etag.
+func (s *FileStore) PutItems(etag, cohort string, items []types.AddressItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.itemsPath(etag, cohort)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return writeJSON(p, items)
+}
+
+// writeJSON writes v to p via write-then-rename so readers never observe a partial file.
+func writeJSON(p string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return writeFile(p, b)
+}
+
+// writeFile writes b to p via write-then-rename.
+func writeFile(p string, b []byte) error {
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+func (s *FileStore) docPath(name string) string {
+	return filepath.Join(s.dir, url.QueryEscape(name)+".json")
+}
+
+// PutDoc stores v as the named JSON document (e.g., "watches").
+func (s *FileStore) PutDoc(name string, v any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeJSON(s.docPath(name), v)
+}
+
+// Doc loads the named JSON document into v. It reports false when the document does
+// not exist yet.
+func (s *FileStore) Doc(name string, v any) (bool, error) {
+	b, err := os.ReadFile(s.docPath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(b, v)
+}
+
+// Items reads the items of one cohort for the snapshot identified by etag.
+func (s *FileStore) Items(etag, cohort string) ([]types.AddressItem, error) {
+	b, err := os.ReadFile(s.itemsPath(etag, cohort))
+	if err != nil {
+		return nil, err
+	}
+	var items []types.AddressItem
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ItemsRange reads up to limit items of one cohort starting at offset, decoding the
+// stored list one item at a time so a page of a large cohort does not load all of it.
+func (s *FileStore) ItemsRange(etag, cohort string, offset, limit int) ([]types.AddressItem, error) {
+	f, err := os.Open(s.itemsPath(etag, cohort))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(bufio.NewReader(f))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('[') {
+		return nil, errors.New("stored items are not a list")
+	}
+	var items []types.AddressItem
+	for i := 0; dec.More() && len(items) < limit; i++ {
+		if i < offset {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var it types.AddressItem
+		if err := dec.Decode(&it); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+func (s *FileStore) exportPath(etag, name string) string {
+	return filepath.Join(s.dir, "items", url.PathEscape(etag), url.QueryEscape(name)+".export")
+}
+
+// Export opens the bulk export name (e.g., "items.ndjson") of the snapshot identified by
+// etag, calling write to create it on first use. An export is written once per ETag,
+// through a buffer to a temporary file that is then renamed into place, and is removed
+// with the snapshot's items by PruneItems. The caller closes the file.
+func (s *FileStore) Export(etag, name string, write func(io.Writer) error) (*os.File, error) {
+	p := s.exportPath(etag, name)
+	if f, err := os.Open(p); err == nil || !errors.Is(err, os.ErrNotExist) {
+		return f, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return nil, err
+	}
+	// concurrent first requests each write their own copy; the bytes are the same
+	tmp, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(tmp)
+	err = write(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+// PruneItems removes item sets for all snapshots except the given ETags.
+func (s *FileStore) PruneItems(keep ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	root := filepath.Join(s.dir, "items")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	keepSet := make(map[string]bool, len(keep))
+	for _, k := range keep {
+		keepSet[url.PathEscape(k)] = true
+	}
+	var errs []error
+	for _, e := range entries {
+		if !e.IsDir() || keepSet[e.Name()] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(root, e.Name())); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
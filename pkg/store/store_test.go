@@ -0,0 +1,259 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+func TestItemsRoundTripAndPrune(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	items := []types.AddressItem{{Address: "lumera1a", Amount: "10", EndDate: "forever", Permanent: true}}
+	if err := s.PutItems("etag1", "module:claim", items); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if err := s.PutItems("etag2", "claim_delayed", items); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	got, err := s.Items("etag1", "module:claim")
+	if err != nil {
+		t.Fatalf("items: %v", err)
+	}
+	if !reflect.DeepEqual(got, items) {
+		t.Fatalf("unexpected items: %+v", got)
+	}
+	if err := s.PruneItems("etag2"); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if _, err := s.Items("etag1", "module:claim"); err == nil {
+		t.Fatalf("expected etag1 items to be pruned")
+	}
+	if _, err := s.Items("etag2", "claim_delayed"); err != nil {
+		t.Fatalf("etag2 items should remain: %v", err)
+	}
+}
+
+func TestHistoryLookupAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	h, err := s.OpenHistory()
+	if err != nil {
+		t.Fatalf("open history: %v", err)
+	}
+	for _, snap := range []*types.SupplySnapshot{
+		{ETag: "e30", Height: 30, Total: "3"},
+		{ETag: "e10", Height: 10, Total: "1"},
+		{ETag: "e20", Height: 20, Total: "2"},
+	} {
+		if err := h.Put(snap); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+	}
+	if got, err := h.AtHeight(25); err != nil || got.ETag != "e20" {
+		t.Fatalf("AtHeight(25): %v, %v", got, err)
+	}
+	if _, err := h.AtHeight(5); err != ErrNotFound {
+		t.Fatalf("AtHeight(5): expected ErrNotFound, got %v", err)
+	}
+	if err := h.Prune(2); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	// reopen to check the index is rebuilt from disk
+	h2, _ := s.OpenHistory()
+	if _, err := h2.ByETag("e10"); err != ErrNotFound {
+		t.Fatalf("pruned snapshot still present: %v", err)
+	}
+	if got, err := h2.ByETag("e30"); err != nil || got.Total != "3" {
+		t.Fatalf("ByETag(e30): %v, %v", got, err)
+	}
+}
+
+func TestHistoryStoresItemSetsOnce(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	h, err := s.OpenHistory()
+	if err != nil {
+		t.Fatalf("open history: %v", err)
+	}
+	claims := []types.AddressItem{{Address: "lumera1a", Amount: "10", EndDate: "2026-01-01"}}
+	snapAt := func(height int64, vesting string) *types.SupplySnapshot {
+		return &types.SupplySnapshot{ETag: fmt.Sprintf("e%d", height), Height: height, NonCirculating: types.NonCircBreakdown{Cohorts: []types.CohortEntry{
+			{Name: "claim_delayed", Amount: "10", Items: claims},
+			{Name: "foundation", Amount: vesting, Items: []types.AddressItem{{Address: "lumera1f", Amount: vesting}}},
+		}}}
+	}
+	for i, amount := range []string{"30", "20", "10"} {
+		snap := snapAt(int64(i+1)*10, amount)
+		if err := h.Put(snap); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+		if snap.NonCirculating.Cohorts[0].Items == nil {
+			t.Fatalf("Put modified the snapshot")
+		}
+	}
+	sets := func() int {
+		entries, err := os.ReadDir(filepath.Join(dir, "item_sets"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(entries)
+	}
+	// the claims once, the foundation items once per amount
+	if n := sets(); n != 4 {
+		t.Fatalf("%d item sets stored, want 4", n)
+	}
+	got, err := h.ByETag("e20")
+	if err != nil {
+		t.Fatalf("ByETag(e20): %v", err)
+	}
+	if !reflect.DeepEqual(got.NonCirculating.Cohorts, snapAt(20, "20").NonCirculating.Cohorts) {
+		t.Fatalf("items not restored: %+v", got.NonCirculating.Cohorts)
+	}
+
+	if err := h.Prune(1); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if n := sets(); n != 2 {
+		t.Fatalf("%d item sets after pruning to one snapshot, want 2", n)
+	}
+	h2, _ := s.OpenHistory()
+	if got, err := h2.ByETag("e30"); err != nil || !reflect.DeepEqual(got.NonCirculating.Cohorts, snapAt(30, "10").NonCirculating.Cohorts) {
+		t.Fatalf("ByETag(e30) after prune: %+v, %v", got, err)
+	}
+}
+
+func TestHistoryPoints(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	h, err := s.OpenHistory()
+	if err != nil {
+		t.Fatalf("open history: %v", err)
+	}
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := int64(1); i <= 3; i++ {
+		snap := &types.SupplySnapshot{Denom: "ulume", ETag: fmt.Sprintf("e%d", i), Height: i, UpdatedAt: day.Add(time.Duration(i) * time.Hour),
+			Total: "10", Circulating: fmt.Sprint(i), NonCirculating: types.NonCircBreakdown{Sum: fmt.Sprint(10 - i)}}
+		if err := h.Put(snap); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+	}
+	if err := h.Prune(1); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	// points survive pruning and a reopen
+	h2, err := s.OpenHistory()
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	got := h2.Points("ulume", day.Add(2*time.Hour), time.Time{})
+	if len(got) != 2 || got[0].Height != 2 || got[1].Circulating != "3" || got[1].NonCirculating != "7" {
+		t.Fatalf("points: %+v", got)
+	}
+	if got := h2.Points("other", time.Time{}, time.Time{}); len(got) != 0 {
+		t.Fatalf("points for another denom: %+v", got)
+	}
+
+	// a store without the points file is backfilled from its snapshots
+	if err := os.Remove(filepath.Join(dir, "history.ndjson")); err != nil {
+		t.Fatal(err)
+	}
+	h3, err := s.OpenHistory()
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got := h3.Points("ulume", time.Time{}, time.Time{}); len(got) != 1 || got[0].ETag != "e3" {
+		t.Fatalf("backfilled points: %+v", got)
+	}
+}
+
+func TestHistoryReadsOlderSchemaVersions(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	// a snapshot and a point as written before they recorded schema_version
+	if err := os.MkdirAll(filepath.Join(dir, "snapshots"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old := `{"denom":"ulume","height":7,"updated_at":"2025-01-02T03:04:05Z","etag":"e7","policy-etag":"p","total":"10","circulating":"9","max":null,` +
+		`"non_circulating":{"sum":"1","cohorts":[{"name":"community_pool","slug":"","reason":"","amount":"1","as_of_time":"2025-01-02T03:04:05Z"}]}}`
+	if err := os.WriteFile(filepath.Join(dir, "snapshots", "7_e7.json"), []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	point := `{"time":"2025-01-02T03:04:05Z","height":7,"etag":"e7","denom":"ulume","total":"10","circulating":"9","non_circulating":"1"}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "history.ndjson"), []byte(point), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := s.OpenHistory()
+	if err != nil {
+		t.Fatalf("open history: %v", err)
+	}
+	snap, err := h.ByETag("e7")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if snap.SchemaVersion != types.SchemaVersion || !snap.EvaluatedAt.Equal(snap.UpdatedAt) || snap.NonCirculating.Cohorts[0].Amount != "1" {
+		t.Fatalf("upgraded snapshot: %+v", snap)
+	}
+	if got := h.Points("ulume", time.Time{}, time.Time{}); len(got) != 1 || got[0].SchemaVersion != types.SchemaVersion || got[0].Circulating != "9" {
+		t.Fatalf("upgraded points: %+v", got)
+	}
+
+	// a newer version's fields that this one does not know are dropped, not fatal
+	newer := fmt.Sprintf(`{"schema_version":%d,"denom":"ulume","height":8,"etag":"e8","total":"10","future_field":{"x":1}}`, types.SchemaVersion+1)
+	if err := os.WriteFile(filepath.Join(dir, "snapshots", "8_e8.json"), []byte(newer), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h2, err := s.OpenHistory()
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if snap, err := h2.ByETag("e8"); err != nil || snap.SchemaVersion != types.SchemaVersion+1 || snap.Total != "10" {
+		t.Fatalf("newer snapshot: %+v %v", snap, err)
+	}
+}
+
+func TestItemsRange(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	var items []types.AddressItem
+	for i := 0; i < 5; i++ {
+		items = append(items, types.AddressItem{Address: fmt.Sprintf("lumera1%d", i), Amount: "1"})
+	}
+	if err := s.PutItems("etag1", "cohort", items); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	for _, tc := range []struct{ offset, limit, want int }{{0, 2, 2}, {3, 5, 2}, {5, 1, 0}, {9, 1, 0}} {
+		got, err := s.ItemsRange("etag1", "cohort", tc.offset, tc.limit)
+		if err != nil {
+			t.Fatalf("range: %v", err)
+		}
+		if len(got) != tc.want || len(got) > 0 && !reflect.DeepEqual(got, items[tc.offset:tc.offset+tc.want]) {
+			t.Errorf("range(%d, %d): %+v", tc.offset, tc.limit, got)
+		}
+	}
+	if _, err := s.ItemsRange("etag1", "missing", 0, 1); err == nil {
+		t.Fatalf("expected an error for missing items")
+	}
+}
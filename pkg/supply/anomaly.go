@@ -0,0 +1,68 @@
+package supply
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+var negativeCirculating = metrics.Default.NewCounter(
+	"lumera_supply_negative_circulating_total",
+	"Snapshots whose non-circulating cohorts summed to more than total supply.",
+	"denom",
+)
+
+// ErrNegativeCirculating is returned by ComputeSnapshot when non-circulating cohorts sum
+// to more than total supply and such snapshots are not published.
+var ErrNegativeCirculating = errors.New("negative circulating supply")
+
+// maxListedOverlaps bounds the overlapping addresses named in anomaly messages.
+const maxListedOverlaps = 5
+
+// SetPublishNegative chooses what happens to snapshots with negative circulating supply.
+// By default they are rejected, so the last good snapshot stays cached; with publish set
+// they are published with the negative figure and the anomaly attached.
+func (c *Computer) SetPublishNegative(publish bool) {
+	c.mu.Lock()
+	c.publishNegative = publish
+	c.mu.Unlock()
+}
+
+// negativeAnomaly describes circ < 0, listing the addresses counted by several cohorts
+// as the likely cause.
+func negativeAnomaly(total, sum, circ *big.Int, cohorts []types.CohortEntry) *types.SupplyAnomaly {
+	a := &types.SupplyAnomaly{Kind: types.AnomalyNegativeCirculating, Overlaps: findOverlaps(cohorts)}
+	var b strings.Builder
+	fmt.Fprintf(&b, "non-circulating %s exceeds total supply %s by %s", sum, total, new(big.Int).Neg(circ))
+	if len(a.Overlaps) > 0 {
+		fmt.Fprintf(&b, "; %d address(es) counted by several cohorts:", len(a.Overlaps))
+		for i, o := range a.Overlaps {
+			if i == maxListedOverlaps {
+				b.WriteString(" ...")
+				break
+			}
+			fmt.Fprintf(&b, " %s (%s)", o.Address, strings.Join(o.Cohorts, ", "))
+		}
+	}
+	a.Message = b.String()
+	return a
+}
+
+// checkCirculating counts and logs a snapshot anomaly and, unless such snapshots are
+// published, rejects the snapshot.
+func (c *Computer) checkCirculating(snap *types.SupplySnapshot) error {
+	if snap.Anomaly == nil {
+		return nil
+	}
+	negativeCirculating.Inc(snap.Denom)
+	log.Printf("warn: %s at height %d: %s", snap.Denom, snap.Height, snap.Anomaly.Message)
+	if c.publishNegative {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrNegativeCirculating, snap.Anomaly.Message)
+}
@@ -0,0 +1,46 @@
+package supply
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+func TestNegativeCirculating(t *testing.T) {
+	const govAddr = "lumera10d07y265gmmuvt4z0w9aw880jnsr700j6cqwzj"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"30"}}`))
+		case "/cosmos/auth/v1beta1/module_accounts/gov":
+			_, _ = w.Write([]byte(`{"account":{"base_account":{"address":"` + govAddr + `"}}}`))
+		case "/cosmos/bank/v1beta1/balances/" + govAddr + "/by_denom":
+			_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"40"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	c := NewComputer(lcd.NewClient(ts.URL, ts.Client()), &policy.Policy{GovDeposits: true})
+
+	if _, err := c.ComputeSnapshot(context.Background(), "ulume"); !errors.Is(err, ErrNegativeCirculating) || !strings.Contains(err.Error(), "exceeds total supply 30 by 10") {
+		t.Fatalf("expected the snapshot to be rejected, got %v", err)
+	}
+	c.SetPublishNegative(true)
+	snap, err := c.ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Circulating != "-10" || snap.Anomaly == nil || snap.Anomaly.Kind != types.AnomalyNegativeCirculating {
+		t.Fatalf("expected a published anomaly: circ=%s anomaly=%+v", snap.Circulating, snap.Anomaly)
+	}
+}
@@ -0,0 +1,129 @@
+package supply
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/store"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+var burnTrackerHeight = metrics.Default.NewGauge(
+	"lumera_supply_burn_tracker_height",
+	"Last block whose results the burn tracker has processed.",
+	"denom",
+)
+
+// maxBurnBlocks bounds the blocks one Sync scans, so catching up after downtime is
+// spread over several refreshes instead of stalling one.
+const maxBurnBlocks = 200
+
+// BurnTracker follows block results from the height it first syncs to and accumulates
+// one denom's mints, burns and transfers to burn addresses. Progress is persisted to the
+// store (optional), so totals survive restarts as long as the node keeps block results.
+type BurnTracker struct {
+	denom string
+	store *store.FileStore
+
+	mu     sync.Mutex
+	totals types.BurnTotals
+}
+
+func burnsDoc(denom string) string { return "burns_" + denom }
+
+// NewBurnTracker loads persisted totals for denom from st (optional).
+func NewBurnTracker(denom string, st *store.FileStore) (*BurnTracker, error) {
+	t := &BurnTracker{denom: denom, store: st}
+	if st != nil {
+		if _, err := st.Doc(burnsDoc(denom), &t.totals); err != nil {
+			return nil, fmt.Errorf("load burn totals: %w", err)
+		}
+	}
+	return t, nil
+}
+
+// Denom returns the tracked denom.
+func (t *BurnTracker) Denom() string { return t.denom }
+
+// Sync processes the blocks after the last one seen up to height (at most maxBurnBlocks
+// per call) and returns the totals. The first call starts tracking at height. On error
+// the blocks processed so far are kept and the next call resumes after them.
+func (t *BurnTracker) Sync(l *lcd.Client, height int64, burnAddrs []string) (types.BurnTotals, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.totals.Height == 0 {
+		t.totals = types.BurnTotals{FromHeight: height, Height: height, Minted: "0", Burned: "0", BurnTransferred: "0"}
+		t.save()
+		return t.totals, nil
+	}
+	addrs := make(map[string]bool, len(burnAddrs))
+	for _, a := range burnAddrs {
+		addrs[a] = true
+	}
+	minted, _ := new(big.Int).SetString(t.totals.Minted, 10)
+	burned, _ := new(big.Int).SetString(t.totals.Burned, 10)
+	sent, _ := new(big.Int).SetString(t.totals.BurnTransferred, 10)
+	if minted == nil || burned == nil || sent == nil {
+		return t.totals, fmt.Errorf("corrupt burn totals %+v", t.totals)
+	}
+	var err error
+	to := min(height, t.totals.Height+maxBurnBlocks)
+	for h := t.totals.Height + 1; h <= to; h++ {
+		var events []lcd.Event
+		if events, err = l.BlockResults(h); err != nil {
+			err = fmt.Errorf("block results %d: %w", h, err)
+			break
+		}
+		var ev lcd.SupplyEvents
+		if ev, err = lcd.ExtractSupplyEvents(events, t.denom, addrs); err != nil {
+			err = fmt.Errorf("block %d: %w", h, err)
+			break
+		}
+		minted.Add(minted, ev.Minted)
+		burned.Add(burned, ev.Burned)
+		sent.Add(sent, ev.BurnTransferred)
+		t.totals.Height = h
+	}
+	t.totals.Minted, t.totals.Burned, t.totals.BurnTransferred = minted.String(), burned.String(), sent.String()
+	burnTrackerHeight.Set(float64(t.totals.Height), t.denom)
+	t.save()
+	return t.totals, err
+}
+
+func (t *BurnTracker) save() {
+	if t.store == nil {
+		return
+	}
+	if err := t.store.PutDoc(burnsDoc(t.denom), t.totals); err != nil {
+		log.Printf("warn: save burn totals: %v", err)
+	}
+}
+
+// SetBurnTracker enables burn tracking for the tracker's denom; snapshots of that denom
+// carry its totals. nil disables it.
+func (c *Computer) SetBurnTracker(t *BurnTracker) {
+	c.mu.Lock()
+	c.burns = t
+	c.mu.Unlock()
+}
+
+// trackBurns syncs the burn tracker to snap's height and attaches its totals. Failures
+// are logged; the totals then trail the snapshot height.
+func (c *Computer) trackBurns(snap *types.SupplySnapshot) {
+	if c.burns == nil || c.burns.Denom() != snap.Denom {
+		return
+	}
+	var addrs []string
+	if c.policy != nil {
+		addrs = c.policy.BurnAddresses
+	}
+	totals, err := c.burns.Sync(c.lcd, snap.Height, addrs)
+	if err != nil {
+		log.Printf("warn: burn tracker: %v", err)
+	}
+	snap.Burns = &totals
+}
@@ -0,0 +1,69 @@
+package supply
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/store"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+func TestBurnTracker(t *testing.T) {
+	// every block mints 10ulume and burns 3ulume; block 12 also sends 5ulume to the burn address
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		var req struct {
+			Params struct {
+				Height string `json:"height"`
+			} `json:"params"`
+		}
+		_ = json.Unmarshal(b, &req)
+		tx := ""
+		if req.Params.Height == "12" {
+			tx = `{"code":0,"events":[{"type":"transfer","attributes":[{"key":"recipient","value":"lumera1dead"},{"key":"amount","value":"5ulume"}]}]}`
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"finalize_block_events":[
+			{"type":"coinbase","attributes":[{"key":"amount","value":"10ulume"}]},
+			{"type":"burn","attributes":[{"key":"amount","value":"3ulume"}]}],"txs_results":[%s]}}`, tx)
+	}))
+	defer rpc.Close()
+	l := lcd.NewClient("http://unused", rpc.Client())
+	l.SetRPC(rpc.URL)
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr, _ := NewBurnTracker("ulume", st)
+	if got, _ := tr.Sync(l, 10, nil); got.FromHeight != 10 || got.Height != 10 || got.Minted != "0" {
+		t.Fatalf("first sync should start tracking: %+v", got)
+	}
+	got, err := tr.Sync(l, 13, []string{"lumera1dead"})
+	if err != nil || got.Height != 13 || got.Minted != "30" || got.Burned != "9" || got.BurnTransferred != "5" {
+		t.Fatalf("unexpected totals: %+v, %v", got, err)
+	}
+	// progress survives a restart
+	tr, _ = NewBurnTracker("ulume", st)
+	if got, _ := tr.Sync(l, 14, nil); got.FromHeight != 10 || got.Height != 14 || got.Minted != "40" {
+		t.Fatalf("unexpected totals after reload: %+v", got)
+	}
+
+	// a diff between tracked snapshots splits the total change into mint and burn
+	from := &types.SupplySnapshot{Height: 11, Total: "100", Circulating: "100", NonCirculating: types.NonCircBreakdown{Sum: "0"},
+		Burns: &types.BurnTotals{FromHeight: 10, Height: 11, Minted: "10", Burned: "3", BurnTransferred: "0"}}
+	to := &types.SupplySnapshot{Height: 14, Total: "121", Circulating: "121", NonCirculating: types.NonCircBreakdown{Sum: "0"},
+		Burns: &types.BurnTotals{FromHeight: 10, Height: 14, Minted: "40", Burned: "12", BurnTransferred: "5"}}
+	attr := map[string]string{}
+	for _, a := range Diff(from, to).Attribution {
+		attr[a.Cause] = a.Amount
+	}
+	// +21 total = +30 minted -9 burned; nothing unexplained
+	if attr[CauseMint] != "30" || attr[CauseBurn] != "-9" || len(attr) != 2 {
+		t.Fatalf("unexpected attribution: %v", attr)
+	}
+}
@@ -0,0 +1,71 @@
+package supply
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+var candidateDelta = metrics.Default.NewGauge(
+	"lumera_supply_candidate_circulating_delta",
+	"Candidate policy circulating supply minus the active one, in base units, at the latest snapshot height.",
+	"denom",
+)
+
+// Candidate is a candidate policy's evaluation at the same height and chain state as
+// the active snapshot it accompanies.
+type Candidate struct {
+	Active    *types.SupplySnapshot
+	Candidate *types.SupplySnapshot
+}
+
+// SetCandidate loads a candidate policy that is evaluated alongside the active one on
+// every snapshot, so a definition change can be monitored before it is switched on.
+// nil disables it.
+func (c *Computer) SetCandidate(p *policy.Policy) {
+	c.mu.Lock()
+	c.candidate = withETag(p)
+	c.mu.Unlock()
+	c.candMu.Lock()
+	c.candidates = nil
+	c.candMu.Unlock()
+}
+
+// Candidate returns the latest candidate evaluation for denom, or nil when no
+// candidate policy is loaded or no snapshot has been computed since.
+func (c *Computer) Candidate(denom string) *Candidate {
+	c.candMu.Lock()
+	defer c.candMu.Unlock()
+	return c.candidates[denom]
+}
+
+// evaluateCandidate recomputes the breakdown of an accepted active snapshot under the
+// candidate policy, with queries pinned to the snapshot height and made under ctx.
+// Called with c.mu held.
+func (c *Computer) evaluateCandidate(ctx context.Context, active *types.SupplySnapshot) {
+	if c.candidate == nil {
+		return
+	}
+	shadow := &Computer{lcd: c.lcd.WithContext(ctx).AtHeight(active.Height), policy: c.candidate}
+	cand := shadow.build(active.Denom, active.Height, active.UpdatedAt, active.Total)
+	if ctx.Err() != nil {
+		return
+	}
+
+	a, _ := new(big.Int).SetString(active.Circulating, 10)
+	b, _ := new(big.Int).SetString(cand.Circulating, 10)
+	if a != nil && b != nil {
+		f, _ := new(big.Float).SetInt(new(big.Int).Sub(b, a)).Float64()
+		candidateDelta.Set(f, active.Denom)
+	}
+
+	c.candMu.Lock()
+	if c.candidates == nil {
+		c.candidates = map[string]*Candidate{}
+	}
+	c.candidates[active.Denom] = &Candidate{Active: active, Candidate: cand}
+	c.candMu.Unlock()
+}
@@ -0,0 +1,61 @@
+package supply
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+)
+
+func TestCandidatePolicy(t *testing.T) {
+	const modAddr = "lumera1modulexxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1000"}}`))
+		case "/cosmos/auth/v1beta1/module_accounts/gov":
+			_, _ = w.Write([]byte(`{"account":{"base_account":{"address":"` + modAddr + `"}}}`))
+		case "/cosmos/bank/v1beta1/balances/" + modAddr + "/by_denom":
+			if r.Header.Get("x-cosmos-block-height") != "100" {
+				t.Errorf("candidate query not pinned to the snapshot height")
+			}
+			_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"300"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	comp := NewComputer(lcd.NewClient(ts.URL, ts.Client()), &policy.Policy{ETag: "active"})
+	if _, err := comp.ComputeSnapshot(context.Background(), "ulume"); err != nil {
+		t.Fatal(err)
+	}
+	if comp.Candidate("ulume") != nil {
+		t.Fatal("no candidate loaded, expected nil")
+	}
+
+	comp.SetCandidate(&policy.Policy{ETag: "cand", ModuleAccounts: []string{"gov"}})
+	if _, err := comp.ComputeSnapshot(context.Background(), "ulume"); err != nil {
+		t.Fatal(err)
+	}
+	c := comp.Candidate("ulume")
+	if c == nil {
+		t.Fatal("expected a candidate evaluation")
+	}
+	if c.Active.Circulating != "1000" || c.Candidate.Circulating != "700" {
+		t.Fatalf("circulating active=%s candidate=%s", c.Active.Circulating, c.Candidate.Circulating)
+	}
+	if c.Candidate.Height != c.Active.Height || c.Candidate.PolicyETag != "cand" {
+		t.Fatalf("unexpected candidate snapshot: %+v", c.Candidate)
+	}
+
+	comp.SetCandidate(nil)
+	if comp.Candidate("ulume") != nil {
+		t.Fatal("expected candidate cleared")
+	}
+}
@@ -1,13 +1,18 @@
 package supply
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"math/big"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lumera-labs/lumera-supply/pkg/lcd"
@@ -17,179 +22,543 @@ import (
 )
 
 type Computer struct {
-	lcd    *lcd.Client
+	lcd *lcd.Client
+	// mu guards policy; ComputeSnapshot holds it for reading so a reload never lands mid-computation
+	mu     sync.RWMutex
 	policy *policy.Policy
+	// independent LCDs for quorum verification (see quorum.go)
+	peers []*lcd.Client
+	// candidate policy evaluated alongside the active one (see candidate.go)
+	candidate  *policy.Policy
+	candMu     sync.Mutex
+	candidates map[string]*Candidate
+	// optional block results follower (see burns.go)
+	burns *BurnTracker
+	// results of cohorts with a refresh interval (see freshness.go)
+	memo *cohortMemo
+	// publish snapshots with negative circulating supply (see anomaly.go)
+	publishNegative bool
+	// wall clock for compute timing and cohort refresh intervals; locks are evaluated
+	// at block time regardless
+	clock vesting.Clock
+	// what-if evaluation instant replacing the block time (see ComputeSnapshotAt)
+	evalAt time.Time
+	// per-address lookups run in parallel (see SetConcurrency)
+	concurrency int
+	// fill in Explain on cohorts and items (see ExplainSnapshot)
+	explain bool
+	// diagnostics of the latest computations, and the fetch failures of the one
+	// running (see diagnostics.go)
+	diags    *diagnosticsLog
+	failures *failureLog
+	// bank metadata lookups of display units (see display.go)
+	display *displayMemo
 }
 
 func NewComputer(l *lcd.Client, p *policy.Policy) *Computer {
-	return &Computer{lcd: l, policy: p}
+	return &Computer{lcd: l, policy: withETag(p), memo: &cohortMemo{}, diags: &diagnosticsLog{}, display: &displayMemo{}, clock: vesting.SystemClock, concurrency: DefaultConcurrency}
 }
 
-// ComputeSnapshot fetches on-chain data and computes a snapshot at latest height.
-func (c *Computer) ComputeSnapshot(denom string) (*types.SupplySnapshot, error) {
-	height, t, err := c.lcd.LatestHeight()
+// SetClock replaces the wall clock (for tests).
+func (c *Computer) SetClock(clock vesting.Clock) {
+	c.mu.Lock()
+	c.clock = clock
+	c.mu.Unlock()
+}
+
+// now reads the computer's clock, the wall clock when none is set.
+func (c *Computer) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+// SetPolicy replaces the policy used by subsequent snapshots.
+func (c *Computer) SetPolicy(p *policy.Policy) {
+	c.mu.Lock()
+	c.policy = withETag(p)
+	c.mu.Unlock()
+}
+
+// PolicyETag returns the active policy's ETag ("" without a policy).
+func (c *Computer) PolicyETag() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.policy == nil {
+		return ""
+	}
+	return c.policy.ETag
+}
+
+// withETag sets the content ETag on policies built in code rather than loaded.
+func withETag(p *policy.Policy) *policy.Policy {
+	if p != nil && p.ETag == "" {
+		p.ETag = p.ComputeETag()
+	}
+	return p
+}
+
+// ComputeSnapshot fetches on-chain data and computes a snapshot at latest height. Every
+// upstream request is made under ctx; once ctx is done the snapshot is abandoned with
+// ctx's error rather than published with the cohorts it could not fetch.
+// The outcome, published or not, is recorded for Diagnostics.
+func (c *Computer) ComputeSnapshot(ctx context.Context, denom string) (_ *types.SupplySnapshot, err error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	start := c.now()
+	var stats lcd.CallStats
+	var snap *types.SupplySnapshot
+	failures := &failureLog{}
+	defer func() { c.recordDiagnostics(ctx, denom, snap, failures, err) }()
+	base := c.lcd.WithContext(ctx).WithStats(&stats)
+	height, t, err := base.LatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	// same policy and peers, with upstream calls counted for this snapshot only and every
+	// query pinned to height, so blocks produced during the computation are not mixed in
+	run := &Computer{lcd: base.AtHeight(height), policy: c.policy, peers: peersWithContext(ctx, c.peers), burns: c.burns, memo: c.memo, publishNegative: c.publishNegative, clock: c.clock, concurrency: c.concurrency, failures: failures}
+	total, err := run.lcd.TotalSupplyByDenom(denom)
+	if err != nil {
+		return nil, err
+	}
+	snap = run.build(denom, height, t, total)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// outside the snapshot's call stats: the metadata is looked up once per denom
+	snap.Display = c.displayUnit(c.lcd.WithContext(ctx), denom)
+	if err := run.verifyQuorum(snap); err != nil {
+		return nil, err
+	}
+	if err := run.checkOverlaps(snap); err != nil {
+		return nil, err
+	}
+	if err := run.checkCirculating(snap); err != nil {
+		return nil, err
+	}
+	run.trackBurns(snap)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	snap.ComputeStats = &types.ComputeStats{
+		DurationMS: c.now().Sub(start).Milliseconds(),
+		LCDCalls:   stats.Calls(),
+		Retries:    stats.Retries(),
+		CacheHits:  stats.CacheHits(),
+	}
+	c.evaluateCandidate(ctx, snap)
+	return snap, nil
+}
+
+// ComputeSnapshotAt computes a what-if snapshot of the latest chain state with every lock
+// evaluated at the instant at instead of the block time. It is for operators (the admin
+// evaluate endpoint and the CLI): peers, burn tracking, the candidate policy and cohort
+// refresh intervals are skipped, and overlap or negative-supply policies only annotate
+// the result, which callers must not cache or publish as the current snapshot.
+func (c *Computer) ComputeSnapshotAt(ctx context.Context, denom string, at time.Time) (*types.SupplySnapshot, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	run := &Computer{lcd: c.lcd.WithContext(ctx), policy: c.policy, clock: c.clock, evalAt: at, concurrency: c.concurrency}
+	height, t, err := run.lcd.LatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	total, err := run.lcd.TotalSupplyByDenom(denom)
+	if err != nil {
+		return nil, err
+	}
+	snap := run.build(denom, height, t, total)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	snap.Display = c.displayUnit(run.lcd, denom)
+	return snap, nil
+}
+
+// ComputeSnapshotAtHeight computes denom's snapshot at a past height, with every query
+// pinned to it (lcd.Client.AtHeight) and locks evaluated at that block's time, so
+// auditors can reproduce published figures. The node must keep state at height (an
+// archive node). The active policy applies; peers, burn tracking, the candidate policy
+// and cohort refresh intervals are skipped, and overlap or negative-supply policies only
+// annotate the result, which callers must not publish as the current snapshot.
+func (c *Computer) ComputeSnapshotAtHeight(ctx context.Context, denom string, height int64) (*types.SupplySnapshot, error) {
+	return c.computeAtHeight(ctx, denom, height, false)
+}
+
+func (c *Computer) computeAtHeight(ctx context.Context, denom string, height int64, explain bool) (*types.SupplySnapshot, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	start := c.now()
+	var stats lcd.CallStats
+	base := c.lcd.WithContext(ctx).WithStats(&stats)
+	h, t, err := base.Block(height)
 	if err != nil {
 		return nil, err
 	}
-	total, err := c.lcd.TotalSupplyByDenom(denom)
+	run := &Computer{lcd: base.AtHeight(h), policy: c.policy, clock: c.clock, concurrency: c.concurrency, explain: explain}
+	total, err := run.lcd.TotalSupplyByDenom(denom)
 	if err != nil {
 		return nil, err
 	}
+	snap := run.build(denom, h, t, total)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	snap.Display = c.displayUnit(c.lcd.WithContext(ctx), denom)
+	snap.ComputeStats = &types.ComputeStats{
+		DurationMS: c.now().Sub(start).Milliseconds(),
+		LCDCalls:   stats.Calls(),
+		Retries:    stats.Retries(),
+		CacheHits:  stats.CacheHits(),
+	}
+	return snap, nil
+}
 
-	ve := vesting.NewEngine()
+// Cohort sources: the chain data a cohort is computed from.
+const (
+	SourceIBCEscrow        = "ibc_total_escrow"
+	SourceCommunityPool    = "community_pool"
+	SourceModuleAccount    = "module_account"
+	SourceDisclosedLockups = "disclosed_lockups"
+	SourceClaimRecords     = "claim_records"
+	SourceStakingPool      = "staking_pool"
+)
+
+// CohortSource returns the source of the cohort named name, as computed by build, or ""
+// for a name build does not produce.
+func CohortSource(name string) string {
+	switch {
+	case name == "ibc_escrow":
+		return SourceIBCEscrow
+	case name == "community_pool":
+		return SourceCommunityPool
+	case strings.HasPrefix(name, "module:"), name == "gov_deposits":
+		return SourceModuleAccount
+	case name == "foundation_genesis", name == "supernode_bootstraps":
+		return SourceDisclosedLockups
+	case name == "claim_delayed":
+		return SourceClaimRecords
+	case name == "staking_bonded":
+		return SourceStakingPool
+	}
+	return ""
+}
+
+// build computes the non-circulating breakdown under c.policy at height/t given the
+// total supply. Every lock is evaluated at the block time t (ve.Now()), never wall time,
+// so a snapshot depends only on chain state at height; only ComputeSnapshotAt
+// substitutes another instant. Cohort fetch failures, and panics in a cohort's source,
+// are logged and the cohort skipped.
+func (c *Computer) build(denom string, height int64, t time.Time, total string) *types.SupplySnapshot {
+	at := t
+	if !c.evalAt.IsZero() {
+		at = c.evalAt
+	}
+	ve := vesting.NewEngineWithClock(vesting.FixedClock(at.UTC()))
 	var breakdown types.NonCircBreakdown
+	staked := c.stakedSupply(denom)
 
 	// Cohort: IBC escrow total (single call aggregates all transfer channels)
-	if esc, err := c.lcd.IBCTotalEscrow(denom); err == nil {
-		breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
-			Name:   "ibc_escrow",
-			Reason: "ICS20 transfer escrows",
-			Amount: esc,
-		})
-	} else {
-		log.Printf("warn: ibc escrow fetch failed: %v", err)
-	}
+	c.guardSource("ibc_escrow", func() {
+		if e, ok := c.reuse(denom, "ibc_escrow"); ok {
+			breakdown.Cohorts = append(breakdown.Cohorts, e)
+		} else if esc, err := c.lcd.IBCTotalEscrow(denom); err == nil {
+			breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
+				Name:   "ibc_escrow",
+				Reason: "ICS20 transfer escrows",
+				Amount: esc,
+			})
+		} else {
+			c.fetchFailed("ibc_escrow", "ibc escrow fetch", err)
+		}
+	})
 	// Community pool (distribution module)
-	if cp, err := c.lcd.CommunityPool(denom); err == nil {
-		breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
-			Name:   "community_pool",
-			Reason: "distribution community pool",
-			Amount: cp,
-		})
-	} else {
-		log.Printf("warn: community pool fetch failed: %v", err)
-	}
+	c.guardSource("community_pool", func() {
+		if e, ok := c.reuse(denom, "community_pool"); ok {
+			breakdown.Cohorts = append(breakdown.Cohorts, e)
+		} else if cp, err := c.lcd.CommunityPool(denom); err == nil {
+			breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
+				Name:   "community_pool",
+				Reason: "distribution community pool",
+				Amount: cp,
+			})
+		} else {
+			c.fetchFailed("community_pool", "community pool fetch", err)
+		}
+	})
 
 	if c.policy != nil {
 		// Module accounts: accept names; report single address
 		for _, accountName := range c.policy.ModuleAccounts {
-			var accountAddress string
-			if a, err := c.lcd.ModuleAddressByName(accountName); err == nil && a != "" {
-				accountAddress = a
-			} else {
-				log.Printf("warn: module name %q resolution failed: %v", accountName, err)
-				continue
-			}
-			amt, err := c.lcd.BalanceByDenom(accountAddress, denom)
-			if err != nil {
-				log.Printf("warn: module acct balance %s: %v", accountAddress, err)
-				continue
-			}
-			breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
-				Name:    "module:" + accountName,
-				Reason:  "protocol-controlled module account",
-				Address: accountAddress,
-				Amount:  amt,
+			c.guardSource("module:"+accountName, func() {
+				if e, ok := c.reuse(denom, "module:"+accountName); ok {
+					breakdown.Cohorts = append(breakdown.Cohorts, e)
+					return
+				}
+				var accountAddress string
+				if a, err := c.lcd.ModuleAddressByName(accountName); err == nil && a != "" {
+					accountAddress = a
+				} else if err != nil {
+					c.fetchFailed("module:"+accountName, fmt.Sprintf("module name %q resolution", accountName), err)
+					return
+				} else {
+					log.Printf("warn: module name %q resolution failed: no address", accountName)
+					return
+				}
+				amt, err := c.lcd.BalanceByDenom(accountAddress, denom)
+				if err != nil {
+					c.fetchFailed("module:"+accountName, "module acct balance "+accountAddress, err)
+					return
+				}
+				breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
+					Name:    "module:" + accountName,
+					Reason:  "protocol-controlled module account",
+					Address: accountAddress,
+					Amount:  amt,
+				})
 			})
 		}
 
-		// Foundation genesis: compute locked portion per address; include end_date
-		if len(c.policy.Disclosed.FoundationGenesis) > 0 {
-			items := make([]types.AddressItem, 0, len(c.policy.Disclosed.FoundationGenesis))
-			totalLocked := big.NewInt(0)
-			for _, e := range c.policy.Disclosed.FoundationGenesis {
-				locked, end, _, err := c.lockedAndEndFromAuthAccount(e.Address, t, denom, ve)
-				if err != nil {
-					log.Printf("warn: foundation vesting compute for %s: %v", e.Address, err)
-					continue
+		// Governance deposits: escrowed in the gov module account until refunded or burned
+		if c.policy.GovDeposits {
+			c.guardSource("gov_deposits", func() {
+				if e, ok := c.reuse(denom, "gov_deposits"); ok {
+					breakdown.Cohorts = append(breakdown.Cohorts, e)
+				} else if slices.Contains(c.policy.ModuleAccounts, "gov") {
+					log.Printf("warn: gov_deposits ignored: gov is already listed in module_accounts")
+				} else if addr, err := c.lcd.ModuleAddressByName("gov"); err != nil {
+					c.fetchFailed("gov_deposits", "gov module address resolution", err)
+				} else if addr == "" {
+					log.Printf("warn: gov module address resolution failed: no address")
+				} else if amt, err := c.lcd.BalanceByDenom(addr, denom); err != nil {
+					c.fetchFailed("gov_deposits", "gov deposits balance "+addr, err)
+				} else {
+					breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
+						Name:    "gov_deposits",
+						Reason:  "governance proposal deposits escrowed in the gov module account",
+						Address: addr,
+						Amount:  amt,
+					})
+				}
+			})
+		}
+
+		// Bonded tokens, from the staking pool
+		if c.policy.StakingBonded {
+			c.guardSource("staking_bonded", func() {
+				if e, ok := c.reuse(denom, "staking_bonded"); ok {
+					breakdown.Cohorts = append(breakdown.Cohorts, e)
+				} else if slices.Contains(c.policy.ModuleAccounts, "bonded_tokens_pool") {
+					log.Printf("warn: staking_bonded ignored: bonded_tokens_pool is already listed in module_accounts")
+				} else if staked != nil {
+					breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
+						Name:   "staking_bonded",
+						Reason: "tokens bonded to validators (staking pool)",
+						Amount: staked.Bonded,
+					})
 				}
-				v, _ := new(big.Int).SetString(locked, 10)
-				totalLocked.Add(totalLocked, v)
-				items = append(items, types.AddressItem{Address: e.Address, Amount: locked, EndDate: end})
-			}
-			breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
-				Name:   "foundation_genesis",
-				Reason: "protocol/foundation vesting locked portion",
-				Items:  items,
-				Amount: totalLocked.String(),
 			})
 		}
 
+		// Foundation genesis: compute locked portion per address; include end_date
+		sc := c.newStakeCheck(denom)
+		c.guardSource("foundation_genesis", func() {
+			if e, ok := c.reuse(denom, "foundation_genesis"); ok {
+				breakdown.Cohorts = append(breakdown.Cohorts, e)
+			} else if len(c.policy.Disclosed.FoundationGenesis) > 0 {
+				entries := c.policy.Disclosed.FoundationGenesis
+				found := make([]*types.AddressItem, len(entries))
+				errs := make([]error, len(entries))
+				c.each(len(entries), func(i int) {
+					it, err := c.vestingItem(entries[i].Address, denom, ve)
+					if err != nil {
+						errs[i] = fmt.Errorf("%s: %w", entries[i].Address, err)
+						return
+					}
+					it.AddressGroup = entries[i].Group
+					sc.apply("foundation_genesis", &it)
+					found[i] = &it
+				})
+				c.fetchesFailed("foundation_genesis", "foundation vesting compute", errs)
+				items := make([]types.AddressItem, 0, len(entries))
+				totalLocked := big.NewInt(0)
+				for _, it := range found {
+					if it == nil {
+						continue
+					}
+					v, _ := new(big.Int).SetString(it.Amount, 10)
+					totalLocked.Add(totalLocked, v)
+					items = append(items, *it)
+				}
+				breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
+					Name:   "foundation_genesis",
+					Reason: "protocol/foundation vesting locked portion",
+					Items:  items,
+					Amount: totalLocked.String(),
+				})
+			}
+		})
+
 		// Supernode bootstraps: from policy + on-chain; include per-address end_date (or forever)
-		if len(c.policy.Disclosed.SupernodeBootstraps) > 0 {
-			items := make([]types.AddressItem, 0, len(c.policy.Disclosed.SupernodeBootstraps))
-			totalLocked := big.NewInt(0)
-			for _, e := range c.policy.Disclosed.SupernodeBootstraps {
-				locked, end, _, err := c.lockedAndEndFromAuthAccount(e.Address, t, denom, ve)
-				if err != nil || locked == "0" {
-					// Fallback to policy hints
-					if e.Permanent {
-						if bal, err2 := c.lcd.BalanceByDenom(e.Address, denom); err2 == nil {
-							locked = bal
-							end = "forever"
-							err = nil
-						}
-					} else if e.DurationMonths != nil {
-						start := e.StartTime
-						if start == nil {
-							start = &t
-						}
-						endTime := start.AddDate(0, *e.DurationMonths, 0)
-						if bal, err2 := c.lcd.BalanceByDenom(e.Address, denom); err2 == nil {
-							locked = ve.DelayedLocked(bal, t, endTime)
-							end = endTime.UTC().Format(time.RFC3339)
-							err = nil
+		c.guardSource("supernode_bootstraps", func() {
+			if e, ok := c.reuse(denom, "supernode_bootstraps"); ok {
+				breakdown.Cohorts = append(breakdown.Cohorts, e)
+			} else if len(c.policy.Disclosed.SupernodeBootstraps) > 0 {
+				entries := c.policy.Disclosed.SupernodeBootstraps
+				found := make([]types.AddressItem, len(entries))
+				c.each(len(entries), func(i int) {
+					e := entries[i]
+					it, err := c.vestingItem(e.Address, denom, ve)
+					if err != nil || it.Amount == "0" {
+						locked, end, ex, source := it.Amount, it.EndDate, it.Explain, ItemSourceAuthVesting
+						// Fallback to policy hints
+						if e.Permanent {
+							if bal, err2 := c.lcd.BalanceByDenom(e.Address, denom); err2 == nil {
+								locked = capPrincipal(bal, e.Amount, denom)
+								end = "forever"
+								ex, source = c.explainPolicyLock(bal, e.Amount, nil), ItemSourcePolicyPermanent
+							}
+						} else if e.DurationMonths != nil {
+							// without a policy start time the lock runs from the evaluation instant
+							start := ve.Now()
+							if e.StartTime != nil {
+								start = *e.StartTime
+							}
+							endTime := start.AddDate(0, *e.DurationMonths, 0)
+							if bal, err2 := c.lcd.BalanceByDenom(e.Address, denom); err2 == nil {
+								locked = ve.DelayedLocked(capPrincipal(bal, e.Amount, denom), ve.Now(), endTime)
+								end = endTime.UTC().Format(time.RFC3339)
+								ex, source = c.explainPolicyLock(bal, e.Amount, &endTime), ItemSourcePolicyDelayed
+							}
 						}
+						it = newAddressItem(e.Address, locked, end, source)
+						it.Explain = ex
 					}
+					found[i] = it
+					found[i].AddressGroup = e.Group
+					sc.apply("supernode_bootstraps", &found[i])
+				})
+				items := make([]types.AddressItem, 0, len(entries))
+				totalLocked := big.NewInt(0)
+				for _, it := range found {
+					v, _ := new(big.Int).SetString(it.Amount, 10)
+					totalLocked.Add(totalLocked, v)
+					items = append(items, it)
 				}
-				v, _ := new(big.Int).SetString(locked, 10)
-				totalLocked.Add(totalLocked, v)
-				items = append(items, types.AddressItem{Address: e.Address, Amount: locked, EndDate: end})
+				breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
+					Name:   "supernode_bootstraps",
+					Reason: "protocol supernode bootstrap locks",
+					Items:  items,
+					Amount: totalLocked.String(),
+				})
 			}
-			breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
-				Name:   "supernode_bootstraps",
-				Reason: "protocol supernode bootstrap locks",
-				Items:  items,
-				Amount: totalLocked.String(),
-			})
-		}
+		})
 
 		// Claimed accounts delayed locks (tiers 1..4): prefer on-chain vesting via AuthAccount; fallback to claim-record schedule; per-address
-		claimedLocked := big.NewInt(0)
-		items := make([]types.AddressItem, 0)
-		for tier := 1; tier <= 4; tier++ {
-			recs, err := c.lcd.ClaimListClaimed(tier, denom)
-			if err != nil {
-				log.Printf("warn: claim list tier %d: %v", tier, err)
-				continue
-			}
-			months := tier * 6 // 1=>6m,2=>12m,3=>18m,4=>24m
-			for _, r := range recs {
-				if locked, end, _, err := c.lockedAndEndFromAuthAccount(r.Address, t, denom, ve); err == nil && locked != "" {
-					v, _ := new(big.Int).SetString(locked, 10)
-					claimedLocked.Add(claimedLocked, v)
-					items = append(items, types.AddressItem{Address: r.Address, Amount: locked, EndDate: end})
-					continue
-				}
-				// Fallback: delayed vesting from claim time
-				start := t
-				if r.Time != nil {
-					start = *r.Time
-				}
-				endTime := start.AddDate(0, months, 0)
-				amt := r.Amount
-				if amt == "" { // fallback to on-chain balance if claim record lacks amount
-					if bal, err := c.lcd.BalanceByDenom(r.Address, denom); err == nil {
-						amt = bal
+		c.guardSource("claim_delayed", func() {
+			if e, ok := c.reuse(denom, "claim_delayed"); ok {
+				breakdown.Cohorts = append(breakdown.Cohorts, e)
+			} else {
+				claimedLocked := big.NewInt(0)
+				items := make([]types.AddressItem, 0)
+				for tier := 1; tier <= 4; tier++ {
+					recs, err := c.lcd.ClaimListClaimed(tier, denom)
+					if err != nil {
+						c.fetchFailed("claim_delayed", fmt.Sprintf("claim list tier %d", tier), err)
+						continue
+					}
+					months := tier * 6 // 1=>6m,2=>12m,3=>18m,4=>24m
+					onChain := make([]*types.AddressItem, len(recs))
+					c.each(len(recs), func(i int) {
+						if it, err := c.vestingItem(recs[i].Address, denom, ve); err == nil && it.Amount != "" {
+							it.Tier = tier
+							onChain[i] = &it
+						}
+					})
+					var fallback []lcd.ClaimRecord
+					for i, r := range recs {
+						if it := onChain[i]; it != nil {
+							v, _ := new(big.Int).SetString(it.Amount, 10)
+							claimedLocked.Add(claimedLocked, v)
+							items = append(items, *it)
+							continue
+						}
+						fallback = append(fallback, r)
+					}
+					// On-chain balances for fallback records lacking a claim amount (batched when the node supports it)
+					var need []string
+					for _, r := range fallback {
+						if r.Amount == "" {
+							need = append(need, r.Address)
+						}
+					}
+					bals := map[string]string{}
+					if len(need) > 0 {
+						if bals, err = c.lcd.BalancesByDenom(need, denom); err != nil {
+							c.fetchFailed("claim_delayed", fmt.Sprintf("claim balances tier %d", tier), err)
+						}
+					}
+					for _, r := range fallback {
+						// Fallback: delayed vesting from claim time; records without one are
+						// treated as claimed at the evaluation instant (block time)
+						start := ve.Now()
+						if r.Time != nil {
+							start = *r.Time
+						}
+						endTime := start.AddDate(0, months, 0)
+						amt, source := r.Amount, ItemSourceClaimRecord
+						if amt == "" { // fallback to on-chain balance if claim record lacks amount
+							amt, source = bals[r.Address], ItemSourceClaimBalance
+						}
+						if amt != "" {
+							locked := ve.DelayedLocked(amt, ve.Now(), endTime)
+							v, _ := new(big.Int).SetString(locked, 10)
+							claimedLocked.Add(claimedLocked, v)
+							it := newAddressItem(r.Address, locked, endTime.UTC().Format(time.RFC3339), source)
+							it.Tier, it.Explain = tier, c.explainClaimRecord(r, tier, amt, start, endTime)
+							items = append(items, it)
+						}
 					}
 				}
-				if amt != "" {
-					locked := ve.DelayedLocked(amt, t, endTime)
-					v, _ := new(big.Int).SetString(locked, 10)
-					claimedLocked.Add(claimedLocked, v)
-					items = append(items, types.AddressItem{Address: r.Address, Amount: locked, EndDate: endTime.UTC().Format(time.RFC3339)})
+				if claimedLocked.Sign() > 0 || len(items) > 0 {
+					breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
+						Name:   "claim_delayed",
+						Reason: "claim module delayed locks (6/12/18/24m) with on-chain vesting preference",
+						Items:  items,
+						Amount: claimedLocked.String(),
+					})
 				}
 			}
-		}
-		if claimedLocked.Sign() > 0 || len(items) > 0 {
-			breakdown.Cohorts = append(breakdown.Cohorts, types.CohortEntry{
-				Name:   "claim_delayed",
-				Reason: "claim module delayed locks (6/12/18/24m) with on-chain vesting preference",
-				Items:  items,
-				Amount: claimedLocked.String(),
-			})
+		})
+	}
+
+	// Attach policy tags and item counts to cohorts; fresh ones are as of this snapshot
+	for i := range breakdown.Cohorts {
+		co := &breakdown.Cohorts[i]
+		co.Tags = c.policy.CohortTags(co.Name)
+		co.DisclosureURL, co.Notes = c.policy.CohortDisclosure(co.Name)
+		co.ID, co.Slug = c.policy.CohortID(co.Name)
+		co.ItemCount = len(co.Items)
+		if co.AsOfHeight == 0 {
+			co.AsOfHeight, co.AsOfTime = height, t.UTC()
 		}
 	}
+	c.remember(denom, height, breakdown.Cohorts)
+
+	// Addresses counted by several cohorts (deduplicated when the policy says so)
+	var overlaps []types.CohortOverlap
+	breakdown.Cohorts, overlaps = c.resolveOverlaps(breakdown.Cohorts)
+	for i := range breakdown.Cohorts {
+		breakdown.Cohorts[i].ItemCount = len(breakdown.Cohorts[i].Items)
+		breakdown.Cohorts[i].Tiers = tierTotals(breakdown.Cohorts[i].Items)
+		breakdown.Cohorts[i].AddressGroups = c.addressGroups(breakdown.Cohorts[i].Items)
+		breakdown.Cohorts[i].Explain = c.explainCohort(&breakdown.Cohorts[i], denom, staked)
+	}
+
+	// Stable order so diffs between snapshots are meaningful and ETags reproducible
+	sortBreakdown(&breakdown)
 
 	// Sum non-circ
 	sum := big.NewInt(0)
@@ -202,31 +571,117 @@ func (c *Computer) ComputeSnapshot(denom string) (*types.SupplySnapshot, error)
 	// Circulating = total - non_circ
 	T, _ := new(big.Int).SetString(total, 10)
 	circ := new(big.Int).Sub(T, sum)
+	var anomaly *types.SupplyAnomaly
 	if circ.Sign() < 0 {
-		circ.SetInt64(0)
+		anomaly = negativeAnomaly(T, sum, circ, breakdown.Cohorts)
 	}
 
-	etag := computeETag(height, denom, total, circ.String(), breakdown.Sum)
-
 	var maxSupply *string
-	if c.policy != nil && c.policy.MaxSupply != nil {
+	policyETag := ""
+	if c.policy != nil {
 		maxSupply = c.policy.MaxSupply
+		policyETag = c.policy.ETag
 	}
+	etag := computeETag(height, denom, total, circ.String(), breakdown.Sum, policyETag)
 
- return &types.SupplySnapshot{
+	return &types.SupplySnapshot{
+		SchemaVersion:  types.SchemaVersion,
 		Denom:          denom,
 		Height:         height,
 		UpdatedAt:      t.UTC(),
 		ETag:           etag,
-		PolicyETag:     func() string { if c.policy != nil { return c.policy.ETag }; return "" }(),
+		PolicyETag:     policyETag,
 		Total:          total,
 		Circulating:    circ.String(),
 		Max:            maxSupply,
 		NonCirculating: breakdown,
-	}, nil
+		EvaluatedAt:    at.UTC(),
+		Overlaps:       overlaps,
+		Anomaly:        anomaly,
+		Staked:         staked,
+	}
+}
+
+// sortBreakdown orders cohorts by name and each cohort's items by address (then end date).
+func sortBreakdown(b *types.NonCircBreakdown) {
+	sort.SliceStable(b.Cohorts, func(i, j int) bool { return b.Cohorts[i].Name < b.Cohorts[j].Name })
+	for _, c := range b.Cohorts {
+		items := c.Items
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].Address != items[j].Address {
+				return items[i].Address < items[j].Address
+			}
+			return items[i].EndDate < items[j].EndDate
+		})
+	}
 }
 
-func computeETag(height int64, denom, total, circ, non string) string {
+// newAddressItem builds an item with the structured end fields derived from end
+// (RFC3339, "forever", or empty) and the given source (one of the ItemSource values).
+func newAddressItem(address, amount, end, source string) types.AddressItem {
+	it := types.AddressItem{Address: address, Amount: amount, EndDate: end, Source: source}
+	if end == "forever" {
+		it.Permanent = true
+	} else if t, err := time.Parse(time.RFC3339, end); err == nil {
+		it.EndUnix = t.Unix()
+	}
+	return it
+}
+
+// tierTotals sums items by tier, in tier order; nil when no item has a tier.
+func tierTotals(items []types.AddressItem) []types.TierTotal {
+	sums := map[int]*big.Int{}
+	counts := map[int]int{}
+	for _, it := range items {
+		if it.Tier == 0 {
+			continue
+		}
+		if sums[it.Tier] == nil {
+			sums[it.Tier] = new(big.Int)
+		}
+		if v, ok := new(big.Int).SetString(it.Amount, 10); ok {
+			sums[it.Tier].Add(sums[it.Tier], v)
+		}
+		counts[it.Tier]++
+	}
+	if len(sums) == 0 {
+		return nil
+	}
+	out := make([]types.TierTotal, 0, len(sums))
+	for tier, sum := range sums {
+		out = append(out, types.TierTotal{Tier: tier, Amount: sum.String(), ItemCount: counts[tier]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tier < out[j].Tier })
+	return out
+}
+
+// addressGroups returns the policy metadata of the address groups items name, nil when
+// they name none.
+func (c *Computer) addressGroups(items []types.AddressItem) map[string]types.AddressGroup {
+	var out map[string]types.AddressGroup
+	for _, it := range items {
+		if it.AddressGroup == "" {
+			continue
+		}
+		if _, ok := out[it.AddressGroup]; ok {
+			continue
+		}
+		g, ok := c.policy.AddressGroup(it.AddressGroup)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = map[string]types.AddressGroup{}
+		}
+		out[it.AddressGroup] = types.AddressGroup{Custody: g.Custody, Notes: g.Notes, Links: g.Links}
+	}
+	return out
+}
+
+// computeETag hashes the figures together with the policy and schema versions: a
+// policy change that leaves the sums unchanged still changes cohort semantics, so
+// clients must refetch.
+func computeETag(height int64, denom, total, circ, non, policyETag string) string {
 	h := sha1.New()
 	h.Write([]byte(denom))
 	h.Write([]byte{0})
@@ -237,42 +692,73 @@ func computeETag(height int64, denom, total, circ, non string) string {
 	h.Write([]byte(non))
 	h.Write([]byte{0})
 	h.Write([]byte(time.Unix(height, 0).UTC().Format(time.RFC3339)))
+	h.Write([]byte{0})
+	h.Write([]byte(policyETag))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(types.SchemaVersion)))
 	return hex.EncodeToString(h.Sum(nil))
 }
 
 // lockedFromAuthAccount computes the locked amount for a vesting account based on its on-chain account JSON.
-func (c *Computer) lockedFromAuthAccount(address string, now time.Time, denom string, ve *vesting.Engine) (string, error) {
-	locked, _, _, err := c.lockedAndEndFromAuthAccount(address, now, denom, ve)
-	return locked, err
+func (c *Computer) lockedFromAuthAccount(address string, denom string, ve *vesting.Engine) (string, error) {
+	it, err := c.vestingItem(address, denom, ve)
+	return it.Amount, err
 }
 
-// lockedAndEndFromAuthAccount computes the locked amount and end date (if any) for a vesting account based on its on-chain account JSON.
-// Returns (locked, endDate, accountType, error). endDate is RFC3339, or "forever" for permanent locks, or empty if not applicable.
-func (c *Computer) lockedAndEndFromAuthAccount(address string, now time.Time, denom string, ve *vesting.Engine) (string, string, string, error) {
+// vestingItem builds the item of address from its on-chain vesting account: the amount
+// locked at ve.Now(), the end date (RFC3339, "forever" for permanent locks, or empty if
+// not applicable) and the schedule releasing it. Explain is nil unless c.explain.
+func (c *Computer) vestingItem(address string, denom string, ve *vesting.Engine) (types.AddressItem, error) {
 	acctRaw, typ, err := c.lcd.AuthAccount(address)
 	if err != nil {
-		return "", "", "", err
-	}
-	// Generic struct covering common vesting account fields
-	var v struct {
-		BaseVestingAccount struct {
-			OriginalVesting []struct {
-				Denom  string `json:"denom"`
-				Amount string `json:"amount"`
-			} `json:"original_vesting"`
-			EndTime string `json:"end_time"`
-		} `json:"base_vesting_account"`
-		StartTime      string `json:"start_time"`
-		VestingPeriods []struct {
-			Length string `json:"length"`
-			Amount []struct {
-				Denom  string `json:"denom"`
-				Amount string `json:"amount"`
-			} `json:"amount"`
-		} `json:"vesting_periods"`
+		return types.AddressItem{}, err
 	}
+	locked, end, sched, err := decodeVestingAccount(acctRaw, typ, denom, ve.Now(), ve)
+	if err != nil {
+		return types.AddressItem{}, err
+	}
+	it := newAddressItem(address, locked, end, ItemSourceAuthVesting)
+	it.Schedule = sched
+	if c.explain {
+		it.Explain = explainVestingAccount(acctRaw, typ, denom, ve.Now())
+	}
+	return it, nil
+}
+
+// maxUnix is the last second of year 9999; later end dates would not format as RFC3339.
+const maxUnix = 253402300799
+
+// isAmount reports whether s is a non-negative integer amount.
+func isAmount(s string) bool {
+	n, ok := new(big.Int).SetString(s, 10)
+	return ok && n.Sign() >= 0
+}
+
+// vestingAccountJSON covers the fields common vesting account types share.
+type vestingAccountJSON struct {
+	BaseVestingAccount struct {
+		OriginalVesting []coinJSON `json:"original_vesting"`
+		EndTime         string     `json:"end_time"`
+	} `json:"base_vesting_account"`
+	StartTime      string `json:"start_time"`
+	VestingPeriods []struct {
+		Length string     `json:"length"`
+		Amount []coinJSON `json:"amount"`
+	} `json:"vesting_periods"`
+}
+
+type coinJSON struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+// decodeVestingAccount evaluates the lock of an account of type typ (its JSON in acctRaw)
+// at now: (locked, endDate, schedule, error), endDate as vestingItem describes it. The
+// schedule is set for continuous and periodic accounts with something still locked.
+func decodeVestingAccount(acctRaw json.RawMessage, typ, denom string, now time.Time, ve *vesting.Engine) (string, string, *types.LockSchedule, error) {
+	var v vestingAccountJSON
 	if err := json.Unmarshal(acctRaw, &v); err != nil {
-		return "", "", "", err
+		return "", "", nil, err
 	}
 	ov := "0"
 	for _, c := range v.BaseVestingAccount.OriginalVesting {
@@ -283,35 +769,51 @@ func (c *Computer) lockedAndEndFromAuthAccount(address string, now time.Time, de
 	}
 	// If no vesting info, nothing locked
 	if ov == "0" {
-		return "0", "", typ, nil
+		return "0", "", nil, nil
+	}
+	// the vesting engine takes amounts as given; after an upgrade they may not be
+	if !isAmount(ov) {
+		return "", "", nil, fmt.Errorf("original_vesting %q is not an amount", ov)
 	}
 	// Helpers to parse times (seconds since epoch in strings)
+	var tsErr error
 	parseTS := func(s string) time.Time {
 		if s == "" {
 			return time.Time{}
 		}
 		var sec int64
 		_, _ = fmt.Sscan(s, &sec)
+		if sec < 0 || sec > maxUnix {
+			tsErr = fmt.Errorf("time %q out of range", s)
+		}
 		return time.Unix(sec, 0).UTC()
 	}
 	start := parseTS(v.StartTime)
 	end := parseTS(v.BaseVestingAccount.EndTime)
+	if tsErr != nil {
+		return "", "", nil, tsErr
+	}
 
 	switch {
 	case strings.Contains(typ, "PermanentLockedAccount"):
-		return ve.PermanentLocked(ov), "forever", typ, nil
+		return ve.PermanentLocked(ov), "forever", nil, nil
 	case strings.Contains(typ, "DelayedVestingAccount"):
 		endStr := ""
 		if !end.IsZero() {
 			endStr = end.Format(time.RFC3339)
 		}
-		return ve.DelayedLocked(ov, now, end), endStr, typ, nil
+		return ve.DelayedLocked(ov, now, end), endStr, nil, nil
 	case strings.Contains(typ, "ContinuousVestingAccount"):
 		endStr := ""
 		if !end.IsZero() {
 			endStr = end.Format(time.RFC3339)
 		}
-		return ve.ContinuousLocked(ov, now, start, end), endStr, typ, nil
+		locked := ve.ContinuousLocked(ov, now, start, end)
+		var sched *types.LockSchedule
+		if locked != "0" && !end.IsZero() {
+			sched = &types.LockSchedule{Kind: ScheduleContinuous, OriginalVesting: ov, StartUnix: start.Unix(), EndUnix: end.Unix()}
+		}
+		return locked, endStr, sched, nil
 	case strings.Contains(typ, "PeriodicVestingAccount") || strings.Contains(typ, "ClawbackVestingAccount"):
 		// Build periods timeline and remember the last end time
 		elapsed := time.Duration(0)
@@ -319,6 +821,9 @@ func (c *Computer) lockedAndEndFromAuthAccount(address string, now time.Time, de
 		for _, p := range v.VestingPeriods {
 			var durSec int64
 			_, _ = fmt.Sscan(p.Length, &durSec)
+			if durSec < 0 || durSec > maxUnix || start.Unix()+int64(elapsed/time.Second)+durSec > maxUnix {
+				return "", "", nil, fmt.Errorf("vesting period length %q out of range", p.Length)
+			}
 			elapsed += time.Second * time.Duration(durSec)
 			amount := "0"
 			for _, a := range p.Amount {
@@ -327,6 +832,9 @@ func (c *Computer) lockedAndEndFromAuthAccount(address string, now time.Time, de
 					break
 				}
 			}
+			if !isAmount(amount) {
+				return "", "", nil, fmt.Errorf("vesting period amount %q is not an amount", amount)
+			}
 			periods = append(periods, vesting.Period{End: start.Add(elapsed), Amount: amount})
 		}
 		locked := ve.PeriodicLocked(periods, now)
@@ -334,9 +842,18 @@ func (c *Computer) lockedAndEndFromAuthAccount(address string, now time.Time, de
 		if len(periods) > 0 {
 			endStr = periods[len(periods)-1].End.UTC().Format(time.RFC3339)
 		}
-		return locked, endStr, typ, nil
+		var sched *types.LockSchedule
+		if locked != "0" {
+			sched = &types.LockSchedule{Kind: SchedulePeriodic}
+			for _, p := range periods {
+				if now.Before(p.End) && p.Amount != "0" {
+					sched.Periods = append(sched.Periods, types.LockPeriod{EndUnix: p.End.Unix(), Amount: p.Amount})
+				}
+			}
+		}
+		return locked, endStr, sched, nil
 	default:
 		// Unknown type: assume not vesting
-		return "0", "", typ, nil
+		return "0", "", nil, nil
 	}
 }
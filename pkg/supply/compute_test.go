@@ -0,0 +1,484 @@
+package supply
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/mockchain"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+	"github.com/lumera-labs/lumera-supply/pkg/vesting"
+)
+
+func TestSortBreakdown(t *testing.T) {
+	b := types.NonCircBreakdown{Cohorts: []types.CohortEntry{
+		{Name: "ibc_escrow"},
+		{Name: "claim_delayed", Items: []types.AddressItem{
+			{Address: "lumera1b", EndDate: "2026-01-01T00:00:00Z"},
+			{Address: "lumera1a", EndDate: "2026-06-01T00:00:00Z"},
+			{Address: "lumera1a", EndDate: "2026-03-01T00:00:00Z"},
+		}},
+		{Name: "community_pool"},
+	}}
+	sortBreakdown(&b)
+
+	names := []string{b.Cohorts[0].Name, b.Cohorts[1].Name, b.Cohorts[2].Name}
+	if names[0] != "claim_delayed" || names[1] != "community_pool" || names[2] != "ibc_escrow" {
+		t.Fatalf("unexpected cohort order: %v", names)
+	}
+	items := b.Cohorts[0].Items
+	if items[0].Address != "lumera1a" || items[0].EndDate != "2026-03-01T00:00:00Z" || items[2].Address != "lumera1b" {
+		t.Fatalf("unexpected item order: %+v", items)
+	}
+}
+
+func TestDecodeVestingSchedule(t *testing.T) {
+	now := time.Unix(1750000000, 0).UTC()
+	ve := vesting.NewEngine()
+	_, _, sched, err := decodeVestingAccount([]byte(`{"base_vesting_account":{"original_vesting":[{"denom":"ulume","amount":"10"}],"end_time":"1800000000"},"start_time":"1700000000"}`),
+		"/cosmos.vesting.v1beta1.ContinuousVestingAccount", "ulume", now, ve)
+	if err != nil || sched == nil || sched.Kind != ScheduleContinuous || sched.OriginalVesting != "10" || sched.StartUnix != 1700000000 || sched.EndUnix != 1800000000 {
+		t.Fatalf("continuous schedule %+v, %v", sched, err)
+	}
+	// periods already vested are left out
+	_, _, sched, err = decodeVestingAccount([]byte(`{"base_vesting_account":{"original_vesting":[{"denom":"ulume","amount":"30"}]},"start_time":"1700000000","vesting_periods":[`+
+		`{"length":"1000","amount":[{"denom":"ulume","amount":"10"}]},{"length":"100000000","amount":[{"denom":"ulume","amount":"20"}]}]}`),
+		"/cosmos.vesting.v1beta1.PeriodicVestingAccount", "ulume", now, ve)
+	if err != nil || sched == nil || sched.Kind != SchedulePeriodic || len(sched.Periods) != 1 || sched.Periods[0].EndUnix != 1700000000+1000+100000000 || sched.Periods[0].Amount != "20" {
+		t.Fatalf("periodic schedule %+v, %v", sched, err)
+	}
+}
+
+func TestETagCoversPolicy(t *testing.T) {
+	a := computeETag(100, "ulume", "1000", "900", "100", "policy-a")
+	if a != computeETag(100, "ulume", "1000", "900", "100", "policy-a") {
+		t.Fatal("etag not deterministic")
+	}
+	if a == computeETag(100, "ulume", "1000", "900", "100", "policy-b") {
+		t.Fatal("policy change with equal sums must change the etag")
+	}
+}
+
+func TestGovDeposits(t *testing.T) {
+	const govAddr = "lumera10d07y265gmmuvt4z0w9aw880jnsr700j6cqwzj"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1000"}}`))
+		case "/cosmos/auth/v1beta1/module_accounts/gov":
+			_, _ = w.Write([]byte(`{"account":{"base_account":{"address":"` + govAddr + `"}}}`))
+		case "/cosmos/bank/v1beta1/balances/" + govAddr + "/by_denom":
+			_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"40"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	client := lcd.NewClient(ts.URL, ts.Client())
+
+	snap, err := NewComputer(client, &policy.Policy{GovDeposits: true}).ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// latest block, supply, staking params, escrow (+ channel fallback), community pool,
+	// gov address, gov balance, 4 claim tiers under both default claim prefixes
+	if cs := snap.ComputeStats; cs == nil || cs.LCDCalls != 16 {
+		t.Fatalf("unexpected compute stats: %+v", cs)
+	}
+	if len(snap.NonCirculating.Cohorts) != 1 || snap.NonCirculating.Cohorts[0].Name != "gov_deposits" ||
+		snap.NonCirculating.Cohorts[0].Address != govAddr || snap.Circulating != "960" {
+		t.Fatalf("unexpected snapshot: circ=%s cohorts=%+v", snap.Circulating, snap.NonCirculating.Cohorts)
+	}
+
+	// gov already listed as a module account: counted once, as module:gov
+	snap, err = NewComputer(client, &policy.Policy{GovDeposits: true, ModuleAccounts: []string{"gov"}}).ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.NonCirculating.Cohorts) != 1 || snap.NonCirculating.Cohorts[0].Name != "module:gov" || snap.Circulating != "960" {
+		t.Fatalf("unexpected snapshot: circ=%s cohorts=%+v", snap.Circulating, snap.NonCirculating.Cohorts)
+	}
+}
+
+func TestStakingBonded(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"` + r.URL.Query().Get("denom") + `","amount":"1000"}}`))
+		case "/cosmos/staking/v1beta1/params":
+			_, _ = w.Write([]byte(`{"params":{"bond_denom":"ulume"}}`))
+		case "/cosmos/staking/v1beta1/pool":
+			_, _ = w.Write([]byte(`{"pool":{"bonded_tokens":"600","not_bonded_tokens":"25"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	client := lcd.NewClient(ts.URL, ts.Client())
+
+	snap, err := NewComputer(client, &policy.Policy{StakingBonded: true}).ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Staked == nil || *snap.Staked != (types.StakedSupply{Bonded: "600", NotBonded: "25"}) {
+		t.Fatalf("staked: %+v", snap.Staked)
+	}
+	if len(snap.NonCirculating.Cohorts) != 1 || snap.NonCirculating.Cohorts[0].Name != "staking_bonded" || snap.Circulating != "400" {
+		t.Fatalf("unexpected snapshot: circ=%s cohorts=%+v", snap.Circulating, snap.NonCirculating.Cohorts)
+	}
+
+	// the pool is only reported for the bond denom, and bonded tokens only count when
+	// the policy says so
+	snap, err = NewComputer(client, &policy.Policy{StakingBonded: true}).ComputeSnapshot(context.Background(), "uother")
+	if err != nil || snap.Staked != nil || len(snap.NonCirculating.Cohorts) != 0 {
+		t.Fatalf("other denom: %+v %v", snap, err)
+	}
+	snap, err = NewComputer(client, &policy.Policy{}).ComputeSnapshot(context.Background(), "ulume")
+	if err != nil || snap.Staked == nil || snap.Circulating != "1000" {
+		t.Fatalf("without the toggle: %+v %v", snap, err)
+	}
+}
+
+func TestComputeSnapshotAt(t *testing.T) {
+	const addr = "lumera1foundationxxxxxxxxxxxxxxxxxxxxxxxxx"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"10000"}}`))
+		case "/cosmos/auth/v1beta1/accounts/" + addr:
+			// delayed vesting of 1000 ending 2030-01-01
+			_, _ = w.Write([]byte(`{"account":{"@type":"/cosmos.vesting.v1beta1.DelayedVestingAccount",
+				"base_vesting_account":{"original_vesting":[{"denom":"ulume","amount":"1000"}],"end_time":"1893456000"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	pol := &policy.Policy{Disclosed: policy.DisclosedLockups{FoundationGenesis: []policy.FoundationEntry{{Name: "seed", Address: addr}}}}
+	c := NewComputer(lcd.NewClient(ts.URL, ts.Client()), pol)
+
+	snap, err := c.ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snap.EvaluatedAt.Equal(snap.UpdatedAt) || snap.Circulating != "9000" {
+		t.Fatalf("expected evaluation at block time with 1000 locked: evaluated_at=%s circ=%s", snap.EvaluatedAt, snap.Circulating)
+	}
+	at := time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)
+	whatIf, err := c.ComputeSnapshotAt(context.Background(), "ulume", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !whatIf.EvaluatedAt.Equal(at) || whatIf.Height != 100 || whatIf.Circulating != "10000" {
+		t.Fatalf("expected the lock released at %s: evaluated_at=%s circ=%s", at, whatIf.EvaluatedAt, whatIf.Circulating)
+	}
+}
+
+func TestCohortSourcePanicSkipsCohort(t *testing.T) {
+	const addr = "lumera1bootstrapxxxxxxxxxxxxxxxxxxxxxxxxxx"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1000"}}`))
+		case "/cosmos/bank/v1beta1/balances/" + addr + "/by_denom":
+			// not a number: summing it panics
+			_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"12x"}}`))
+		case "/cosmos/distribution/v1beta1/community_pool":
+			_, _ = w.Write([]byte(`{"pool":[{"denom":"ulume","amount":"100.000000000000000000"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	pol := &policy.Policy{Disclosed: policy.DisclosedLockups{SupernodeBootstraps: []policy.SupernodeEntry{{Address: addr, Permanent: true}}}}
+	before := cohortErrors.Value("supernode_bootstraps", "panic")
+
+	snap, err := NewComputer(lcd.NewClient(ts.URL, ts.Client()), pol).ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.NonCirculating.Cohorts) != 1 || snap.NonCirculating.Cohorts[0].Name != "community_pool" || snap.Circulating != "900" {
+		t.Fatalf("expected only the community pool: circ=%s cohorts=%+v", snap.Circulating, snap.NonCirculating.Cohorts)
+	}
+	if got := cohortErrors.Value("supernode_bootstraps", "panic"); got != before+1 {
+		t.Fatalf("cohort_errors_total{kind=panic} = %v, want %v", got, before+1)
+	}
+}
+
+func TestComputeSnapshotAtHeight(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-06-01T00:00:00Z"}}}`))
+		case "/cosmos/base/tendermint/v1beta1/blocks/42":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"42","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			// the supply grew after height 42
+			amt := "1000"
+			if r.Header.Get("x-cosmos-block-height") == "42" {
+				amt = "800"
+			}
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"` + amt + `"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	snap, err := NewComputer(lcd.NewClient(ts.URL, ts.Client()), &policy.Policy{}).ComputeSnapshotAtHeight(context.Background(), "ulume", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Height != 42 || !snap.UpdatedAt.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) || snap.Total != "800" || snap.Circulating != "800" {
+		t.Fatalf("unexpected snapshot: height=%d time=%s total=%s circ=%s", snap.Height, snap.UpdatedAt, snap.Total, snap.Circulating)
+	}
+}
+
+func TestComputeSnapshotCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1000"}}`))
+		default:
+			// shutting down while the cohorts are fetched
+			cancel()
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	_, err := NewComputer(lcd.NewClient(ts.URL, ts.Client()), &policy.Policy{GovDeposits: true}).ComputeSnapshot(ctx, "ulume")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("a snapshot missing cancelled cohorts must be abandoned, got %v", err)
+	}
+}
+
+func TestCohortSource(t *testing.T) {
+	for name, want := range map[string]string{
+		"ibc_escrow":           SourceIBCEscrow,
+		"community_pool":       SourceCommunityPool,
+		"module:claim":         SourceModuleAccount,
+		"gov_deposits":         SourceModuleAccount,
+		"foundation_genesis":   SourceDisclosedLockups,
+		"supernode_bootstraps": SourceDisclosedLockups,
+		"claim_delayed":        SourceClaimRecords,
+		"unknown":              "",
+	} {
+		if got := CohortSource(name); got != want {
+			t.Errorf("CohortSource(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// FuzzDecodeVestingAccount feeds auth account JSON to the vesting decoder; the seeds in
+// testdata/fuzz are accounts of each type as the chain serves them.
+func FuzzDecodeVestingAccount(f *testing.F) {
+	f.Add([]byte(`{"base_vesting_account":{"original_vesting":[{"denom":"ulume","amount":"10"}],"end_time":"1800000000"},"start_time":"1700000000"}`), "/cosmos.vesting.v1beta1.ContinuousVestingAccount", "ulume")
+	f.Add([]byte(`{"base_vesting_account":{"original_vesting":[{"denom":"ulume","amount":"10"}]},"vesting_periods":[{"length":"x","amount":[{"denom":"ulume","amount":"y"}]}]}`), "/cosmos.vesting.v1beta1.PeriodicVestingAccount", "ulume")
+	f.Add([]byte(`{"base_vesting_account":{"original_vesting":[{"denom":"ulume","amount":"1e6"}],"end_time":"1800000000"},"start_time":"1700000000"}`), "/cosmos.vesting.v1beta1.ContinuousVestingAccount", "ulume")
+	f.Add([]byte(`{"base_vesting_account":{"original_vesting":[{"denom":"ulume","amount":"10"}],"end_time":"99999999999999"}}`), "/cosmos.vesting.v1beta1.DelayedVestingAccount", "ulume")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ve := vesting.NewEngineWithClock(vesting.FixedClock(now))
+	f.Fuzz(func(t *testing.T, raw []byte, typ, denom string) {
+		locked, end, _, err := decodeVestingAccount(raw, typ, denom, now, ve)
+		if err != nil {
+			return
+		}
+		n, ok := new(big.Int).SetString(locked, 10)
+		if !ok || n.Sign() < 0 {
+			t.Fatalf("locked %q is not a non-negative amount", locked)
+		}
+		if end != "" && end != "forever" {
+			if _, err := time.Parse(time.RFC3339, end); err != nil {
+				t.Fatalf("end %q: %v", end, err)
+			}
+		}
+	})
+}
+
+func TestParallelLookups(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	st := mockchain.Default(now, 60)
+	pol := &policy.Policy{}
+	for i := 0; i < 30; i++ {
+		a := mockchain.Account{Type: mockchain.TypeDelayed, Address: mockchain.Address("lumera", fmt.Sprintf("fg/%d", i)), Balance: fmt.Sprint(1000 + i)}
+		end := now.AddDate(0, i%3, 1)
+		a.EndTime = &end
+		st.Accounts = append(st.Accounts, a)
+		pol.Disclosed.FoundationGenesis = append(pol.Disclosed.FoundationGenesis, policy.FoundationEntry{Name: a.Address, Address: a.Address})
+	}
+	mock, err := mockchain.New(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.Freeze()
+	var inFlight, peak atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/cosmos/auth/v1beta1/accounts/") {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(time.Millisecond)
+		}
+		mock.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	compute := func(n int) *types.SupplySnapshot {
+		t.Helper()
+		comp := NewComputer(lcd.NewClient(ts.URL, ts.Client()), pol)
+		comp.SetConcurrency(n)
+		peak.Store(0)
+		snap, err := comp.ComputeSnapshot(context.Background(), "ulume")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := peak.Load(); p > int64(n) {
+			t.Fatalf("concurrency %d: %d lookups in flight", n, p)
+		}
+		return snap
+	}
+	seq, par := compute(1), compute(6)
+	if peak.Load() < 2 {
+		t.Fatalf("lookups did not overlap")
+	}
+	if seq.ETag != par.ETag {
+		t.Fatalf("etag %s sequential, %s parallel", seq.ETag, par.ETag)
+	}
+	a, _ := json.Marshal(seq.NonCirculating)
+	b, _ := json.Marshal(par.NonCirculating)
+	if string(a) != string(b) {
+		t.Fatalf("breakdowns differ:\n%s\n%s", a, b)
+	}
+}
+
+func TestEachReraisesPanics(t *testing.T) {
+	c := &Computer{concurrency: 4}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("panic in a lookup was swallowed")
+		}
+	}()
+	c.each(10, func(i int) {
+		if i == 7 {
+			panic("malformed")
+		}
+	})
+}
+
+func TestClaimTiers(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	st := mockchain.Default(now, 12)
+	st.Time = &now
+	mock, err := mockchain.New(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(mock)
+	defer ts.Close()
+
+	snap, err := NewComputer(lcd.NewClient(ts.URL, ts.Client()), &policy.Policy{}).ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, co := range snap.NonCirculating.Cohorts {
+		if co.Name != "claim_delayed" {
+			if co.Tiers != nil {
+				t.Fatalf("cohort %s has tiers %+v", co.Name, co.Tiers)
+			}
+			continue
+		}
+		byTier := map[int]*big.Int{}
+		for _, it := range co.Items {
+			if it.Tier < 1 || it.Tier > 4 {
+				t.Fatalf("item %s has tier %d", it.Address, it.Tier)
+			}
+			if byTier[it.Tier] == nil {
+				byTier[it.Tier] = new(big.Int)
+			}
+			v, _ := new(big.Int).SetString(it.Amount, 10)
+			byTier[it.Tier].Add(byTier[it.Tier], v)
+		}
+		if len(co.Tiers) != 4 {
+			t.Fatalf("expected the four tiers, got %+v", co.Tiers)
+		}
+		sum, count := new(big.Int), 0
+		for i, tt := range co.Tiers {
+			if tt.Tier != i+1 || tt.Amount != byTier[tt.Tier].String() {
+				t.Fatalf("tier %d: %+v, items sum to %s", i+1, tt, byTier[i+1])
+			}
+			v, _ := new(big.Int).SetString(tt.Amount, 10)
+			sum.Add(sum, v)
+			count += tt.ItemCount
+		}
+		if sum.String() != co.Amount || count != co.ItemCount {
+			t.Fatalf("tiers sum to %s over %d items, cohort %s over %d", sum, count, co.Amount, co.ItemCount)
+		}
+		return
+	}
+	t.Fatal("no claim_delayed cohort")
+}
+
+func TestAddressGroupsOnItems(t *testing.T) {
+	const addr = "lumera1foundationxxxxxxxxxxxxxxxxxxxxxxxxx"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"10000"}}`))
+		case "/cosmos/auth/v1beta1/accounts/" + addr:
+			_, _ = w.Write([]byte(`{"account":{"@type":"/cosmos.vesting.v1beta1.DelayedVestingAccount",
+				"base_vesting_account":{"original_vesting":[{"denom":"ulume","amount":"1000"}],"end_time":"1893456000"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	pol := &policy.Policy{
+		AddressGroups: map[string]policy.AddressGroup{
+			"treasury": {Custody: "multisig", Links: []string{"https://lumera.io/disclosures/treasury"}},
+			"unused":   {Notes: "referenced by no entry"},
+		},
+		Disclosed: policy.DisclosedLockups{FoundationGenesis: []policy.FoundationEntry{{Name: "seed", Address: addr, Group: "treasury"}}},
+		Cohorts:   map[string]policy.CohortMeta{"foundation_genesis": {DisclosureURL: "https://lumera.io/disclosures/genesis", Notes: "vesting"}},
+	}
+
+	snap, err := NewComputer(lcd.NewClient(ts.URL, ts.Client()), pol).ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	co := snap.NonCirculating.Cohorts[0]
+	if co.Name != "foundation_genesis" || len(co.Items) != 1 || co.Items[0].AddressGroup != "treasury" {
+		t.Fatalf("unexpected cohort %+v", co)
+	}
+	if g, ok := co.AddressGroups["treasury"]; !ok || len(co.AddressGroups) != 1 || g.Custody != "multisig" || len(g.Links) != 1 {
+		t.Fatalf("address groups %+v", co.AddressGroups)
+	}
+	if co.DisclosureURL != "https://lumera.io/disclosures/genesis" || co.Notes != "vesting" {
+		t.Fatalf("disclosure %q %q", co.DisclosureURL, co.Notes)
+	}
+}
@@ -0,0 +1,152 @@
+package supply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+var invariantViolations = metrics.Default.NewCounter(
+	"lumera_supply_invariant_violations_total",
+	"Computed snapshots breaking an invariant, published or not.",
+	"denom", "invariant",
+)
+
+// failureLog collects the cohort fetch failures of one computation. A nil log drops
+// them, for computations nobody inspects (what-if, historical, candidate).
+type failureLog struct {
+	mu       sync.Mutex
+	failures []types.CohortFailure
+}
+
+func (l *failureLog) add(f types.CohortFailure) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.failures = append(l.failures, f)
+	l.mu.Unlock()
+}
+
+// list returns the failures ordered by cohort, in the order recorded within a cohort.
+func (l *failureLog) list() []types.CohortFailure {
+	out := []types.CohortFailure{}
+	if l == nil {
+		return out
+	}
+	l.mu.Lock()
+	out = append(out, l.failures...)
+	l.mu.Unlock()
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Cohort < out[j].Cohort })
+	return out
+}
+
+// diagnosticsLog keeps, per denom, the diagnostics of the latest computation and of the
+// latest one that broke an invariant.
+type diagnosticsLog struct {
+	mu            sync.Mutex
+	latest        map[string]*types.Diagnostics
+	lastViolation map[string]*types.Diagnostics
+}
+
+func (l *diagnosticsLog) record(d *types.Diagnostics) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.latest == nil {
+		l.latest, l.lastViolation = map[string]*types.Diagnostics{}, map[string]*types.Diagnostics{}
+	}
+	l.latest[d.Denom] = d
+	if len(d.Invariants) > 0 {
+		l.lastViolation[d.Denom] = d
+	}
+}
+
+// Diagnostics returns the diagnostics of denom's latest snapshot computation, and of the
+// latest one since startup that broke an invariant, so a transient violation stays
+// visible after the figures recover. Either is nil when there is none.
+func (c *Computer) Diagnostics(denom string) (latest, lastViolation *types.Diagnostics) {
+	c.diags.mu.Lock()
+	defer c.diags.mu.Unlock()
+	return c.diags.latest[denom], c.diags.lastViolation[denom]
+}
+
+// recordDiagnostics records the outcome of a ComputeSnapshot of denom: snap is the
+// computed snapshot (nil when computing failed first), err what kept it from being
+// published. Abandoned computations are not recorded.
+func (c *Computer) recordDiagnostics(ctx context.Context, denom string, snap *types.SupplySnapshot, failures *failureLog, err error) {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	d := &types.Diagnostics{
+		Denom:          denom,
+		ComputedAt:     c.now().UTC(),
+		Published:      err == nil,
+		Invariants:     []types.InvariantViolation{},
+		CohortFailures: failures.list(),
+		Overlaps:       []types.CohortOverlap{},
+	}
+	if err != nil {
+		d.Error = err.Error()
+	}
+	if snap != nil {
+		d.Height, d.Circulating, d.NonCirculating = snap.Height, snap.Circulating, snap.NonCirculating.Sum
+		d.Invariants = checkInvariants(snap)
+		d.Overlaps = append(d.Overlaps, snap.Overlaps...)
+		var broken []string
+		for _, v := range d.Invariants {
+			invariantViolations.Inc(denom, v.Invariant)
+			// negative circulating supply is logged by checkCirculating
+			if v.Invariant != types.InvariantCirculatingNonNegative {
+				broken = append(broken, v.Message)
+			}
+		}
+		if len(broken) > 0 {
+			log.Printf("warn: %s at height %d breaks invariants: %s", denom, snap.Height, strings.Join(broken, "; "))
+		}
+	}
+	c.diags.record(d)
+}
+
+// checkInvariants lists the invariants snap breaks: circulating supply is not negative,
+// cohort amounts are non-negative integers, and a cohort with items amounts to their sum.
+// Cohorts whose items were offloaded (see types.CohortEntry.ItemCount) are not summed.
+func checkInvariants(snap *types.SupplySnapshot) []types.InvariantViolation {
+	out := []types.InvariantViolation{}
+	if circ, ok := new(big.Int).SetString(snap.Circulating, 10); ok && circ.Sign() < 0 {
+		msg := fmt.Sprintf("non-circulating %s exceeds total supply %s", snap.NonCirculating.Sum, snap.Total)
+		if snap.Anomaly != nil {
+			msg = snap.Anomaly.Message
+		}
+		out = append(out, types.InvariantViolation{Invariant: types.InvariantCirculatingNonNegative, Message: msg})
+	}
+	for _, co := range snap.NonCirculating.Cohorts {
+		amount, ok := new(big.Int).SetString(co.Amount, 10)
+		if !ok || amount.Sign() < 0 {
+			out = append(out, types.InvariantViolation{Invariant: types.InvariantCohortAmount, Cohort: co.Name,
+				Message: fmt.Sprintf("cohort %s has amount %q", co.Name, co.Amount)})
+			continue
+		}
+		if len(co.Items) == 0 || len(co.Items) != co.ItemCount {
+			continue
+		}
+		sum := new(big.Int)
+		for _, it := range co.Items {
+			if v, ok := new(big.Int).SetString(it.Amount, 10); ok {
+				sum.Add(sum, v)
+			}
+		}
+		if sum.Cmp(amount) != 0 {
+			out = append(out, types.InvariantViolation{Invariant: types.InvariantCohortItemsSum, Cohort: co.Name,
+				Message: fmt.Sprintf("cohort %s amounts to %s but its %d items sum to %s", co.Name, co.Amount, len(co.Items), sum)})
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package supply
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+func TestDiagnostics(t *testing.T) {
+	const (
+		locked  = "lumera1foundationxxxxxxxxxxxxxxxxxxxxxxxxx"
+		missing = "lumera1missingxxxxxxxxxxxxxxxxxxxxxxxxxxxx"
+	)
+	total := "500"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"` + total + `"}}`))
+		case "/cosmos/auth/v1beta1/accounts/" + locked:
+			_, _ = w.Write([]byte(`{"account":{"@type":"/cosmos.vesting.v1beta1.DelayedVestingAccount",
+				"base_vesting_account":{"original_vesting":[{"denom":"ulume","amount":"1000"}],"end_time":"1893456000"}}}`))
+		default:
+			// the community pool and the missing account
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	pol := &policy.Policy{Disclosed: policy.DisclosedLockups{FoundationGenesis: []policy.FoundationEntry{
+		{Name: "seed", Address: locked}, {Name: "gone", Address: missing},
+	}}}
+	c := NewComputer(lcd.NewClient(ts.URL, ts.Client()), pol)
+
+	if latest, last := c.Diagnostics("ulume"); latest != nil || last != nil {
+		t.Fatalf("diagnostics before any computation: %+v %+v", latest, last)
+	}
+	if _, err := c.ComputeSnapshot(context.Background(), "ulume"); !errors.Is(err, ErrNegativeCirculating) {
+		t.Fatalf("expected the negative snapshot rejected, got %v", err)
+	}
+	latest, last := c.Diagnostics("ulume")
+	if latest == nil || latest.Published || latest.Height != 100 || latest.Circulating != "-500" || latest != last {
+		t.Fatalf("unexpected diagnostics: latest=%+v last_violation=%+v", latest, last)
+	}
+	if len(latest.Invariants) != 1 || latest.Invariants[0].Invariant != types.InvariantCirculatingNonNegative {
+		t.Fatalf("invariants %+v", latest.Invariants)
+	}
+	failed := map[string]types.CohortFailure{}
+	for _, f := range latest.CohortFailures {
+		failed[f.Cohort] = f
+	}
+	if f := failed["foundation_genesis"]; f.Kind != "not_found" || f.Failed != 1 || f.Of != 2 {
+		t.Fatalf("foundation failure %+v in %+v", f, latest.CohortFailures)
+	}
+	if f := failed["community_pool"]; f.Kind != "not_found" || f.Failed != 1 {
+		t.Fatalf("community pool failure %+v in %+v", f, latest.CohortFailures)
+	}
+
+	// once the figures recover, the violation stays visible next to them
+	total = "5000"
+	if _, err := c.ComputeSnapshot(context.Background(), "ulume"); err != nil {
+		t.Fatal(err)
+	}
+	latest, last = c.Diagnostics("ulume")
+	if !latest.Published || len(latest.Invariants) != 0 || last == nil || last.Published {
+		t.Fatalf("unexpected diagnostics after recovery: latest=%+v last_violation=%+v", latest, last)
+	}
+}
+
+func TestCheckInvariants(t *testing.T) {
+	snap := &types.SupplySnapshot{Total: "100", Circulating: "40", NonCirculating: types.NonCircBreakdown{Sum: "60", Cohorts: []types.CohortEntry{
+		{Name: "a", Amount: "50", ItemCount: 2, Items: []types.AddressItem{{Amount: "20"}, {Amount: "20"}}},
+		{Name: "b", Amount: "x"},
+		{Name: "c", Amount: "10"},
+	}}}
+	got := checkInvariants(snap)
+	if len(got) != 2 || got[0].Invariant != types.InvariantCohortItemsSum || got[0].Cohort != "a" ||
+		got[1].Invariant != types.InvariantCohortAmount || got[1].Cohort != "b" {
+		t.Fatalf("violations %+v", got)
+	}
+}
@@ -0,0 +1,226 @@
+package supply
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// Diff compares two hydrated snapshots at cohort and item level. Items are matched by
+// address and end date; unchanged cohorts are omitted.
+func Diff(from, to *types.SupplySnapshot) types.SnapshotDiff {
+	d := types.SnapshotDiff{
+		Denom:          to.Denom,
+		From:           refOf(from),
+		To:             refOf(to),
+		Total:          delta(from.Total, to.Total),
+		Circulating:    delta(from.Circulating, to.Circulating),
+		NonCirculating: delta(from.NonCirculating.Sum, to.NonCirculating.Sum),
+		Cohorts:        []types.CohortDiff{},
+	}
+	// cohorts match by policy ID when both sides have one, so a renamed cohort diffs as
+	// changed rather than removed and added; otherwise by name
+	byName := map[string]int{}
+	byID := map[int]int{}
+	for i, c := range from.NonCirculating.Cohorts {
+		byName[c.Name] = i
+		if c.ID > 0 {
+			byID[c.ID] = i
+		}
+	}
+	seen := map[int]bool{}
+	for _, c := range to.NonCirculating.Cohorts {
+		i, ok := byID[c.ID]
+		if !ok {
+			// a name reused under another ID is a different cohort
+			i, ok = byName[c.Name]
+			ok = ok && (c.ID == 0 || from.NonCirculating.Cohorts[i].ID == 0)
+		}
+		if !ok || seen[i] {
+			d.Cohorts = append(d.Cohorts, types.CohortDiff{Name: c.Name, ID: c.ID, Slug: c.Slug, Status: "added", Amount: delta("0", c.Amount), Added: c.Items})
+			continue
+		}
+		seen[i] = true
+		cd := diffItems(from.NonCirculating.Cohorts[i], c)
+		if cd.Amount.Delta != "0" || len(cd.Added)+len(cd.Removed)+len(cd.Changed) > 0 {
+			d.Cohorts = append(d.Cohorts, cd)
+		}
+	}
+	for i, c := range from.NonCirculating.Cohorts {
+		if !seen[i] {
+			d.Cohorts = append(d.Cohorts, types.CohortDiff{Name: c.Name, ID: c.ID, Slug: c.Slug, Status: "removed", Amount: delta(c.Amount, "0"), Removed: c.Items})
+		}
+	}
+	sort.Slice(d.Cohorts, func(i, j int) bool { return d.Cohorts[i].Name < d.Cohorts[j].Name })
+	minted, burned := burnDelta(from, to)
+	d.Attribution = attribute(d, minted, burned)
+	return d
+}
+
+// burnDelta returns the minted and burned amounts between two snapshots when both carry
+// burn totals from the same tracking run, caught up to their heights; otherwise nils.
+func burnDelta(from, to *types.SupplySnapshot) (minted, burned *big.Int) {
+	a, b := from.Burns, to.Burns
+	if a == nil || b == nil || a.FromHeight != b.FromHeight || a.Height != from.Height || b.Height != to.Height {
+		return nil, nil
+	}
+	return new(big.Int).Sub(parseDelta(b.Minted), parseDelta(a.Minted)), new(big.Int).Sub(parseDelta(b.Burned), parseDelta(a.Burned))
+}
+
+// Attribution causes.
+const (
+	CauseVestingUnlock = "vesting_unlock"
+	CauseNewClaims     = "new_claims"
+	CauseNewLock       = "new_lock"
+	CauseModuleBalance = "module_balance"
+	CauseCommunityPool = "community_pool"
+	CauseIBCFlows      = "ibc_flows"
+	CauseMintBurn      = "mint_burn"
+	CauseMint          = "mint"
+	CauseBurn          = "burn"
+	CauseOther         = "other"
+)
+
+// attribute explains the circulating delta: a total supply change adds to circulating
+// as mint/burn (split into mint and burn when tracked amounts are given, with any
+// remainder left as mint_burn), and every non-circulating change subtracts from it. Per-address items
+// that shrink or disappear count as vesting unlocks and new or growing items as new
+// claims (claim cohorts) or new locks; single-amount cohorts map by kind.
+func attribute(d types.SnapshotDiff, minted, burned *big.Int) []types.Attribution {
+	sums := map[string]*big.Int{}
+	cohorts := map[string]map[string]bool{}
+	add := func(cause, cohort string, v *big.Int) {
+		if v.Sign() == 0 {
+			return
+		}
+		if sums[cause] == nil {
+			sums[cause] = new(big.Int)
+			cohorts[cause] = map[string]bool{}
+		}
+		sums[cause].Add(sums[cause], v)
+		if cohort != "" {
+			cohorts[cause][cohort] = true
+		}
+	}
+	totalDelta := parseDelta(d.Total.Delta)
+	if minted != nil {
+		add(CauseMint, "", minted)
+		add(CauseBurn, "", new(big.Int).Neg(burned))
+		totalDelta = new(big.Int).Add(new(big.Int).Sub(totalDelta, minted), burned)
+	}
+	add(CauseMintBurn, "", totalDelta)
+
+	for _, c := range d.Cohorts {
+		// effect on circulating is the negated non-circulating change
+		remaining := new(big.Int).Neg(parseDelta(c.Amount.Delta))
+		if len(c.Added)+len(c.Removed)+len(c.Changed) > 0 {
+			grow := CauseNewLock
+			if strings.Contains(c.Name, "claim") {
+				grow = CauseNewClaims
+			}
+			for _, it := range c.Removed {
+				v := parseDelta(it.Amount)
+				add(CauseVestingUnlock, c.Name, v)
+				remaining.Sub(remaining, v)
+			}
+			for _, it := range c.Added {
+				v := new(big.Int).Neg(parseDelta(it.Amount))
+				add(grow, c.Name, v)
+				remaining.Sub(remaining, v)
+			}
+			for _, it := range c.Changed {
+				v := new(big.Int).Neg(parseDelta(it.Delta))
+				if v.Sign() > 0 {
+					add(CauseVestingUnlock, c.Name, v)
+				} else {
+					add(grow, c.Name, v)
+				}
+				remaining.Sub(remaining, v)
+			}
+		}
+		// cohort-level movement not explained by items
+		add(cohortCause(c.Name), c.Name, remaining)
+	}
+
+	out := []types.Attribution{}
+	for cause, v := range sums {
+		if v.Sign() == 0 {
+			continue
+		}
+		a := types.Attribution{Cause: cause, Amount: v.String()}
+		for name := range cohorts[cause] {
+			a.Cohorts = append(a.Cohorts, name)
+		}
+		sort.Strings(a.Cohorts)
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Cause < out[j].Cause })
+	return out
+}
+
+func cohortCause(name string) string {
+	switch {
+	case strings.HasPrefix(name, "module:"), name == "gov_deposits":
+		return CauseModuleBalance
+	case name == "community_pool":
+		return CauseCommunityPool
+	case name == "ibc_escrow":
+		return CauseIBCFlows
+	default:
+		return CauseOther
+	}
+}
+
+func parseDelta(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return v
+}
+
+func diffItems(prev, cur types.CohortEntry) types.CohortDiff {
+	cd := types.CohortDiff{Name: cur.Name, ID: cur.ID, Slug: cur.Slug, Status: "changed", Amount: delta(prev.Amount, cur.Amount)}
+	type key struct{ addr, end string }
+	old := make(map[key]types.AddressItem, len(prev.Items))
+	for _, it := range prev.Items {
+		old[key{it.Address, it.EndDate}] = it
+	}
+	for _, it := range cur.Items {
+		k := key{it.Address, it.EndDate}
+		p, ok := old[k]
+		if !ok {
+			cd.Added = append(cd.Added, it)
+			continue
+		}
+		delete(old, k)
+		if p.Amount != it.Amount {
+			cd.Changed = append(cd.Changed, types.ItemDelta{Address: it.Address, EndDate: it.EndDate, AmountDelta: delta(p.Amount, it.Amount)})
+		}
+	}
+	for _, it := range prev.Items {
+		if _, ok := old[key{it.Address, it.EndDate}]; ok {
+			cd.Removed = append(cd.Removed, it)
+		}
+	}
+	return cd
+}
+
+func refOf(s *types.SupplySnapshot) types.SnapshotRef {
+	return types.SnapshotRef{ETag: s.ETag, PolicyETag: s.PolicyETag, Height: s.Height, UpdatedAt: s.UpdatedAt}
+}
+
+// delta returns to - from; unparsable amounts count as zero.
+func delta(from, to string) types.AmountDelta {
+	a, ok := new(big.Int).SetString(from, 10)
+	if !ok {
+		a = new(big.Int)
+	}
+	b, ok := new(big.Int).SetString(to, 10)
+	if !ok {
+		b = new(big.Int)
+	}
+	return types.AmountDelta{From: from, To: to, Delta: new(big.Int).Sub(b, a).String()}
+}
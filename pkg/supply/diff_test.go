@@ -0,0 +1,62 @@
+package supply
+
+import (
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+func TestDiff(t *testing.T) {
+	from := &types.SupplySnapshot{Total: "100", Circulating: "60", NonCirculating: types.NonCircBreakdown{Sum: "40", Cohorts: []types.CohortEntry{
+		{Name: "claim_delayed", Amount: "30", Items: []types.AddressItem{
+			{Address: "a", Amount: "10", EndDate: "2026-01-01T00:00:00Z"},
+			{Address: "b", Amount: "20", EndDate: "2026-01-01T00:00:00Z"},
+		}},
+		{Name: "community_pool", Amount: "10"},
+	}}}
+	to := &types.SupplySnapshot{Total: "110", Circulating: "75", NonCirculating: types.NonCircBreakdown{Sum: "35", Cohorts: []types.CohortEntry{
+		{Name: "claim_delayed", Amount: "25", Items: []types.AddressItem{
+			{Address: "b", Amount: "15", EndDate: "2026-01-01T00:00:00Z"},
+			{Address: "c", Amount: "10", EndDate: "2027-01-01T00:00:00Z"},
+		}},
+		{Name: "community_pool", Amount: "10"},
+	}}}
+	d := Diff(from, to)
+	if d.Circulating.Delta != "15" || d.Total.Delta != "10" || d.NonCirculating.Delta != "-5" {
+		t.Fatalf("unexpected totals: %+v", d)
+	}
+	if len(d.Cohorts) != 1 {
+		t.Fatalf("unchanged cohorts should be omitted: %+v", d.Cohorts)
+	}
+	c := d.Cohorts[0]
+	if c.Amount.Delta != "-5" || len(c.Added) != 1 || c.Added[0].Address != "c" ||
+		len(c.Removed) != 1 || c.Removed[0].Address != "a" || len(c.Changed) != 1 || c.Changed[0].Delta != "-5" {
+		t.Fatalf("unexpected cohort diff: %+v", c)
+	}
+	// +10 minted, +10 (a) +5 (b) vested, -10 new claim (c): sums to +15 circulating
+	got := map[string]string{}
+	for _, a := range d.Attribution {
+		got[a.Cause] = a.Amount
+	}
+	if got[CauseMintBurn] != "10" || got[CauseVestingUnlock] != "15" || got[CauseNewClaims] != "-10" || len(got) != 3 {
+		t.Fatalf("unexpected attribution: %+v", d.Attribution)
+	}
+}
+
+func TestDiffMatchesCohortIDs(t *testing.T) {
+	from := &types.SupplySnapshot{Total: "100", Circulating: "90", NonCirculating: types.NonCircBreakdown{Sum: "10", Cohorts: []types.CohortEntry{
+		{Name: "foundation", ID: 4, Slug: "foundation", Amount: "10"},
+	}}}
+	to := &types.SupplySnapshot{Total: "100", Circulating: "88", NonCirculating: types.NonCircBreakdown{Sum: "12", Cohorts: []types.CohortEntry{
+		{Name: "foundation_genesis", ID: 4, Slug: "foundation", Amount: "12"},
+	}}}
+	d := Diff(from, to)
+	if len(d.Cohorts) != 1 || d.Cohorts[0].Status != "changed" || d.Cohorts[0].Name != "foundation_genesis" || d.Cohorts[0].ID != 4 || d.Cohorts[0].Amount.Delta != "2" {
+		t.Fatalf("renamed cohort should diff as changed: %+v", d.Cohorts)
+	}
+	// same name, different id: a different cohort
+	to.NonCirculating.Cohorts[0].Name, to.NonCirculating.Cohorts[0].ID = "foundation", 5
+	if d := Diff(from, to); len(d.Cohorts) != 2 {
+		t.Fatalf("expected removed and added: %+v", d.Cohorts)
+	}
+}
@@ -0,0 +1,64 @@
+package supply
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// displayRetry is how long a denom the chain has no metadata for waits before it is
+// looked up again.
+const displayRetry = 10 * time.Minute
+
+// maxDisplayDenoms bounds the denoms whose metadata is remembered; the oldest lookup
+// makes room for a new denom.
+const maxDisplayDenoms = 16
+
+// displayMemo remembers the bank metadata lookups of displayUnit per denom.
+type displayMemo struct {
+	mu      sync.Mutex
+	entries map[string]displayEntry
+}
+
+type displayEntry struct {
+	unit *types.DisplayUnit // nil when the chain has no metadata
+	at   time.Time
+}
+
+// displayUnit returns the display unit of denom from the chain's bank metadata through
+// l, nil when the chain has none. Metadata is looked up once per denom, and every
+// displayRetry while missing; concurrent computations wait for the same lookup.
+func (c *Computer) displayUnit(l *lcd.Client, denom string) *types.DisplayUnit {
+	if c.display == nil {
+		return nil
+	}
+	c.display.mu.Lock()
+	defer c.display.mu.Unlock()
+	e, found := c.display.entries[denom]
+	if found && (e.unit != nil || c.now().Sub(e.at) < displayRetry) {
+		return e.unit
+	}
+	e = displayEntry{at: c.now()}
+	if m, err := l.DenomMetadata(denom); err == nil && m.Display != "" {
+		e.unit = &types.DisplayUnit{Denom: m.Display, Decimals: m.Decimals}
+	} else if !found {
+		log.Printf("warn: denom metadata for %s: %v; amounts are shown with the default unit", denom, err)
+	}
+	if c.display.entries == nil {
+		c.display.entries = make(map[string]displayEntry)
+	}
+	if !found && len(c.display.entries) >= maxDisplayDenoms {
+		oldest := ""
+		for d, o := range c.display.entries {
+			if oldest == "" || o.at.Before(c.display.entries[oldest].at) {
+				oldest = d
+			}
+		}
+		delete(c.display.entries, oldest)
+	}
+	c.display.entries[denom] = e
+	return e.unit
+}
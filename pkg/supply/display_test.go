@@ -0,0 +1,64 @@
+package supply
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+)
+
+func TestDisplayUnit(t *testing.T) {
+	var lookups atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case r.URL.Path == "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1000"}}`))
+		case r.URL.Path == "/cosmos/bank/v1beta1/denoms_metadata/ulume":
+			lookups.Add(1)
+			_, _ = w.Write([]byte(`{"metadata":{"base":"ulume","display":"lume","denom_units":[{"denom":"ulume","exponent":0},{"denom":"lume","exponent":8}]}}`))
+		case strings.HasPrefix(r.URL.Path, "/cosmos/bank/v1beta1/denoms_metadata/"):
+			lookups.Add(1)
+			http.NotFound(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	l := lcd.NewClient(ts.URL, ts.Client())
+	c := NewComputer(l, nil)
+
+	for i := 0; i < 2; i++ {
+		snap, err := c.ComputeSnapshot(context.Background(), "ulume")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snap.Display == nil || snap.Display.Denom != "lume" || snap.Display.Decimals != 8 {
+			t.Fatalf("display %+v", snap.Display)
+		}
+	}
+	if n := lookups.Load(); n != 1 {
+		t.Fatalf("%d metadata lookups for two snapshots, want 1", n)
+	}
+
+	// denoms without metadata are retried later, and the memo stays bounded
+	for i := 0; i < 2*maxDisplayDenoms; i++ {
+		if u := c.displayUnit(l, fmt.Sprintf("unone%d", i)); u != nil {
+			t.Fatalf("display unit %+v for a denom without metadata", u)
+		}
+	}
+	if n := len(c.display.entries); n != maxDisplayDenoms {
+		t.Fatalf("%d remembered denoms, want %d", n, maxDisplayDenoms)
+	}
+	before := lookups.Load()
+	c.displayUnit(l, fmt.Sprintf("unone%d", 2*maxDisplayDenoms-1))
+	if lookups.Load() != before {
+		t.Fatal("a missing denom was looked up again before displayRetry")
+	}
+}
@@ -0,0 +1,157 @@
+package supply
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// Item sources: where an item's locked amount comes from (types.AddressItem.Source).
+const (
+	ItemSourceAuthVesting     = "auth_vesting"
+	ItemSourcePolicyPermanent = "policy_permanent_fallback"
+	ItemSourcePolicyDelayed   = "policy_delayed_fallback"
+	ItemSourceClaimRecord     = "claim_record"
+	ItemSourceClaimBalance    = "balance_fallback"
+)
+
+// ExplainSnapshot recomputes denom's snapshot at height, as ComputeSnapshotAtHeight
+// does, with every cohort and item carrying an Explain trace: the source used, the raw
+// inputs read and the formula applied. Traces are bulky, so regular snapshots never
+// carry them; this is for operators asking why an address is counted as it is.
+func (c *Computer) ExplainSnapshot(ctx context.Context, denom string, height int64) (*types.SupplySnapshot, error) {
+	return c.computeAtHeight(ctx, denom, height, true)
+}
+
+// explainVestingAccount traces the lock decodeVestingAccount evaluates at now for the
+// account in acctRaw.
+func explainVestingAccount(acctRaw json.RawMessage, typ, denom string, now time.Time) *types.Explanation {
+	var v vestingAccountJSON
+	_ = json.Unmarshal(acctRaw, &v)
+	ex := &types.Explanation{Source: ItemSourceAuthVesting, Inputs: map[string]string{
+		"account_type": typ,
+		"evaluated_at": now.UTC().Format(time.RFC3339),
+	}}
+	ov := "0"
+	for _, c := range v.BaseVestingAccount.OriginalVesting {
+		if c.Denom == denom {
+			ov = c.Amount
+			break
+		}
+	}
+	ex.Inputs["original_vesting"] = ov
+	if ov == "0" {
+		ex.Formula = "no original vesting in " + denom + ": nothing locked"
+		return ex
+	}
+	if t := explainTime(v.StartTime); t != "" {
+		ex.Inputs["start_time"] = t
+	}
+	if t := explainTime(v.BaseVestingAccount.EndTime); t != "" {
+		ex.Inputs["end_time"] = t
+	}
+	switch {
+	case strings.Contains(typ, "PermanentLockedAccount"):
+		ex.Formula = "locked = original_vesting (permanently locked)"
+	case strings.Contains(typ, "DelayedVestingAccount"):
+		ex.Formula = "locked = original_vesting while evaluated_at < end_time, then 0"
+	case strings.Contains(typ, "ContinuousVestingAccount"):
+		ex.Formula = "locked = original_vesting × (end_time − evaluated_at) / (end_time − start_time), all of it before start_time and 0 from end_time"
+	case strings.Contains(typ, "PeriodicVestingAccount") || strings.Contains(typ, "ClawbackVestingAccount"):
+		ex.Inputs["periods"] = strconv.Itoa(len(v.VestingPeriods))
+		ex.Formula = "locked = sum of the amounts of periods ending after evaluated_at; period i ends at start_time + length_1 + … + length_i"
+	default:
+		ex.Formula = "not a vesting account type: nothing locked"
+	}
+	return ex
+}
+
+// explainTime renders a vesting account time (Unix seconds) as RFC3339.
+func explainTime(s string) string {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || sec <= 0 || sec > maxUnix {
+		return ""
+	}
+	return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+}
+
+// explainPolicyLock traces a supernode bootstrap lock taken from the policy because the
+// chain has no schedule for the address: permanent when end is nil, delayed until end
+// otherwise.
+func (c *Computer) explainPolicyLock(balance, principal string, end *time.Time) *types.Explanation {
+	if !c.explain {
+		return nil
+	}
+	in := map[string]string{"balance": balance}
+	capped := "balance"
+	if principal != "" {
+		in["policy_amount"] = principal
+		capped = "min(balance, policy_amount)"
+	}
+	if end == nil {
+		return &types.Explanation{Source: ItemSourcePolicyPermanent, Inputs: in,
+			Formula: "no on-chain vesting schedule; the policy marks the lock permanent: locked = " + capped}
+	}
+	in["end_time"] = end.UTC().Format(time.RFC3339)
+	return &types.Explanation{Source: ItemSourcePolicyDelayed, Inputs: in,
+		Formula: "no on-chain vesting schedule; the policy gives a duration: locked = " + capped + " while evaluated_at < end_time (policy start_time + duration_months), then 0"}
+}
+
+// explainClaimRecord traces a claim_delayed item computed from its claim record:
+// amount (from the record, or the address's balance when the record has none) locked
+// from start until end.
+func (c *Computer) explainClaimRecord(r lcd.ClaimRecord, tier int, amount string, start, end time.Time) *types.Explanation {
+	if !c.explain {
+		return nil
+	}
+	ex := &types.Explanation{Source: ItemSourceClaimRecord, Inputs: map[string]string{
+		"tier":     strconv.Itoa(tier),
+		"amount":   amount,
+		"end_time": end.UTC().Format(time.RFC3339),
+	}}
+	if r.Amount == "" {
+		ex.Source = ItemSourceClaimBalance
+	}
+	if r.Time != nil {
+		ex.Inputs["claim_time"] = start.UTC().Format(time.RFC3339)
+	}
+	ex.Formula = "no on-chain vesting schedule; locked = amount while evaluated_at < end_time (claim_time + " + strconv.Itoa(tier*6) + " months for tier " + strconv.Itoa(tier) + "), then 0"
+	if r.Time == nil {
+		ex.Formula += "; without a claim time the record counts as claimed at evaluated_at"
+	}
+	if r.Amount == "" {
+		ex.Formula += "; the record has no amount, so amount is the address's balance"
+	}
+	return ex
+}
+
+// explainCohort traces how co's amount was computed, nil unless c.explain.
+func (c *Computer) explainCohort(co *types.CohortEntry, denom string, staked *types.StakedSupply) *types.Explanation {
+	if !c.explain {
+		return nil
+	}
+	ex := &types.Explanation{Source: CohortSource(co.Name), Inputs: map[string]string{"denom": denom}}
+	switch {
+	case co.Name == "ibc_escrow":
+		ex.Formula = "amount = ICS20 total escrow of denom, over all transfer channels"
+	case co.Name == "community_pool":
+		ex.Formula = "amount = community pool balance of denom, fraction truncated"
+	case strings.HasPrefix(co.Name, "module:"), co.Name == "gov_deposits":
+		ex.Inputs["address"] = co.Address
+		ex.Formula = "amount = bank balance of the module account at address"
+	case co.Name == "staking_bonded":
+		if staked != nil {
+			ex.Inputs["bonded_tokens"] = staked.Bonded
+		}
+		ex.Formula = "amount = bonded_tokens of the staking pool"
+	default:
+		ex.Inputs["items"] = strconv.Itoa(len(co.Items))
+		ex.Formula = "amount = sum of the items' locked amounts (see each item's explain)"
+	}
+	return ex
+}
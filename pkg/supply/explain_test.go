@@ -0,0 +1,77 @@
+package supply
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/mockchain"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+)
+
+func TestExplainSnapshot(t *testing.T) {
+	pol, err := policy.Load("../../policy.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	st := mockchain.Default(now, 12)
+	st.Time = &now
+	st.AddPolicy(pol, now)
+	mock, err := mockchain.New(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.Freeze()
+	ts := httptest.NewServer(mock)
+	defer ts.Close()
+	comp := NewComputer(lcd.NewClient(ts.URL, ts.Client()), pol)
+
+	snap, err := comp.ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Explained() {
+		t.Fatal("regular snapshot carries explain traces")
+	}
+	itemSources := map[string]string{}
+	for _, co := range snap.NonCirculating.Cohorts {
+		for _, it := range co.Items {
+			if it.Source == "" {
+				t.Fatalf("%s item %s: no source", co.Name, it.Address)
+			}
+			itemSources[co.Name+"/"+it.Address] = it.Source
+		}
+	}
+	ex, err := comp.ExplainSnapshot(context.Background(), "ulume", snap.Height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ex.ETag != snap.ETag || ex.Circulating != snap.Circulating {
+		t.Fatalf("explained figures differ: %s/%s, snapshot %s/%s", ex.ETag, ex.Circulating, snap.ETag, snap.Circulating)
+	}
+
+	sources := map[string]int{}
+	for _, co := range ex.NonCirculating.Cohorts {
+		if co.Explain == nil || co.Explain.Formula == "" || co.Explain.Source != CohortSource(co.Name) {
+			t.Fatalf("cohort %s: explain %+v", co.Name, co.Explain)
+		}
+		for _, it := range co.Items {
+			if it.Explain == nil || it.Explain.Formula == "" {
+				t.Fatalf("%s item %s: no explain", co.Name, it.Address)
+			}
+			sources[it.Explain.Source]++
+			if it.Source != it.Explain.Source || itemSources[co.Name+"/"+it.Address] != it.Source {
+				t.Fatalf("%s item %s: source %q, explained %q", co.Name, it.Address, it.Source, it.Explain.Source)
+			}
+			if it.Explain.Source == ItemSourceAuthVesting && it.Explain.Inputs["original_vesting"] == "" {
+				t.Fatalf("%s item %s: no original vesting in %+v", co.Name, it.Address, it.Explain.Inputs)
+			}
+		}
+	}
+	if sources[ItemSourceAuthVesting] == 0 {
+		t.Fatalf("item sources %v, want on-chain vesting", sources)
+	}
+}
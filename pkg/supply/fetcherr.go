@@ -0,0 +1,94 @@
+package supply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/recovery"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+var cohortErrors = metrics.Default.NewCounter(
+	"lumera_supply_cohort_errors_total",
+	"LCD/RPC failures while computing cohorts, by kind: not_found (the node does not serve the route or object), upstream (node down or overloaded), panic (a malformed answer crashed the source) or other.",
+	"cohort", "kind",
+)
+
+// errorKind classifies an LCD failure (see lcd.Error): a missing route or object is a
+// node capability, a retryable failure is an outage, anything else is a bad answer.
+func errorKind(err error) string {
+	switch {
+	case lcd.IsNotFound(err):
+		return "not_found"
+	case lcd.IsRetryable(err):
+		return "upstream"
+	default:
+		return "other"
+	}
+}
+
+// fetchFailed logs, counts and records (see Diagnostics) a failed fetch for cohort; what
+// describes the fetch. Cancelled fetches are not failures of the source: the snapshot
+// they belong to is abandoned.
+func (c *Computer) fetchFailed(cohort, what string, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	kind := errorKind(err)
+	cohortErrors.Inc(cohort, kind)
+	c.failures.add(types.CohortFailure{Cohort: cohort, Kind: kind, Fetch: what, Failed: 1, Error: err.Error()})
+	log.Printf("warn: %s failed (%s): %v", what, kind, err)
+}
+
+// fetchesFailed counts the failed per-address fetches of cohort, errs holding one entry
+// per address (nil where the fetch worked), and logs them in one line: how many failed,
+// by kind, and the first error. Diagnostics get one failure per kind.
+func (c *Computer) fetchesFailed(cohort, what string, errs []error) {
+	var first error
+	failed := 0
+	kinds := map[string]int{}
+	firstOf := map[string]error{}
+	for _, err := range errs {
+		if err == nil || errors.Is(err, context.Canceled) {
+			continue
+		}
+		kind := errorKind(err)
+		cohortErrors.Inc(cohort, kind)
+		if kinds[kind]++; firstOf[kind] == nil {
+			firstOf[kind] = err
+		}
+		if failed++; first == nil {
+			first = err
+		}
+	}
+	if failed == 0 {
+		return
+	}
+	names := make([]string, 0, len(kinds))
+	for kind := range kinds {
+		names = append(names, kind)
+	}
+	sort.Strings(names)
+	byKind := make([]string, len(names))
+	for i, kind := range names {
+		byKind[i] = fmt.Sprintf("%s=%d", kind, kinds[kind])
+		c.failures.add(types.CohortFailure{Cohort: cohort, Kind: kind, Fetch: what, Failed: kinds[kind], Of: len(errs), Error: firstOf[kind].Error()})
+	}
+	log.Printf("warn: %s failed for %d of %d addresses (%s); first: %v", what, failed, len(errs), strings.Join(byKind, " "), first)
+}
+
+// guardSource runs the source of one cohort. A panic in it (e.g. on a malformed upstream
+// payload) is recovered and counted as a failed fetch of kind panic, and the cohort is
+// skipped like any other failed fetch.
+func (c *Computer) guardSource(cohort string, fn func()) {
+	if err := recovery.Guard("cohort:"+cohort, func() error { fn(); return nil }); err != nil {
+		cohortErrors.Inc(cohort, "panic")
+		c.failures.add(types.CohortFailure{Cohort: cohort, Kind: "panic", Fetch: "cohort source", Failed: 1, Error: err.Error()})
+	}
+}
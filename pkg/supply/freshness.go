@@ -0,0 +1,75 @@
+package supply
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// cohortMemo keeps the last result of cohorts that have a policy refresh interval, so
+// snapshots taken before the interval elapses reuse it instead of refetching. Entries
+// are keyed by policy ETag, so a policy change recomputes everything.
+type cohortMemo struct {
+	mu      sync.Mutex
+	entries map[string]memoEntry
+}
+
+type memoEntry struct {
+	cohort types.CohortEntry
+	at     time.Time
+}
+
+func memoKey(policyETag, denom, name string) string {
+	return policyETag + "|" + denom + "|" + name
+}
+
+// reuse returns the remembered result for cohort name when its refresh interval has
+// not elapsed. The entry keeps the height and time it was computed at.
+func (c *Computer) reuse(denom, name string) (types.CohortEntry, bool) {
+	every := c.policy.RefreshInterval(name)
+	if c.memo == nil || every <= 0 {
+		return types.CohortEntry{}, false
+	}
+	c.memo.mu.Lock()
+	defer c.memo.mu.Unlock()
+	e, ok := c.memo.entries[memoKey(c.policy.ETag, denom, name)]
+	if !ok || c.now().Sub(e.at) >= every {
+		return types.CohortEntry{}, false
+	}
+	return cloneCohort(e.cohort), true
+}
+
+// remember records the cohorts computed at height that have a refresh interval,
+// replacing their earlier results. A still-valid result from the same height is what
+// reuse returned, and is kept so its interval is not extended while the chain stalls.
+func (c *Computer) remember(denom string, height int64, cohorts []types.CohortEntry) {
+	if c.memo == nil {
+		return
+	}
+	c.memo.mu.Lock()
+	defer c.memo.mu.Unlock()
+	if c.memo.entries == nil {
+		c.memo.entries = make(map[string]memoEntry)
+	}
+	now := c.now()
+	for _, co := range cohorts {
+		every := c.policy.RefreshInterval(co.Name)
+		if co.AsOfHeight != height || every <= 0 {
+			continue
+		}
+		k := memoKey(c.policy.ETag, denom, co.Name)
+		if e, ok := c.memo.entries[k]; ok && e.cohort.AsOfHeight == height && now.Sub(e.at) < every {
+			continue
+		}
+		c.memo.entries[k] = memoEntry{cohort: cloneCohort(co), at: now}
+	}
+}
+
+// cloneCohort copies co's slices: published snapshots get their items sorted and
+// offloaded (see cache.SnapshotCache), which must not reach the memo.
+func cloneCohort(co types.CohortEntry) types.CohortEntry {
+	co.Items = append([]types.AddressItem(nil), co.Items...)
+	co.Tags = append([]string(nil), co.Tags...)
+	return co
+}
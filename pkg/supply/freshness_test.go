@@ -0,0 +1,106 @@
+package supply
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+)
+
+func TestCohortRefreshInterval(t *testing.T) {
+	const govAddr = "lumera10d07y265gmmuvt4z0w9aw880jnsr700j6cqwzj"
+	var height, govReads atomic.Int64
+	height.Store(100)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			h := height.Add(1)
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"` + strconv.FormatInt(h, 10) + `","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1000"}}`))
+		case "/cosmos/auth/v1beta1/module_accounts/gov":
+			_, _ = w.Write([]byte(`{"account":{"base_account":{"address":"` + govAddr + `"}}}`))
+		case "/cosmos/bank/v1beta1/balances/" + govAddr + "/by_denom":
+			govReads.Add(1)
+			_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"40"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	p := &policy.Policy{GovDeposits: true, Cohorts: map[string]policy.CohortMeta{"gov_deposits": {RefreshInterval: "1h"}}}
+	c := NewComputer(lcd.NewClient(ts.URL, ts.Client()), p)
+
+	first, err := c.ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if govReads.Load() != 1 {
+		t.Fatalf("gov balance read %d times, want 1", govReads.Load())
+	}
+	co := second.NonCirculating.Cohorts[0]
+	if second.Height != 102 || co.Name != "gov_deposits" || co.Amount != "40" || co.AsOfHeight != first.Height {
+		t.Fatalf("expected the cohort from height %d to be reused at %d: %+v", first.Height, second.Height, co)
+	}
+
+	// a policy change recomputes
+	c.SetPolicy(&policy.Policy{GovDeposits: true, Cohorts: map[string]policy.CohortMeta{"gov_deposits": {RefreshInterval: "2h"}}})
+	third, err := c.ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if govReads.Load() != 2 || third.NonCirculating.Cohorts[0].AsOfHeight != third.Height {
+		t.Fatalf("expected a fresh cohort after the policy change: reads=%d %+v", govReads.Load(), third.NonCirculating.Cohorts[0])
+	}
+}
+
+// manualClock is a wall clock tests move by hand.
+type manualClock struct{ t time.Time }
+
+func (c *manualClock) Now() time.Time { return c.t }
+
+func TestCohortRefreshIntervalExpires(t *testing.T) {
+	const govAddr = "lumera10d07y265gmmuvt4z0w9aw880jnsr700j6cqwzj"
+	var govReads atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"1000"}}`))
+		case "/cosmos/auth/v1beta1/module_accounts/gov":
+			_, _ = w.Write([]byte(`{"account":{"base_account":{"address":"` + govAddr + `"}}}`))
+		case "/cosmos/bank/v1beta1/balances/" + govAddr + "/by_denom":
+			govReads.Add(1)
+			_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"40"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	c := NewComputer(lcd.NewClient(ts.URL, ts.Client()), &policy.Policy{GovDeposits: true, Cohorts: map[string]policy.CohortMeta{"gov_deposits": {RefreshInterval: "1h"}}})
+	clock := &manualClock{t: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c.SetClock(clock)
+	for _, step := range []struct {
+		advance time.Duration
+		reads   int64
+	}{{0, 1}, {59 * time.Minute, 1}, {time.Minute, 2}, {time.Minute, 2}} {
+		clock.t = clock.t.Add(step.advance)
+		if _, err := c.ComputeSnapshot(context.Background(), "ulume"); err != nil {
+			t.Fatal(err)
+		}
+		if govReads.Load() != step.reads {
+			t.Fatalf("after +%s: gov balance read %d times, want %d", step.advance, govReads.Load(), step.reads)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package supply
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+	"github.com/lumera-labs/lumera-supply/pkg/vesting"
+)
+
+// InspectAccount reads address's account and denom balances at height and evaluates its
+// vesting lock at at, as snapshots do (pass the snapshot's height and evaluation
+// instant). An address the chain has no account for comes back with an empty
+// AccountType and nothing held.
+func (c *Computer) InspectAccount(ctx context.Context, denom, address string, height int64, at time.Time) (*types.AccountInspection, error) {
+	l := c.lcd.WithContext(ctx).AtHeight(height)
+	out := &types.AccountInspection{Address: address, Balance: "0", Spendable: "0", OriginalVesting: "0", Locked: "0"}
+	raw, typ, err := l.AuthAccount(address)
+	if lcd.StatusOf(err) == http.StatusNotFound {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ve := vesting.NewEngineWithClock(vesting.FixedClock(at.UTC()))
+	locked, end, _, err := decodeVestingAccount(raw, typ, denom, at, ve)
+	if err != nil {
+		return nil, err
+	}
+	it := newAddressItem(address, locked, end, "")
+	out.AccountType, out.Locked, out.EndDate, out.EndUnix, out.Permanent = typ, it.Amount, it.EndDate, it.EndUnix, it.Permanent
+	out.Vesting = explainVestingAccount(raw, typ, denom, at)
+	out.OriginalVesting = out.Vesting.Inputs["original_vesting"]
+	if out.Balance, err = l.BalanceByDenom(address, denom); err != nil {
+		return nil, err
+	}
+	if out.Spendable, err = l.SpendableBalance(address, denom); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
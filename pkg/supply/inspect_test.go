@@ -0,0 +1,49 @@
+package supply
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/mockchain"
+)
+
+func TestInspectAccount(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	st := mockchain.Default(now, 4)
+	st.Time = &now
+	mock, err := mockchain.New(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.Freeze()
+	ts := httptest.NewServer(mock)
+	defer ts.Close()
+	comp := NewComputer(lcd.NewClient(ts.URL, ts.Client()), nil)
+	height, at := mock.Latest()
+
+	addr := mockchain.Address("lumera", "mock/delayed")
+	acct, err := comp.InspectAccount(context.Background(), "ulume", addr, height, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(acct.AccountType, "DelayedVestingAccount") || acct.Locked == "0" || acct.EndDate == "" || acct.Vesting == nil {
+		t.Fatalf("delayed account: %+v", acct)
+	}
+	if acct.OriginalVesting != acct.Locked || acct.Spendable == acct.Balance {
+		t.Fatalf("locked %s of %s, spendable %s of %s", acct.Locked, acct.OriginalVesting, acct.Spendable, acct.Balance)
+	}
+	// after the end date nothing is locked
+	later, err := comp.InspectAccount(context.Background(), "ulume", addr, height, at.AddDate(10, 0, 0))
+	if err != nil || later.Locked != "0" {
+		t.Fatalf("ten years on: %+v %v", later, err)
+	}
+
+	none, err := comp.InspectAccount(context.Background(), "ulume", mockchain.Address("lumera", "nobody"), height, at)
+	if err != nil || none.AccountType != "" || none.Balance != "0" || none.Vesting != nil {
+		t.Fatalf("unknown address: %+v %v", none, err)
+	}
+}
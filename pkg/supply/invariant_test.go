@@ -1,6 +1,7 @@
 package supply
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -75,7 +76,7 @@ func TestInvariantTotalEqualsCircPlusNonCirc(t *testing.T) {
 	pol := &policy.Policy{ModuleAccounts: []string{modAddr}, DisclosedLockups: []policy.Cohort{{Name: "foundation", Reason: "lockup", Addresses: []string{lockAddr}}}}
 	comp := NewComputer(client, pol)
 
-	snap, err := comp.ComputeSnapshot("ulume")
+	snap, err := comp.ComputeSnapshot(context.Background(), "ulume")
 	if err != nil {
 		t.Fatalf("compute snapshot error: %v", err)
 	}
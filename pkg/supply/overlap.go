@@ -0,0 +1,138 @@
+package supply
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+	"sort"
+
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+var cohortOverlaps = metrics.Default.NewGauge(
+	"lumera_supply_cohort_overlaps",
+	"Addresses counted by more than one cohort in the latest snapshot (before policy dedupe).",
+	"denom",
+)
+
+// ErrCohortOverlap is returned by ComputeSnapshot when the policy's overlap mode is
+// "error" and an address is counted by several cohorts.
+var ErrCohortOverlap = errors.New("address counted by several cohorts")
+
+// findOverlaps lists the addresses with a non-zero amount in more than one cohort,
+// from cohort items and single-address cohorts, ordered by address. Cohorts are listed
+// in the order given.
+func findOverlaps(cohorts []types.CohortEntry) []types.CohortOverlap {
+	seen := map[string][]string{}
+	add := func(addr, amount, cohort string) {
+		if addr == "" || amount == "" || amount == "0" {
+			return
+		}
+		if names := seen[addr]; len(names) == 0 || names[len(names)-1] != cohort {
+			seen[addr] = append(names, cohort)
+		}
+	}
+	for _, co := range cohorts {
+		add(co.Address, co.Amount, co.Name)
+		for _, it := range co.Items {
+			add(it.Address, it.Amount, co.Name)
+		}
+	}
+	var out []types.CohortOverlap
+	for addr, names := range seen {
+		if len(names) > 1 {
+			out = append(out, types.CohortOverlap{Address: addr, Cohorts: names})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return out
+}
+
+// resolveOverlaps finds the addresses several cohorts count (cohorts in computation
+// order) and, in dedupe mode, keeps each in its highest-precedence cohort only.
+// Single-address cohorts that lose their address are dropped.
+func (c *Computer) resolveOverlaps(cohorts []types.CohortEntry) ([]types.CohortEntry, []types.CohortOverlap) {
+	overlaps := findOverlaps(cohorts)
+	if len(overlaps) == 0 || c.policy.OverlapMode() != policy.OverlapDedupe {
+		return cohorts, overlaps
+	}
+	rank := map[string]int{}
+	for i, name := range c.policy.Overlaps.Precedence {
+		if _, ok := rank[name]; !ok {
+			rank[name] = i
+		}
+	}
+	for i, co := range cohorts {
+		if _, ok := rank[co.Name]; !ok {
+			rank[co.Name] = len(c.policy.Overlaps.Precedence) + i
+		}
+	}
+	drop := map[string]map[string]bool{} // cohort -> addresses it no longer counts
+	for i, o := range overlaps {
+		keep := slices.MinFunc(o.Cohorts, func(a, b string) int { return rank[a] - rank[b] })
+		overlaps[i].CountedIn = keep
+		for _, name := range o.Cohorts {
+			if name == keep {
+				continue
+			}
+			if drop[name] == nil {
+				drop[name] = map[string]bool{}
+			}
+			drop[name][o.Address] = true
+		}
+	}
+	out := make([]types.CohortEntry, 0, len(cohorts))
+	for _, co := range cohorts {
+		addrs := drop[co.Name]
+		if addrs == nil {
+			out = append(out, co)
+			continue
+		}
+		if co.Address != "" && addrs[co.Address] {
+			continue
+		}
+		amount, _ := new(big.Int).SetString(co.Amount, 10)
+		if amount == nil {
+			amount = new(big.Int)
+		}
+		items := make([]types.AddressItem, 0, len(co.Items))
+		for _, it := range co.Items {
+			if !addrs[it.Address] {
+				items = append(items, it)
+				continue
+			}
+			if v, ok := new(big.Int).SetString(it.Amount, 10); ok {
+				amount.Sub(amount, v)
+			}
+		}
+		co.Items, co.Amount = items, amount.String()
+		out = append(out, co)
+	}
+	return out, overlaps
+}
+
+// checkOverlaps logs and counts the snapshot's overlaps and, when the policy's overlap
+// mode is "error", rejects the snapshot.
+func (c *Computer) checkOverlaps(snap *types.SupplySnapshot) error {
+	cohortOverlaps.Set(float64(len(snap.Overlaps)), snap.Denom)
+	if len(snap.Overlaps) == 0 {
+		return nil
+	}
+	mode := c.policy.OverlapMode()
+	o := snap.Overlaps[0]
+	msg := fmt.Sprintf("%d address(es) counted by several cohorts, e.g. %s in %v", len(snap.Overlaps), o.Address, o.Cohorts)
+	if mode == policy.OverlapError {
+		return fmt.Errorf("%w: %s", ErrCohortOverlap, msg)
+	}
+	if mode == policy.OverlapDedupe {
+		// handled as configured
+		log.Printf("debug: %s at height %d: %s (deduplicated)", snap.Denom, snap.Height, msg)
+		return nil
+	}
+	log.Printf("warn: %s at height %d: %s", snap.Denom, snap.Height, msg)
+	return nil
+}
@@ -0,0 +1,56 @@
+package supply
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+func overlapCohorts() []types.CohortEntry {
+	return []types.CohortEntry{
+		{Name: "module:claim", Address: "lumera1a", Amount: "5"},
+		{Name: "foundation_genesis", Amount: "7", Items: []types.AddressItem{
+			{Address: "lumera1a", Amount: "5"}, {Address: "lumera1b", Amount: "2"}, {Address: "lumera1c", Amount: "0"},
+		}},
+		{Name: "claim_delayed", Amount: "3", Items: []types.AddressItem{
+			{Address: "lumera1b", Amount: "1"}, {Address: "lumera1b", Amount: "2"}, {Address: "lumera1c", Amount: "0"},
+		}},
+	}
+}
+
+func TestFindOverlaps(t *testing.T) {
+	got := findOverlaps(overlapCohorts())
+	// lumera1c is listed twice but locks nothing; lumera1b's two claim items count once
+	if len(got) != 2 || got[0].Address != "lumera1a" || strings.Join(got[0].Cohorts, ",") != "module:claim,foundation_genesis" ||
+		got[1].Address != "lumera1b" || strings.Join(got[1].Cohorts, ",") != "foundation_genesis,claim_delayed" {
+		t.Fatalf("unexpected overlaps: %+v", got)
+	}
+}
+
+func TestDedupeOverlaps(t *testing.T) {
+	// foundation_genesis outranks everything; module:claim and claim_delayed follow in computation order
+	c := &Computer{policy: &policy.Policy{Overlaps: &policy.OverlapPolicy{Mode: policy.OverlapDedupe, Precedence: []string{"foundation_genesis"}}}}
+	out, overlaps := c.resolveOverlaps(overlapCohorts())
+	if len(overlaps) != 2 || overlaps[0].CountedIn != "foundation_genesis" || overlaps[1].CountedIn != "foundation_genesis" {
+		t.Fatalf("unexpected overlaps: %+v", overlaps)
+	}
+	// module:claim only counted lumera1a, so it goes; claim_delayed keeps lumera1c's zero item
+	if len(out) != 2 || out[0].Name != "foundation_genesis" || out[0].Amount != "7" ||
+		out[1].Name != "claim_delayed" || out[1].Amount != "0" || len(out[1].Items) != 1 {
+		t.Fatalf("unexpected cohorts after dedupe: %+v", out)
+	}
+
+	// error mode rejects, report mode only reports
+	snap := &types.SupplySnapshot{Denom: "ulume", Overlaps: overlaps}
+	c.policy.Overlaps.Mode = policy.OverlapError
+	if err := c.checkOverlaps(snap); !errors.Is(err, ErrCohortOverlap) || !strings.Contains(err.Error(), "lumera1a") {
+		t.Fatalf("expected an overlap error, got %v", err)
+	}
+	c.policy.Overlaps = nil
+	if err := c.checkOverlaps(snap); err != nil {
+		t.Fatalf("report mode must not fail: %v", err)
+	}
+}
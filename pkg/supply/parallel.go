@@ -0,0 +1,69 @@
+package supply
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultConcurrency is the number of per-address lookups a snapshot runs in parallel
+// unless SetConcurrency says otherwise.
+const DefaultConcurrency = 8
+
+// SetConcurrency caps the per-address LCD lookups (vesting accounts, balances, slashing
+// checks) one snapshot runs in parallel. n < 1 means one at a time.
+func (c *Computer) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	c.mu.Lock()
+	c.concurrency = n
+	c.mu.Unlock()
+}
+
+// each calls fn(i) for every i in [0, n) on up to c.concurrency goroutines and returns
+// once all calls have. Callers write results to index i of a slice, so the order of
+// their output does not depend on scheduling. A panic in fn is raised again in the
+// caller, where guardSource recovers it as for sequential sources.
+func (c *Computer) each(n int, fn func(i int)) {
+	workers := min(max(c.concurrency, 1), n)
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	var (
+		wg      sync.WaitGroup
+		next    = make(chan int)
+		panicMu sync.Mutex
+		panicV  any
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				func() {
+					defer func() {
+						if p := recover(); p != nil {
+							panicMu.Lock()
+							if panicV == nil {
+								panicV = fmt.Errorf("lookup %d: %v", i, p)
+							}
+							panicMu.Unlock()
+						}
+					}()
+					fn(i)
+				}()
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	if panicV != nil {
+		panic(panicV)
+	}
+}
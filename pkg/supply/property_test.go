@@ -0,0 +1,185 @@
+package supply
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/mockchain"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// randomChain generates a chain with random balances, module accounts, vesting
+// schedules and claims, and a policy disclosing a random part of it. Addresses are
+// distinct, so no amount belongs to two cohorts.
+func randomChain(rng *rand.Rand, now time.Time) (*mockchain.State, *policy.Policy) {
+	amount := func() string { return fmt.Sprint(rng.Int63n(1e15)) }
+	at := func(from time.Time, maxDays int) *time.Time {
+		t := from.Add(time.Duration(rng.Intn(maxDays*24)) * time.Hour).Truncate(time.Second)
+		return &t
+	}
+	seq := 0
+	addr := func() string {
+		seq++
+		return mockchain.Address("lumera", fmt.Sprintf("prop/%d", seq))
+	}
+	pool := rng.Int63n(1e12)
+	st := &mockchain.State{
+		Denom: "ulume", Prefix: "lumera", Height: 1000, Time: &now,
+		IBCEscrow:     amount(),
+		CommunityPool: fmt.Sprintf("%d.%d", pool, rng.Intn(1000)),
+		Accounts:      []mockchain.Account{{Type: mockchain.TypeModule, Name: "distribution", Balance: fmt.Sprint(pool + rng.Int63n(1e12))}},
+	}
+	pol := &policy.Policy{}
+	for i := rng.Intn(5); i >= 0; i-- {
+		st.Accounts = append(st.Accounts, mockchain.Account{Type: mockchain.TypeBase, Address: addr(), Balance: amount()})
+	}
+	for _, name := range []string{"claim", "mint", "gov", "fee_collector"} {
+		st.Accounts = append(st.Accounts, mockchain.Account{Type: mockchain.TypeModule, Name: name, Balance: amount()})
+		if rng.Intn(2) == 0 {
+			pol.ModuleAccounts = append(pol.ModuleAccounts, name)
+		}
+	}
+
+	kinds := []string{mockchain.TypeDelayed, mockchain.TypeContinuous, mockchain.TypePeriodic, mockchain.TypePermanent}
+	for i := rng.Intn(5); i > 0; i-- {
+		a := mockchain.Account{Type: kinds[rng.Intn(len(kinds))], Address: addr(), Balance: amount()}
+		a.StartTime = at(now.AddDate(-2, 0, 0), 760)
+		a.EndTime = at(a.StartTime.Add(24*time.Hour), 1100)
+		if a.Type == mockchain.TypePeriodic {
+			left, _ := new(big.Int).SetString(a.Balance, 10)
+			for n := rng.Intn(5) + 1; n > 0; n-- {
+				part := new(big.Int)
+				if n == 1 || left.Sign() == 0 {
+					part.Set(left)
+				} else {
+					part.Rand(rng, left)
+				}
+				left.Sub(left, part)
+				a.Periods = append(a.Periods, mockchain.Period{LengthSeconds: rng.Int63n(400 * 86400), Amount: part.String()})
+			}
+		}
+		st.Accounts = append(st.Accounts, a)
+		pol.Disclosed.FoundationGenesis = append(pol.Disclosed.FoundationGenesis, policy.FoundationEntry{Name: a.Address, Address: a.Address})
+	}
+	for i := rng.Intn(4); i > 0; i-- {
+		a := mockchain.Account{Type: mockchain.TypeDelayed, Address: addr(), Balance: amount(), EndTime: at(now.AddDate(0, -6, 0), 760)}
+		e := policy.SupernodeEntry{Name: a.Address, Address: a.Address, EndTime: a.EndTime}
+		if rng.Intn(4) == 0 {
+			a.Type, a.EndTime, e.EndTime, e.Permanent = mockchain.TypePermanent, nil, nil, true
+		}
+		st.Accounts = append(st.Accounts, a)
+		pol.Disclosed.SupernodeBootstraps = append(pol.Disclosed.SupernodeBootstraps, e)
+	}
+	for i := rng.Intn(20); i > 0; i-- {
+		st.Claims = append(st.Claims, mockchain.Claim{Address: addr(), Tier: rng.Intn(4) + 1, Time: *at(now.AddDate(-2, 0, 0), 730), Amount: amount()})
+	}
+	return st, pol
+}
+
+// TestSupplyProperties checks invariants over random chains, each observed as its
+// locks run out: total = circulating + non-circulating, amounts are never negative,
+// the total does not move, and locked amounts only decrease.
+func TestSupplyProperties(t *testing.T) {
+	cases := 40
+	if testing.Short() {
+		cases = 5
+	}
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for seed := int64(1); seed <= int64(cases); seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		st, pol := randomChain(rng, now)
+		mock, err := mockchain.New(st)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		mock.Freeze()
+		ts := httptest.NewServer(mock)
+		comp := NewComputer(lcd.NewClient(ts.URL, ts.Client()), pol)
+
+		var prev *types.SupplySnapshot
+		for step := 0; step < 5; step++ {
+			snap, err := comp.ComputeSnapshot(context.Background(), "ulume")
+			if err != nil {
+				t.Fatalf("seed %d step %d: %v", seed, step, err)
+			}
+			if msg := checkSnapshot(snap, prev); msg != "" {
+				t.Fatalf("seed %d step %d: %s", seed, step, msg)
+			}
+			prev = snap
+			mock.Advance(time.Duration(rng.Intn(200*24)) * time.Hour)
+		}
+		ts.Close()
+	}
+}
+
+// checkSnapshot describes the first property snap breaks, given the snapshot of the
+// same chain at an earlier time (nil for the first).
+func checkSnapshot(snap, prev *types.SupplySnapshot) string {
+	num := func(what, s string) (*big.Int, string) {
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok || n.Sign() < 0 {
+			return nil, fmt.Sprintf("%s %q is not a non-negative amount", what, s)
+		}
+		return n, ""
+	}
+	total, msg := num("total", snap.Total)
+	if msg != "" {
+		return msg
+	}
+	circ, msg := num("circulating", snap.Circulating)
+	if msg != "" {
+		return msg
+	}
+	non, msg := num("non_circulating", snap.NonCirculating.Sum)
+	if msg != "" {
+		return msg
+	}
+	if new(big.Int).Add(circ, non).Cmp(total) != 0 {
+		return fmt.Sprintf("total %s != circulating %s + non_circulating %s", total, circ, non)
+	}
+	if snap.Anomaly != nil {
+		return fmt.Sprintf("anomaly: %+v", *snap.Anomaly)
+	}
+	sum := new(big.Int)
+	locked := map[string]*big.Int{}
+	for _, co := range snap.NonCirculating.Cohorts {
+		n, msg := num("cohort "+co.Name, co.Amount)
+		if msg != "" {
+			return msg
+		}
+		sum.Add(sum, n)
+		locked[co.Name] = n
+		for _, it := range co.Items {
+			if _, msg := num(co.Name+" item "+it.Address, it.Amount); msg != "" {
+				return msg
+			}
+		}
+	}
+	if sum.Cmp(non) != 0 {
+		return fmt.Sprintf("cohorts sum to %s, non_circulating is %s", sum, non)
+	}
+	if prev == nil {
+		return ""
+	}
+	if snap.Total != prev.Total {
+		return fmt.Sprintf("total moved from %s to %s", prev.Total, snap.Total)
+	}
+	for _, co := range prev.NonCirculating.Cohorts {
+		was, _ := new(big.Int).SetString(co.Amount, 10)
+		now := locked[co.Name]
+		if now == nil {
+			now = new(big.Int)
+		}
+		if now.Cmp(was) > 0 {
+			return fmt.Sprintf("cohort %s grew from %s to %s as time passed", co.Name, was, now)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package supply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+var quorumMismatches = metrics.Default.NewCounter(
+	"lumera_supply_quorum_mismatches_total",
+	"Quorum checks where an LCD endpoint disagreed with the primary (or failed to answer).",
+	"endpoint", "check",
+)
+
+// ErrQuorum is returned by ComputeSnapshot when quorum verification rejects a snapshot.
+var ErrQuorum = errors.New("quorum verification failed")
+
+// SetQuorum enables paranoid mode: each snapshot's total supply and module account
+// balances are re-read at the snapshot height from the primary and every peer, and the
+// snapshot is only published when a strict majority of all endpoints agree on each value.
+func (c *Computer) SetQuorum(peers []*lcd.Client) {
+	c.peers = peers
+}
+
+// peersWithContext returns peers making their requests under ctx.
+func peersWithContext(ctx context.Context, peers []*lcd.Client) []*lcd.Client {
+	out := make([]*lcd.Client, len(peers))
+	for i, p := range peers {
+		out[i] = p.WithContext(ctx)
+	}
+	return out
+}
+
+// verifyQuorum checks snap against the configured peers: each value is re-read from the
+// primary at the height it was computed at and must match the snapshot, and a strict
+// majority of all endpoints must agree with it. Disagreements are logged and counted per
+// endpoint; the error wraps ErrQuorum.
+func (c *Computer) verifyQuorum(snap *types.SupplySnapshot) error {
+	if len(c.peers) == 0 {
+		return nil
+	}
+	type check struct {
+		name   string
+		height int64
+		have   string
+		fetch  func(*lcd.Client) (string, error)
+	}
+	checks := []check{{"total", snap.Height, snap.Total, func(l *lcd.Client) (string, error) { return l.TotalSupplyByDenom(snap.Denom) }}}
+	for _, co := range snap.NonCirculating.Cohorts {
+		if co.Address == "" {
+			continue
+		}
+		addr, height := co.Address, co.AsOfHeight
+		if height == 0 {
+			height = snap.Height
+		}
+		checks = append(checks, check{co.Name, height, co.Amount, func(l *lcd.Client) (string, error) { return l.BalanceByDenom(addr, snap.Denom) }})
+	}
+
+	endpoints := append([]*lcd.Client{c.lcd}, c.peers...)
+	need := len(endpoints)/2 + 1
+	var errs []error
+	for _, ck := range checks {
+		want, err := ck.fetch(c.lcd.AtHeight(ck.height))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: primary at height %d: %w", ck.name, ck.height, err))
+			continue
+		}
+		if want != ck.have {
+			quorumMismatches.Inc(c.lcd.Base(), ck.name)
+			errs = append(errs, fmt.Errorf("%s: primary reports %s at height %d, the snapshot has %s", ck.name, want, ck.height, ck.have))
+			continue
+		}
+		agree := 1
+		for _, p := range c.peers {
+			got, err := ck.fetch(p.AtHeight(ck.height))
+			switch {
+			case err != nil:
+				log.Printf("warn: quorum %s: %s at height %d: %v", ck.name, p.Base(), ck.height, err)
+			case got != want:
+				log.Printf("warn: quorum %s mismatch at height %d: %s=%s primary=%s", ck.name, ck.height, p.Base(), got, want)
+			default:
+				agree++
+				continue
+			}
+			quorumMismatches.Inc(p.Base(), ck.name)
+		}
+		if agree < need {
+			errs = append(errs, fmt.Errorf("%s: %d of %d endpoints agree (need %d)", ck.name, agree, len(endpoints), need))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrQuorum, errors.Join(errs...))
+	}
+	return nil
+}
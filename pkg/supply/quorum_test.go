@@ -0,0 +1,89 @@
+package supply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// fakeLCD answers supply and balance queries with fixed amounts, requiring a pinned height.
+func fakeLCD(t *testing.T, total, balance string) *lcd.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-cosmos-block-height") != "100" {
+			t.Errorf("%s: missing height pin", r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"` + total + `"}}`))
+		case "/cosmos/bank/v1beta1/balances/lumera1mod/by_denom":
+			_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"` + balance + `"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return lcd.NewClient(srv.URL, srv.Client())
+}
+
+func TestVerifyQuorum(t *testing.T) {
+	snap := &types.SupplySnapshot{Denom: "ulume", Height: 100, Total: "1000", NonCirculating: types.NonCircBreakdown{
+		Cohorts: []types.CohortEntry{{Name: "module:claim", Address: "lumera1mod", Amount: "5"}},
+	}}
+
+	c := &Computer{lcd: fakeLCD(t, "1000", "5")}
+	c.SetQuorum([]*lcd.Client{fakeLCD(t, "1000", "5"), fakeLCD(t, "999", "5")})
+	if err := c.verifyQuorum(snap); err != nil {
+		t.Fatalf("2 of 3 agreeing should pass: %v", err)
+	}
+
+	c.SetQuorum([]*lcd.Client{fakeLCD(t, "1000", "6"), fakeLCD(t, "999", "7")})
+	if err := c.verifyQuorum(snap); !errors.Is(err, ErrQuorum) {
+		t.Fatalf("expected ErrQuorum, got %v", err)
+	}
+
+	// every endpoint agrees, but not with what the snapshot was computed from
+	c.SetQuorum([]*lcd.Client{fakeLCD(t, "1000", "5"), fakeLCD(t, "1000", "5")})
+	snap.Total = "1100"
+	if err := c.verifyQuorum(snap); !errors.Is(err, ErrQuorum) {
+		t.Fatalf("a snapshot disagreeing with the primary should fail, got %v", err)
+	}
+}
+
+func TestComputeSnapshotPinsHeight(t *testing.T) {
+	// the head moves past 100 once the snapshot has read it; unpinned queries see 1100
+	var latest atomic.Int64
+	latest.Store(100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			fmt.Fprintf(w, `{"block":{"header":{"height":"%d","time":"2025-06-01T00:00:00Z"}}}`, latest.Add(1)-1)
+		case "/cosmos/bank/v1beta1/supply/by_denom":
+			total := "1100"
+			if r.Header.Get("x-cosmos-block-height") == "100" {
+				total = "1000"
+			}
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"` + total + `"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewComputer(lcd.NewClient(srv.URL, srv.Client()), nil)
+	c.SetQuorum([]*lcd.Client{fakeLCD(t, "1000", "5"), fakeLCD(t, "1000", "5")})
+	snap, err := c.ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Height != 100 || snap.Total != "1000" {
+		t.Fatalf("snapshot at height %d with total %s, want 100 and 1000", snap.Height, snap.Total)
+	}
+}
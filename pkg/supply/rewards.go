@@ -0,0 +1,27 @@
+package supply
+
+import (
+	"log"
+	"math/big"
+	"strings"
+)
+
+// capPrincipal limits a balance used as a locked amount to the policy principal
+// (amount in base units, optionally suffixed with denom). An account's balance also
+// holds withdrawn staking rewards, which are spendable; without a principal the
+// balance is returned unchanged.
+func capPrincipal(balance, principal, denom string) string {
+	if principal == "" {
+		return balance
+	}
+	p, ok := new(big.Int).SetString(strings.TrimSuffix(principal, denom), 10)
+	if !ok {
+		log.Printf("warn: invalid principal amount %q", principal)
+		return balance
+	}
+	b, ok := new(big.Int).SetString(balance, 10)
+	if !ok || b.Cmp(p) <= 0 {
+		return balance
+	}
+	return p.String()
+}
@@ -0,0 +1,18 @@
+package supply
+
+import "testing"
+
+func TestCapPrincipal(t *testing.T) {
+	cases := []struct{ bal, principal, want string }{
+		{"1200", "1000ulume", "1000"}, // withdrawn rewards stay circulating
+		{"800", "1000ulume", "800"},
+		{"1200", "1000", "1000"},
+		{"1200", "", "1200"},
+		{"1200", "bogus", "1200"},
+	}
+	for _, c := range cases {
+		if got := capPrincipal(c.bal, c.principal, "ulume"); got != c.want {
+			t.Errorf("capPrincipal(%s, %q) = %s, want %s", c.bal, c.principal, got, c.want)
+		}
+	}
+}
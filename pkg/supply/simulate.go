@@ -0,0 +1,46 @@
+package supply
+
+import (
+	"context"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+// Simulate projects denom's supply over months months: it reads the latest chain state
+// once, then evaluates every lock at from and at the same day of each following month,
+// on the code path snapshots take (see ComputeSnapshotAt). A zero from is the block
+// time. Balances, claims and the total stay as read, so the curve shows scheduled
+// unlocks only, not inflation, burns or future claims.
+func (c *Computer) Simulate(ctx context.Context, denom string, from time.Time, months int) (*types.SupplyProjection, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	base := c.lcd.WithContext(ctx)
+	height, t, err := base.LatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	// every evaluation reads the same state; only the first asks the node
+	run := &Computer{lcd: base.AtHeight(height).Memoized(), policy: c.policy, clock: c.clock, concurrency: c.concurrency}
+	total, err := run.lcd.TotalSupplyByDenom(denom)
+	if err != nil {
+		return nil, err
+	}
+	if from.IsZero() {
+		from = t
+	}
+	proj := &types.SupplyProjection{Denom: denom, Height: height, BlockTime: t.UTC()}
+	for m := 0; m <= months; m++ {
+		run.evalAt = from.AddDate(0, m, 0)
+		snap := run.build(denom, height, t, total)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p := types.ProjectionPoint{Time: run.evalAt.UTC(), Total: snap.Total, Circulating: snap.Circulating, NonCirculating: snap.NonCirculating.Sum}
+		for _, co := range snap.NonCirculating.Cohorts {
+			p.Cohorts = append(p.Cohorts, types.CohortAmount{Cohort: co.Name, CohortID: co.ID, CohortSlug: co.Slug, Amount: co.Amount})
+		}
+		proj.Points = append(proj.Points, p)
+	}
+	return proj, nil
+}
@@ -0,0 +1,73 @@
+package supply
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/mockchain"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+)
+
+func TestSimulate(t *testing.T) {
+	pol, err := policy.Load("../../policy.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	st := mockchain.Default(now, 40)
+	st.Time = &now
+	st.AddPolicy(pol, now)
+	mock, err := mockchain.New(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.Freeze()
+	var calls atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		mock.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	comp := NewComputer(lcd.NewClient(ts.URL, ts.Client()), pol)
+
+	proj, err := comp.Simulate(context.Background(), "ulume", time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	once := calls.Swap(0)
+	proj, err = comp.Simulate(context.Background(), "ulume", time.Time{}, 36)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != once {
+		t.Fatalf("36 months took %d LCD calls, one evaluation %d", n, once)
+	}
+	if len(proj.Points) != 37 || !proj.Points[0].Time.Equal(proj.BlockTime) || !proj.Points[12].Time.Equal(proj.BlockTime.AddDate(1, 0, 0)) {
+		t.Fatalf("points: %d from %v", len(proj.Points), proj.Points[0].Time)
+	}
+
+	snap, err := comp.ComputeSnapshotAt(context.Background(), "ulume", proj.BlockTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := proj.Points[0]; p.Circulating != snap.Circulating || p.NonCirculating != snap.NonCirculating.Sum {
+		t.Fatalf("first point %s/%s, snapshot %s/%s", p.Circulating, p.NonCirculating, snap.Circulating, snap.NonCirculating.Sum)
+	}
+	prev := new(big.Int)
+	for _, p := range proj.Points {
+		circ, _ := new(big.Int).SetString(p.Circulating, 10)
+		if circ.Cmp(prev) < 0 || p.Total != proj.Points[0].Total {
+			t.Fatalf("%v: circulating %s after %s, total %s", p.Time, circ, prev, p.Total)
+		}
+		prev = circ
+	}
+	if proj.Points[36].Circulating == proj.Points[0].Circulating {
+		t.Fatalf("nothing unlocked in 36 months")
+	}
+}
@@ -0,0 +1,105 @@
+package supply
+
+import (
+	"log"
+	"math/big"
+	"sync"
+
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/metrics"
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+var slashingCapped = metrics.Default.NewCounter(
+	"lumera_supply_slashing_capped_total",
+	"Policy address locked amounts reduced because slashing left the account holding less than its vesting schedule.",
+	"cohort",
+)
+
+// stakeCheck accounts for slashing in policy-listed addresses. A vesting schedule is
+// defined on the original amount, but slashed stake is burned: once an account holds
+// less than its schedule says is locked, counting the schedule would subtract burned
+// tokens (already gone from total supply) a second time. Validator state is memoized
+// for one snapshot.
+type stakeCheck struct {
+	lcd   *lcd.Client
+	denom string
+
+	mu   sync.Mutex // apply runs on parallel lookups
+	vals map[string]lcd.ValidatorState
+}
+
+func (c *Computer) newStakeCheck(denom string) *stakeCheck {
+	return &stakeCheck{lcd: c.lcd, denom: denom, vals: map[string]lcd.ValidatorState{}}
+}
+
+// apply flags delegations to jailed or tombstoned validators on it and caps its locked
+// amount at what the account still holds: bank balance plus delegations and unbonding
+// entries, whose balances already reflect applied slashes. It also records unclaimed
+// rewards. Lookup failures leave the item unchanged.
+func (s *stakeCheck) apply(cohort string, it *types.AddressItem) {
+	locked, ok := new(big.Int).SetString(it.Amount, 10)
+	if !ok || locked.Sign() == 0 {
+		return
+	}
+	// unclaimed rewards circulate; recorded so the item reconciles with the account
+	if rw, err := s.lcd.DelegatorRewards(it.Address, s.denom); err == nil {
+		if rw != "0" {
+			it.Rewards = rw
+		}
+	} else {
+		log.Printf("warn: rewards %s: %v", it.Address, err)
+	}
+	bal, err := s.lcd.BalanceByDenom(it.Address, s.denom)
+	if err != nil {
+		log.Printf("warn: slashing check %s: %v", it.Address, err)
+		return
+	}
+	dels, err := s.lcd.Delegations(it.Address, s.denom)
+	if err != nil {
+		log.Printf("warn: slashing check %s: %v", it.Address, err)
+		return
+	}
+	unbonding, err := s.lcd.UnbondingTotal(it.Address)
+	if err != nil {
+		log.Printf("warn: slashing check %s: %v", it.Address, err)
+		return
+	}
+	held := new(big.Int)
+	add := func(v string) {
+		if n, ok := new(big.Int).SetString(v, 10); ok {
+			held.Add(held, n)
+		}
+	}
+	add(bal)
+	add(unbonding)
+	for _, d := range dels {
+		add(d.Amount)
+		s.mu.Lock()
+		st, seen := s.vals[d.Validator]
+		s.mu.Unlock()
+		if seen {
+			s.lcd.Stats().CacheHit()
+		} else {
+			if st, err = s.lcd.Validator(d.Validator); err != nil {
+				log.Printf("warn: validator %s: %v", d.Validator, err)
+			}
+			s.mu.Lock()
+			s.vals[d.Validator] = st
+			s.mu.Unlock()
+		}
+		if st.Jailed {
+			it.JailedValidators = append(it.JailedValidators, d.Validator)
+		}
+		if st.Tombstoned {
+			log.Printf("warn: %s %s delegates %s to tombstoned validator %s", cohort, it.Address, d.Amount, d.Validator)
+		}
+	}
+	if locked.Cmp(held) > 0 {
+		// the shrink comes from slashing, not from the schedule unlocking
+		it.SlashedLocked = new(big.Int).Sub(locked, held).String()
+		it.Amount = held.String()
+		slashingCapped.Inc(cohort)
+		log.Printf("warn: %s %s locked reduced by slashing: schedule %s, holds %s", cohort, it.Address, locked, held)
+	}
+}
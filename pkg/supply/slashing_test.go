@@ -0,0 +1,63 @@
+package supply
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/bech32"
+	"github.com/lumera-labs/lumera-supply/pkg/lcd"
+	"github.com/lumera-labs/lumera-supply/pkg/policy"
+)
+
+func TestSlashedFoundationStake(t *testing.T) {
+	const addr = "lumera1foundationxxxxxxxxxxxxxxxxxxxxxxxxx"
+	valoper, _ := bech32.Encode("lumeravaloper", make([]byte, 20))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/cosmos/base/tendermint/v1beta1/blocks/latest":
+			_, _ = w.Write([]byte(`{"block":{"header":{"height":"100","time":"2025-01-01T00:00:00Z"}}}`))
+		case r.URL.Path == "/cosmos/bank/v1beta1/supply/by_denom":
+			_, _ = w.Write([]byte(`{"amount":{"denom":"ulume","amount":"10000"}}`))
+		case r.URL.Path == "/cosmos/auth/v1beta1/accounts/"+addr:
+			// delayed vesting of 1000 ending in 2030: fully locked by schedule
+			_, _ = w.Write([]byte(`{"account":{"@type":"/cosmos.vesting.v1beta1.DelayedVestingAccount",
+				"base_vesting_account":{"original_vesting":[{"denom":"ulume","amount":"1000"}],"end_time":"1893456000"}}}`))
+		case r.URL.Path == "/cosmos/bank/v1beta1/balances/"+addr+"/by_denom":
+			_, _ = w.Write([]byte(`{"balance":{"denom":"ulume","amount":"100"}}`))
+		case r.URL.Path == "/cosmos/staking/v1beta1/delegations/"+addr:
+			// 900 delegated, slashed to 600
+			_, _ = w.Write([]byte(`{"delegation_responses":[{"delegation":{"validator_address":"` + valoper + `"},"balance":{"denom":"ulume","amount":"600"}}]}`))
+		case r.URL.Path == "/cosmos/distribution/v1beta1/delegators/"+addr+"/rewards":
+			_, _ = w.Write([]byte(`{"total":[{"denom":"ulume","amount":"12.5"}]}`))
+		case r.URL.Path == "/cosmos/staking/v1beta1/delegators/"+addr+"/unbonding_delegations":
+			_, _ = w.Write([]byte(`{"unbonding_responses":[]}`))
+		case r.URL.Path == "/cosmos/staking/v1beta1/validators/"+valoper:
+			_, _ = w.Write([]byte(`{"validator":{"jailed":true,"consensus_pubkey":{"key":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}}}`))
+		case strings.HasPrefix(r.URL.Path, "/cosmos/slashing/v1beta1/signing_infos/lumeravalcons1"):
+			_, _ = w.Write([]byte(`{"val_signing_info":{"tombstoned":true}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	pol := &policy.Policy{Disclosed: policy.DisclosedLockups{FoundationGenesis: []policy.FoundationEntry{{Name: "seed", Address: addr}}}}
+	snap, err := NewComputer(lcd.NewClient(ts.URL, ts.Client()), pol).ComputeSnapshot(context.Background(), "ulume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	co := snap.NonCirculating.Cohorts[0]
+	if co.Name != "foundation_genesis" || co.Amount != "700" || len(co.Items) != 1 {
+		t.Fatalf("unexpected cohort: %+v", co)
+	}
+	it := co.Items[0]
+	if it.Amount != "700" || it.SlashedLocked != "300" || it.Rewards != "12" || len(it.JailedValidators) != 1 || it.JailedValidators[0] != valoper {
+		t.Fatalf("unexpected item: %+v", it)
+	}
+	if snap.Circulating != "9300" {
+		t.Fatalf("circulating = %s", snap.Circulating)
+	}
+}
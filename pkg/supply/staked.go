@@ -0,0 +1,23 @@
+package supply
+
+import "github.com/lumera-labs/lumera-supply/pkg/types"
+
+// stakedSupply fetches the staking pool for the snapshot of denom. It is nil when denom
+// is not the bond denom, or when the pool cannot be fetched (logged; the staking_bonded
+// cohort is then skipped like any failed source).
+func (c *Computer) stakedSupply(denom string) *types.StakedSupply {
+	bond, err := c.lcd.BondDenom()
+	if err != nil {
+		c.fetchFailed("staking_bonded", "staking params fetch", err)
+		return nil
+	}
+	if bond != denom {
+		return nil
+	}
+	p, err := c.lcd.StakingPool()
+	if err != nil {
+		c.fetchFailed("staking_bonded", "staking pool fetch", err)
+		return nil
+	}
+	return &types.StakedSupply{Bonded: p.Bonded, NotBonded: p.NotBonded}
+}
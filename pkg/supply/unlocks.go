@@ -0,0 +1,167 @@
+package supply
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+	"github.com/lumera-labs/lumera-supply/pkg/vesting"
+)
+
+// Lock schedule kinds (types.LockSchedule.Kind).
+const (
+	ScheduleContinuous = "continuous"
+	SchedulePeriodic   = "periodic"
+)
+
+// UnlockSchedule returns what the snapshot's dated items release in (from, until],
+// earliest first, following their vesting schedules: a delayed lock releases at its end
+// and a periodic one at each period's end. Continuous vesting releases steadily; it is
+// reported as what it releases by the start of each calendar month, by its end and by
+// until. Permanent locks and undated items never unlock. snap must be hydrated (see
+// cache.Hydrate).
+func UnlockSchedule(snap *types.SupplySnapshot, from, until time.Time) []types.UnlockEvent {
+	return UnlockScheduleBy(snap, from, until, nil)
+}
+
+// MonthStart maps t to the start of its calendar month (UTC), for UnlockScheduleBy.
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// UnlockScheduleBy is UnlockSchedule with the releases merged into buckets: bucket maps an
+// instant to the time of its bucket (e.g. MonthStart), nil keeps every release instant
+// apart. A bucket starting before from is dated at from. Continuous vesting counts in the
+// bucket it vests in. An event's Addresses counts each address once per bucket.
+func UnlockScheduleBy(snap *types.SupplySnapshot, from, until time.Time, bucket func(time.Time) time.Time) []types.UnlockEvent {
+	type acc struct {
+		total    *big.Int
+		cohorts  map[string]*big.Int
+		addrs    map[string]bool
+		unixTime int64
+	}
+	byTime := map[int64]*acc{}
+	refs := map[string]types.CohortAmount{}
+	for _, c := range snap.NonCirculating.Cohorts {
+		refs[c.Name] = types.CohortAmount{Cohort: c.Name, CohortID: c.ID, CohortSlug: c.Slug}
+		for _, it := range c.Items {
+			for _, rel := range itemReleases(it, from, until) {
+				at := rel.at.Unix()
+				if bucket != nil {
+					at = max(bucket(rel.in).Unix(), from.Unix())
+				}
+				a := byTime[at]
+				if a == nil {
+					a = &acc{total: new(big.Int), cohorts: map[string]*big.Int{}, addrs: map[string]bool{}, unixTime: at}
+					byTime[at] = a
+				}
+				a.total.Add(a.total, rel.amount)
+				if a.cohorts[c.Name] == nil {
+					a.cohorts[c.Name] = new(big.Int)
+				}
+				a.cohorts[c.Name].Add(a.cohorts[c.Name], rel.amount)
+				a.addrs[it.Address] = true
+			}
+		}
+	}
+	out := make([]types.UnlockEvent, 0, len(byTime))
+	for _, a := range byTime {
+		ev := types.UnlockEvent{Time: time.Unix(a.unixTime, 0).UTC(), Amount: a.total.String(), Addresses: len(a.addrs)}
+		for name, v := range a.cohorts {
+			ca := refs[name]
+			ca.Amount = v.String()
+			ev.Cohorts = append(ev.Cohorts, ca)
+		}
+		sort.Slice(ev.Cohorts, func(i, j int) bool { return ev.Cohorts[i].Cohort < ev.Cohorts[j].Cohort })
+		out = append(out, ev)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out
+}
+
+// release is part of an item's lock released at at; in is an instant of the span it
+// vested over, which decides its bucket.
+type release struct {
+	at, in time.Time
+	amount *big.Int
+}
+
+// itemReleases lists what it releases in (from, until], earliest first.
+func itemReleases(it types.AddressItem, from, until time.Time) []release {
+	amount, ok := new(big.Int).SetString(it.Amount, 10)
+	if !ok || amount.Sign() <= 0 || it.Permanent || it.EndUnix == 0 {
+		return nil
+	}
+	var cuts []time.Time
+	continuous := false
+	switch s := it.Schedule; {
+	case s != nil && s.Kind == SchedulePeriodic:
+		for _, p := range s.Periods {
+			cuts = append(cuts, time.Unix(p.EndUnix, 0).UTC())
+		}
+	case s != nil && s.Kind == ScheduleContinuous:
+		continuous = true
+		end := time.Unix(s.EndUnix, 0).UTC()
+		for m := MonthStart(from).AddDate(0, 1, 0); m.Before(end) && m.Before(until); m = m.AddDate(0, 1, 0) {
+			cuts = append(cuts, m)
+		}
+		if end.Before(until) {
+			cuts = append(cuts, end)
+		} else {
+			cuts = append(cuts, until)
+		}
+	default:
+		cuts = []time.Time{time.Unix(it.EndUnix, 0).UTC()}
+	}
+	var out []release
+	prev, before := from, lockedAt(it, amount, from)
+	for _, t := range cuts {
+		if !t.After(from) {
+			continue
+		}
+		if t.After(until) {
+			break
+		}
+		after := lockedAt(it, amount, t)
+		if v := new(big.Int).Sub(before, after); v.Sign() > 0 {
+			in := t
+			if continuous {
+				in = prev
+			}
+			out = append(out, release{at: t, in: in, amount: v})
+		}
+		prev, before = t, after
+	}
+	return out
+}
+
+// lockedAt is how much of it is still locked at t: what its schedule keeps locked,
+// capped at amount, the item's lock at the evaluation instant (slashing or a balance cap
+// may have reduced it below the schedule). Without a schedule, all of amount is locked
+// until EndUnix.
+func lockedAt(it types.AddressItem, amount *big.Int, t time.Time) *big.Int {
+	ve := vesting.NewEngine()
+	var locked string
+	switch s := it.Schedule; {
+	case s != nil && s.Kind == SchedulePeriodic:
+		periods := make([]vesting.Period, 0, len(s.Periods))
+		for _, p := range s.Periods {
+			periods = append(periods, vesting.Period{End: time.Unix(p.EndUnix, 0), Amount: p.Amount})
+		}
+		locked = ve.PeriodicLocked(periods, t)
+	case s != nil && s.Kind == ScheduleContinuous:
+		locked = ve.ContinuousLocked(s.OriginalVesting, t, time.Unix(s.StartUnix, 0), time.Unix(s.EndUnix, 0))
+	default:
+		locked = ve.DelayedLocked(it.Amount, t, time.Unix(it.EndUnix, 0))
+	}
+	v, ok := new(big.Int).SetString(locked, 10)
+	if !ok || v.Sign() < 0 {
+		return new(big.Int)
+	}
+	if v.Cmp(amount) > 0 {
+		return new(big.Int).Set(amount)
+	}
+	return v
+}
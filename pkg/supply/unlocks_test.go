@@ -0,0 +1,104 @@
+package supply
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/lumera-labs/lumera-supply/pkg/types"
+)
+
+func TestUnlockSchedule(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	jun := now.AddDate(0, 5, 0).Unix()
+	snap := &types.SupplySnapshot{NonCirculating: types.NonCircBreakdown{Cohorts: []types.CohortEntry{
+		{Name: "claim_delayed", Items: []types.AddressItem{
+			{Address: "a", Amount: "10", EndUnix: jun},
+			{Address: "b", Amount: "5", EndUnix: now.AddDate(0, 1, 0).Unix()},
+			{Address: "c", Amount: "7", EndUnix: now.AddDate(-1, 0, 0).Unix()}, // already ended
+		}},
+		{Name: "foundation_genesis", Items: []types.AddressItem{
+			{Address: "a", Amount: "20", EndUnix: jun},
+			{Address: "d", Amount: "99", Permanent: true, EndDate: "forever"},
+			{Address: "e", Amount: "1", EndUnix: now.AddDate(3, 0, 0).Unix()}, // beyond window
+		}},
+	}}}
+	got := UnlockSchedule(snap, now, now.AddDate(1, 0, 0))
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %+v", got)
+	}
+	if got[0].Amount != "5" || got[1].Amount != "30" || got[1].Addresses != 1 || len(got[1].Cohorts) != 2 {
+		t.Fatalf("unexpected schedule: %+v", got)
+	}
+}
+
+func TestUnlockScheduleByMonth(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	snap := &types.SupplySnapshot{NonCirculating: types.NonCircBreakdown{Cohorts: []types.CohortEntry{
+		{Name: "claim_delayed", Items: []types.AddressItem{
+			{Address: "a", Amount: "10", EndUnix: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC).Unix()},
+			{Address: "a", Amount: "5", EndUnix: time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC).Unix()},
+			{Address: "b", Amount: "1", EndUnix: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC).Unix()},
+		}},
+	}}}
+	got := UnlockScheduleBy(snap, now, now.AddDate(1, 0, 0), MonthStart)
+	if len(got) != 2 || !got[0].Time.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || got[0].Amount != "15" || got[0].Addresses != 1 ||
+		!got[1].Time.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) || got[1].Amount != "1" {
+		t.Fatalf("unexpected monthly schedule: %+v", got)
+	}
+}
+
+func TestUnlockScheduleContinuous(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	snap := &types.SupplySnapshot{NonCirculating: types.NonCircBreakdown{Cohorts: []types.CohortEntry{
+		{Name: "foundation_genesis", Items: []types.AddressItem{
+			{Address: "a", Amount: "1200", EndUnix: end.Unix(), Schedule: &types.LockSchedule{
+				Kind: ScheduleContinuous, OriginalVesting: "1200", StartUnix: start.Unix(), EndUnix: end.Unix()}},
+		}},
+	}}}
+	got := UnlockSchedule(snap, start, end)
+	if len(got) != 12 {
+		t.Fatalf("expected an event per month, got %+v", got)
+	}
+	total := 0
+	for i, ev := range got {
+		if want := start.AddDate(0, i+1, 0); !ev.Time.Equal(want) {
+			t.Fatalf("event %d at %s, want %s", i, ev.Time, want)
+		}
+		n, _ := strconv.Atoi(ev.Amount)
+		total += n
+	}
+	// January vests 31 of 365 days, 101.9
+	if got[0].Amount != "102" || total != 1200 {
+		t.Fatalf("january %s, total %d: %+v", got[0].Amount, total, got)
+	}
+
+	// a window ending mid-vesting reports what vests by its end
+	got = UnlockSchedule(snap, start, start.AddDate(0, 0, 10))
+	if len(got) != 1 || got[0].Amount != "33" {
+		t.Fatalf("partial window: %+v", got)
+	}
+}
+
+func TestUnlockSchedulePeriodic(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sched := &types.LockSchedule{Kind: SchedulePeriodic, Periods: []types.LockPeriod{
+		{EndUnix: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC).Unix(), Amount: "100"},
+		{EndUnix: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC).Unix(), Amount: "200"},
+		{EndUnix: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC).Unix(), Amount: "300"},
+	}}
+	end := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC).Unix()
+	snap := &types.SupplySnapshot{NonCirculating: types.NonCircBreakdown{Cohorts: []types.CohortEntry{
+		{Name: "foundation_genesis", Items: []types.AddressItem{
+			{Address: "a", Amount: "600", EndUnix: end, Schedule: sched},
+			// slashed below its schedule: the earliest periods release nothing
+			{Address: "b", Amount: "450", EndUnix: end, Schedule: sched},
+		}},
+	}}}
+	got := UnlockSchedule(snap, now, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC))
+	if len(got) != 2 || got[0].Amount != "100" || got[0].Addresses != 1 ||
+		!got[1].Time.Equal(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)) || got[1].Amount != "350" || got[1].Addresses != 2 {
+		t.Fatalf("unexpected periodic schedule: %+v", got)
+	}
+}
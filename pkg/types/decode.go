@@ -0,0 +1,88 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// record is a stored document as raw fields, so upgrades can rename, move or derive
+// fields the current structs no longer declare.
+type record map[string]json.RawMessage
+
+// An upgrade turns a version v record into a version v+1 one.
+type upgrade func(record) error
+
+// snapshotUpgrades[v] upgrades a version v snapshot. A model change bumps
+// SchemaVersion and adds the step from the previous version here.
+var snapshotUpgrades = map[int]upgrade{
+	// 0: written before snapshots recorded schema_version. Locks were always
+	// evaluated at the block time.
+	0: func(r record) error {
+		if _, ok := r["evaluated_at"]; !ok {
+			r["evaluated_at"] = r["updated_at"]
+		}
+		return nil
+	},
+}
+
+// pointUpgrades[v] upgrades a version v SupplyPoint.
+var pointUpgrades = map[int]upgrade{
+	// 0: written before points recorded schema_version; same fields
+	0: func(record) error { return nil },
+}
+
+// DecodeSnapshot decodes a stored snapshot of any schema version: older ones are
+// upgraded to the current model first. Snapshots from a newer version decode as far as
+// this version understands them (unknown fields are dropped) and keep their
+// SchemaVersion, so callers can tell.
+func DecodeSnapshot(b []byte) (*SupplySnapshot, error) {
+	var s SupplySnapshot
+	if err := decode(b, snapshotUpgrades, &s); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	return &s, nil
+}
+
+// DecodePoint decodes a stored SupplyPoint of any schema version (see DecodeSnapshot).
+func DecodePoint(b []byte) (SupplyPoint, error) {
+	var p SupplyPoint
+	if err := decode(b, pointUpgrades, &p); err != nil {
+		return SupplyPoint{}, fmt.Errorf("decode point: %w", err)
+	}
+	return p, nil
+}
+
+// decode reads b's schema_version (0 when absent), runs the upgrades from there to
+// SchemaVersion and decodes the result into v. Current and newer documents are decoded
+// directly.
+func decode(b []byte, upgrades map[int]upgrade, v any) error {
+	var head struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(b, &head); err != nil {
+		return err
+	}
+	if head.SchemaVersion >= SchemaVersion {
+		return json.Unmarshal(b, v)
+	}
+	var r record
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	for ver := head.SchemaVersion; ver < SchemaVersion; ver++ {
+		up, ok := upgrades[ver]
+		if !ok {
+			return fmt.Errorf("no upgrade from schema version %d", ver)
+		}
+		if err := up(r); err != nil {
+			return fmt.Errorf("upgrade from schema version %d: %w", ver, err)
+		}
+		r["schema_version"] = json.RawMessage(strconv.Itoa(ver + 1))
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -1,10 +1,22 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
+
+// SchemaVersion versions the snapshot data model and response semantics. It is an
+// ETag input, so bumping it makes every client refetch. Stored snapshots and points
+// record the version they were written with; bumping it needs an upgrade step in
+// decode.go so they stay readable.
+const SchemaVersion = 1
 
 // SupplySnapshot is an atomic snapshot of supply-related figures for a given block height.
 // All values are in base denom units as strings to avoid float rounding; use integers in atoms.
 type SupplySnapshot struct {
+	// SchemaVersion is the data model version the snapshot was computed with (see
+	// DecodeSnapshot).
+	SchemaVersion  int              `json:"schema_version"`
 	Denom          string           `json:"denom"`
 	Height         int64            `json:"height"`
 	UpdatedAt      time.Time        `json:"updated_at"`
@@ -14,6 +26,185 @@ type SupplySnapshot struct {
 	Circulating    string           `json:"circulating"`
 	Max            *string          `json:"max"`
 	NonCirculating NonCircBreakdown `json:"non_circulating"`
+	// EvaluatedAt is the instant locks were evaluated at: the block time (UpdatedAt),
+	// except for what-if snapshots (see supply.Computer.ComputeSnapshotAt). Zero in
+	// snapshots stored by older versions.
+	EvaluatedAt time.Time `json:"evaluated_at"`
+	// ComputeStats describes the work that produced the snapshot (nil for snapshots
+	// not computed by this process version).
+	ComputeStats *ComputeStats `json:"compute_stats,omitempty"`
+	// Burns are the tracked supply events up to this height (nil unless burn tracking
+	// is enabled for the denom).
+	Burns *BurnTotals `json:"burns,omitempty"`
+	// Overlaps are the addresses counted by several cohorts, before any policy dedupe.
+	Overlaps []CohortOverlap `json:"overlaps,omitempty"`
+	// Anomaly is set when the figures are inconsistent (only published when configured).
+	Anomaly *SupplyAnomaly `json:"anomaly,omitempty"`
+	// Staked is the staking pool (nil when Denom is not the bond denom or the pool could
+	// not be fetched).
+	Staked *StakedSupply `json:"staked,omitempty"`
+	// Display is the unit the chain's bank metadata gives for Denom (nil when the chain
+	// has none, or for snapshots stored by older versions).
+	Display *DisplayUnit `json:"display,omitempty"`
+}
+
+// EvaluationTime returns EvaluatedAt, or the block time for snapshots without it.
+func (s *SupplySnapshot) EvaluationTime() time.Time {
+	if s.EvaluatedAt.IsZero() {
+		return s.UpdatedAt
+	}
+	return s.EvaluatedAt
+}
+
+// PolicyLoaded reports whether the snapshot was computed with a policy. Without one
+// only the built-in cohorts (IBC escrow, community pool) are non-circulating, so
+// circulating supply is overstated.
+func (s *SupplySnapshot) PolicyLoaded() bool {
+	return s.PolicyETag != ""
+}
+
+// Explained reports whether s carries Explain traces (see supply.Computer.ExplainSnapshot).
+// An explained snapshot has the ETag of the figures it traces, so it must not be cached
+// in their place.
+func (s *SupplySnapshot) Explained() bool {
+	for _, c := range s.NonCirculating.Cohorts {
+		if c.Explain != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CanonicalJSON encodes the snapshot as compact JSON followed by a newline: fields in
+// declaration order, cohorts and items in the order the computer sorted them. Callers
+// wanting every item must hydrate offloaded cohorts first (see cache.SnapshotCache).
+func (s *SupplySnapshot) CanonicalJSON() ([]byte, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// AnomalyNegativeCirculating marks snapshots whose non-circulating cohorts sum to more
+// than total supply.
+const AnomalyNegativeCirculating = "negative_circulating"
+
+// SupplyAnomaly explains an inconsistent snapshot.
+type SupplyAnomaly struct {
+	Kind    string `json:"kind" enum:"negative_circulating"`
+	Message string `json:"message"`
+	// Overlaps are addresses counted by several cohorts, a common cause of overstated
+	// non-circulating supply.
+	Overlaps []CohortOverlap `json:"overlaps,omitempty"`
+}
+
+// CohortOverlap is an address whose balance more than one cohort counts.
+type CohortOverlap struct {
+	Address string   `json:"address"`
+	Cohorts []string `json:"cohorts"`
+	// CountedIn is the one cohort still counting the address after policy dedupe.
+	CountedIn string `json:"counted_in,omitempty"`
+}
+
+// Invariants checked on every computed snapshot (see supply.Computer.Diagnostics).
+const (
+	// InvariantCirculatingNonNegative: non-circulating cohorts sum to at most total supply.
+	InvariantCirculatingNonNegative = "circulating_non_negative"
+	// InvariantCohortAmount: every cohort amount is a non-negative integer.
+	InvariantCohortAmount = "cohort_amount"
+	// InvariantCohortItemsSum: a cohort with items amounts to the sum of its items.
+	InvariantCohortItemsSum = "cohort_items_sum"
+)
+
+// InvariantViolation is an invariant a computed snapshot breaks.
+type InvariantViolation struct {
+	Invariant string `json:"invariant" enum:"circulating_non_negative,cohort_amount,cohort_items_sum"`
+	// Cohort is the offending cohort, for per-cohort invariants.
+	Cohort  string `json:"cohort,omitempty"`
+	Message string `json:"message"`
+}
+
+// CohortFailure is a failed upstream fetch while computing a cohort. The cohort was
+// skipped, or, for per-address fetches, computed without the failed addresses.
+type CohortFailure struct {
+	Cohort string `json:"cohort"`
+	// Kind classifies the failure: not_found, upstream, panic or other.
+	Kind string `json:"kind" enum:"not_found,upstream,panic,other"`
+	// Fetch describes what was being fetched.
+	Fetch string `json:"fetch"`
+	// Failed and Of count failed per-address fetches (Of is 0 for single fetches).
+	Failed int    `json:"failed"`
+	Of     int    `json:"of,omitempty"`
+	Error  string `json:"error"`
+}
+
+// Diagnostics describes the latest snapshot computation for a denom, published or not:
+// the invariants it broke, the cohort fetches that failed and the addresses several
+// cohorts count. It explains figures that look wrong, or a snapshot that was rejected.
+type Diagnostics struct {
+	Denom      string    `json:"denom"`
+	Height     int64     `json:"height,omitempty"`
+	ComputedAt time.Time `json:"computed_at"`
+	// Published is false when the computation failed or the snapshot was rejected;
+	// Error then says why, and the previous snapshot is still served.
+	Published      bool                 `json:"published"`
+	Error          string               `json:"error,omitempty"`
+	Circulating    string               `json:"circulating,omitempty"`
+	NonCirculating string               `json:"non_circulating,omitempty"`
+	Invariants     []InvariantViolation `json:"invariants"`
+	CohortFailures []CohortFailure      `json:"cohort_failures"`
+	Overlaps       []CohortOverlap      `json:"overlaps"`
+}
+
+// BurnTotals are cumulative amounts from block results in (FromHeight, Height]: bank
+// mints and burns, and transfers to the policy's burn addresses. Height trails the
+// snapshot height while the tracker catches up.
+type BurnTotals struct {
+	FromHeight      int64  `json:"from_height"`
+	Height          int64  `json:"height"`
+	Minted          string `json:"minted"`
+	Burned          string `json:"burned"`
+	BurnTransferred string `json:"burn_transferred"`
+}
+
+// StakedSupply is the staking pool at the snapshot height: tokens bonded to active
+// validators, and tokens of unbonded validators and unbonding delegations.
+type StakedSupply struct {
+	Bonded    string `json:"bonded"`
+	NotBonded string `json:"not_bonded"`
+}
+
+// DisplayUnit is the unit a denom's amounts are shown in: one Denom ("lume") is
+// 10^Decimals base units.
+type DisplayUnit struct {
+	Denom    string `json:"denom"`
+	Decimals int    `json:"decimals"`
+}
+
+// ComputeStats is the cost of one snapshot computation, for spotting performance
+// regressions as the policy grows.
+type ComputeStats struct {
+	DurationMS int64 `json:"duration_ms"`
+	// LCDCalls counts HTTP requests to the primary LCD/RPC, quorum re-reads included.
+	LCDCalls  int64 `json:"lcd_calls"`
+	Retries   int64 `json:"retries"`
+	CacheHits int64 `json:"cache_hits"`
+}
+
+// AddressGroup is metadata the policy shares between disclosed addresses: who controls
+// them, notes, and links to the public disclosures covering them.
+type AddressGroup struct {
+	Custody string   `json:"custody,omitempty"`
+	Notes   string   `json:"notes,omitempty"`
+	Links   []string `json:"links,omitempty"`
+}
+
+// TierTotal is the part of a cohort locked under one tier.
+type TierTotal struct {
+	Tier      int    `json:"tier"`
+	Amount    string `json:"amount"`
+	ItemCount int    `json:"item_count"`
 }
 
 type NonCircBreakdown struct {
@@ -24,19 +215,228 @@ type NonCircBreakdown struct {
 // AddressItem represents per-address details for cohorts that require per-address reporting
 // (e.g., foundation_genesis, claim_delayed, supernode_bootstraps).
 // EndDate uses RFC3339 when applicable; for permanent locks, use "forever".
+// EndUnix and Permanent carry the same information for programmatic consumers.
 type AddressItem struct {
-	Address string `json:"address"`
+	Address   string `json:"address"`
+	Amount    string `json:"amount"`
+	EndDate   string `json:"end_date,omitempty"`
+	EndUnix   int64  `json:"end_unix,omitempty"`
+	Permanent bool   `json:"permanent"`
+	// Source is where Amount comes from: the on-chain vesting schedule
+	// ("auth_vesting"), or a fallback when the chain has none ("policy_permanent_fallback",
+	// "policy_delayed_fallback", "claim_record", "balance_fallback").
+	Source string `json:"source,omitempty"`
+	// Tier is the claim tier of claim_delayed items (1 to 4, locked 6, 12, 18 or 24
+	// months after the claim); 0 elsewhere.
+	Tier int `json:"tier,omitempty"`
+	// AddressGroup names the policy address group of the entry the item comes from; its
+	// metadata is in the cohort's AddressGroups.
+	AddressGroup string `json:"address_group,omitempty"`
+	// JailedValidators lists jailed (or tombstoned) validators the address delegates to.
+	JailedValidators []string `json:"jailed_validators,omitempty"`
+	// SlashedLocked is the part of the scheduled locked amount no longer held because
+	// of slashing; Amount is already reduced by it.
+	SlashedLocked string `json:"slashed_locked,omitempty"`
+	// Rewards are the address's unclaimed staking rewards. They are spendable and
+	// therefore circulating; reported for reconciliation only.
+	Rewards string `json:"rewards,omitempty"`
+	// Explain is how Amount was computed; only explain runs fill it in (see
+	// supply.Computer.ExplainSnapshot).
+	Explain *Explanation `json:"explain,omitempty"`
+	// Schedule is how Amount releases when it does so gradually (continuous and periodic
+	// vesting accounts); without one it releases at once at EndUnix.
+	Schedule *LockSchedule `json:"schedule,omitempty"`
+}
+
+// LockSchedule is the on-chain vesting schedule an item's lock follows after the
+// evaluation instant, for unlock projections (see supply.UnlockSchedule).
+type LockSchedule struct {
+	// Kind is "continuous" (OriginalVesting releases linearly from StartUnix to EndUnix)
+	// or "periodic" (each of Periods releases at its end).
+	Kind            string `json:"kind" enum:"continuous,periodic"`
+	OriginalVesting string `json:"original_vesting,omitempty"`
+	StartUnix       int64  `json:"start_unix,omitempty"`
+	EndUnix         int64  `json:"end_unix,omitempty"`
+	// Periods are the periods still locked at the evaluation instant, in order.
+	Periods []LockPeriod `json:"periods,omitempty"`
+}
+
+// LockPeriod is one step of a periodic vesting schedule.
+type LockPeriod struct {
+	EndUnix int64  `json:"end_unix"`
 	Amount  string `json:"amount"`
-	EndDate string `json:"end_date,omitempty"`
+}
+
+// Explanation traces an amount to the data it was computed from: the source, the raw
+// inputs read from it, and the formula applied to them.
+type Explanation struct {
+	Source  string            `json:"source"`
+	Inputs  map[string]string `json:"inputs,omitempty"`
+	Formula string            `json:"formula"`
+}
+
+// AccountInspection is an address's on-chain account as a snapshot sees it: balances at
+// the snapshot height and the vesting lock evaluated at its evaluation instant.
+type AccountInspection struct {
+	Address string `json:"address"`
+	// AccountType is the account's @type; empty when the chain has no account for the
+	// address.
+	AccountType     string `json:"account_type"`
+	Balance         string `json:"balance"`
+	Spendable       string `json:"spendable"`
+	OriginalVesting string `json:"original_vesting"`
+	Locked          string `json:"locked"`
+	EndDate         string `json:"end_date,omitempty"`
+	EndUnix         int64  `json:"end_unix,omitempty"`
+	Permanent       bool   `json:"permanent"`
+	// Vesting traces Locked to the account's schedule; nil without an account.
+	Vesting *Explanation `json:"vesting,omitempty"`
 }
 
 type CohortEntry struct {
-	Name   string `json:"name"`
+	Name string `json:"name"`
+	// ID (0 when the policy assigns none) and Slug are the policy's stable identifiers
+	// for the cohort; key downstream models on them rather than on Name.
+	ID     int    `json:"id,omitempty"`
+	Slug   string `json:"slug"`
 	Reason string `json:"reason"`
 	// Address is used for single-address cohorts (e.g., module accounts).
 	Address string `json:"address,omitempty"`
-	// Items is used to list per-address details for cohorts.
+	// Items is used to list per-address details for cohorts. It may be nil in memory
+	// while ItemCount > 0 when items were offloaded to the store (see cache.Hydrate).
 	Items []AddressItem `json:"items,omitempty"`
+	// ItemCount is the number of per-address items in the cohort.
+	ItemCount int `json:"item_count,omitempty"`
 	// Amount is the total amount for the cohort (sum of items when present).
 	Amount string `json:"amount"`
+	// Tiers splits Amount by item tier, for cohorts whose items have one (claim_delayed).
+	Tiers []TierTotal `json:"tiers,omitempty"`
+	// AddressGroups holds the metadata of the address groups its items name.
+	AddressGroups map[string]AddressGroup `json:"address_groups,omitempty"`
+	// Tags are reporting categories from policy (e.g., "protocol", "investors").
+	Tags []string `json:"tags,omitempty"`
+	// DisclosureURL and Notes are the policy's justification for counting the cohort
+	// as non-circulating: a link to the public document and a short explanation.
+	DisclosureURL string `json:"disclosure_url,omitempty"`
+	Notes         string `json:"notes,omitempty"`
+	// AsOfHeight and AsOfTime are the height and block time the cohort was computed at.
+	// They trail the snapshot for cohorts with a policy refresh interval.
+	AsOfHeight int64     `json:"as_of_height,omitempty"`
+	AsOfTime   time.Time `json:"as_of_time"`
+	// Explain is how Amount was computed, as on AddressItem.
+	Explain *Explanation `json:"explain,omitempty"`
+}
+
+// UnlockEvent aggregates what the items of a snapshot release at the same instant (or,
+// grouped, within the same period starting at Time).
+type UnlockEvent struct {
+	Time      time.Time      `json:"time"`
+	Amount    string         `json:"amount"`
+	Addresses int            `json:"addresses"`
+	Cohorts   []CohortAmount `json:"cohorts"`
+}
+
+// CohortAmount is one cohort's share of an UnlockEvent.
+type CohortAmount struct {
+	Cohort     string `json:"cohort"`
+	CohortID   int    `json:"cohort_id,omitempty"`
+	CohortSlug string `json:"cohort_slug"`
+	Amount     string `json:"amount"`
+}
+
+// SupplyPoint is a stored snapshot's headline figures: one point of a /history series.
+// Time is the snapshot's block time.
+type SupplyPoint struct {
+	// SchemaVersion is the data model version the point was written with.
+	SchemaVersion  int       `json:"schema_version"`
+	Time           time.Time `json:"time"`
+	Height         int64     `json:"height"`
+	ETag           string    `json:"etag"`
+	Denom          string    `json:"denom"`
+	Total          string    `json:"total"`
+	Circulating    string    `json:"circulating"`
+	NonCirculating string    `json:"non_circulating"`
+	Max            *string   `json:"max,omitempty"`
+}
+
+// PointOf returns snap's SupplyPoint.
+func PointOf(snap *SupplySnapshot) SupplyPoint {
+	return SupplyPoint{SchemaVersion: SchemaVersion, Time: snap.UpdatedAt.UTC(), Height: snap.Height, ETag: snap.ETag, Denom: snap.Denom,
+		Total: snap.Total, Circulating: snap.Circulating, NonCirculating: snap.NonCirculating.Sum, Max: snap.Max}
+}
+
+// SupplyProjection is a supply curve projected from one chain state (see
+// supply.Computer.Simulate): balances, claims and the total stay as they were at
+// Height; only time passes, unlocking what the schedules release.
+type SupplyProjection struct {
+	Denom     string            `json:"denom"`
+	Height    int64             `json:"height"`
+	BlockTime time.Time         `json:"block_time"`
+	Points    []ProjectionPoint `json:"points"`
+}
+
+// ProjectionPoint is the supply with every lock evaluated at Time.
+type ProjectionPoint struct {
+	Time           time.Time      `json:"time"`
+	Total          string         `json:"total"`
+	Circulating    string         `json:"circulating"`
+	NonCirculating string         `json:"non_circulating"`
+	Cohorts        []CohortAmount `json:"cohorts"`
+}
+
+// SnapshotRef identifies a stored snapshot.
+type SnapshotRef struct {
+	ETag       string    `json:"etag"`
+	PolicyETag string    `json:"policy-etag,omitempty"`
+	Height     int64     `json:"height"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AmountDelta is a before/after pair of base-unit amounts and their signed difference.
+type AmountDelta struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Delta string `json:"delta"`
+}
+
+// SnapshotDiff lists the differences between two snapshots of the same denom.
+type SnapshotDiff struct {
+	Denom          string       `json:"denom"`
+	From           SnapshotRef  `json:"from"`
+	To             SnapshotRef  `json:"to"`
+	Total          AmountDelta  `json:"total"`
+	Circulating    AmountDelta  `json:"circulating"`
+	NonCirculating AmountDelta  `json:"non_circulating"`
+	Cohorts        []CohortDiff `json:"cohorts"`
+	// Attribution splits the circulating delta by cause; amounts sum to Circulating.Delta.
+	Attribution []Attribution `json:"attribution"`
+}
+
+// Attribution is one cause's signed contribution to the circulating delta.
+type Attribution struct {
+	// Cause is one of vesting_unlock, new_claims, new_lock, module_balance,
+	// community_pool, ibc_flows, mint_burn, other.
+	Cause   string   `json:"cause"`
+	Amount  string   `json:"amount"`
+	Cohorts []string `json:"cohorts,omitempty"`
+}
+
+// CohortDiff describes one cohort that was added, removed, or changed.
+type CohortDiff struct {
+	Name string `json:"name"`
+	ID   int    `json:"id,omitempty"`
+	Slug string `json:"slug"`
+	// Status is "added", "removed", or "changed".
+	Status  string        `json:"status"`
+	Amount  AmountDelta   `json:"amount"`
+	Added   []AddressItem `json:"added,omitempty"`
+	Removed []AddressItem `json:"removed,omitempty"`
+	Changed []ItemDelta   `json:"changed,omitempty"`
+}
+
+// ItemDelta is an item (same address and end date) whose amount changed.
+type ItemDelta struct {
+	Address string `json:"address"`
+	EndDate string `json:"end_date,omitempty"`
+	AmountDelta
 }
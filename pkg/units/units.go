@@ -0,0 +1,67 @@
+// Package units formats base-denom amounts for display.
+package units
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Symbol maps a base denom to its display symbol (ulume -> LUME).
+func Symbol(denom string) string {
+	if len(denom) > 1 && denom[0] == 'u' && !strings.Contains(denom, "/") {
+		return strings.ToUpper(denom[1:])
+	}
+	return denom
+}
+
+// Format renders a base-unit integer string with decimals and thousands
+// separators, trimming trailing fractional zeros (1234500000 -> 1,234.5).
+func Format(base string, decimals int) string {
+	v, ok := new(big.Int).SetString(base, 10)
+	if !ok {
+		return base
+	}
+	neg := v.Sign() < 0
+	v.Abs(v)
+	q, m := new(big.Int).QuoRem(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil), new(big.Int))
+	whole := q.String()
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	if decimals == 0 {
+		return b.String()
+	}
+	ms := m.String()
+	if frac := strings.TrimRight(strings.Repeat("0", decimals-len(ms))+ms, "0"); frac != "" {
+		b.WriteByte('.')
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// Human renders a base-unit amount in display units with a K/M/B/T suffix and one
+// decimal (123456789000000 with 6 decimals -> 123.5M).
+func Human(base string, decimals int) string {
+	v, ok := new(big.Rat).SetString(base)
+	if !ok {
+		return base
+	}
+	v.Quo(v, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	abs := new(big.Rat).Abs(v)
+	for _, u := range []struct {
+		suffix string
+		scale  int64
+	}{{"T", 1e12}, {"B", 1e9}, {"M", 1e6}, {"K", 1e3}} {
+		if abs.Cmp(big.NewRat(u.scale, 1)) >= 0 {
+			return new(big.Rat).Quo(v, big.NewRat(u.scale, 1)).FloatString(1) + u.suffix
+		}
+	}
+	return v.FloatString(1)
+}
@@ -5,11 +5,39 @@ import (
 	"time"
 )
 
+// Clock supplies the current time. Injecting one makes evaluations deterministic: the
+// supply computer evaluates every lock at the snapshot's block time through a
+// FixedClock, and tests pin or advance time without sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock reads the wall clock.
+var SystemClock Clock = systemClock{}
+
+// FixedClock always returns the same instant.
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time { return time.Time(c) }
+
 // Engine exposes methods to compute locked amount at a point in time for different vesting types.
 // All amounts are strings of integer base units.
-type Engine struct{}
+type Engine struct {
+	clock Clock
+}
+
+// NewEngine returns an engine evaluating at wall time.
+func NewEngine() *Engine { return &Engine{clock: SystemClock} }
+
+// NewEngineWithClock returns an engine whose evaluation instant (Now) comes from clock.
+func NewEngineWithClock(clock Clock) *Engine { return &Engine{clock: clock} }
 
-func NewEngine() *Engine { return &Engine{} }
+// Now is the instant callers should evaluate locks at.
+func (e *Engine) Now() time.Time { return e.clock.Now() }
 
 // DelayedLocked - nothing unlocked until End; at End all unlocked.
 func (e *Engine) DelayedLocked(total string, now, end time.Time) string {
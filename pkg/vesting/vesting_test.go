@@ -13,6 +13,20 @@ func mustTime(s string) time.Time {
 	return t
 }
 
+func TestEngineClock(t *testing.T) {
+	at := mustTime("2024-03-01T00:00:00Z")
+	e := NewEngineWithClock(FixedClock(at))
+	if !e.Now().Equal(at) {
+		t.Fatalf("Now() = %s, want %s", e.Now(), at)
+	}
+	if got := e.DelayedLocked("1000", e.Now(), mustTime("2024-06-01T00:00:00Z")); got != "1000" {
+		t.Fatalf("expected 1000 locked at the fixed instant, got %s", got)
+	}
+	if NewEngine().Now().IsZero() {
+		t.Fatal("default engine should read the wall clock")
+	}
+}
+
 func TestDelayedLocked(t *testing.T) {
 	e := NewEngine()
 	now := mustTime("2024-01-01T00:00:00Z")
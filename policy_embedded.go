@@ -0,0 +1,12 @@
+//go:build embedpolicy
+
+// Package lumerasupply holds files of the repository root that are compiled into the
+// binaries, such as the default policy.
+package lumerasupply
+
+import _ "embed"
+
+// EmbeddedPolicy is policy.json as of the build, compiled in with -tags embedpolicy.
+//
+//go:embed policy.json
+var EmbeddedPolicy []byte
@@ -0,0 +1,9 @@
+//go:build !embedpolicy
+
+// Package lumerasupply holds files of the repository root that are compiled into the
+// binaries, such as the default policy.
+package lumerasupply
+
+// EmbeddedPolicy is empty unless the binary is built with -tags embedpolicy, which
+// compiles in policy.json.
+var EmbeddedPolicy []byte
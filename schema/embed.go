@@ -1,7 +1,16 @@
 package schema
 
-import _ "embed"
+import "embed"
+
+//go:generate go run ./gen -out json
 
 // OpenAPI holds the embedded OpenAPI (Swagger) YAML for the Lumera Supply API.
+//
 //go:embed openapi.yaml
 var OpenAPI []byte
+
+// Responses holds the generated JSON Schema documents for response payloads,
+// one file per endpoint under json/ (e.g., json/total.json).
+//
+//go:embed json/*.json
+var Responses embed.FS
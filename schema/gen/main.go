@@ -0,0 +1,31 @@
+// Command gen writes JSON Schema documents for every HTTP response payload.
+// It is invoked via `go generate ./schema`.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/lumera-labs/lumera-supply/pkg/httpserver"
+	"github.com/lumera-labs/lumera-supply/schema"
+)
+
+func main() {
+	out := flag.String("out", "json", "Output directory for generated schemas")
+	flag.Parse()
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("mkdir %s: %v", *out, err)
+	}
+	for name, v := range httpserver.ResponseTypes() {
+		b, err := schema.JSONSchema(name, v, httpserver.SchemaSiblings()...)
+		if err != nil {
+			log.Fatalf("schema %s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(*out, name+".json"), b, 0o644); err != nil {
+			log.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
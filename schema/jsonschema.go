@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// A Sibling is a field responses add on request next to every property named Field
+// (e.g. "amount_numeric" next to "amount"): its name is Field, "_" and a match of the
+// regular expression Suffix, its value matches Schema.
+type Sibling struct {
+	Field  string
+	Suffix string
+	Schema map[string]any
+}
+
+// JSONSchema builds a JSON Schema (draft 2020-12) document describing the JSON
+// encoding of v, honoring encoding/json struct tags. Fields without omitempty are
+// marked required; pointer fields are nullable. An `enum:"a,b"` tag restricts a field
+// to the listed values. Objects allow the siblings of their properties as pattern
+// properties.
+func JSONSchema(title string, v any, siblings ...Sibling) ([]byte, error) {
+	doc := typeSchema(reflect.TypeOf(v), siblings)
+	doc["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	doc["title"] = title
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func typeSchema(t reflect.Type, sib []Sibling) map[string]any {
+	if t == nil {
+		return map[string]any{}
+	}
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawType:
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := typeSchema(t.Elem(), sib)
+		if typ, ok := s["type"].(string); ok {
+			s["type"] = []string{typ, "null"}
+		}
+		return s
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), sib)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), sib)}
+	case reflect.Struct:
+		return structSchema(t, sib)
+	default:
+		return map[string]any{}
+	}
+}
+
+// enumValues converts enum tag values to the field's JSON type.
+func enumValues(t reflect.Type, vals []string) []any {
+	out := make([]any, 0, len(vals))
+	for _, v := range vals {
+		if t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64 {
+			if n, err := strconv.Atoi(v); err == nil {
+				out = append(out, n)
+				continue
+			}
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func structSchema(t reflect.Type, sib []Sibling) map[string]any {
+	props := map[string]any{}
+	patterns := map[string]any{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fs := typeSchema(f.Type, sib)
+		if e := f.Tag.Get("enum"); e != "" {
+			fs["enum"] = enumValues(f.Type, strings.Split(e, ","))
+		}
+		props[name] = fs
+		for _, sb := range sib {
+			if sb.Field == name {
+				patterns["^"+name+"_"+sb.Suffix+"$"] = sb.Schema
+			}
+		}
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(patterns) > 0 {
+		s["patternProperties"] = patterns
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
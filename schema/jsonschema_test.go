@@ -0,0 +1,65 @@
+package schema_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lumera-labs/lumera-supply/pkg/httpserver"
+	"github.com/lumera-labs/lumera-supply/schema"
+)
+
+// TestGeneratedSchemasUpToDate fails when response structs changed without re-running `go generate ./schema`.
+func TestGeneratedSchemasUpToDate(t *testing.T) {
+	for name, v := range httpserver.ResponseTypes() {
+		want, err := schema.JSONSchema(name, v, httpserver.SchemaSiblings()...)
+		if err != nil {
+			t.Fatalf("schema %s: %v", name, err)
+		}
+		got, err := schema.Responses.ReadFile("json/" + name + ".json")
+		if err != nil {
+			t.Fatalf("missing generated schema for %s: %v", name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("schema for %s is stale; run go generate ./schema", name)
+		}
+	}
+}
+
+func TestEnumTag(t *testing.T) {
+	type payload struct {
+		Version int    `json:"version" enum:"1"`
+		State   string `json:"state" enum:"up,down"`
+	}
+	b, err := schema.JSONSchema("p", payload{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"enum": [
+        1
+      ]`, `"enum": [
+        "up",
+        "down"
+      ]`} {
+		if !bytes.Contains(b, []byte(want)) {
+			t.Fatalf("schema missing %s:\n%s", want, b)
+		}
+	}
+}
+
+func TestSiblingPatterns(t *testing.T) {
+	type payload struct {
+		Amount string `json:"amount"`
+		Height int64  `json:"height"`
+	}
+	b, err := schema.JSONSchema("p", payload{}, schema.Sibling{Field: "amount", Suffix: "numeric", Schema: map[string]any{"type": "number"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b, []byte(`"patternProperties": {
+    "^amount_numeric$": {
+      "type": "number"
+    }
+  }`)) {
+		t.Fatalf("schema missing the sibling pattern:\n%s", b)
+	}
+}